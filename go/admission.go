@@ -0,0 +1,169 @@
+package gwp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// AdmissionConfig limits the statements a connection or session sends, to
+// keep a misbehaving caller from overloading a shared server. Statements of
+// a session count against both the session's and the connection's limits.
+type AdmissionConfig struct {
+	// MaxInFlight limits statements sent whose cursors have not finished.
+	// Zero means no limit.
+	MaxInFlight int
+	// Rate limits statements started per second, with bursts of up to
+	// Burst statements. Zero means no limit.
+	Rate float64
+	// Burst defaults to Rate rounded up, and to at least 1.
+	Burst int
+	// FailFast rejects statements over a limit with an *AdmissionError.
+	// Otherwise they wait for capacity until their context is done.
+	FailFast bool
+}
+
+// AdmissionError is returned for a statement rejected by an AdmissionConfig
+// with FailFast set.
+type AdmissionError struct {
+	// Limit is "in_flight" or "rate".
+	Limit string
+	// RetryAfter is how long until the rate limit admits a statement;
+	// zero for the in-flight limit.
+	RetryAfter time.Duration
+}
+
+func (e *AdmissionError) Error() string {
+	if e.Limit == "rate" {
+		return fmt.Sprintf("statement rejected: rate limit exceeded, retry in %s", e.RetryAfter.Round(time.Millisecond))
+	}
+	return "statement rejected: too many statements in flight"
+}
+
+// WithAdmission limits the statements of the session, in addition to the
+// connection's ConnectionConfig.Admission.
+func WithAdmission(config AdmissionConfig) SessionOption {
+	return func(o *sessionOptions) {
+		o.admission = &config
+	}
+}
+
+// limiter enforces an AdmissionConfig: a semaphore for the in-flight limit
+// and a token bucket for the rate.
+type limiter struct {
+	config AdmissionConfig
+	slots  chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(config AdmissionConfig) *limiter {
+	l := &limiter{config: config}
+	if config.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, config.MaxInFlight)
+	}
+	if config.Rate > 0 {
+		if l.config.Burst <= 0 {
+			l.config.Burst = max(1, int(math.Ceil(config.Rate)))
+		}
+		l.tokens = float64(l.config.Burst)
+	}
+	return l
+}
+
+// acquire waits for a rate token and an in-flight slot, and returns a
+// function that gives the slot back.
+func (l *limiter) acquire(ctx context.Context) (release func(), err error) {
+	if l.config.Rate > 0 {
+		if err := l.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		if l.config.FailFast {
+			return nil, &AdmissionError{Limit: "in_flight"}
+		}
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-l.slots }) }, nil
+}
+
+// wait takes a token from the bucket, waiting for one to accrue unless
+// FailFast is set.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(float64(l.config.Burst), l.tokens+now.Sub(l.last).Seconds()*l.config.Rate)
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration((1 - l.tokens) / l.config.Rate * float64(time.Second))
+	if l.config.FailFast {
+		l.mu.Unlock()
+		return &AdmissionError{Limit: "rate", RetryAfter: delay}
+	}
+	// Reserve the token now so that waiters are admitted in order.
+	l.tokens--
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// admit applies the session's and the connection's admission limits to a
+// statement, and returns a function to call once it has finished.
+func (s *GqlSession) admit(ctx context.Context) (release func(), err error) {
+	var releases []func()
+	release = func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+	for _, l := range []*limiter{s.limiter, s.connLimiter()} {
+		if l == nil {
+			continue
+		}
+		r, err := l.acquire(ctx)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+	return release, nil
+}
+
+func (s *GqlSession) connLimiter() *limiter {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.limiter
+}
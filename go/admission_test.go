@@ -0,0 +1,134 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func TestLimiterInFlight(t *testing.T) {
+	ctx := context.Background()
+	l := newLimiter(AdmissionConfig{MaxInFlight: 1})
+	release, err := l.acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire over the limit = %v", err)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		r, err := l.acquire(ctx)
+		if err == nil {
+			r()
+		}
+		acquired <- err
+	}()
+	release()
+	release()
+	if err := <-acquired; err != nil {
+		t.Fatal(err)
+	}
+
+	l = newLimiter(AdmissionConfig{MaxInFlight: 1, FailFast: true})
+	l.acquire(ctx)
+	var admission *AdmissionError
+	if _, err := l.acquire(ctx); !errors.As(err, &admission) || admission.Limit != "in_flight" {
+		t.Fatalf("fail-fast acquire = %v", err)
+	}
+}
+
+func TestLimiterRate(t *testing.T) {
+	ctx := context.Background()
+	l := newLimiter(AdmissionConfig{Rate: 50, Burst: 2, FailFast: true})
+	for i := 0; i < 2; i++ {
+		if _, err := l.acquire(ctx); err != nil {
+			t.Fatalf("burst %d: %v", i, err)
+		}
+	}
+	var admission *AdmissionError
+	if _, err := l.acquire(ctx); !errors.As(err, &admission) || admission.Limit != "rate" || admission.RetryAfter <= 0 {
+		t.Fatalf("acquire over the rate = %v", err)
+	}
+
+	l = newLimiter(AdmissionConfig{Rate: 50, Burst: 1})
+	l.acquire(ctx)
+	start := time.Now()
+	if _, err := l.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 10*time.Millisecond {
+		t.Fatalf("second statement admitted after %s, want about 20ms", d)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := l.acquire(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("acquire with cancelled context = %v", err)
+	}
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -1 {
+		t.Fatalf("cancelled wait kept its token: %v", tokens)
+	}
+}
+
+func TestSessionAdmission(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeGqlClient{}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, limiter: newLimiter(AdmissionConfig{MaxInFlight: 1, FailFast: true})}
+
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("n"), summaryFrame(Success, 0)}}
+	cursor, err := s.Execute(ctx, "RETURN 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var admission *AdmissionError
+	if _, err := s.Execute(ctx, "RETURN 2", nil); !errors.As(err, &admission) {
+		t.Fatalf("second statement = %v", err)
+	}
+	if _, err := cursor.CollectRows(); err != nil {
+		t.Fatal(err)
+	}
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("n"), summaryFrame(Success, 0)}}
+	if _, err := s.Execute(ctx, "RETURN 3", nil); err != nil {
+		t.Fatalf("statement after the first finished = %v", err)
+	}
+}
+
+func TestAdmissionReleasedByCloseAndCancel(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeGqlClient{}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, limiter: newLimiter(AdmissionConfig{MaxInFlight: 1, FailFast: true})}
+
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("n"), batchFrame([]any{int64(1)}), batchFrame([]any{int64(2)}), summaryFrame(Success, 0)}}
+	cursor, err := s.Execute(ctx, "RETURN 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cursor.NextRow(); err != nil {
+		t.Fatal(err)
+	}
+	cursor.Close()
+	cursor.Close()
+
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("n"), batchFrame([]any{int64(1)}), summaryFrame(Success, 0)}}
+	cursor, err = s.Execute(ctx, "RETURN 2", nil)
+	if err != nil {
+		t.Fatalf("statement after Close = %v", err)
+	}
+	cursor.Cancel()
+
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("n"), summaryFrame(Success, 0)}}
+	if _, err := s.Execute(ctx, "RETURN 3", nil); err != nil {
+		t.Fatalf("statement after Cancel = %v", err)
+	}
+}
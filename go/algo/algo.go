@@ -0,0 +1,295 @@
+// Package algo runs common graph traversals without hand-written GQL.
+//
+//	alice := algo.Node("Person", "name", "Alice")
+//	bob := algo.Node("Person", "name", "Bob")
+//	path, err := algo.ShortestPath(ctx, session, alice, bob, algo.Options{
+//		EdgeTypes: []string{"KNOWS"},
+//	})
+//
+// The functions generate parameterized GQL using quantified path patterns
+// and run it with an Executor, either a *gwp.GqlSession or a
+// *gwp.Transaction. Node values are sent as parameters; labels, property
+// keys and edge types are quoted as identifiers.
+package algo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// DefaultMaxDepth is the longest path ShortestPath searches when
+// Options.MaxDepth is zero.
+const DefaultMaxDepth = 15
+
+// Executor runs statements. *gwp.GqlSession and *gwp.Transaction implement
+// it.
+type Executor interface {
+	Execute(ctx context.Context, statement string, params map[string]any, opts ...gwp.ExecuteOption) (*gwp.ResultCursor, error)
+}
+
+// Direction restricts which edges a traversal follows.
+type Direction int
+
+const (
+	// Both follows edges in either direction.
+	Both Direction = iota
+	// Outgoing follows edges from their source to their target.
+	Outgoing
+	// Incoming follows edges from their target to their source.
+	Incoming
+)
+
+// NodeRef identifies the nodes a traversal starts or ends at: those with
+// Label whose Key property equals Value. An empty Label or Key matches any.
+type NodeRef struct {
+	Label string
+	Key   string
+	Value any
+}
+
+// Node returns a reference to the nodes with label whose key property
+// equals value.
+func Node(label, key string, value any) NodeRef {
+	return NodeRef{Label: label, Key: key, Value: value}
+}
+
+// Options restrict a traversal.
+type Options struct {
+	// EdgeTypes are the edge labels to follow. Empty follows any edge.
+	EdgeTypes []string
+	// Direction restricts the direction edges are followed in.
+	Direction Direction
+	// MaxDepth is the longest path ShortestPath searches. Defaults to
+	// DefaultMaxDepth.
+	MaxDepth int
+	// Limit, if positive, caps the number of paths Neighborhood reads.
+	Limit int
+}
+
+// Graph is a set of nodes and the edges between them.
+type Graph struct {
+	Nodes []*gwp.GqlNode
+	Edges []*gwp.GqlEdge
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id []byte) *gwp.GqlNode {
+	for _, n := range g.Nodes {
+		if n.ElementID() == gwp.NewElementID(id) {
+			return n
+		}
+	}
+	return nil
+}
+
+// DegreeCount is the number of nodes having a degree.
+type DegreeCount struct {
+	Degree int64
+	Nodes  int64
+}
+
+// ShortestPath returns a shortest path from a node matching from to one
+// matching to, or nil if there is none within opts.MaxDepth edges.
+func ShortestPath(ctx context.Context, ex Executor, from, to NodeRef, opts Options) (*gwp.GqlPath, error) {
+	stmt, params := shortestPathStatement(from, to, opts)
+	cursor, err := ex.Execute(ctx, stmt, params)
+	if err != nil {
+		return nil, err
+	}
+	row, err := cursor.NextRow()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cursor.Summary(); err != nil {
+		return nil, err
+	}
+	if len(row) == 0 || row[0] == nil {
+		return nil, nil
+	}
+	path, ok := row[0].(*gwp.GqlPath)
+	if !ok {
+		return nil, fmt.Errorf("algo: expected a path, got %T", row[0])
+	}
+	return path, nil
+}
+
+// Neighborhood returns the nodes matching node, every node within depth
+// edges of them, and the edges on the paths between. Each edge is followed
+// at most once per path.
+func Neighborhood(ctx context.Context, ex Executor, node NodeRef, depth int, opts Options) (*Graph, error) {
+	if depth < 0 {
+		return nil, fmt.Errorf("algo: negative depth %d", depth)
+	}
+	stmt, params := neighborhoodStatement(node, depth, opts)
+	cursor, err := ex.Execute(ctx, stmt, params)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := cursor.CollectRows()
+	if err != nil {
+		return nil, err
+	}
+	g := newGraphBuilder()
+	for _, row := range rows {
+		for _, v := range row {
+			if err := g.add(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &g.Graph, nil
+}
+
+// DegreeDistribution counts the nodes with label by degree, the number of
+// edges they have, in ascending order of degree. Nodes without edges have
+// degree 0.
+func DegreeDistribution(ctx context.Context, ex Executor, label string, opts Options) ([]DegreeCount, error) {
+	stmt := degreeStatement(label, opts)
+	cursor, err := ex.Execute(ctx, stmt, nil)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := cursor.CollectRows()
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]DegreeCount, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 2 {
+			return nil, fmt.Errorf("algo: unexpected degree row %v", row)
+		}
+		degree, ok1 := row[0].(int64)
+		nodes, ok2 := row[1].(int64)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("algo: unexpected degree row %v", row)
+		}
+		counts = append(counts, DegreeCount{Degree: degree, Nodes: nodes})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Degree < counts[j].Degree })
+	return counts, nil
+}
+
+func shortestPathStatement(from, to NodeRef, opts Options) (string, map[string]any) {
+	depth := opts.MaxDepth
+	if depth <= 0 {
+		depth = DefaultMaxDepth
+	}
+	params := map[string]any{}
+	var b strings.Builder
+	b.WriteString("MATCH p = ANY SHORTEST ")
+	writeNode(&b, "a", from, "from", params)
+	writeEdge(&b, opts, fmt.Sprintf("{1,%d}", depth))
+	writeNode(&b, "b", to, "to", params)
+	b.WriteString(" RETURN p")
+	return b.String(), params
+}
+
+func neighborhoodStatement(node NodeRef, depth int, opts Options) (string, map[string]any) {
+	params := map[string]any{}
+	var b strings.Builder
+	b.WriteString("MATCH ")
+	writeNode(&b, "n", node, "node", params)
+	if depth == 0 {
+		b.WriteString(" RETURN n")
+	} else {
+		b.WriteString(" OPTIONAL MATCH p = TRAIL (n)")
+		writeEdge(&b, opts, fmt.Sprintf("{1,%d}", depth))
+		b.WriteString("() RETURN n, p")
+	}
+	if opts.Limit > 0 {
+		fmt.Fprintf(&b, " LIMIT %d", opts.Limit)
+	}
+	return b.String(), params
+}
+
+func degreeStatement(label string, opts Options) string {
+	var b strings.Builder
+	b.WriteString("MATCH ")
+	writeNode(&b, "n", NodeRef{Label: label}, "", nil)
+	b.WriteString(" OPTIONAL MATCH (n)")
+	writeEdge(&b, opts, "")
+	b.WriteString("() RETURN n, count(e) AS degree GROUP BY n")
+	b.WriteString(" NEXT RETURN degree, count(*) AS nodes GROUP BY degree ORDER BY degree")
+	return b.String()
+}
+
+// writeNode writes a node pattern binding variable to the nodes ref
+// matches, with its value in params under param.
+func writeNode(b *strings.Builder, variable string, ref NodeRef, param string, params map[string]any) {
+	b.WriteString("(" + variable)
+	if ref.Label != "" {
+		b.WriteString(":" + gwp.EscapeIdentifier(ref.Label))
+	}
+	if ref.Key != "" {
+		fmt.Fprintf(b, " {%s: $%s}", gwp.EscapeIdentifier(ref.Key), param)
+		params[param] = ref.Value
+	}
+	b.WriteString(")")
+}
+
+// writeEdge writes an edge pattern bound to e, followed by quantifier.
+func writeEdge(b *strings.Builder, opts Options, quantifier string) {
+	filler := "e"
+	if quantifier != "" {
+		// Quantified edges are not referenced, and binding them would
+		// return a list per path.
+		filler = ""
+	}
+	if len(opts.EdgeTypes) > 0 {
+		types := make([]string, len(opts.EdgeTypes))
+		for i, t := range opts.EdgeTypes {
+			types[i] = gwp.EscapeIdentifier(t)
+		}
+		filler += ":" + strings.Join(types, "|")
+	}
+	switch opts.Direction {
+	case Outgoing:
+		b.WriteString("-[" + filler + "]->")
+	case Incoming:
+		b.WriteString("<-[" + filler + "]-")
+	default:
+		b.WriteString("-[" + filler + "]-")
+	}
+	b.WriteString(quantifier)
+}
+
+// graphBuilder collects the distinct nodes and edges of result values.
+type graphBuilder struct {
+	Graph
+	seenNodes map[gwp.ElementID]bool
+	seenEdges map[gwp.ElementID]bool
+}
+
+func newGraphBuilder() *graphBuilder {
+	return &graphBuilder{seenNodes: make(map[gwp.ElementID]bool), seenEdges: make(map[gwp.ElementID]bool)}
+}
+
+func (g *graphBuilder) add(v any) error {
+	switch t := v.(type) {
+	case nil:
+	case *gwp.GqlNode:
+		if !g.seenNodes[t.ElementID()] {
+			g.seenNodes[t.ElementID()] = true
+			g.Nodes = append(g.Nodes, t)
+		}
+	case *gwp.GqlEdge:
+		if !g.seenEdges[t.ElementID()] {
+			g.seenEdges[t.ElementID()] = true
+			g.Edges = append(g.Edges, t)
+		}
+	case *gwp.GqlPath:
+		for _, n := range t.Nodes {
+			g.add(n)
+		}
+		for _, e := range t.Edges {
+			g.add(e)
+		}
+	default:
+		return fmt.Errorf("algo: expected a node, edge or path, got %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package algo
+
+import (
+	"reflect"
+	"testing"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+func TestShortestPathStatement(t *testing.T) {
+	stmt, params := shortestPathStatement(
+		Node("Person", "name", "Alice"),
+		Node("Person", "name", "Bob"),
+		Options{EdgeTypes: []string{"KNOWS", "WORKS WITH"}, Direction: Outgoing, MaxDepth: 4},
+	)
+	want := "MATCH p = ANY SHORTEST (a:Person {name: $from})-[:KNOWS|`WORKS WITH`]->{1,4}(b:Person {name: $to}) RETURN p"
+	if stmt != want {
+		t.Fatalf("statement:\n%s\nwant:\n%s", stmt, want)
+	}
+	if !reflect.DeepEqual(params, map[string]any{"from": "Alice", "to": "Bob"}) {
+		t.Fatalf("params = %v", params)
+	}
+
+	stmt, _ = shortestPathStatement(NodeRef{Label: "City"}, NodeRef{}, Options{Direction: Incoming})
+	if want := "MATCH p = ANY SHORTEST (a:City)<-[]-{1,15}(b) RETURN p"; stmt != want {
+		t.Fatalf("statement = %s, want %s", stmt, want)
+	}
+}
+
+func TestNeighborhoodStatement(t *testing.T) {
+	stmt, params := neighborhoodStatement(Node("Person", "id", int64(7)), 2, Options{Limit: 100})
+	want := "MATCH (n:Person {id: $node}) OPTIONAL MATCH p = TRAIL (n)-[]-{1,2}() RETURN n, p LIMIT 100"
+	if stmt != want || params["node"] != int64(7) {
+		t.Fatalf("statement = %s, params = %v", stmt, params)
+	}
+	if stmt, _ := neighborhoodStatement(Node("Person", "id", 1), 0, Options{}); stmt != "MATCH (n:Person {id: $node}) RETURN n" {
+		t.Fatalf("depth 0 statement = %s", stmt)
+	}
+}
+
+func TestDegreeStatement(t *testing.T) {
+	stmt := degreeStatement("Person", Options{EdgeTypes: []string{"KNOWS"}, Direction: Outgoing})
+	want := "MATCH (n:Person) OPTIONAL MATCH (n)-[e:KNOWS]->() RETURN n, count(e) AS degree GROUP BY n" +
+		" NEXT RETURN degree, count(*) AS nodes GROUP BY degree ORDER BY degree"
+	if stmt != want {
+		t.Fatalf("statement:\n%s\nwant:\n%s", stmt, want)
+	}
+}
+
+func TestGraphBuilder(t *testing.T) {
+	alice := &gwp.GqlNode{ID: []byte{1}, Labels: []string{"Person"}}
+	bob := &gwp.GqlNode{ID: []byte{2}, Labels: []string{"Person"}}
+	carol := &gwp.GqlNode{ID: []byte{3}, Labels: []string{"Person"}}
+	ab := &gwp.GqlEdge{ID: []byte{10}, SourceNodeID: alice.ID, TargetNodeID: bob.ID}
+	bc := &gwp.GqlEdge{ID: []byte{11}, SourceNodeID: bob.ID, TargetNodeID: carol.ID}
+
+	g := newGraphBuilder()
+	values := []any{
+		alice, &gwp.GqlPath{Nodes: []*gwp.GqlNode{alice, bob}, Edges: []*gwp.GqlEdge{ab}},
+		alice, &gwp.GqlPath{Nodes: []*gwp.GqlNode{alice, bob, carol}, Edges: []*gwp.GqlEdge{ab, bc}},
+		nil,
+	}
+	for _, v := range values {
+		if err := g.add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(g.Nodes) != 3 || len(g.Edges) != 2 {
+		t.Fatalf("graph has %d nodes and %d edges, want 3 and 2", len(g.Nodes), len(g.Edges))
+	}
+	if g.Node([]byte{3}) != carol || g.Node([]byte{9}) != nil {
+		t.Fatal("Node lookup failed")
+	}
+	if err := g.add("Alice"); err == nil {
+		t.Fatal("expected error for a non-graph value")
+	}
+}
@@ -0,0 +1,100 @@
+package gwp
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Statement annotations travel to the server as request headers, since
+// ExecuteRequest has no field for them. Servers use them to attribute
+// statements in query logs and to schedule them under workload management.
+const (
+	applicationNameKey  = "gwp-application-name"
+	requestIDKey        = "gwp-request-id"
+	priorityKey         = "gwp-priority"
+	queueKey            = "gwp-queue"
+	annotationKeyPrefix = "gwp-annotation-"
+)
+
+// Priority is a scheduling hint for the server's workload manager. The
+// server may ignore it.
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+)
+
+// WithApplicationName sends the name of the calling application or
+// component with the statement in the "gwp-application-name" header.
+func WithApplicationName(name string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.setAnnotation(applicationNameKey, name)
+	}
+}
+
+// WithRequestID sends the ID of the request that issued the statement, such
+// as an inbound HTTP request's correlation ID, in the "gwp-request-id"
+// header. Unlike WithQueryID, several statements may share a request ID.
+func WithRequestID(id string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.setAnnotation(requestIDKey, id)
+	}
+}
+
+// WithPriority sends a scheduling priority with the statement in the
+// "gwp-priority" header.
+func WithPriority(p Priority) ExecuteOption {
+	return func(o *executeOptions) {
+		o.setAnnotation(priorityKey, string(p))
+	}
+}
+
+// WithQueue names the server-side workload queue or resource group the
+// statement should run in, in the "gwp-queue" header.
+func WithQueue(name string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.setAnnotation(queueKey, name)
+	}
+}
+
+// WithAnnotation sends an application-defined annotation with the
+// statement in the "gwp-annotation-<key>" header. Keys are lowercased, as
+// gRPC requires; a later annotation replaces an earlier one with the same
+// key.
+func WithAnnotation(key, value string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.setAnnotation(annotationKeyPrefix+strings.ToLower(key), value)
+	}
+}
+
+func (o *executeOptions) setAnnotation(key, value string) {
+	if o.annotations == nil {
+		o.annotations = make(map[string]string)
+	}
+	o.annotations[key] = value
+}
+
+// withAnnotations attaches the annotations to the outgoing metadata of ctx,
+// skipping empty values.
+func withAnnotations(ctx context.Context, annotations map[string]string) context.Context {
+	if len(annotations) == 0 {
+		return ctx
+	}
+	keys := make([]string, 0, len(annotations))
+	for k, v := range annotations {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	kv := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		kv = append(kv, k, annotations[k])
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
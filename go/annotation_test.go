@@ -0,0 +1,45 @@
+package gwp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStatementAnnotations(t *testing.T) {
+	ms, s := startMetadataServer(t, ConnectionConfig{})
+	s.interceptors = interceptorChain{StatementInterceptorFuncs{Before: func(ctx context.Context, info *StatementInfo) (context.Context, error) {
+		info.Annotations[annotationKeyPrefix+"tenant"] = "acme"
+		return ctx, nil
+	}}}
+
+	cursor, err := s.Execute(context.Background(), "RETURN 1", nil,
+		WithApplicationName("billing"),
+		WithRequestID("req-9"),
+		WithPriority(PriorityLow),
+		WithQueue("batch"),
+		WithAnnotation("Team", "payments"),
+		WithRequestID(""),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cursor.Summary(); err != nil {
+		t.Fatal(err)
+	}
+
+	md := ms.last()
+	for key, want := range map[string]string{
+		applicationNameKey:             "billing",
+		priorityKey:                    "low",
+		queueKey:                       "batch",
+		annotationKeyPrefix + "team":   "payments",
+		annotationKeyPrefix + "tenant": "acme",
+	} {
+		if got := md.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("%s = %v, want %q", key, got, want)
+		}
+	}
+	if got := md.Get(requestIDKey); len(got) != 0 {
+		t.Errorf("%s = %v after being cleared", requestIDKey, got)
+	}
+}
@@ -0,0 +1,129 @@
+package gwp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultTokenRefreshWindow is how long before expiry a token is refreshed.
+const defaultTokenRefreshWindow = time.Minute
+
+// Token is a bearer access token.
+type Token struct {
+	AccessToken string
+	// Expiry is when the token stops being valid. Zero means it does not
+	// expire.
+	Expiry time.Time
+}
+
+// TokenSource supplies bearer tokens, such as short-lived OIDC or cloud IAM
+// tokens. An oauth2.TokenSource can be adapted with TokenSourceFunc.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token calls f.
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// tokenCache holds the current token of a connection's TokenSource and
+// refreshes it when it is about to expire or the server rejects it.
+type tokenCache struct {
+	source        TokenSource
+	window        time.Duration
+	allowInsecure bool
+
+	mu      sync.Mutex
+	current *Token
+}
+
+func newTokenCache(config ConnectionConfig) *tokenCache {
+	window := config.TokenRefreshWindow
+	if window == 0 {
+		window = defaultTokenRefreshWindow
+	}
+	return &tokenCache{source: config.TokenSource, window: window, allowInsecure: config.AllowInsecureTokens}
+}
+
+// token returns the cached token, fetching a new one if there is none or
+// it expires within the refresh window.
+func (t *tokenCache) token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != nil && (t.current.Expiry.IsZero() || time.Until(t.current.Expiry) > t.window) {
+		return t.current.AccessToken, nil
+	}
+	tok, err := t.source.Token(ctx)
+	if err != nil {
+		return "", status.Errorf(codes.Unauthenticated, "token source: %v", err)
+	}
+	t.current = tok
+	return tok.AccessToken, nil
+}
+
+// invalidate drops the cached token so the next call fetches a new one.
+func (t *tokenCache) invalidate() {
+	t.mu.Lock()
+	t.current = nil
+	t.mu.Unlock()
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials.
+func (t *tokenCache) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	tok, err := t.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + tok}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials.
+func (t *tokenCache) RequireTransportSecurity() bool {
+	return !t.allowInsecure
+}
+
+// handshakeCredentials returns the credentials to present in a handshake.
+func (t *tokenCache) handshakeCredentials(ctx context.Context) (*pb.AuthCredentials, error) {
+	tok, err := t.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AuthCredentials{Method: &pb.AuthCredentials_BearerToken{BearerToken: tok}}, nil
+}
+
+// dialOptions attaches the token to every RPC and retries a unary RPC
+// rejected as UNAUTHENTICATED once with a fresh token. Statement streams are
+// not retried, since rows may already have been delivered.
+func (t *tokenCache) dialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithPerRPCCredentials(t),
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if status.Code(err) != codes.Unauthenticated {
+				return err
+			}
+			t.invalidate()
+			if hs, ok := req.(*pb.HandshakeRequest); ok && hs.Credentials != nil {
+				creds, credErr := t.handshakeCredentials(ctx)
+				if credErr != nil {
+					return err
+				}
+				hs = proto.Clone(hs).(*pb.HandshakeRequest)
+				hs.Credentials = creds
+				req = hs
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+	}
+}
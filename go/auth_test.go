@@ -0,0 +1,115 @@
+package gwp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// tokenServer accepts only tokens in valid, in the handshake and in the
+// authorization header of pings.
+type tokenServer struct {
+	handshakeServer
+
+	mu    sync.Mutex
+	valid map[string]bool
+	seen  []string
+}
+
+func (s *tokenServer) check(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen = append(s.seen, token)
+	if !s.valid[token] {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return nil
+}
+
+func (s *tokenServer) Handshake(ctx context.Context, r *pb.HandshakeRequest) (*pb.HandshakeResponse, error) {
+	if err := s.check(r.GetCredentials().GetBearerToken()); err != nil {
+		return nil, err
+	}
+	return s.handshakeServer.Handshake(ctx, r)
+}
+
+func (s *tokenServer) Ping(ctx context.Context, r *pb.PingRequest) (*pb.PongResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header string
+	if v := md.Get("authorization"); len(v) > 0 {
+		header = v[0]
+	}
+	if err := s.check(header); err != nil {
+		return nil, err
+	}
+	return s.handshakeServer.Ping(ctx, r)
+}
+
+func TestTokenSource(t *testing.T) {
+	server := &tokenServer{valid: map[string]bool{"t2": true, "Bearer t2": true, "Bearer t3": true, "Bearer t4": true}}
+	lis := serveBufconn(t, server)
+
+	var mu sync.Mutex
+	issued := 0
+	expiry := time.Now().Add(time.Hour)
+	source := TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		issued++
+		return &Token{AccessToken: fmt.Sprintf("t%d", issued), Expiry: expiry}, nil
+	})
+
+	ctx := context.Background()
+	conn := connectBufconn(t, lis, ConnectionConfig{TokenSource: source, AllowInsecureTokens: true})
+
+	// The first token is rejected; the handshake is retried with a new one.
+	s, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, err := s.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	// A token about to expire is refreshed before it is used.
+	mu.Lock()
+	expiry = time.Now().Add(10 * time.Second)
+	mu.Unlock()
+	conn.tokens.invalidate()
+	if _, err := s.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if _, err := s.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	want := "[t1 t2 Bearer t2 Bearer t3 Bearer t4]"
+	if got := fmt.Sprint(server.seen); got != want {
+		t.Fatalf("server saw %s, want %s", got, want)
+	}
+}
+
+func TestTokenSourceRequiresTransportSecurity(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	ctx := context.Background()
+	source := TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		return &Token{AccessToken: "secret"}, nil
+	})
+	_, err := ConnectWithConfig(ctx, "bufnet", ConnectionConfig{
+		TokenSource: source,
+		Dialer:      bufDialer(lis),
+	})
+	if err == nil {
+		t.Fatal("tokens allowed over an insecure transport")
+	}
+}
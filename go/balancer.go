@@ -0,0 +1,269 @@
+package gwp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/health" // client-side health checking
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+)
+
+// Load-balancing policies for ConnectEndpoints.
+const (
+	// BalanceRoundRobin places successive sessions on successive
+	// endpoints.
+	BalanceRoundRobin = "gwp_round_robin"
+	// BalanceLeastInFlight places each session on the endpoint with the
+	// fewest calls in progress, counting open statement streams, so slow
+	// endpoints receive less work.
+	BalanceLeastInFlight = "gwp_least_in_flight"
+)
+
+const (
+	// endpointsScheme is the resolver scheme of ConnectEndpoints
+	// connections.
+	endpointsScheme = "gwp-endpoints"
+	// endpointKeyHeader carries the key pinning a session's calls to the
+	// endpoint that created it.
+	endpointKeyHeader = "gwp-endpoint-key"
+)
+
+// endpointSubConns maps endpoint keys to the sub-connection chosen for
+// them. Keys are random, so connections share the map.
+var endpointSubConns sync.Map
+
+func init() {
+	for _, policy := range []string{BalanceRoundRobin, BalanceLeastInFlight} {
+		balancer.Register(base.NewBalancerBuilder(policy, endpointPickerBuilder{leastInFlight: policy == BalanceLeastInFlight}, base.Config{HealthCheck: true}))
+	}
+}
+
+// ConnectEndpoints creates a connection that spreads sessions across a
+// static list of server addresses, for deployments without a routing
+// service. config.LoadBalancing selects the policy. With
+// config.EndpointHealthChecks set, endpoints whose grpc.health.v1 status is
+// not SERVING receive no new sessions until they recover; servers without
+// the health service are always used.
+//
+// A session lives on the server that created it, so every call of a
+// session goes to that endpoint, and fails with codes.Unavailable while it
+// is down.
+func ConnectEndpoints(ctx context.Context, addresses []string, config ConnectionConfig) (*GqlConnection, error) {
+	if len(addresses) == 0 {
+		return nil, &GqlError{Message: "no endpoints"}
+	}
+	r := manual.NewBuilderWithScheme(endpointsScheme)
+	r.InitialState(endpointsState(addresses))
+	return connectBalanced(ctx, endpointsScheme+":///", config, r)
+}
+
+// endpointsState is the resolver state listing addresses as endpoints.
+func endpointsState(addresses []string) resolver.State {
+	var state resolver.State
+	for _, addr := range addresses {
+		a := resolver.Address{Addr: addr}
+		state.Addresses = append(state.Addresses, a)
+		state.Endpoints = append(state.Endpoints, resolver.Endpoint{Addresses: []resolver.Address{a}})
+	}
+	return state
+}
+
+// connectBalanced creates a connection whose endpoints come from r and
+// whose sessions are spread across them by config.LoadBalancing.
+func connectBalanced(ctx context.Context, target string, config ConnectionConfig, r resolver.Builder) (*GqlConnection, error) {
+	policy := config.LoadBalancing
+	if policy == "" {
+		policy = BalanceRoundRobin
+	}
+	if policy != BalanceRoundRobin && policy != BalanceLeastInFlight {
+		return nil, &GqlError{Message: fmt.Sprintf("unknown load-balancing policy %q", policy)}
+	}
+	serviceConfig := fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]`, policy)
+	if config.EndpointHealthChecks {
+		serviceConfig += `, "healthCheckConfig": {"serviceName": ""}`
+	}
+	serviceConfig += "}"
+
+	affinity := &endpointAffinity{keys: make(map[string]string), addrs: make(map[string]string)}
+	c, err := connect(ctx, target, config, []grpc.DialOption{
+		grpc.WithResolvers(r),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithChainUnaryInterceptor(affinity.intercept),
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.affinity = affinity
+	return c, nil
+}
+
+// endpointAffinity maps the sessions of a connection to their endpoint
+// keys.
+type endpointAffinity struct {
+	mu    sync.Mutex
+	keys  map[string]string
+	addrs map[string]string
+}
+
+func (a *endpointAffinity) key(sessionID string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.keys[sessionID]
+}
+
+// address returns the address of the endpoint a session was created on.
+func (a *endpointAffinity) address(sessionID string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.addrs[sessionID]
+}
+
+// intercept gives each handshake a new endpoint key and sends the key of
+// the session with its later calls.
+func (a *endpointAffinity) intercept(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if _, ok := req.(*pb.HandshakeRequest); ok {
+		key := newEndpointKey()
+		var p peer.Peer
+		err := invoker(metadata.AppendToOutgoingContext(ctx, endpointKeyHeader, key), method, req, reply, cc, append(opts, grpc.Peer(&p))...)
+		if err != nil {
+			endpointSubConns.Delete(key)
+			return err
+		}
+		sessionID := reply.(*pb.HandshakeResponse).SessionId
+		a.mu.Lock()
+		a.keys[sessionID] = key
+		if p.Addr != nil {
+			a.addrs[sessionID] = p.Addr.String()
+		}
+		a.mu.Unlock()
+		return nil
+	}
+
+	r, ok := req.(interface{ GetSessionId() string })
+	if !ok {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	sessionID := r.GetSessionId()
+	key := a.key(sessionID)
+	if key != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, endpointKeyHeader, key)
+	}
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if _, closing := req.(*pb.CloseRequest); closing && key != "" {
+		a.mu.Lock()
+		delete(a.keys, sessionID)
+		delete(a.addrs, sessionID)
+		a.mu.Unlock()
+		endpointSubConns.Delete(key)
+	}
+	return err
+}
+
+func newEndpointKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// withEndpointKey pins a statement stream to the session's endpoint.
+func (s *GqlSession) withEndpointKey(ctx context.Context) context.Context {
+	if s.conn == nil || s.conn.affinity == nil {
+		return ctx
+	}
+	if key := s.conn.affinity.key(s.sessionID); key != "" {
+		return metadata.AppendToOutgoingContext(ctx, endpointKeyHeader, key)
+	}
+	return ctx
+}
+
+type endpointPickerBuilder struct {
+	leastInFlight bool
+}
+
+func (b endpointPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &endpointPicker{leastInFlight: b.leastInFlight, ready: make(map[balancer.SubConn]*inFlightConn)}
+	for sc := range info.ReadySCs {
+		c := &inFlightConn{sc: sc}
+		p.conns = append(p.conns, c)
+		p.ready[sc] = c
+	}
+	return p
+}
+
+// endpointPicker sends calls carrying an endpoint key to the endpoint
+// chosen for the key, and chooses endpoints for new keys in rotation or by
+// fewest calls in progress.
+type endpointPicker struct {
+	leastInFlight bool
+	conns         []*inFlightConn
+	ready         map[balancer.SubConn]*inFlightConn
+	next          atomic.Uint32
+}
+
+type inFlightConn struct {
+	sc       balancer.SubConn
+	inFlight atomic.Int64
+}
+
+func (p *endpointPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	var key string
+	if md, ok := metadata.FromOutgoingContext(info.Ctx); ok {
+		if v := md.Get(endpointKeyHeader); len(v) > 0 {
+			key = v[len(v)-1]
+		}
+	}
+	if key != "" {
+		if sc, ok := endpointSubConns.Load(key); ok {
+			c, ready := p.ready[sc.(balancer.SubConn)]
+			if !ready {
+				return balancer.PickResult{}, status.Error(codes.Unavailable, "the session's endpoint is unavailable")
+			}
+			return c.pick(), nil
+		}
+	}
+
+	c := p.choose()
+	if key != "" {
+		endpointSubConns.Store(key, c.sc)
+	}
+	return c.pick(), nil
+}
+
+func (p *endpointPicker) choose() *inFlightConn {
+	start := int(p.next.Add(1))
+	if !p.leastInFlight {
+		return p.conns[start%len(p.conns)]
+	}
+	var best *inFlightConn
+	for i := range p.conns {
+		c := p.conns[(start+i)%len(p.conns)]
+		if best == nil || c.inFlight.Load() < best.inFlight.Load() {
+			best = c
+		}
+	}
+	return best
+}
+
+func (c *inFlightConn) pick() balancer.PickResult {
+	c.inFlight.Add(1)
+	return balancer.PickResult{
+		SubConn: c.sc,
+		Done:    func(balancer.DoneInfo) { c.inFlight.Add(-1) },
+	}
+}
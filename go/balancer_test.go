@@ -0,0 +1,140 @@
+package gwp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// endpointServer names its sessions after itself and only knows its own.
+type endpointServer struct {
+	handshakeServer
+	name string
+
+	mu       sync.Mutex
+	sessions int
+}
+
+func (s *endpointServer) Handshake(ctx context.Context, r *pb.HandshakeRequest) (*pb.HandshakeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions++
+	return &pb.HandshakeResponse{ProtocolVersion: 1, SessionId: fmt.Sprintf("%s-%d", s.name, s.sessions)}, nil
+}
+
+func (s *endpointServer) Ping(ctx context.Context, r *pb.PingRequest) (*pb.PongResponse, error) {
+	if !strings.HasPrefix(r.SessionId, s.name+"-") {
+		return nil, status.Errorf(codes.NotFound, "session %s not found on %s", r.SessionId, s.name)
+	}
+	return &pb.PongResponse{Timestamp: 1}, nil
+}
+
+// serveEndpoints starts a server per name and returns a dialer reaching
+// them by name, and their health servers.
+func serveEndpoints(t *testing.T, names ...string) (func(context.Context, string) (net.Conn, error), map[string]*health.Server) {
+	listeners := make(map[string]*bufconn.Listener)
+	healths := make(map[string]*health.Server)
+	for _, name := range names {
+		healths[name] = health.NewServer()
+		listeners[name] = serveBufconn(t, &endpointServer{name: name}, healths[name])
+	}
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		return listeners[addr].DialContext(ctx)
+	}, healths
+}
+
+func TestConnectEndpoints(t *testing.T) {
+	dialer, _ := serveEndpoints(t, "a", "b")
+	ctx := context.Background()
+	conn, err := ConnectEndpoints(ctx, []string{"a", "b"}, ConnectionConfig{Dialer: dialer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+
+	var sessions []*GqlSession
+	seen := make(map[string]bool)
+	for i := 0; i < 50 && len(seen) < 2; i++ {
+		s, err := conn.CreateSession(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions = append(sessions, s)
+		seen[s.SessionID()[:1]] = true
+	}
+	if len(seen) != 2 {
+		t.Fatalf("sessions only created on %v", seen)
+	}
+	for _, s := range sessions {
+		for i := 0; i < 3; i++ {
+			if _, err := s.Ping(ctx); err != nil {
+				t.Fatalf("Ping on %s: %v", s.SessionID(), err)
+			}
+		}
+	}
+}
+
+func TestConnectEndpointsHealthChecks(t *testing.T) {
+	dialer, healths := serveEndpoints(t, "a", "b")
+	healths["b"].SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	ctx := context.Background()
+	conn, err := ConnectEndpoints(ctx, []string{"a", "b"}, ConnectionConfig{
+		Dialer:               dialer,
+		LoadBalancing:        BalanceLeastInFlight,
+		EndpointHealthChecks: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	for i := 0; i < 20; i++ {
+		s, err := conn.CreateSession(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(s.SessionID(), "a-") {
+			t.Fatalf("session %s created on an endpoint that is not serving", s.SessionID())
+		}
+	}
+
+	if _, err := ConnectEndpoints(ctx, []string{"a"}, ConnectionConfig{LoadBalancing: "random"}); err == nil {
+		t.Fatal("unknown policy accepted")
+	}
+}
+
+type fakeSubConn struct {
+	balancer.SubConn
+	id int
+}
+
+func TestLeastInFlightPicker(t *testing.T) {
+	a, b := &fakeSubConn{id: 1}, &fakeSubConn{id: 2}
+	picker := endpointPickerBuilder{leastInFlight: true}.Build(base.PickerBuildInfo{
+		ReadySCs: map[balancer.SubConn]base.SubConnInfo{a: {}, b: {}},
+	})
+	info := balancer.PickInfo{Ctx: context.Background()}
+
+	first, _ := picker.Pick(info)
+	second, _ := picker.Pick(info)
+	if first.SubConn == second.SubConn {
+		t.Fatal("second call should go to the idle endpoint")
+	}
+	first.Done(balancer.DoneInfo{})
+	third, _ := picker.Pick(info)
+	if third.SubConn != first.SubConn {
+		t.Fatal("third call should go to the endpoint whose call finished")
+	}
+}
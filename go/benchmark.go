@@ -0,0 +1,206 @@
+package gwp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BenchmarkConfig controls RunBenchmark.
+type BenchmarkConfig struct {
+	// Statements are executed in turn by each worker, so several
+	// statements make a workload mix.
+	Statements []Statement
+	// Concurrency is the number of workers, each executing statements on
+	// a session of its own. Defaults to 1.
+	Concurrency int
+	// Iterations is the number of measured executions across all workers.
+	// Defaults to 100 unless Duration is set.
+	Iterations int
+	// Duration, if set, runs workers until it has elapsed, or Iterations
+	// executions if also set, whichever comes first.
+	Duration time.Duration
+	// Warmup is the number of unmeasured executions per worker before
+	// measurement starts.
+	Warmup int
+	// SessionOptions configure the workers' sessions.
+	SessionOptions []SessionOption
+}
+
+// BenchmarkResult summarizes a benchmark run. Latencies cover executing a
+// statement and reading all of its rows.
+type BenchmarkResult struct {
+	Concurrency int
+	Executions  int
+	Rows        int64
+	Elapsed     time.Duration
+	Min         time.Duration
+	Mean        time.Duration
+	P50         time.Duration
+	P90         time.Duration
+	P99         time.Duration
+	Max         time.Duration
+}
+
+// Throughput returns the executions per second.
+func (r *BenchmarkResult) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Executions) / r.Elapsed.Seconds()
+}
+
+// Format renders the result as a line of Go benchmark output, such as
+// "BenchmarkLookup-8 1000 64004 ns/op 3.00 rows/op 488 p50-us 1953 p99-us",
+// so results of separate runs can be compared with benchstat. As for
+// parallel Go benchmarks, ns/op is the elapsed time divided by the
+// executions. name should start with "Benchmark"; the worker count is
+// appended to it.
+func (r *BenchmarkResult) Format(name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s-%d\t%d", name, r.Concurrency, r.Executions)
+	if r.Executions > 0 {
+		fmt.Fprintf(&b, "\t%d ns/op\t%.2f rows/op", r.Elapsed.Nanoseconds()/int64(r.Executions), float64(r.Rows)/float64(r.Executions))
+	}
+	fmt.Fprintf(&b, "\t%d p50-us\t%d p99-us", r.P50.Microseconds(), r.P99.Microseconds())
+	return b.String()
+}
+
+// RunBenchmark measures statement latency and throughput against a
+// deployment, for capacity planning and comparing client or server
+// versions. It stops at the first failed statement and returns its error
+// with the result measured so far.
+func RunBenchmark(ctx context.Context, conn *GqlConnection, config BenchmarkConfig) (*BenchmarkResult, error) {
+	if len(config.Statements) == 0 {
+		return nil, &GqlError{Message: "benchmark has no statements"}
+	}
+	workers := max(config.Concurrency, 1)
+	if config.Iterations <= 0 && config.Duration <= 0 {
+		config.Iterations = 100
+	}
+
+	sessions := make([]*GqlSession, workers)
+	for i := range sessions {
+		s, err := conn.CreateSession(ctx, config.SessionOptions...)
+		if err != nil {
+			for _, s := range sessions[:i] {
+				s.Close(context.WithoutCancel(ctx))
+			}
+			return nil, err
+		}
+		sessions[i] = s
+	}
+	defer func() {
+		for _, s := range sessions {
+			s.Close(context.WithoutCancel(ctx))
+		}
+	}()
+
+	for _, s := range sessions {
+		for i := 0; i < config.Warmup; i++ {
+			if _, err := benchmarkExecute(ctx, s, config.Statements[i%len(config.Statements)]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		latencies []time.Duration
+		rows      atomic.Int64
+		started   atomic.Int64
+		firstErr  error
+	)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if config.Duration > 0 {
+		var stop context.CancelFunc
+		runCtx, stop = context.WithTimeout(runCtx, config.Duration)
+		defer stop()
+	}
+	start := time.Now()
+	for _, s := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				if config.Iterations > 0 && started.Add(1) > int64(config.Iterations) {
+					return
+				}
+				t := time.Now()
+				n, err := benchmarkExecute(runCtx, s, config.Statements[i%len(config.Statements)])
+				if err != nil {
+					if runCtx.Err() != nil {
+						// Time is up, or another worker failed.
+						return
+					}
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+					return
+				}
+				d := time.Since(t)
+				rows.Add(n)
+				mu.Lock()
+				latencies = append(latencies, d)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+
+	result := &BenchmarkResult{Concurrency: workers, Executions: len(latencies), Rows: rows.Load(), Elapsed: time.Since(start)}
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		var total time.Duration
+		for _, d := range latencies {
+			total += d
+		}
+		result.Min = latencies[0]
+		result.Max = latencies[len(latencies)-1]
+		result.Mean = total / time.Duration(len(latencies))
+		result.P50 = percentile(latencies, 0.50)
+		result.P90 = percentile(latencies, 0.90)
+		result.P99 = percentile(latencies, 0.99)
+	}
+	return result, firstErr
+}
+
+// benchmarkExecute executes a statement, reads all its rows and returns
+// their number.
+func benchmarkExecute(ctx context.Context, s *GqlSession, stmt Statement) (int64, error) {
+	cursor, err := s.Execute(ctx, stmt.Statement, stmt.Params, stmt.Options...)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for {
+		row, err := cursor.NextRow()
+		if err != nil {
+			return n, err
+		}
+		if row == nil {
+			break
+		}
+		n++
+	}
+	return n, checkCursorStatus(cursor)
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(p*float64(len(sorted))+0.5) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
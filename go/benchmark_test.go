@@ -0,0 +1,189 @@
+package gwp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/protobuf/proto"
+)
+
+// Run the decode, encode and end-to-end benchmarks with
+//
+//	go test -run '^$' -bench . -count 10 > new.txt
+//
+// and compare against a baseline with benchstat old.txt new.txt.
+
+// decodeFrames returns a result of batches rows wide, 100 rows a batch,
+// built by row, and its wire size.
+func decodeFrames(batches int, row func(i int) []any) ([]*pb.ExecuteResponse, int64) {
+	first := row(0)
+	columns := make([]string, len(first))
+	for i := range columns {
+		columns[i] = "c" + strings.Repeat("x", i%4)
+	}
+	frames := []*pb.ExecuteResponse{headerFrame(columns...)}
+	for b := 0; b < batches; b++ {
+		rows := make([][]any, 100)
+		for i := range rows {
+			rows[i] = row(b*100 + i)
+		}
+		frames = append(frames, batchFrame(rows...))
+	}
+	frames = append(frames, summaryFrame(Success, 0))
+	var size int64
+	for _, f := range frames {
+		size += int64(proto.Size(f))
+	}
+	return frames, size
+}
+
+func benchmarkDecode(b *testing.B, frames []*pb.ExecuteResponse, size int64) {
+	b.SetBytes(size)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c := newResultCursor(&fakeStream{frames: frames})
+		if _, err := c.CollectRows(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeNarrowRows(b *testing.B) {
+	frames, size := decodeFrames(100, func(i int) []any {
+		return []any{int64(i)}
+	})
+	benchmarkDecode(b, frames, size)
+}
+
+func BenchmarkDecodeWideRows(b *testing.B) {
+	frames, size := decodeFrames(10, func(i int) []any {
+		row := make([]any, 50)
+		for c := range row {
+			switch c % 3 {
+			case 0:
+				row[c] = int64(i * c)
+			case 1:
+				row[c] = float64(i) / float64(c)
+			default:
+				row[c] = "value"
+			}
+		}
+		return row
+	})
+	benchmarkDecode(b, frames, size)
+}
+
+func BenchmarkDecodeNodes(b *testing.B) {
+	node := func(i int) *pb.Value {
+		return &pb.Value{Kind: &pb.Value_NodeValue{NodeValue: &pb.Node{
+			Id:     []byte{byte(i), byte(i >> 8)},
+			Labels: []string{"Person", "Employee"},
+			Properties: map[string]*pb.Value{
+				"name":  mustValueToProto("Alice"),
+				"age":   mustValueToProto(int64(30)),
+				"score": mustValueToProto(0.5),
+				"tags":  mustValueToProto([]any{"a", "b", "c"}),
+			},
+		}}}
+	}
+	frames := []*pb.ExecuteResponse{headerFrame("n", "m")}
+	for batch := 0; batch < 10; batch++ {
+		rows := make([]*pb.Row, 100)
+		for i := range rows {
+			rows[i] = &pb.Row{Values: []*pb.Value{node(i), node(i + 1)}}
+		}
+		frames = append(frames, &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: &pb.RowBatch{Rows: rows}}})
+	}
+	frames = append(frames, summaryFrame(Success, 0))
+	var size int64
+	for _, f := range frames {
+		size += int64(proto.Size(f))
+	}
+	benchmarkDecode(b, frames, size)
+}
+
+func BenchmarkEncodeParams(b *testing.B) {
+	ids := make([]any, 1000)
+	for i := range ids {
+		ids[i] = int64(i)
+	}
+	params := map[string]any{
+		"ids":  ids,
+		"name": "Alice",
+		"props": map[string]any{
+			"age":    30,
+			"scores": []float64{1, 2, 3},
+			"tags":   []string{"a", "b"},
+		},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := encodeParams(params, ParamLimits{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkExecute measures statement round trips against the test server.
+func BenchmarkExecute(b *testing.B) {
+	requireTestServer(b)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer session.Close(ctx)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := benchmarkExecute(ctx, session, Statement{Statement: "MATCH (n) RETURN n"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRunBenchmark(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+
+	result, err := RunBenchmark(ctx, conn, BenchmarkConfig{
+		Statements: []Statement{
+			{Statement: "MATCH (n) RETURN n"},
+			{Statement: "INSERT (:Person)"},
+		},
+		Concurrency: 3,
+		Iterations:  30,
+		Warmup:      1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Executions != 30 || result.Rows == 0 || result.Concurrency != 3 {
+		t.Fatalf("result = %+v", result)
+	}
+	if result.Min > result.P50 || result.P50 > result.P99 || result.P99 > result.Max || result.Throughput() <= 0 {
+		t.Fatalf("inconsistent latencies: %+v", result)
+	}
+	if line := result.Format("BenchmarkMix"); !strings.HasPrefix(line, "BenchmarkMix-3\t30\t") || !strings.Contains(line, " ns/op\t") {
+		t.Fatalf("Format = %q", line)
+	}
+
+	if _, err := RunBenchmark(ctx, conn, BenchmarkConfig{
+		Statements: []Statement{{Statement: "ERROR"}},
+		Iterations: 5,
+	}); err == nil {
+		t.Fatal("expected the failing statement's error")
+	}
+}
@@ -0,0 +1,150 @@
+package gwp
+
+import (
+	"math/big"
+	"strconv"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// Extended-precision integers (INT128/256, UINT128/256) decode to *big.Int
+// and extended-precision floats (FLOAT128/256) to *big.Float. Both are sent
+// as parameters in the same form: a *big.Int as a signed BigInteger, and a
+// *big.Float as a FLOAT128 if its precision fits, FLOAT256 otherwise.
+
+// bigIntegerFromProto decodes a BigInteger: big-endian two's complement if
+// signed, an unsigned magnitude otherwise.
+func bigIntegerFromProto(b *pb.BigInteger) *big.Int {
+	if b.IsSigned {
+		return fromTwosComplement(b.Value)
+	}
+	return new(big.Int).SetBytes(b.Value)
+}
+
+func bigIntegerToProto(x *big.Int) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_BigIntegerValue{BigIntegerValue: &pb.BigInteger{
+		Value:    twosComplement(x),
+		IsSigned: true,
+	}}}
+}
+
+// binaryFormat describes an IEEE 754 binary interchange format.
+type binaryFormat struct {
+	width    int // total bits
+	exponent int // exponent bits
+}
+
+func (f binaryFormat) mantissa() int { return f.width - 1 - f.exponent }
+func (f binaryFormat) bias() int     { return 1<<(f.exponent-1) - 1 }
+
+var (
+	binary128 = binaryFormat{width: 128, exponent: 15}
+	binary256 = binaryFormat{width: 256, exponent: 19}
+)
+
+func binaryFormatOf(width uint32) (binaryFormat, bool) {
+	switch width {
+	case 128:
+		return binary128, true
+	case 256:
+		return binary256, true
+	}
+	return binaryFormat{}, false
+}
+
+// bigFloatFromProto decodes a big-endian IEEE 754 binary128 or binary256
+// value. It reports false for other widths, a value of the wrong length and
+// NaN, which *big.Float cannot represent.
+func bigFloatFromProto(b *pb.BigFloat) (*big.Float, bool) {
+	format, ok := binaryFormatOf(b.Width)
+	if !ok || len(b.Value) != format.width/8 {
+		return nil, false
+	}
+	m := format.mantissa()
+	bits := new(big.Int).SetBytes(b.Value)
+	negative := bits.Bit(format.width-1) == 1
+	exp := int(new(big.Int).Rsh(bits, uint(m)).Int64() & (1<<format.exponent - 1))
+	frac := new(big.Int).And(bits, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(m)), big.NewInt(1)))
+
+	f := new(big.Float).SetPrec(uint(m + 1))
+	switch exp {
+	case 1<<format.exponent - 1:
+		if frac.Sign() != 0 {
+			return nil, false
+		}
+		f.SetInf(negative)
+		return f, true
+	case 0:
+		f.SetInt(frac)
+		f.SetMantExp(f, 1-format.bias()-m)
+	default:
+		frac.SetBit(frac, m, 1)
+		f.SetInt(frac)
+		f.SetMantExp(f, exp-format.bias()-m)
+	}
+	if negative {
+		f.Neg(f)
+	}
+	return f, true
+}
+
+// bigFloatToProto encodes f as a FLOAT128 if its precision fits, FLOAT256
+// otherwise, rounding to nearest even. Values out of range become infinite.
+func bigFloatToProto(f *big.Float) *pb.Value {
+	format := binary128
+	if f.Prec() > uint(binary128.mantissa()+1) {
+		format = binary256
+	}
+	m, bias := format.mantissa(), format.bias()
+	maxExp := 1<<format.exponent - 1
+
+	var exp int
+	frac := new(big.Int)
+	switch {
+	case f.IsInf():
+		exp = maxExp
+	case f.Sign() == 0:
+	default:
+		if e := f.MantExp(nil) - 1; e < 1-bias {
+			// Subnormal: keep the bits down to 2^(1-bias-m).
+			prec := e - (1 - bias - m) + 1
+			if prec <= 0 {
+				break
+			}
+			x := new(big.Float).SetMode(big.ToNearestEven).SetPrec(uint(prec)).Abs(f)
+			x.SetMantExp(x, bias-1+m)
+			x.Int(frac)
+			if frac.BitLen() > m {
+				// Rounded up to the smallest normal value.
+				exp = 1
+				frac.SetBit(frac, m, 0)
+			}
+			break
+		}
+		x := new(big.Float).SetMode(big.ToNearestEven).SetPrec(uint(m + 1)).Abs(f)
+		e := x.MantExp(nil) - 1
+		if e > bias {
+			exp = maxExp
+			break
+		}
+		exp = e + bias
+		x.SetMantExp(x, m-e)
+		x.Int(frac)
+		frac.SetBit(frac, m, 0)
+	}
+
+	bits := new(big.Int).Lsh(big.NewInt(int64(exp)), uint(m))
+	bits.Or(bits, frac)
+	if f.Signbit() {
+		bits.SetBit(bits, format.width-1, 1)
+	}
+	return &pb.Value{Kind: &pb.Value_BigFloatValue{BigFloatValue: &pb.BigFloat{
+		Value: bits.FillBytes(make([]byte, format.width/8)),
+		Width: uint32(format.width),
+	}}}
+}
+
+// bigFloatKind names a BigFloat that cannot be decoded in errors.
+func bigFloatKind(b *pb.BigFloat) string {
+	return "big_float_value (width " + strconv.FormatUint(uint64(b.Width), 10) + ")"
+}
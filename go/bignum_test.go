@@ -0,0 +1,77 @@
+package gwp
+
+import (
+	"encoding/hex"
+	"math"
+	"math/big"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func TestBigIntegerValues(t *testing.T) {
+	signed := &pb.Value{Kind: &pb.Value_BigIntegerValue{BigIntegerValue: &pb.BigInteger{Value: []byte{0xff, 0xfe}, IsSigned: true}}}
+	if got := valueFromProto(signed); got.(*big.Int).Int64() != -2 {
+		t.Fatalf("signed = %v, want -2", got)
+	}
+	unsigned := &pb.Value{Kind: &pb.Value_BigIntegerValue{BigIntegerValue: &pb.BigInteger{Value: []byte{0xff, 0xfe}}}}
+	if got := valueFromProto(unsigned); got.(*big.Int).Int64() != 0xfffe {
+		t.Fatalf("unsigned = %v, want 65534", got)
+	}
+
+	x, _ := new(big.Int).SetString("-170141183460469231731687303715884105728", 10)
+	v, err := valueToProto(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := valueFromProto(v); got.(*big.Int).Cmp(x) != 0 {
+		t.Fatalf("round trip = %v, want %v", got, x)
+	}
+}
+
+func TestBigFloatValues(t *testing.T) {
+	one, _ := hex.DecodeString("3fff0000000000000000000000000000")
+	v := &pb.Value{Kind: &pb.Value_BigFloatValue{BigFloatValue: &pb.BigFloat{Value: one, Width: 128}}}
+	if got := valueFromProto(v).(*big.Float); got.Cmp(big.NewFloat(1)) != 0 || got.Prec() != 113 {
+		t.Fatalf("binary128 1.0 = %v (prec %d)", got, got.Prec())
+	}
+	enc := bigFloatToProto(big.NewFloat(1)).GetBigFloatValue()
+	if enc.Width != 128 || hex.EncodeToString(enc.Value) != "3fff0000000000000000000000000000" {
+		t.Fatalf("encoded 1.0 = %x width %d", enc.Value, enc.Width)
+	}
+
+	pi := new(big.Float).SetPrec(200)
+	pi.SetString("3.14159265358979323846264338327950288419716939937510582097494459")
+	tiny := new(big.Float).SetMantExp(big.NewFloat(1), -16450) // subnormal in binary128
+	for _, f := range []*big.Float{
+		big.NewFloat(-2.5),
+		big.NewFloat(math.SmallestNonzeroFloat64),
+		new(big.Float).Neg(new(big.Float)),
+		new(big.Float).SetInf(true),
+		tiny,
+		pi,
+	} {
+		v, err := valueToProto(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := valueFromProto(v).(*big.Float)
+		if got.Cmp(f) != 0 || got.Signbit() != f.Signbit() {
+			t.Errorf("round trip of %v = %v", f, got)
+		}
+	}
+	if w := bigFloatToProto(pi).GetBigFloatValue().Width; w != 256 {
+		t.Errorf("200-bit float sent as width %d, want 256", w)
+	}
+
+	huge := new(big.Float).SetMantExp(big.NewFloat(1), 20000)
+	if got := valueFromProto(bigFloatToProto(huge)).(*big.Float); !got.IsInf() {
+		t.Errorf("out of range value = %v, want +Inf", got)
+	}
+
+	nan := append([]byte{0x7f, 0xff, 0x80}, make([]byte, 13)...)
+	d := valueDecoder{}
+	if got := d.decode(&pb.Value{Kind: &pb.Value_BigFloatValue{BigFloatValue: &pb.BigFloat{Value: nan, Width: 128}}}); got != nil || d.err == nil {
+		t.Errorf("NaN = %v, %v; want an UnsupportedTypeError", got, d.err)
+	}
+}
@@ -0,0 +1,100 @@
+package gwp
+
+import (
+	"context"
+	"maps"
+)
+
+// Bind returns the statement with params bound in addition to s.Params, as
+// the first layer of a query built up in steps, such as a base query bound
+// with a tenant filter and then with a page's bounds:
+//
+//	base := gwp.Statement{Statement: "MATCH (o:Order) WHERE o.tenant = $tenant AND o.id > $after RETURN o LIMIT $limit"}
+//	tenant := base.Bind(map[string]any{"tenant": tenantID})
+//	cursor, err := tenant.Bind(map[string]any{"after": after, "limit": 50}).Execute(ctx, session)
+func (s Statement) Bind(params map[string]any) BoundStatement {
+	b := BoundStatement{
+		statement: s.Statement,
+		names:     StatementParams(s.Statement),
+		options:   s.Options,
+	}
+	return b.bind(s.Params).bind(params)
+}
+
+// BoundStatement is a statement with some of its parameters bound, created
+// by Statement.Bind. Binding returns a new BoundStatement and leaves the
+// original unchanged, so one can be shared and bound further by several
+// callers.
+type BoundStatement struct {
+	statement string
+	// names are the statement's parameter references.
+	names   []string
+	params  map[string]any
+	options []ExecuteOption
+}
+
+// Bind returns the statement with params bound as well. A parameter bound
+// again takes its new value.
+func (b BoundStatement) Bind(params map[string]any) BoundStatement {
+	return b.bind(params)
+}
+
+func (b BoundStatement) bind(params map[string]any) BoundStatement {
+	if len(params) == 0 {
+		return b
+	}
+	merged := make(map[string]any, len(b.params)+len(params))
+	maps.Copy(merged, b.params)
+	maps.Copy(merged, params)
+	b.params = merged
+	return b
+}
+
+// Statement returns the statement with its bound parameters and options.
+func (b BoundStatement) Statement() Statement {
+	return Statement{Statement: b.statement, Params: maps.Clone(b.params), Options: b.options}
+}
+
+// Unbound returns the parameters the statement references that are not
+// bound, in order of first appearance.
+func (b BoundStatement) Unbound() []string {
+	var unbound []string
+	for _, name := range b.names {
+		if _, ok := b.params[name]; !ok {
+			unbound = append(unbound, name)
+		}
+	}
+	return unbound
+}
+
+// Execute executes the statement on q with the statement's options followed
+// by opts. It fails with a *ParamError, without sending the statement, if a
+// parameter the statement references is bound neither here nor, when q is
+// a session or one of its transactions, as a session parameter or default
+// parameter.
+func (b BoundStatement) Execute(ctx context.Context, q Querier, opts ...ExecuteOption) (*ResultCursor, error) {
+	var session *GqlSession
+	switch q := q.(type) {
+	case *GqlSession:
+		session = q
+	case *Transaction:
+		session = q.session
+	}
+	var state SessionState
+	if session != nil {
+		state = session.State()
+	}
+	for _, name := range b.Unbound() {
+		_, isParameter := state.Parameters[name]
+		_, isDefault := state.DefaultParams[name]
+		if !isParameter && !isDefault {
+			return nil, &ParamError{Path: "$" + name, Message: "not bound"}
+		}
+	}
+	if len(opts) > 0 {
+		opts = append(append([]ExecuteOption(nil), b.options...), opts...)
+	} else {
+		opts = b.options
+	}
+	return q.Execute(ctx, b.statement, b.params, opts...)
+}
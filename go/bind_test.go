@@ -0,0 +1,62 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestStatementBind(t *testing.T) {
+	base := Statement{
+		Statement: "MATCH (o:Order) WHERE o.tenant = $tenant AND o.id > $after RETURN o LIMIT $limit",
+		Params:    map[string]any{"limit": int64(10)},
+	}
+	tenant := base.Bind(map[string]any{"tenant": "acme"})
+	if got := tenant.Unbound(); !slices.Equal(got, []string{"after"}) {
+		t.Fatalf("Unbound() = %v, want [after]", got)
+	}
+
+	page := tenant.Bind(map[string]any{"after": int64(5), "limit": int64(50)})
+	if got := page.Unbound(); len(got) != 0 {
+		t.Fatalf("Unbound() = %v, want none", got)
+	}
+	if got := page.Statement().Params; got["limit"] != int64(50) || got["tenant"] != "acme" {
+		t.Errorf("params = %v", got)
+	}
+	if _, ok := tenant.Statement().Params["after"]; ok {
+		t.Error("binding modified the statement it was bound from")
+	}
+
+	client := &fakeGqlClient{stream: &fakeStream{}}
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+	var pe *ParamError
+	if _, err := tenant.Execute(context.Background(), s); !errors.As(err, &pe) || pe.Path != "$after" {
+		t.Fatalf("Execute with $after unbound = %v, want ParamError", err)
+	}
+	if client.lastReq != nil {
+		t.Fatal("statement with unbound parameters was sent")
+	}
+
+	if _, err := page.Execute(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+	sent := client.lastReq.Parameters
+	if len(sent) != 3 || sent["after"].GetIntegerValue() != 5 || sent["tenant"].GetStringValue() != "acme" {
+		t.Errorf("params sent = %v", sent)
+	}
+}
+
+func TestStatementBindSessionDefaults(t *testing.T) {
+	client := &fakeGqlClient{stream: &fakeStream{}}
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+	s.SetDefaultParams(map[string]any{"tenant": "acme"})
+
+	stmt := Statement{Statement: "MATCH (o:Order {tenant: $tenant}) RETURN o"}.Bind(nil)
+	if _, err := stmt.Execute(context.Background(), s); err != nil {
+		t.Fatalf("Execute with $tenant a default parameter: %v", err)
+	}
+	if got := client.lastReq.Parameters["tenant"].GetStringValue(); got != "acme" {
+		t.Errorf("tenant sent = %q", got)
+	}
+}
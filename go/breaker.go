@@ -0,0 +1,278 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BreakerState is the state of an endpoint's circuit breaker.
+type BreakerState int
+
+// Circuit breaker states.
+const (
+	// BreakerClosed lets statements through and counts failures.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails statements locally until OpenDuration has passed.
+	BreakerOpen
+	// BreakerHalfOpen lets a few probe statements through to find out
+	// whether the endpoint has recovered.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// CircuitBreakerConfig configures the circuit breakers of a connection.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed statements that
+	// opens the breaker. Defaults to 5.
+	FailureThreshold int
+	// OpenDuration is how long an open breaker fails statements before it
+	// lets probes through. Defaults to 30 seconds.
+	OpenDuration time.Duration
+	// HalfOpenProbes is the number of probe statements let through while
+	// half open. The breaker closes once they all succeed and opens again
+	// at the first failure. Closing or cancelling a probe's cursor gives the
+	// probe back; probes still outstanding after OpenDuration, such as of
+	// dropped cursors, are given up and new ones let through. Defaults to 1.
+	HalfOpenProbes int
+	// IsFailure reports whether a statement error counts against the
+	// endpoint. Defaults to IsEndpointFailure.
+	IsFailure func(error) bool
+}
+
+// IsEndpointFailure reports whether err suggests that the server is down or
+// overloaded: a gRPC Unavailable, DeadlineExceeded or ResourceExhausted
+// status. GQLSTATUS errors and cancellations do not count.
+func IsEndpointFailure(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// CircuitOpenError is returned in place of sending a statement to an
+// endpoint whose circuit breaker is open.
+type CircuitOpenError struct {
+	Endpoint string
+	// RetryAfter is how long until the breaker lets probes through, or
+	// zero if it is half open and all probes are in progress.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("circuit breaker for %s is open; retry in %s", e.Endpoint, e.RetryAfter.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("circuit breaker for %s is half open and probing", e.Endpoint)
+}
+
+// BreakerListener receives circuit breaker state changes. Connection
+// listeners that implement it are notified when the breaker of one of the
+// connection's endpoints changes state. Calls must not block.
+type BreakerListener interface {
+	BreakerStateChanged(endpoint string, from, to BreakerState)
+}
+
+// circuitBreaker tracks the statements sent to one endpoint.
+type circuitBreaker struct {
+	endpoint string
+	config   CircuitBreakerConfig
+	notify   func(endpoint string, from, to BreakerState)
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+	// probedAt is when the last probe was let through.
+	probedAt time.Time
+}
+
+func newCircuitBreaker(endpoint string, config CircuitBreakerConfig, notify func(string, BreakerState, BreakerState)) *circuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = 30 * time.Second
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = IsEndpointFailure
+	}
+	return &circuitBreaker{endpoint: endpoint, config: config, notify: notify}
+}
+
+// allow reports whether a statement may be sent at now, and whether it is
+// sent as a probe of a half-open breaker.
+func (b *circuitBreaker) allow(now time.Time) (probe bool, err error) {
+	b.mu.Lock()
+	from := b.state
+	if b.state == BreakerOpen {
+		if wait := b.config.OpenDuration - now.Sub(b.openedAt); wait > 0 {
+			b.mu.Unlock()
+			return false, &CircuitOpenError{Endpoint: b.endpoint, RetryAfter: wait}
+		}
+		b.state = BreakerHalfOpen
+		b.probes, b.successes = 0, 0
+	}
+	if b.state == BreakerHalfOpen {
+		if b.probes >= b.config.HalfOpenProbes && now.Sub(b.probedAt) >= b.config.OpenDuration {
+			b.probes, b.successes = 0, 0
+		}
+		if b.probes >= b.config.HalfOpenProbes {
+			b.mu.Unlock()
+			b.changed(from, BreakerHalfOpen)
+			return false, &CircuitOpenError{Endpoint: b.endpoint}
+		}
+		b.probes++
+		b.probedAt = now
+		probe = true
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.changed(from, to)
+	return probe, nil
+}
+
+// record counts the outcome of a statement let through by allow.
+// Cancelled statements say nothing about the endpoint and only give back
+// their probe.
+func (b *circuitBreaker) record(probe bool, err error, now time.Time) {
+	b.mu.Lock()
+	from := b.state
+	cancelled := errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled
+	failed := err != nil && !cancelled && b.config.IsFailure(err)
+	switch {
+	case b.state == BreakerClosed && !probe:
+		if failed {
+			b.failures++
+			if b.failures >= b.config.FailureThreshold {
+				b.open(now)
+			}
+		} else if !cancelled {
+			b.failures = 0
+		}
+	case b.state == BreakerHalfOpen && probe:
+		switch {
+		case cancelled:
+			if b.probes > 0 {
+				b.probes--
+			}
+		case failed:
+			b.open(now)
+		default:
+			b.successes++
+			if b.successes >= b.config.HalfOpenProbes {
+				b.state = BreakerClosed
+				b.failures = 0
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.changed(from, to)
+}
+
+// open opens the breaker. b.mu is held.
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.failures = 0
+}
+
+func (b *circuitBreaker) changed(from, to BreakerState) {
+	if from != to && b.notify != nil {
+		b.notify(b.endpoint, from, to)
+	}
+}
+
+func (b *circuitBreaker) currentState() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// breaker returns the circuit breaker of endpoint, or nil if circuit
+// breaking is disabled.
+func (c *GqlConnection) breaker(endpoint string) *circuitBreaker {
+	if c.config.CircuitBreaker == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.breakers[endpoint]
+	if b == nil {
+		if c.breakers == nil {
+			c.breakers = make(map[string]*circuitBreaker)
+		}
+		b = newCircuitBreaker(endpoint, *c.config.CircuitBreaker, c.breakerStateChanged)
+		c.breakers[endpoint] = b
+	}
+	return b
+}
+
+func (c *GqlConnection) breakerStateChanged(endpoint string, from, to BreakerState) {
+	for _, l := range c.config.Listeners {
+		if bl, ok := l.(BreakerListener); ok {
+			bl.BreakerStateChanged(endpoint, from, to)
+		}
+	}
+}
+
+// BreakerStates returns the circuit breaker state of every endpoint that
+// statements have been sent to, keyed by endpoint address. It returns nil
+// if ConnectionConfig.CircuitBreaker is not set.
+func (c *GqlConnection) BreakerStates() map[string]BreakerState {
+	c.mu.Lock()
+	breakers := make([]*circuitBreaker, 0, len(c.breakers))
+	for _, b := range c.breakers {
+		breakers = append(breakers, b)
+	}
+	c.mu.Unlock()
+	if c.config.CircuitBreaker == nil {
+		return nil
+	}
+	states := make(map[string]BreakerState, len(breakers))
+	for _, b := range breakers {
+		states[b.endpoint] = b.currentState()
+	}
+	return states
+}
+
+// endpoint returns the address of the server the session lives on: the
+// peer of its handshake on load-balanced connections, the connection
+// target otherwise.
+func (s *GqlSession) endpoint() string {
+	if s.conn.affinity != nil {
+		if addr := s.conn.affinity.address(s.sessionID); addr != "" {
+			return addr
+		}
+	}
+	if s.conn.conn == nil {
+		return ""
+	}
+	return s.conn.conn.Target()
+}
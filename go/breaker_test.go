@@ -0,0 +1,133 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	var transitions []string
+	b := newCircuitBreaker("db:7687", CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenDuration:     time.Second,
+		HalfOpenProbes:   2,
+	}, func(endpoint string, from, to BreakerState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+	unavailable := status.Error(codes.Unavailable, "down")
+	now := time.Now()
+
+	b.record(false, unavailable, now)
+	b.record(false, &GqlStatusError{Code: "42001"}, now)
+	b.record(false, unavailable, now)
+	if b.currentState() != BreakerClosed {
+		t.Fatal("a GQLSTATUS error should reset the failure count")
+	}
+	b.record(false, unavailable, now)
+	if b.currentState() != BreakerOpen {
+		t.Fatalf("state = %s, want open", b.currentState())
+	}
+
+	var open *CircuitOpenError
+	if _, err := b.allow(now.Add(500 * time.Millisecond)); !errors.As(err, &open) || open.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("allow while open = %v", err)
+	}
+
+	now = now.Add(time.Second)
+	p1, err1 := b.allow(now)
+	p2, err2 := b.allow(now)
+	if !p1 || !p2 || err1 != nil || err2 != nil {
+		t.Fatalf("probes = %v %v, %v %v", p1, p2, err1, err2)
+	}
+	if _, err := b.allow(now); !errors.As(err, &open) {
+		t.Fatalf("third probe = %v", err)
+	}
+	b.record(false, unavailable, now) // sent before the breaker opened
+	b.record(true, context.Canceled, now)
+	if p, err := b.allow(now); !p || err != nil {
+		t.Fatalf("cancelled probe not given back: %v", err)
+	}
+	b.record(true, nil, now)
+	b.record(true, nil, now)
+	if b.currentState() != BreakerClosed {
+		t.Fatalf("state = %s, want closed", b.currentState())
+	}
+
+	want := []string{"closed->open", "open->half_open", "half_open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v", transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("transitions = %v, want %v", transitions, want)
+		}
+	}
+
+	b.record(false, unavailable, now)
+	b.record(false, unavailable, now)
+	b.allow(now.Add(time.Second))
+	b.record(true, status.Error(codes.DeadlineExceeded, "slow"), now.Add(time.Second))
+	if b.currentState() != BreakerOpen {
+		t.Fatalf("failed probe: state = %s, want open", b.currentState())
+	}
+}
+
+func TestCircuitBreakerStaleProbe(t *testing.T) {
+	b := newCircuitBreaker("db:7687", CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Second}, nil)
+	now := time.Now()
+	b.record(false, status.Error(codes.Unavailable, "down"), now)
+	now = now.Add(time.Second)
+	if p, err := b.allow(now); !p || err != nil {
+		t.Fatalf("probe = %v, %v", p, err)
+	}
+	var open *CircuitOpenError
+	if _, err := b.allow(now.Add(500 * time.Millisecond)); !errors.As(err, &open) {
+		t.Fatalf("allow with probe outstanding = %v", err)
+	}
+	now = now.Add(time.Second)
+	if p, err := b.allow(now); !p || err != nil {
+		t.Fatalf("stale probe not given up: %v, %v", p, err)
+	}
+	b.record(true, nil, now)
+	if b.currentState() != BreakerClosed {
+		t.Fatalf("state = %s, want closed", b.currentState())
+	}
+}
+
+func TestSessionCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	var changes []BreakerState
+	conn := &GqlConnection{config: ConnectionConfig{
+		CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 2, OpenDuration: time.Minute},
+		Listeners: []ConnectionListener{ConnectionListenerFuncs{
+			OnBreakerStateChange: func(endpoint string, from, to BreakerState) { changes = append(changes, to) },
+		}},
+	}}
+	client := &fakeGqlClient{execErr: status.Error(codes.Unavailable, "down")}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, conn: conn}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Execute(ctx, "RETURN 1", nil); status.Code(err) != codes.Unavailable {
+			t.Fatalf("Execute %d = %v", i, err)
+		}
+	}
+	client.lastReq = nil
+	var open *CircuitOpenError
+	if _, err := s.Execute(ctx, "RETURN 1", nil); !errors.As(err, &open) {
+		t.Fatalf("Execute with open breaker = %v", err)
+	}
+	if client.lastReq != nil {
+		t.Fatal("statement sent through an open breaker")
+	}
+	if len(changes) != 1 || changes[0] != BreakerOpen {
+		t.Fatalf("listener saw %v", changes)
+	}
+	if states := conn.BreakerStates(); len(states) != 1 || states[""] != BreakerOpen {
+		t.Fatalf("BreakerStates = %v", states)
+	}
+}
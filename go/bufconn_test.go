@@ -0,0 +1,70 @@
+package gwp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve serves each service on lis until the test ends, registering it as
+// every session, GQL and health service it implements.
+func serve(t testing.TB, lis net.Listener, services ...any) *grpc.Server {
+	t.Helper()
+	srv := grpc.NewServer()
+	for _, service := range services {
+		registered := false
+		if s, ok := service.(pb.SessionServiceServer); ok {
+			pb.RegisterSessionServiceServer(srv, s)
+			registered = true
+		}
+		if s, ok := service.(pb.GqlServiceServer); ok {
+			pb.RegisterGqlServiceServer(srv, s)
+			registered = true
+		}
+		if s, ok := service.(healthpb.HealthServer); ok {
+			healthpb.RegisterHealthServer(srv, s)
+			registered = true
+		}
+		if !registered {
+			t.Fatalf("serve: %T implements no service", service)
+		}
+	}
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+// serveBufconn serves each service on a new in-memory listener until the
+// test ends.
+func serveBufconn(t testing.TB, services ...any) *bufconn.Listener {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	serve(t, lis, services...)
+	return lis
+}
+
+// bufDialer returns a ConnectionConfig.Dialer that dials lis.
+func bufDialer(lis *bufconn.Listener) func(context.Context, string) (net.Conn, error) {
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}
+}
+
+// connectBufconn connects to lis with config and closes the connection
+// when the test ends.
+func connectBufconn(t testing.TB, lis *bufconn.Listener, config ConnectionConfig) *GqlConnection {
+	t.Helper()
+	ctx := context.Background()
+	config.Dialer = bufDialer(lis)
+	conn, err := ConnectWithConfig(ctx, "bufnet", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+	return conn
+}
@@ -0,0 +1,347 @@
+package gwp
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/protobuf/proto"
+)
+
+// CachedResult is a statement result held by a CacheStore: the response
+// frames of a completed statement. Frames are shared by every cursor
+// served from the cache and must not be modified.
+type CachedResult struct {
+	Frames  []*pb.ExecuteResponse
+	Expires time.Time
+}
+
+// CacheStore holds the results of a ResultCache. Implementations must be
+// safe for concurrent use and may drop results at any time.
+type CacheStore interface {
+	Get(key string) (*CachedResult, bool)
+	Set(key string, result *CachedResult)
+	Clear()
+}
+
+// lruStore is the in-memory CacheStore returned by NewLRUCacheStore.
+type lruStore struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key    string
+	result *CachedResult
+}
+
+// NewLRUCacheStore returns an in-memory CacheStore holding up to capacity
+// results, evicting the least recently used.
+func NewLRUCacheStore(capacity int) CacheStore {
+	return &lruStore{capacity: capacity, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (s *lruStore) Get(key string) (*CachedResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(e)
+	return e.Value.(*lruEntry).result, true
+}
+
+func (s *lruStore) Set(key string, result *CachedResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok {
+		e.Value.(*lruEntry).result = result
+		s.order.MoveToFront(e)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, result: result})
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (s *lruStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order.Init()
+	clear(s.entries)
+}
+
+// ResultCacheConfig holds configuration for a ResultCache.
+type ResultCacheConfig struct {
+	// Store holds the cached results. Defaults to an in-memory LRU store
+	// of 1000 results.
+	Store CacheStore
+	// TTL is how long a result is served from the cache. Defaults to one
+	// minute.
+	TTL time.Duration
+	// MaxRows is the largest result cached, in rows. Defaults to 10000.
+	MaxRows int
+}
+
+// ResultCache serves repeated read-only statements executed with WithCache
+// from memory, without a round trip to the server. Results are keyed by
+// the whitespace-normalized statement as sent, its parameters including the
+// session's default parameters, and the session state it runs under: graph,
+// schema, time zone and session parameters. Statements in read-write
+// transactions bypass the cache, as do statements executed with WithProfile,
+// WithRawFrames, WithRowLease or WithColumnar, and results that fail or
+// have several result sets.
+//
+// Cached results are not invalidated by writes; call Invalidate or
+// InvalidateAll after changing the data they were read from.
+type ResultCache struct {
+	config ResultCacheConfig
+
+	mu          sync.Mutex
+	generation  uint64
+	generations map[string]uint64
+}
+
+// NewResultCache returns a ResultCache. Set it as
+// ConnectionConfig.ResultCache; one cache may be shared by several
+// connections to the same database.
+func NewResultCache(config ResultCacheConfig) *ResultCache {
+	if config.Store == nil {
+		config.Store = NewLRUCacheStore(1000)
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if config.MaxRows <= 0 {
+		config.MaxRows = 10000
+	}
+	return &ResultCache{config: config, generations: make(map[string]uint64)}
+}
+
+// Invalidate drops the cached results of statements run against graph,
+// where "" is the server's default graph.
+func (c *ResultCache) Invalidate(graph string) {
+	c.mu.Lock()
+	c.generation++
+	c.generations[graph] = c.generation
+	c.mu.Unlock()
+}
+
+// InvalidateAll drops every cached result.
+func (c *ResultCache) InvalidateAll() {
+	c.mu.Lock()
+	c.generation++
+	clear(c.generations)
+	c.mu.Unlock()
+	c.config.Store.Clear()
+}
+
+// cacheScope is the session state a cached result depends on besides its
+// statement and parameters.
+type cacheScope struct {
+	graph    string
+	schema   string
+	timeZone string         // offset in minutes, or "" if not set
+	params   map[string]any // server-side session parameters
+}
+
+// key returns the cache key of a statement. It includes the generation of
+// the graph, so that invalidated results are no longer found. It fails if
+// a parameter cannot be encoded.
+func (c *ResultCache) key(scope cacheScope, statement string, params map[string]any) (string, error) {
+	c.mu.Lock()
+	generation := c.generations[scope.graph]
+	c.mu.Unlock()
+
+	h := sha256.New()
+	for _, part := range []string{strconv.FormatUint(generation, 10), scope.graph, scope.schema, scope.timeZone, normalizeStatement(statement)} {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	if err := hashParams(h, scope.params); err != nil {
+		return "", err
+	}
+	h.Write([]byte{0})
+	if err := hashParams(h, params); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashParams writes params to h in name order.
+func hashParams(h io.Writer, params map[string]any) error {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		io.WriteString(h, name)
+		h.Write([]byte{0})
+		pv, err := valueToProto(params[name])
+		if err != nil {
+			return err
+		}
+		value, _ := proto.MarshalOptions{Deterministic: true}.Marshal(pv)
+		h.Write(value)
+		h.Write([]byte{0})
+	}
+	return nil
+}
+
+// normalizeStatement collapses runs of whitespace outside quoted literals
+// and identifiers into single spaces and trims the ends.
+func normalizeStatement(statement string) string {
+	var b strings.Builder
+	b.Grow(len(statement))
+	space := false
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+		switch c {
+		case ' ', '\t', '\n', '\r', '\f', '\v':
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		if c == '\'' || c == '"' || c == '`' {
+			end := skipQuoted(statement, i)
+			b.WriteString(statement[i:min(end+1, len(statement))])
+			i = end
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// get returns the unexpired frames cached under key.
+func (c *ResultCache) get(key string) ([]*pb.ExecuteResponse, bool) {
+	r, ok := c.config.Store.Get(key)
+	if !ok || time.Now().After(r.Expires) {
+		return nil, false
+	}
+	return r.Frames, true
+}
+
+// record returns a stream that copies the frames it reads, and a cursor
+// hook that caches them under key if the statement completes successfully.
+func (c *ResultCache) record(stream resultCursorStream, key string) (*recordingStream, func(*ResultCursor, error)) {
+	r := &recordingStream{stream: stream, maxRows: c.config.MaxRows}
+	return r, func(cursor *ResultCursor, err error) {
+		if err != nil || !r.complete || r.rows > r.maxRows || cursor.summary == nil || IsException(cursor.summary.GetStatus().GetCode()) {
+			return
+		}
+		c.config.Store.Set(key, &CachedResult{Frames: r.frames, Expires: time.Now().Add(c.config.TTL)})
+	}
+}
+
+// recordingStream copies the frames read from a stream until it ends or
+// the result outgrows the cache.
+type recordingStream struct {
+	stream   resultCursorStream
+	maxRows  int
+	frames   []*pb.ExecuteResponse
+	rows     int
+	complete bool
+}
+
+func (r *recordingStream) Recv() (*pb.ExecuteResponse, error) {
+	resp, err := r.stream.Recv()
+	if err == io.EOF {
+		r.complete = true
+	}
+	if err != nil || r.rows > r.maxRows {
+		return resp, err
+	}
+	r.rows += len(resp.GetRowBatch().GetRows())
+	if r.rows <= r.maxRows {
+		r.frames = append(r.frames, resp)
+	} else {
+		r.frames = nil
+	}
+	return resp, nil
+}
+
+// cachedStream replays cached frames.
+type cachedStream struct {
+	frames []*pb.ExecuteResponse
+}
+
+func (s *cachedStream) Recv() (*pb.ExecuteResponse, error) {
+	if len(s.frames) == 0 {
+		return nil, io.EOF
+	}
+	f := s.frames[0]
+	s.frames = s.frames[1:]
+	return f, nil
+}
+
+// WithCache serves the statement from the connection's ResultCache if it
+// holds the result, and caches the result otherwise. The statement must be
+// read-only.
+func WithCache() ExecuteOption {
+	return func(o *executeOptions) {
+		o.cache = true
+	}
+}
+
+// inReadOnlyTransaction marks a statement of a read-only transaction,
+// which may use the result cache.
+func inReadOnlyTransaction() ExecuteOption {
+	return func(o *executeOptions) {
+		o.readOnlyTransaction = true
+	}
+}
+
+// resultCache returns the cache for a statement, or nil if it bypasses
+// the cache.
+func (s *GqlSession) resultCache(o *executeOptions, inTransaction bool) *ResultCache {
+	if !o.cache || s.conn == nil || s.conn.config.ResultCache == nil {
+		return nil
+	}
+	if o.profile || o.rawFrames || o.rowLease || o.columnar {
+		return nil
+	}
+	if inTransaction && !o.readOnlyTransaction {
+		return nil
+	}
+	return s.conn.config.ResultCache
+}
+
+// cacheKey returns the cache key of a statement executed on the session.
+// statement is the statement as sent, with its USE and AT clauses, and
+// params include the session's default parameters.
+func (s *GqlSession) cacheKey(cache *ResultCache, o *executeOptions, statement string, params map[string]any) (string, error) {
+	scope := cacheScope{graph: o.graph, schema: o.schema}
+	s.mu.Lock()
+	if scope.graph == "" {
+		scope.graph = s.graph
+	}
+	if scope.schema == "" {
+		scope.schema = s.schema
+	}
+	if s.timeZoneSet {
+		scope.timeZone = strconv.FormatInt(int64(s.timeZone), 10)
+	}
+	scope.params = maps.Clone(s.params)
+	s.mu.Unlock()
+	return cache.key(scope, statement, params)
+}
@@ -0,0 +1,175 @@
+package gwp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func newCacheSession(cache *ResultCache) (*GqlSession, *fakeGqlClient) {
+	client := &fakeGqlClient{}
+	conn := &GqlConnection{config: ConnectionConfig{ResultCache: cache}}
+	return &GqlSession{sessionID: "s1", gqlClient: client, conn: conn}, client
+}
+
+// cachedName executes a cacheable statement and reports whether it was sent
+// to the server.
+func cachedName(t *testing.T, s *GqlSession, client *fakeGqlClient, statement string, params map[string]any, opts ...ExecuteOption) (string, bool) {
+	t.Helper()
+	client.lastReq = nil
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("name"), batchFrame([]any{"Alice"}), summaryFrame(Success, 0)}}
+	cursor, err := s.Execute(context.Background(), statement, params, append(opts, WithCache())...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := cursor.ScalarString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return name, client.lastReq != nil
+}
+
+func TestResultCache(t *testing.T) {
+	cache := NewResultCache(ResultCacheConfig{})
+	s, client := newCacheSession(cache)
+	params := map[string]any{"name": "Alice", "age": int64(30)}
+
+	if name, sent := cachedName(t, s, client, "MATCH (p {name: $name}) RETURN p.name", params); !sent || name != "Alice" {
+		t.Fatalf("first execution: %q, sent %v", name, sent)
+	}
+	if name, sent := cachedName(t, s, client, "MATCH (p {name: $name})\n  RETURN p.name", params); sent || name != "Alice" {
+		t.Fatalf("repeated execution: %q, sent %v", name, sent)
+	}
+	if _, sent := cachedName(t, s, client, "MATCH (p {name: $name}) RETURN p.name", map[string]any{"name": "Bob"}); !sent {
+		t.Fatal("different parameters served from the cache")
+	}
+	if _, sent := cachedName(t, s, client, "MATCH (p {name: $name}) RETURN p.name", params, WithGraph("other")); !sent {
+		t.Fatal("different graph served from the cache")
+	}
+
+	if _, sent := cachedName(t, s, client, "MATCH (p {name: 'a b'}) RETURN p.name", nil); !sent {
+		t.Fatal("first literal execution served from the cache")
+	}
+	if _, sent := cachedName(t, s, client, "MATCH  (p {name: 'a  b'}) RETURN p.name", nil); !sent {
+		t.Fatal("whitespace inside a string literal ignored by the cache key")
+	}
+	if _, sent := cachedName(t, s, client, "MATCH (p {name: 'a  b'})  RETURN p.name ", nil); sent {
+		t.Fatal("whitespace outside literals not normalized")
+	}
+
+	cache.Invalidate("other")
+	if _, sent := cachedName(t, s, client, "MATCH (p {name: $name}) RETURN p.name", params); sent {
+		t.Fatal("invalidating another graph dropped the result")
+	}
+	cache.Invalidate("")
+	if _, sent := cachedName(t, s, client, "MATCH (p {name: $name}) RETURN p.name", params); !sent {
+		t.Fatal("invalidated result served from the cache")
+	}
+	cache.InvalidateAll()
+	if _, sent := cachedName(t, s, client, "MATCH (p {name: $name}) RETURN p.name", params); !sent {
+		t.Fatal("result served after InvalidateAll")
+	}
+}
+
+func TestResultCacheSessionState(t *testing.T) {
+	s, client := newCacheSession(NewResultCache(ResultCacheConfig{}))
+	const stmt = "MATCH (p) RETURN p.name"
+	cachedName(t, s, client, stmt, nil)
+
+	s.timeZone, s.timeZoneSet = 60, true
+	if _, sent := cachedName(t, s, client, stmt, nil); !sent {
+		t.Fatal("different time zone served from the cache")
+	}
+	s.params = map[string]any{"tenant": "a"}
+	if _, sent := cachedName(t, s, client, stmt, nil); !sent {
+		t.Fatal("different session parameters served from the cache")
+	}
+	if _, sent := cachedName(t, s, client, stmt, nil); sent {
+		t.Fatal("same session state not served from the cache")
+	}
+	s.SetDefaultParams(map[string]any{"limit": int64(10)})
+	if _, sent := cachedName(t, s, client, stmt, nil); !sent {
+		t.Fatal("different default parameters served from the cache")
+	}
+
+	for name, opt := range map[string]ExecuteOption{"WithProfile": WithProfile(), "WithRowLease": WithRowLease()} {
+		for i := 0; i < 2; i++ {
+			if _, sent := cachedName(t, s, client, stmt, nil, opt); !sent {
+				t.Fatalf("execution %d with %s served from the cache", i, name)
+			}
+		}
+	}
+}
+
+func TestResultCacheBypass(t *testing.T) {
+	cache := NewResultCache(ResultCacheConfig{TTL: time.Hour})
+	s, client := newCacheSession(cache)
+	cachedName(t, s, client, "MATCH (n) RETURN n.name", nil)
+
+	tx := &Transaction{session: s, sessionID: "s1", transactionID: "tx1", gqlClient: client}
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("name"), summaryFrame(Success, 0)}}
+	client.lastReq = nil
+	cursor, err := tx.Execute(context.Background(), "MATCH (n) RETURN n.name", nil, WithCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor.CollectRows()
+	if client.lastReq == nil {
+		t.Fatal("statement in a read-write transaction served from the cache")
+	}
+
+	tx.readOnly = true
+	client.lastReq = nil
+	if _, err := tx.Execute(context.Background(), "MATCH (n) RETURN n.name", nil, WithCache()); err != nil || client.lastReq != nil {
+		t.Fatalf("read-only transaction: err = %v, sent %v", err, client.lastReq != nil)
+	}
+
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("n"), summaryFrame("42001", 0)}}
+	for i := 0; i < 2; i++ {
+		client.lastReq = nil
+		cursor, err := s.Execute(context.Background(), "MATCH (", nil, WithCache())
+		if err != nil {
+			t.Fatal(err)
+		}
+		cursor.CollectRows()
+		if client.lastReq == nil {
+			t.Fatal("failed statement served from the cache")
+		}
+		client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("n"), summaryFrame("42001", 0)}}
+	}
+}
+
+func TestResultCacheLimits(t *testing.T) {
+	s, client := newCacheSession(NewResultCache(ResultCacheConfig{MaxRows: 1}))
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("n"), batchFrame([]any{int64(1)}, []any{int64(2)}), summaryFrame(Success, 0)}}
+	cursor, _ := s.Execute(context.Background(), "UNWIND [1, 2] AS n RETURN n", nil, WithCache())
+	if rows, err := cursor.CollectRows(); err != nil || len(rows) != 2 {
+		t.Fatalf("rows = %v, %v", rows, err)
+	}
+	client.stream = &fakeStream{frames: []*pb.ExecuteResponse{headerFrame("n"), summaryFrame(Success, 0)}}
+	client.lastReq = nil
+	cursor, _ = s.Execute(context.Background(), "UNWIND [1, 2] AS n RETURN n", nil, WithCache())
+	cursor.CollectRows()
+	if client.lastReq == nil {
+		t.Fatal("result larger than MaxRows was cached")
+	}
+
+	store := NewLRUCacheStore(2)
+	for _, key := range []string{"a", "b", "a", "c"} {
+		store.Set(key, &CachedResult{})
+	}
+	if _, ok := store.Get("b"); ok {
+		t.Fatal("least recently used result was kept")
+	}
+	if _, ok := store.Get("a"); !ok {
+		t.Fatal("recently used result was evicted")
+	}
+
+	cache := NewResultCache(ResultCacheConfig{Store: store})
+	store.Set("expired", &CachedResult{Expires: time.Now().Add(-time.Second)})
+	if _, ok := cache.get("expired"); ok {
+		t.Fatal("expired result served")
+	}
+}
@@ -0,0 +1,89 @@
+package gwp
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The wire protocol has no cancel RPC: a statement is cancelled on the
+// server by cancelling its Execute stream, which the server sees as the
+// RPC being cancelled. Statements can therefore only be cancelled by the
+// process that sent them.
+
+// errCursorCancelled finishes a cursor closed or cancelled before the end
+// of its stream.
+var errCursorCancelled = status.Error(codes.Canceled, "statement cancelled")
+
+// Cancel aborts the statement by cancelling its result stream. It may be
+// called from a goroutine other than the one reading the cursor, whose
+// next read then fails with a Canceled error. The statement is finished at
+// once, giving back its admission slot. A statement cancelled within a
+// transaction rolls the transaction back, as when the context of its
+// Execute is cancelled.
+func (c *ResultCursor) Cancel() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.finish(errCursorCancelled)
+}
+
+// Close releases a cursor that will not be read further, cancelling its
+// stream unless it has ended. A cursor must be read to the end, closed or
+// cancelled, or it holds on to its admission slot, and Shutdown waits for
+// it. Like Cancel, closing a cursor before the end of its stream rolls back
+// its transaction; closing a finished cursor does nothing.
+func (c *ResultCursor) Close() {
+	c.stop(errCursorCancelled)
+}
+
+// CancelQuery cancels the session's running statements executed
+// WithQueryID(id), as Cancel does, and reports whether there were any.
+func (s *GqlSession) CancelQuery(id string) bool {
+	s.mu.Lock()
+	var cursors []*ResultCursor
+	for cursor, queryID := range s.running {
+		if queryID == id {
+			cursors = append(cursors, cursor)
+		}
+	}
+	s.mu.Unlock()
+	for _, cursor := range cursors {
+		cursor.Cancel()
+	}
+	return len(cursors) > 0
+}
+
+// CancelQuery cancels the running statements executed WithQueryID(id) on
+// any of the connection's sessions, such as for a "kill query" action in an
+// admin UI, and reports whether there were any.
+func (c *GqlConnection) CancelQuery(id string) bool {
+	c.mu.Lock()
+	sessions := make([]*GqlSession, 0, len(c.sessions))
+	for s := range c.sessions {
+		sessions = append(sessions, s)
+	}
+	c.mu.Unlock()
+	cancelled := false
+	for _, s := range sessions {
+		if s.CancelQuery(id) {
+			cancelled = true
+		}
+	}
+	return cancelled
+}
+
+// trackQuery registers a running statement under its query ID until its
+// cursor completes.
+func (s *GqlSession) trackQuery(cursor *ResultCursor, id string) {
+	s.mu.Lock()
+	if s.running == nil {
+		s.running = make(map[*ResultCursor]string)
+	}
+	s.running[cursor] = id
+	s.mu.Unlock()
+	cursor.onDone = append(cursor.onDone, func(error) {
+		s.mu.Lock()
+		delete(s.running, cursor)
+		s.mu.Unlock()
+	})
+}
@@ -0,0 +1,69 @@
+package gwp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blockingServer streams a header and then blocks until the statement is
+// cancelled.
+type blockingServer struct {
+	handshakeServer
+	pb.UnimplementedGqlServiceServer
+	started   chan struct{}
+	cancelled chan struct{}
+}
+
+func (s *blockingServer) Execute(req *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	if err := stream.Send(headerFrame("n")); err != nil {
+		return err
+	}
+	s.started <- struct{}{}
+	<-stream.Context().Done()
+	s.cancelled <- struct{}{}
+	return stream.Context().Err()
+}
+
+func TestCancelQuery(t *testing.T) {
+	bs := &blockingServer{started: make(chan struct{}, 1), cancelled: make(chan struct{}, 1)}
+	ctx := context.Background()
+	conn := connectBufconn(t, serveBufconn(t, bs), ConnectionConfig{})
+	s, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cursor, err := s.Execute(ctx, "MATCH (n) RETURN n", nil, WithQueryID("report-7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-bs.started
+	if conn.CancelQuery("other") {
+		t.Fatal("CancelQuery matched an unknown ID")
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := cursor.NextRow()
+		done <- err
+	}()
+	if !conn.CancelQuery("report-7") {
+		t.Fatal("CancelQuery found no running statement")
+	}
+	select {
+	case <-bs.cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not see the cancellation")
+	}
+	if err := <-done; status.Code(err) != codes.Canceled {
+		t.Fatalf("NextRow after cancel = %v", err)
+	}
+	if s.CancelQuery("report-7") {
+		t.Fatal("finished statement is still registered")
+	}
+}
@@ -0,0 +1,179 @@
+package gwp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// EndpointRole is the role of a server in a cluster.
+type EndpointRole string
+
+// Endpoint roles. RoleAny is used for servers that accept both reads and
+// writes, such as a standalone server.
+const (
+	RolePrimary EndpointRole = "primary"
+	RoleReplica EndpointRole = "replica"
+	RoleAny     EndpointRole = ""
+)
+
+// AccessMode selects whether a cluster session is routed for reads or writes.
+type AccessMode int
+
+// Access modes.
+const (
+	AccessWrite AccessMode = iota
+	AccessRead
+)
+
+// Endpoint is a server in a cluster. The wire protocol does not report a
+// server's role, so it must be given: an endpoint with RoleAny is sent both
+// reads and writes.
+type Endpoint struct {
+	Target string
+	Role   EndpointRole
+}
+
+// RoutingPolicy configures how a cluster routes sessions.
+type RoutingPolicy struct {
+	// ReadFromPrimary allows read sessions on the primary when no replica
+	// is available.
+	ReadFromPrimary bool
+	// RetryAfter is how long an endpoint that failed is skipped before it
+	// is tried again. Defaults to 5 seconds.
+	RetryAfter time.Duration
+}
+
+// GqlCluster routes sessions across multiple GWP servers: writes go to a
+// primary, reads to replicas, and failed endpoints are skipped until
+// RetryAfter elapses. Failover happens only when a session is created; a
+// statement that fails on an established session is not retried on another
+// endpoint, so callers reacting to such failures create a new session.
+type GqlCluster struct {
+	policy  RoutingPolicy
+	members []*clusterMember
+
+	mu   sync.Mutex
+	next int
+}
+
+type clusterMember struct {
+	target    string
+	role      EndpointRole
+	conn      *GqlConnection
+	failedAt  time.Time
+	hasFailed bool
+}
+
+// ConnectCluster connects to every endpoint.
+func ConnectCluster(ctx context.Context, endpoints []Endpoint, policy RoutingPolicy, opts ...grpc.DialOption) (*GqlCluster, error) {
+	if len(endpoints) == 0 {
+		return nil, &GqlError{Message: "cluster requires at least one endpoint"}
+	}
+	if policy.RetryAfter == 0 {
+		policy.RetryAfter = 5 * time.Second
+	}
+
+	c := &GqlCluster{policy: policy}
+	for _, ep := range endpoints {
+		conn, err := Connect(ctx, ep.Target, opts...)
+		if err != nil {
+			c.Close(ctx)
+			return nil, err
+		}
+		c.members = append(c.members, &clusterMember{target: ep.Target, role: ep.Role, conn: conn})
+	}
+	return c, nil
+}
+
+// CreateSession creates a session on an endpoint suited to the access mode,
+// failing over to the next candidate if a handshake fails.
+func (c *GqlCluster) CreateSession(ctx context.Context, mode AccessMode, opts ...SessionOption) (*GqlSession, error) {
+	var lastErr error
+	for _, m := range c.candidates(mode) {
+		s, err := m.conn.CreateSession(ctx, opts...)
+		if err == nil {
+			c.markHealthy(m)
+			return s, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		c.markFailed(m)
+		lastErr = err
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, &SessionError{Message: "no available endpoint for requested access mode"}
+}
+
+// Endpoints returns the cluster members and their roles.
+func (c *GqlCluster) Endpoints() []Endpoint {
+	result := make([]Endpoint, len(c.members))
+	for i, m := range c.members {
+		result[i] = Endpoint{Target: m.target, Role: m.role}
+	}
+	return result
+}
+
+// Close closes the connections to all endpoints, along with any sessions
+// still open on them.
+func (c *GqlCluster) Close(ctx context.Context) error {
+	var firstErr error
+	for _, m := range c.members {
+		if err := m.conn.Close(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// candidates returns the members to try for a mode, healthy members first,
+// rotating the starting point across calls to spread load.
+func (c *GqlCluster) candidates(mode AccessMode) []*clusterMember {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start := c.next
+	c.next++
+
+	var preferred, fallback []*clusterMember
+	for i := range c.members {
+		m := c.members[(start+i)%len(c.members)]
+		switch {
+		case mode == AccessWrite && m.role != RoleReplica:
+			preferred = append(preferred, m)
+		case mode == AccessRead && m.role != RolePrimary:
+			preferred = append(preferred, m)
+		case mode == AccessRead && c.policy.ReadFromPrimary:
+			fallback = append(fallback, m)
+		}
+	}
+
+	now := time.Now()
+	var healthy, failed []*clusterMember
+	for _, m := range append(preferred, fallback...) {
+		if m.hasFailed && now.Sub(m.failedAt) < c.policy.RetryAfter {
+			failed = append(failed, m)
+		} else {
+			healthy = append(healthy, m)
+		}
+	}
+	return append(healthy, failed...)
+}
+
+func (c *GqlCluster) markFailed(m *clusterMember) {
+	c.mu.Lock()
+	m.hasFailed = true
+	m.failedAt = time.Now()
+	c.mu.Unlock()
+}
+
+func (c *GqlCluster) markHealthy(m *clusterMember) {
+	c.mu.Lock()
+	m.hasFailed = false
+	c.mu.Unlock()
+}
@@ -0,0 +1,57 @@
+package gwp
+
+import (
+	"testing"
+	"time"
+)
+
+func testCluster(policy RoutingPolicy, roles ...EndpointRole) *GqlCluster {
+	if policy.RetryAfter == 0 {
+		policy.RetryAfter = time.Minute
+	}
+	c := &GqlCluster{policy: policy}
+	for i, r := range roles {
+		c.members = append(c.members, &clusterMember{target: string(rune('a' + i)), role: r})
+	}
+	return c
+}
+
+func targets(ms []*clusterMember) string {
+	var s string
+	for _, m := range ms {
+		s += m.target
+	}
+	return s
+}
+
+func TestClusterCandidates(t *testing.T) {
+	c := testCluster(RoutingPolicy{}, RolePrimary, RoleReplica, RoleReplica)
+	if got := targets(c.candidates(AccessWrite)); got != "a" {
+		t.Fatalf("write candidates = %q, want a", got)
+	}
+	// Each call rotates the starting member.
+	if got := targets(c.candidates(AccessRead)); got != "bc" {
+		t.Fatalf("read candidates = %q, want bc", got)
+	}
+	if got := targets(c.candidates(AccessRead)); got != "cb" {
+		t.Fatalf("read candidates = %q, want cb", got)
+	}
+
+	c = testCluster(RoutingPolicy{ReadFromPrimary: true}, RolePrimary, RoleReplica)
+	if got := targets(c.candidates(AccessRead)); got != "ba" {
+		t.Fatalf("read candidates with fallback = %q, want ba", got)
+	}
+}
+
+func TestClusterFailedMembersLast(t *testing.T) {
+	c := testCluster(RoutingPolicy{}, RoleAny, RoleAny)
+	c.markFailed(c.members[0])
+	if got := targets(c.candidates(AccessWrite)); got != "ba" {
+		t.Fatalf("candidates = %q, want ba", got)
+	}
+	c.markHealthy(c.members[0])
+	c.next = 0
+	if got := targets(c.candidates(AccessWrite)); got != "ab" {
+		t.Fatalf("candidates = %q, want ab", got)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// runCatalog runs a schema or graph management command.
+func runCatalog(opts options, cmd string, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	ifExists := fs.Bool("if-exists", false, "do not fail if the schema or graph does not exist (drop)")
+	ifNotExists := fs.Bool("if-not-exists", false, "do not fail if the schema or graph already exists (create)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	name := fs.Arg(0)
+	switch cmd {
+	case "schemas", "graphs":
+		if fs.NArg() != 0 {
+			return fmt.Errorf("usage: gwp %s", cmd)
+		}
+	default:
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: gwp %s NAME", cmd)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	conn, err := gwp.Connect(ctx, opts.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	catalog := conn.CreateCatalogClient()
+
+	switch cmd {
+	case "schemas":
+		schemas, err := catalog.ListSchemas(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range schemas {
+			fmt.Fprintf(stdout, "%s\tgraphs=%d graph_types=%d\n", s.Name, s.GraphCount, s.GraphTypeCount)
+		}
+	case "graphs":
+		graphs, err := catalog.ListGraphs(ctx, opts.Schema)
+		if err != nil {
+			return err
+		}
+		for _, g := range graphs {
+			fmt.Fprintf(stdout, "%s\tnodes=%d edges=%d\n", g.Name, g.NodeCount, g.EdgeCount)
+		}
+	case "create-schema":
+		return catalog.CreateSchema(ctx, name, *ifNotExists)
+	case "drop-schema":
+		existed, err := catalog.DropSchema(ctx, name, *ifExists)
+		if err == nil && !existed {
+			fmt.Fprintf(stdout, "schema %s does not exist\n", name)
+		}
+		return err
+	case "create-graph":
+		_, err := catalog.CreateGraph(ctx, gwp.CreateGraphConfig{
+			Schema:      opts.Schema,
+			Name:        name,
+			IfNotExists: *ifNotExists,
+		})
+		return err
+	case "drop-graph":
+		existed, err := catalog.DropGraph(ctx, opts.Schema, name, *ifExists)
+		if err == nil && !existed {
+			fmt.Fprintf(stdout, "graph %s does not exist\n", name)
+		}
+		return err
+	}
+	return nil
+}
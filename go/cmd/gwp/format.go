@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// printer writes result sets in one output format.
+type printer interface {
+	print(rs gwp.ResultSet) error
+}
+
+func newPrinter(w io.Writer, format string) (printer, error) {
+	switch format {
+	case "table", "":
+		return tablePrinter{w}, nil
+	case "json":
+		return jsonPrinter{w}, nil
+	case "csv":
+		return csvPrinter{w}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// summaryLine describes a result set without columns, such as an INSERT.
+func summaryLine(s *gwp.ResultSummary) string {
+	if s == nil {
+		return "OK"
+	}
+	return fmt.Sprintf("%s, %d rows affected", s.StatusCode(), s.RowsAffected())
+}
+
+type tablePrinter struct {
+	w io.Writer
+}
+
+func (p tablePrinter) print(rs gwp.ResultSet) error {
+	if len(rs.Columns) == 0 {
+		_, err := fmt.Fprintln(p.w, summaryLine(rs.Summary))
+		return err
+	}
+
+	cells := make([][]string, len(rs.Rows))
+	widths := make([]int, len(rs.Columns))
+	for i, c := range rs.Columns {
+		widths[i] = utf8.RuneCountInString(c)
+	}
+	for r, row := range rs.Rows {
+		cells[r] = make([]string, len(row))
+		for i, v := range row {
+			cells[r][i] = formatValue(v)
+			if i < len(widths) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(cells[r][i]))
+			}
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(values []string) {
+		for i, v := range values {
+			if i > 0 {
+				b.WriteString(" |")
+			}
+			b.WriteString(" ")
+			b.WriteString(v)
+			if i < len(widths) && i < len(values)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(v)))
+			}
+		}
+		b.WriteString("\n")
+	}
+	writeRow(rs.Columns)
+	for i, w := range widths {
+		if i > 0 {
+			b.WriteString("+")
+		}
+		b.WriteString(strings.Repeat("-", w+2))
+	}
+	b.WriteString("\n")
+	for _, row := range cells {
+		writeRow(row)
+	}
+	if len(rs.Rows) == 1 {
+		b.WriteString("(1 row)\n")
+	} else {
+		fmt.Fprintf(&b, "(%d rows)\n", len(rs.Rows))
+	}
+	_, err := io.WriteString(p.w, b.String())
+	return err
+}
+
+// jsonPrinter writes one JSON object per row, keyed by column name.
+type jsonPrinter struct {
+	w io.Writer
+}
+
+func (p jsonPrinter) print(rs gwp.ResultSet) error {
+	enc := json.NewEncoder(p.w)
+	if len(rs.Columns) == 0 {
+		out := map[string]any{"status": "", "rows_affected": int64(0)}
+		if rs.Summary != nil {
+			out["status"] = rs.Summary.StatusCode()
+			out["rows_affected"] = rs.Summary.RowsAffected()
+		}
+		return enc.Encode(out)
+	}
+	for _, row := range rs.Rows {
+		obj := make(map[string]any, len(rs.Columns))
+		for i, c := range rs.Columns {
+			if i < len(row) {
+				obj[c] = jsonValue(row[i])
+			}
+		}
+		if err := enc.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type csvPrinter struct {
+	w io.Writer
+}
+
+func (p csvPrinter) print(rs gwp.ResultSet) error {
+	if len(rs.Columns) == 0 {
+		_, err := fmt.Fprintln(p.w, summaryLine(rs.Summary))
+		return err
+	}
+	cw := csv.NewWriter(p.w)
+	if err := cw.Write(rs.Columns); err != nil {
+		return err
+	}
+	for _, row := range rs.Rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			if v != nil {
+				record[i] = formatValue(v)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatValue renders a result value in GQL-like literal syntax.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return v
+	case []byte:
+		return "X'" + hex.EncodeToString(v) + "'"
+	case *gwp.GqlNode:
+		return "(" + labels(v.Labels) + properties(v.PropertyMap()) + ")"
+	case *gwp.GqlEdge:
+		return "[" + labels(v.Labels) + properties(v.PropertyMap()) + "]"
+	case *gwp.GqlPath:
+		var b strings.Builder
+		for i, n := range v.Nodes {
+			if i > 0 && i-1 < len(v.Edges) {
+				b.WriteString("-" + formatValue(v.Edges[i-1]) + "->")
+			}
+			b.WriteString(formatValue(n))
+		}
+		return b.String()
+	case *gwp.GqlRecord:
+		fields := make([]string, len(v.Fields))
+		for i, f := range v.Fields {
+			fields[i] = f.Name + ": " + literal(f.Value)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case []any:
+		items := make([]string, len(v))
+		for i, e := range v {
+			items[i] = literal(e)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *gwp.GqlDate:
+		return formatDate(v)
+	case *gwp.GqlLocalTime:
+		return formatTime(v)
+	case *gwp.GqlZonedTime:
+		return formatTime(&v.Time) + formatOffset(v.OffsetMinutes)
+	case *gwp.GqlLocalDateTime:
+		return formatDate(&v.Date) + "T" + formatTime(&v.Time)
+	case *gwp.GqlZonedDateTime:
+		return formatDate(&v.Date) + "T" + formatTime(&v.Time) + formatOffset(v.OffsetMinutes)
+	case *gwp.GqlDuration:
+		return fmt.Sprintf("P%dMT%gS", v.Months, float64(v.Nanoseconds)/1e9)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// literal formats nested values, quoting strings.
+func literal(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return formatValue(v)
+}
+
+func labels(ls []string) string {
+	if len(ls) == 0 {
+		return ""
+	}
+	return ":" + strings.Join(ls, ":")
+}
+
+func properties(props map[string]any) string {
+	if len(props) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]string, len(keys))
+	for i, k := range keys {
+		fields[i] = k + ": " + literal(props[k])
+	}
+	return " {" + strings.Join(fields, ", ") + "}"
+}
+
+func formatDate(d *gwp.GqlDate) string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+func formatTime(t *gwp.GqlLocalTime) string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", t.Nanosecond), "0")
+	}
+	return s
+}
+
+func formatOffset(minutes int32) string {
+	sign := "+"
+	if minutes < 0 {
+		sign, minutes = "-", -minutes
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, minutes/60, minutes%60)
+}
+
+// jsonValue converts a result value into plain JSON data. Graph elements
+// become objects with their labels and properties; other non-JSON values
+// use their literal form.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case nil, bool, int64, uint64, float64, string:
+		return v
+	case *gwp.GqlNode:
+		return map[string]any{"labels": v.Labels, "properties": jsonMap(v.PropertyMap())}
+	case *gwp.GqlEdge:
+		return map[string]any{"labels": v.Labels, "properties": jsonMap(v.PropertyMap())}
+	case *gwp.GqlRecord:
+		out := make(map[string]any, len(v.Fields))
+		for _, f := range v.Fields {
+			out[f.Name] = jsonValue(f.Value)
+		}
+		return out
+	case map[string]any:
+		return jsonMap(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = jsonValue(e)
+		}
+		return out
+	default:
+		return formatValue(v)
+	}
+}
+
+func jsonMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = jsonValue(v)
+	}
+	return out
+}
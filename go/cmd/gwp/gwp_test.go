@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{nil, "NULL"},
+		{int64(42), "42"},
+		{[]any{"a", int64(1)}, `["a", 1]`},
+		{&gwp.GqlNode{Labels: []string{"Person"}, Properties: map[string]any{"name": "Alice", "age": int64(30)}}, `(:Person {age: 30, name: "Alice"})`},
+		{&gwp.GqlDate{Year: 2024, Month: 3, Day: 9}, "2024-03-09"},
+		{&gwp.GqlZonedTime{Time: gwp.GqlLocalTime{Hour: 9, Minute: 5, Nanosecond: 500000000}, OffsetMinutes: -90}, "09:05:00.5-01:30"},
+	}
+	for _, tt := range tests {
+		if got := formatValue(tt.v); got != tt.want {
+			t.Errorf("formatValue(%#v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestPrinters(t *testing.T) {
+	rs := gwp.ResultSet{
+		Columns: []string{"name", "age"},
+		Rows:    [][]any{{"Alice", int64(30)}, {"Bob", nil}},
+	}
+	tests := map[string]string{
+		"table": " name  | age\n-------+------\n Alice | 30\n Bob   | NULL\n(2 rows)\n",
+		"json":  "{\"age\":30,\"name\":\"Alice\"}\n{\"age\":null,\"name\":\"Bob\"}\n",
+		"csv":   "name,age\nAlice,30\nBob,\n",
+	}
+	for format, want := range tests {
+		var buf bytes.Buffer
+		p, err := newPrinter(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.print(rs); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if buf.String() != want {
+			t.Errorf("%s output:\n%s\nwant:\n%s", format, buf.String(), want)
+		}
+	}
+	if _, err := newPrinter(&bytes.Buffer{}, "xml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	fs := flag.NewFlagSet("gwp", flag.ContinueOnError)
+	opts := options{}
+	fs.StringVar(&opts.Addr, "addr", "localhost:50051", "")
+	fs.StringVar(&opts.Graph, "graph", "", "")
+	if err := fs.Parse([]string{"-graph", "explicit"}); err != nil {
+		t.Fatal(err)
+	}
+	got := applyProfile(fs, opts, options{Addr: "prod:50051", Graph: "social", Schema: "app"})
+	if got.Addr != "prod:50051" || got.Graph != "explicit" || got.Schema != "app" {
+		t.Fatalf("applyProfile = %+v", got)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	t.Setenv("GWP_HISTORY", path)
+
+	h := openHistory()
+	h.add("MATCH (n)\nRETURN n")
+	h.add("RETURN 'a;b'")
+
+	h = openHistory()
+	if got := h.last(5); len(got) != 2 || got[1] != "RETURN 'a;b'" {
+		t.Fatalf("history = %q", got)
+	}
+	if got := h.last(1); len(got) != 1 || got[0] != "RETURN 'a;b'" {
+		t.Fatalf("last(1) = %q", got)
+	}
+}
+
+// startTestServer runs the test server binary, skipping the test if it has
+// not been built.
+func startTestServer(t *testing.T) string {
+	t.Helper()
+	binary := filepath.Join("..", "..", "..", "target", "release", "gwp-test-server")
+	if _, err := os.Stat(binary); err != nil {
+		t.Skip("gwp-test-server not built")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	cmd := exec.Command(binary, fmt.Sprint(port))
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	ctx := context.Background()
+	conn, err := gwp.Connect(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	if err := conn.WaitUntilReady(ctx, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func TestExecCommand(t *testing.T) {
+	addr := startTestServer(t)
+	t.Setenv("GWP_PROFILES", filepath.Join(t.TempDir(), "none.json"))
+
+	var out bytes.Buffer
+	err := run([]string{"-addr", addr, "-format", "csv", "exec", "-q", "MATCH (n) RETURN n.name, n.age; INSERT (:Person)"}, nil, &out)
+	if err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if !strings.Contains(out.String(), "Alice,30\nBob,25\n") || !strings.Contains(out.String(), "3 rows affected") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	if err := run([]string{"-addr", addr, "exec", "-q", "ERROR"}, nil, &out); err == nil {
+		t.Fatal("expected error from failing statement")
+	}
+}
+
+func TestShell(t *testing.T) {
+	addr := startTestServer(t)
+	t.Setenv("GWP_PROFILES", filepath.Join(t.TempDir(), "none.json"))
+	t.Setenv("GWP_HISTORY", filepath.Join(t.TempDir(), "history"))
+
+	in := strings.NewReader(":format json\nMATCH (n)\nRETURN n.name AS name;\n:history\n:quit\n")
+	var out bytes.Buffer
+	if err := run([]string{"-addr", addr}, in, &out); err != nil {
+		t.Fatalf("shell: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"  -> ", `"Alice"`, "MATCH (n)\nRETURN n.name AS name;"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("shell output missing %q:\n%s", want, got)
+		}
+	}
+}
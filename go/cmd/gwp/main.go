@@ -0,0 +1,154 @@
+// Command gwp is an interactive shell and scripting tool for GWP servers.
+//
+// Usage:
+//
+//	gwp [flags]                         start the interactive shell
+//	gwp [flags] exec -q "MATCH ..."     run statements and print their results
+//	gwp [flags] exec -f script.gql      run a script (or stdin with no -q/-f)
+//	gwp [flags] schemas                 list schemas
+//	gwp [flags] graphs                  list graphs in -schema
+//	gwp [flags] create-schema NAME
+//	gwp [flags] drop-schema NAME
+//	gwp [flags] create-graph NAME
+//	gwp [flags] drop-graph NAME
+//	gwp profiles                        list connection profiles
+//
+// Statements are separated by semicolons. Results are printed as a table,
+// newline-delimited JSON objects (-format json) or CSV (-format csv).
+//
+// Connection profiles are read from $GWP_PROFILES, or gwp/profiles.json in
+// the user config directory:
+//
+//	{
+//	  "local": {"addr": "localhost:50051", "graph": "social"},
+//	  "prod": {"addr": "gwp.internal:50051", "schema": "app", "format": "json"}
+//	}
+//
+// -profile (or $GWP_PROFILE) selects one; flags given explicitly override
+// its settings.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// options are the resolved connection and output settings.
+type options struct {
+	Addr    string        `json:"addr"`
+	Schema  string        `json:"schema"`
+	Graph   string        `json:"graph"`
+	Format  string        `json:"format"`
+	Timeout time.Duration `json:"-"`
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "gwp:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gwp", flag.ContinueOnError)
+	opts := options{}
+	fs.StringVar(&opts.Addr, "addr", "localhost:50051", "server address")
+	fs.StringVar(&opts.Schema, "schema", "", "session schema")
+	fs.StringVar(&opts.Graph, "graph", "", "session graph")
+	fs.StringVar(&opts.Format, "format", "table", "output format: table, json or csv")
+	fs.DurationVar(&opts.Timeout, "timeout", 30*time.Second, "connect timeout")
+	profile := fs.String("profile", os.Getenv("GWP_PROFILE"), "connection profile")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	profiles, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+	if *profile != "" {
+		p, ok := profiles[*profile]
+		if !ok {
+			return fmt.Errorf("unknown profile %q", *profile)
+		}
+		opts = applyProfile(fs, opts, p)
+	}
+	if _, err := newPrinter(stdout, opts.Format); err != nil {
+		return err
+	}
+
+	cmd, cmdArgs := "shell", fs.Args()
+	if len(cmdArgs) > 0 {
+		cmd, cmdArgs = cmdArgs[0], cmdArgs[1:]
+	}
+	switch cmd {
+	case "shell":
+		return runShell(opts, stdin, stdout)
+	case "exec":
+		return runExec(opts, cmdArgs, stdin, stdout)
+	case "profiles":
+		return printProfiles(stdout, profiles)
+	case "schemas", "graphs", "create-schema", "drop-schema", "create-graph", "drop-graph":
+		return runCatalog(opts, cmd, cmdArgs, stdout)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// connect opens a connection and a session using the schema and graph in
+// opts.
+func connect(ctx context.Context, opts options) (*gwp.GqlConnection, *gwp.GqlSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	conn, err := gwp.Connect(ctx, opts.Addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		conn.Close(ctx)
+		return nil, nil, err
+	}
+	if opts.Schema != "" {
+		if err := session.SetSchema(ctx, opts.Schema); err != nil {
+			conn.Close(ctx)
+			return nil, nil, err
+		}
+	}
+	if opts.Graph != "" {
+		if err := session.SetGraph(ctx, opts.Graph); err != nil {
+			conn.Close(ctx)
+			return nil, nil, err
+		}
+	}
+	return conn, session, nil
+}
+
+// execute runs one statement and prints all of its result sets. An
+// exception status in a summary is returned as a *gwp.GqlStatusError.
+func execute(ctx context.Context, session *gwp.GqlSession, p printer, statement string) error {
+	cursor, err := session.Execute(ctx, statement, nil)
+	if err != nil {
+		return err
+	}
+	result, err := cursor.CollectResultSets()
+	if err != nil {
+		return err
+	}
+	for _, rs := range result.ResultSets {
+		if rs.Summary != nil && gwp.IsException(rs.Summary.StatusCode()) {
+			return &gwp.GqlStatusError{Code: rs.Summary.StatusCode(), Message: rs.Summary.Message()}
+		}
+		if err := p.print(rs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// profilesPath returns the location of the profiles file.
+func profilesPath() (string, error) {
+	if p := os.Getenv("GWP_PROFILES"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gwp", "profiles.json"), nil
+}
+
+// loadProfiles reads the profiles file. A missing file means no profiles.
+func loadProfiles() (map[string]options, error) {
+	path, err := profilesPath()
+	if err != nil {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var profiles map[string]options
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// applyProfile fills the settings of opts that were not given explicitly on
+// the command line from profile p.
+func applyProfile(fs *flag.FlagSet, opts, p options) options {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if !set["addr"] && p.Addr != "" {
+		opts.Addr = p.Addr
+	}
+	if !set["schema"] && p.Schema != "" {
+		opts.Schema = p.Schema
+	}
+	if !set["graph"] && p.Graph != "" {
+		opts.Graph = p.Graph
+	}
+	if !set["format"] && p.Format != "" {
+		opts.Format = p.Format
+	}
+	return opts
+}
+
+func printProfiles(w io.Writer, profiles map[string]options) error {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := profiles[name]
+		if _, err := fmt.Fprintf(w, "%s\t%s\tschema=%s graph=%s\n", name, p.Addr, p.Schema, p.Graph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
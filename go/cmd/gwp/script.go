@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// runExec runs the statements given with -q, read from -f, or read from
+// stdin, stopping at the first error.
+func runExec(opts options, args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+	query := fs.String("q", "", "statements to run")
+	file := fs.String("f", "", "script file to run")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	script := *query
+	if script == "" {
+		var data []byte
+		var err error
+		if *file != "" {
+			data, err = os.ReadFile(*file)
+		} else {
+			data, err = io.ReadAll(stdin)
+		}
+		if err != nil {
+			return err
+		}
+		script = string(data)
+	}
+	statements, rest := gwp.SplitStatements(script)
+	if strings.TrimSpace(rest) != "" {
+		statements = append(statements, strings.TrimSpace(rest))
+	}
+
+	p, err := newPrinter(stdout, opts.Format)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	conn, session, err := connect(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	for _, stmt := range statements {
+		if err := execute(ctx, session, p, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+const shellHelp = `Statements end with a semicolon and may span several lines.
+Ctrl-C cancels the running statement; Ctrl-D exits.
+
+  :help             show this help
+  :format FORMAT    switch output to table, json or csv
+  :schema NAME      set the session schema
+  :graph NAME       set the session graph
+  :history [N]      show the last N statements (default 20)
+  :quit             exit
+`
+
+// shell is an interactive session reading statements from in.
+type shell struct {
+	session *gwp.GqlSession
+	in      *bufio.Scanner
+	out     io.Writer
+	printer printer
+	history *history
+}
+
+func runShell(opts options, stdin io.Reader, stdout io.Writer) error {
+	ctx := context.Background()
+	conn, session, err := connect(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	p, err := newPrinter(stdout, opts.Format)
+	if err != nil {
+		return err
+	}
+	sh := &shell{
+		session: session,
+		in:      bufio.NewScanner(stdin),
+		out:     stdout,
+		printer: p,
+		history: openHistory(),
+	}
+	fmt.Fprintf(stdout, "Connected to %s. Type :help for help.\n", opts.Addr)
+	return sh.loop()
+}
+
+func (sh *shell) loop() error {
+	var pending strings.Builder
+	for {
+		if pending.Len() == 0 {
+			fmt.Fprint(sh.out, "gwp> ")
+		} else {
+			fmt.Fprint(sh.out, "  -> ")
+		}
+		if !sh.in.Scan() {
+			fmt.Fprintln(sh.out)
+			return sh.in.Err()
+		}
+		line := sh.in.Text()
+
+		if pending.Len() == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			quit, err := sh.command(strings.Fields(strings.TrimSpace(line)))
+			if err != nil {
+				fmt.Fprintln(sh.out, "error:", err)
+			}
+			if quit {
+				return nil
+			}
+			continue
+		}
+
+		pending.WriteString(line)
+		pending.WriteString("\n")
+		statements, rest := gwp.SplitStatements(pending.String())
+		pending.Reset()
+		if strings.TrimSpace(rest) != "" {
+			pending.WriteString(rest)
+		}
+		for _, stmt := range statements {
+			sh.history.add(stmt)
+			if err := sh.run(stmt); err != nil {
+				fmt.Fprintln(sh.out, "error:", err)
+			}
+		}
+	}
+}
+
+// run executes one statement; Ctrl-C cancels it without leaving the shell.
+func (sh *shell) run(statement string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return execute(ctx, sh.session, sh.printer, statement)
+}
+
+// command runs a shell command such as ":graph social". It reports whether
+// the shell should exit.
+func (sh *shell) command(args []string) (bool, error) {
+	ctx := context.Background()
+	arg := func() (string, error) {
+		if len(args) != 2 {
+			return "", fmt.Errorf("usage: %s NAME", args[0])
+		}
+		return args[1], nil
+	}
+
+	switch args[0] {
+	case ":quit", ":exit", ":q":
+		return true, nil
+	case ":help":
+		fmt.Fprint(sh.out, shellHelp)
+	case ":format":
+		name, err := arg()
+		if err != nil {
+			return false, err
+		}
+		p, err := newPrinter(sh.out, name)
+		if err != nil {
+			return false, err
+		}
+		sh.printer = p
+	case ":schema":
+		name, err := arg()
+		if err != nil {
+			return false, err
+		}
+		return false, sh.session.SetSchema(ctx, name)
+	case ":graph":
+		name, err := arg()
+		if err != nil {
+			return false, err
+		}
+		return false, sh.session.SetGraph(ctx, name)
+	case ":history":
+		n := 20
+		if len(args) > 1 {
+			if _, err := fmt.Sscan(args[1], &n); err != nil {
+				return false, fmt.Errorf("usage: :history [N]")
+			}
+		}
+		entries := sh.history.last(n)
+		for _, e := range entries {
+			fmt.Fprintln(sh.out, e+";")
+		}
+	default:
+		return false, fmt.Errorf("unknown command %s, type :help for help", args[0])
+	}
+	return false, nil
+}
+
+// history keeps executed statements and appends them to a file so they
+// survive between shell sessions.
+type history struct {
+	path    string
+	entries []string
+}
+
+// openHistory loads $GWP_HISTORY, or .gwp_history in the home directory.
+// History is kept in memory only if neither is available.
+func openHistory() *history {
+	h := &history{path: os.Getenv("GWP_HISTORY")}
+	if h.path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return h
+		}
+		h.path = filepath.Join(home, ".gwp_history")
+	}
+	data, err := os.ReadFile(h.path)
+	if err == nil {
+		h.entries, _ = gwp.SplitStatements(string(data))
+	}
+	return h
+}
+
+func (h *history) add(statement string) {
+	h.entries = append(h.entries, statement)
+	if h.path == "" {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s;\n", statement)
+}
+
+func (h *history) last(n int) []string {
+	if n < 0 || n > len(h.entries) {
+		n = len(h.entries)
+	}
+	return h.entries[len(h.entries)-n:]
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// gqlGoTypes maps GQL type names (and their common shorthands) to the Go
+// types produced by the client's value conversion.
+var gqlGoTypes = map[string]string{
+	"STRING":         "string",
+	"BOOLEAN":        "bool",
+	"BOOL":           "bool",
+	"INT":            "int64",
+	"INTEGER":        "int64",
+	"INT8":           "int64",
+	"INT16":          "int64",
+	"INT32":          "int64",
+	"INT64":          "int64",
+	"UINT8":          "uint64",
+	"UINT16":         "uint64",
+	"UINT32":         "uint64",
+	"UINT64":         "uint64",
+	"FLOAT":          "float64",
+	"FLOAT32":        "float64",
+	"FLOAT64":        "float64",
+	"DOUBLE":         "float64",
+	"DECIMAL":        "*gwp.GqlDecimal",
+	"BYTES":          "[]byte",
+	"DATE":           "*gwp.GqlDate",
+	"LOCAL_TIME":     "*gwp.GqlLocalTime",
+	"ZONED_TIME":     "*gwp.GqlZonedTime",
+	"LOCAL_DATETIME": "*gwp.GqlLocalDateTime",
+	"ZONED_DATETIME": "*gwp.GqlZonedDateTime",
+	"DURATION":       "*gwp.GqlDuration",
+	"LIST":           "[]any",
+	"RECORD":         "*gwp.GqlRecord",
+	"NODE":           "*gwp.GqlNode",
+	"EDGE":           "*gwp.GqlEdge",
+	"PATH":           "*gwp.GqlPath",
+	"ANY":            "any",
+}
+
+var goBuiltins = map[string]bool{
+	"string": true, "bool": true, "int64": true, "uint64": true,
+	"float64": true, "[]byte": true, "any": true, "[]any": true,
+}
+
+// resolveType turns a type annotation into a Go type expression.
+func resolveType(annotation string, schema *gwp.SchemaDescription) (string, error) {
+	if goBuiltins[annotation] {
+		return annotation, nil
+	}
+	if t, ok := gqlGoTypes[strings.ToUpper(annotation)]; ok {
+		return t, nil
+	}
+	if strings.HasPrefix(annotation, "*") || strings.HasPrefix(annotation, "[]") || strings.HasPrefix(annotation, "gwp.") {
+		return annotation, nil
+	}
+	label, prop, ok := strings.Cut(annotation, ".")
+	if !ok {
+		return "", fmt.Errorf("unknown type %q", annotation)
+	}
+	if schema == nil {
+		return "", fmt.Errorf("type %q references the schema but no schema was provided", annotation)
+	}
+	var props []gwp.PropertyInfo
+	if l := schema.Label(label); l != nil {
+		props = l.Properties
+	} else if e := schema.EdgeType(label); e != nil {
+		props = e.Properties
+	} else {
+		return "", fmt.Errorf("type %q: unknown label or edge type %q", annotation, label)
+	}
+	for _, p := range props {
+		if p.Name != prop {
+			continue
+		}
+		if len(p.Types) != 1 {
+			return "any", nil
+		}
+		if t, ok := gqlGoTypes[p.Types[0]]; ok {
+			return t, nil
+		}
+		return "any", nil
+	}
+	return "", fmt.Errorf("type %q: %s has no property %q", annotation, label, prop)
+}
+
+type genField struct {
+	Name   string
+	GoName string
+	GoType string
+}
+
+type genQuery struct {
+	Name      string
+	Kind      string
+	Statement string
+	Params    []genField
+	Columns   []genField
+}
+
+func generate(pkg string, queries []query, schema *gwp.SchemaDescription) ([]byte, error) {
+	var gen []genQuery
+	for _, q := range queries {
+		g := genQuery{Name: exportName(q.Name), Kind: q.Kind, Statement: q.Statement}
+		for _, p := range q.Params {
+			t, err := resolveType(p.Type, schema)
+			if err != nil {
+				return nil, fmt.Errorf("query %s, parameter %s: %w", q.Name, p.Name, err)
+			}
+			g.Params = append(g.Params, genField{Name: p.Name, GoName: exportName(p.Name), GoType: t})
+		}
+		if q.Kind != kindExec && len(q.Columns) == 0 {
+			return nil, fmt.Errorf("query %s returns rows but declares no columns", q.Name)
+		}
+		for _, c := range q.Columns {
+			t, err := resolveType(c.Type, schema)
+			if err != nil {
+				return nil, fmt.Errorf("query %s, column %s: %w", q.Name, c.Name, err)
+			}
+			g.Columns = append(g.Columns, genField{Name: c.Name, GoName: exportName(c.Name), GoType: t})
+		}
+		gen = append(gen, g)
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, struct {
+		Package string
+		Queries []genQuery
+	}{pkg, gen}); err != nil {
+		return nil, err
+	}
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return out, nil
+}
+
+var commonInitialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "ip": true, "json": true, "uuid": true, "api": true,
+}
+
+// exportName converts a snake_case or camelCase name into an exported Go
+// identifier.
+func exportName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == ' ' })
+	var b strings.Builder
+	for _, p := range parts {
+		if commonInitialisms[strings.ToLower(p)] {
+			b.WriteString(strings.ToUpper(p))
+			continue
+		}
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+var fileTemplate = template.Must(template.New("file").Funcs(template.FuncMap{
+	"lower": lowerFirst,
+	"quote": func(s string) string { return "`" + strings.ReplaceAll(s, "`", "` + \"`\" + `") + "`" },
+}).Parse(`// Code generated by gwpgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+var (
+	_ = fmt.Errorf
+	_ gwp.Querier
+)
+{{range .Queries}}{{$q := .}}
+const {{lower .Name}}Statement = {{quote .Statement}}
+{{if .Params}}
+// {{.Name}}Params holds the parameters of {{.Name}}.
+type {{.Name}}Params struct {
+{{- range .Params}}
+	{{.GoName}} {{.GoType}}
+{{- end}}
+}
+
+func (p {{.Name}}Params) params() map[string]any {
+	return map[string]any{
+{{- range .Params}}
+		"{{.Name}}": p.{{.GoName}},
+{{- end}}
+	}
+}
+{{end}}{{if .Columns}}
+// {{.Name}}Row is a single result row of {{.Name}}.
+type {{.Name}}Row struct {
+{{- range .Columns}}
+	{{.GoName}} {{.GoType}}
+{{- end}}
+}
+
+func scan{{.Name}}Row(row []any) ({{.Name}}Row, error) {
+	var r {{.Name}}Row
+	if len(row) != {{len .Columns}} {
+		return r, fmt.Errorf("{{.Name}}: expected {{len .Columns}} columns, got %d", len(row))
+	}
+{{- range $i, $c := .Columns}}
+{{- if eq .GoType "any"}}
+	r.{{.GoName}} = row[{{$i}}]
+{{- else}}
+	if row[{{$i}}] != nil {
+		v, ok := row[{{$i}}].({{.GoType}})
+		if !ok {
+			return r, fmt.Errorf("{{$q.Name}}: column {{.Name}}: expected {{.GoType}}, got %T", row[{{$i}}])
+		}
+		r.{{.GoName}} = v
+	}
+{{- end}}
+{{- end}}
+	return r, nil
+}
+{{end}}
+{{- if eq .Kind "one"}}
+// {{.Name}} executes the {{.Name}} query and returns its single row.
+func {{.Name}}(ctx context.Context, q gwp.Querier{{if .Params}}, p {{.Name}}Params{{end}}) ({{.Name}}Row, error) {
+	cursor, err := q.Execute(ctx, {{lower .Name}}Statement, {{if .Params}}p.params(){{else}}nil{{end}})
+	if err != nil {
+		return {{.Name}}Row{}, err
+	}
+	row, err := cursor.NextRow()
+	if err != nil {
+		return {{.Name}}Row{}, err
+	}
+	if row == nil {
+		return {{.Name}}Row{}, &gwp.GqlError{Message: "{{.Name}}: no rows"}
+	}
+	if _, err := cursor.Summary(); err != nil {
+		return {{.Name}}Row{}, err
+	}
+	return scan{{.Name}}Row(row)
+}
+{{- else if eq .Kind "many"}}
+// {{.Name}}Iterator iterates over the rows of {{.Name}}.
+type {{.Name}}Iterator struct {
+	cursor *gwp.ResultCursor
+}
+
+// Next returns the next row, or nil when done.
+func (it *{{.Name}}Iterator) Next() (*{{.Name}}Row, error) {
+	row, err := it.cursor.NextRow()
+	if err != nil || row == nil {
+		return nil, err
+	}
+	r, err := scan{{.Name}}Row(row)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Collect returns all remaining rows.
+func (it *{{.Name}}Iterator) Collect() ([]{{.Name}}Row, error) {
+	var rows []{{.Name}}Row
+	for {
+		r, err := it.Next()
+		if err != nil {
+			return rows, err
+		}
+		if r == nil {
+			return rows, nil
+		}
+		rows = append(rows, *r)
+	}
+}
+
+// Summary returns the result summary, consuming any remaining rows.
+func (it *{{.Name}}Iterator) Summary() (*gwp.ResultSummary, error) {
+	return it.cursor.Summary()
+}
+
+// {{.Name}} executes the {{.Name}} query and returns an iterator over its rows.
+func {{.Name}}(ctx context.Context, q gwp.Querier{{if .Params}}, p {{.Name}}Params{{end}}) (*{{.Name}}Iterator, error) {
+	cursor, err := q.Execute(ctx, {{lower .Name}}Statement, {{if .Params}}p.params(){{else}}nil{{end}})
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Name}}Iterator{cursor: cursor}, nil
+}
+{{- else}}
+// {{.Name}} executes the {{.Name}} statement and returns its summary.
+func {{.Name}}(ctx context.Context, q gwp.Querier{{if .Params}}, p {{.Name}}Params{{end}}) (*gwp.ResultSummary, error) {
+	cursor, err := q.Execute(ctx, {{lower .Name}}Statement, {{if .Params}}p.params(){{else}}nil{{end}})
+	if err != nil {
+		return nil, err
+	}
+	return cursor.Summary()
+}
+{{- end}}
+{{end}}`))
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+const sampleQueries = `
+-- name: PersonByName :one
+-- param: name Person.name
+-- column: name string
+-- column: age Person.age
+MATCH (p:Person {name: $name}) RETURN p.name AS name, p.age AS age
+
+-- name: FriendsOf :many
+-- column: friend node
+MATCH (:Person {name: $name})-[:knows]->(f) RETURN f
+
+-- name: DeletePerson :exec
+MATCH (p:Person {name: '$notaparam'}) WHERE p.id = $person_id DELETE p
+`
+
+func TestParseQueries(t *testing.T) {
+	queries, err := parseQueries("sample.gql", strings.NewReader(sampleQueries))
+	if err != nil {
+		t.Fatalf("parseQueries: %v", err)
+	}
+	if len(queries) != 3 {
+		t.Fatalf("expected 3 queries, got %d", len(queries))
+	}
+
+	friends := queries[1]
+	if friends.Kind != kindMany || len(friends.Params) != 1 || friends.Params[0].Type != "any" {
+		t.Fatalf("unexpected FriendsOf: %+v", friends)
+	}
+
+	del := queries[2]
+	if len(del.Params) != 1 || del.Params[0].Name != "person_id" {
+		t.Fatalf("expected single person_id param, got %+v", del.Params)
+	}
+}
+
+func TestParseQueriesUnusedParam(t *testing.T) {
+	src := "-- name: Q :exec\n-- param: missing string\nMATCH (n) DELETE n\n"
+	if _, err := parseQueries("q.gql", strings.NewReader(src)); err == nil {
+		t.Fatal("expected error for unused parameter")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	queries, err := parseQueries("sample.gql", strings.NewReader(sampleQueries))
+	if err != nil {
+		t.Fatalf("parseQueries: %v", err)
+	}
+	schema := &gwp.SchemaDescription{
+		Labels: []gwp.LabelInfo{{
+			Name: "Person",
+			Properties: []gwp.PropertyInfo{
+				{Name: "name", Types: []string{"STRING"}},
+				{Name: "age", Types: []string{"INT64"}},
+			},
+		}},
+	}
+
+	src, err := generate("queries", queries, schema)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	out := string(src)
+	for _, want := range []string{
+		"type PersonByNameParams struct",
+		"Age  int64",
+		"func FriendsOf(ctx context.Context, q gwp.Querier, p FriendsOfParams) (*FriendsOfIterator, error)",
+		"Friend *gwp.GqlNode",
+		"PersonID any",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("generated code missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestResolveTypeUnknownProperty(t *testing.T) {
+	schema := &gwp.SchemaDescription{Labels: []gwp.LabelInfo{{Name: "Person"}}}
+	if _, err := resolveType("Person.missing", schema); err == nil {
+		t.Fatal("expected error for unknown property")
+	}
+	if _, err := resolveType("Person.name", nil); err == nil {
+		t.Fatal("expected error without schema")
+	}
+}
+
+func TestExportName(t *testing.T) {
+	tests := map[string]string{
+		"name":      "Name",
+		"person_id": "PersonID",
+		"firstName": "FirstName",
+	}
+	for in, want := range tests {
+		if got := exportName(in); got != want {
+			t.Fatalf("exportName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
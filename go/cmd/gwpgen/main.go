@@ -0,0 +1,151 @@
+// Command gwpgen generates typed Go bindings for annotated GQL queries.
+//
+// Each .gql input file contains one or more queries:
+//
+//	-- name: PersonByName :one
+//	-- param: name Person.name
+//	-- column: name string
+//	-- column: age Person.age
+//	MATCH (p:Person {name: $name}) RETURN p.name AS name, p.age AS age
+//
+// The kind suffix selects the generated function shape: ":one" returns a
+// single row, ":many" returns an iterator and ":exec" returns the summary.
+// Types are Go types, GQL type names (node, date, int64, ...) or
+// Label.property references resolved against an introspected schema, read
+// either from a JSON file (-schema) or from a live server (-addr).
+//
+// Typical use from a go:generate directive:
+//
+//	//go:generate go run github.com/GrafeoDB/gql-wire-protocol/go/cmd/gwpgen -pkg queries -schema schema.json -out queries.gen.go queries/*.gql
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+func main() {
+	pkg := flag.String("pkg", "", "package name of the generated file (default: $GOPACKAGE)")
+	out := flag.String("out", "", "output file (default: stdout)")
+	schemaFile := flag.String("schema", "", "JSON schema description used to resolve Label.property types")
+	addr := flag.String("addr", "", "GWP server to introspect for Label.property types")
+	dumpSchema := flag.Bool("dump-schema", false, "introspect -addr and write the schema as JSON instead of generating code")
+	flag.Parse()
+
+	if err := run(*pkg, *out, *schemaFile, *addr, *dumpSchema, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "gwpgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkg, out, schemaFile, addr string, dumpSchema bool, patterns []string) error {
+	var schema *gwp.SchemaDescription
+	switch {
+	case schemaFile != "":
+		data, err := os.ReadFile(schemaFile)
+		if err != nil {
+			return err
+		}
+		schema = &gwp.SchemaDescription{}
+		if err := json.Unmarshal(data, schema); err != nil {
+			return fmt.Errorf("%s: %w", schemaFile, err)
+		}
+	case addr != "":
+		var err error
+		schema, err = introspect(addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if dumpSchema {
+		if schema == nil {
+			return fmt.Errorf("-dump-schema requires -addr or -schema")
+		}
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeOutput(out, append(data, '\n'))
+	}
+
+	if pkg == "" {
+		pkg = os.Getenv("GOPACKAGE")
+	}
+	if pkg == "" {
+		return fmt.Errorf("-pkg is required outside go generate")
+	}
+
+	var files []string
+	for _, p := range patterns {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no input files")
+	}
+
+	var queries []query
+	seen := make(map[string]string)
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		qs, err := parseQueries(name, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		for _, q := range qs {
+			if prev, ok := seen[q.Name]; ok {
+				return fmt.Errorf("%s: query %s already defined in %s", name, q.Name, prev)
+			}
+			seen[q.Name] = name
+		}
+		queries = append(queries, qs...)
+	}
+
+	src, err := generate(pkg, queries, schema)
+	if err != nil {
+		return err
+	}
+	return writeOutput(out, src)
+}
+
+func introspect(addr string) (*gwp.SchemaDescription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conn, err := gwp.Connect(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(ctx)
+
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close(ctx)
+
+	return session.Introspect(ctx)
+}
+
+func writeOutput(out string, data []byte) error {
+	if out == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(out, data, 0o644)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// Query kinds, selected with the ":one", ":many" or ":exec" suffix of the
+// name annotation.
+const (
+	kindOne  = "one"
+	kindMany = "many"
+	kindExec = "exec"
+)
+
+// query is a single annotated statement read from a .gql file.
+type query struct {
+	Name      string
+	Kind      string
+	Statement string
+	Params    []field
+	Columns   []field
+}
+
+// field is a named, typed parameter or result column. Type is the raw type
+// annotation (a Go type, a shorthand such as "node", or a schema reference
+// such as "Person.age"); it is resolved to a Go type at generation time.
+type field struct {
+	Name string
+	Type string
+}
+
+// parseQueries reads annotated queries from r. Each query starts with a
+// "-- name: <Name> :<kind>" line and may be followed by "-- param: <name> <type>"
+// and "-- column: <name> <type>" annotations. All other lines up to the next
+// name annotation form the statement text.
+func parseQueries(filename string, r io.Reader) ([]query, error) {
+	var queries []query
+	var cur *query
+	var body []string
+
+	flush := func() error {
+		if cur == nil {
+			return nil
+		}
+		cur.Statement = strings.TrimSpace(strings.Join(body, "\n"))
+		if cur.Statement == "" {
+			return fmt.Errorf("%s: query %s has no statement", filename, cur.Name)
+		}
+		if err := bindParams(cur); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		queries = append(queries, *cur)
+		cur = nil
+		body = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if rest, ok := annotation(trimmed, "name:"); ok {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			parts := strings.Fields(rest)
+			if len(parts) != 2 || !strings.HasPrefix(parts[1], ":") {
+				return nil, fmt.Errorf("%s:%d: expected \"-- name: <Name> :one|:many|:exec\"", filename, lineNo)
+			}
+			kind := strings.TrimPrefix(parts[1], ":")
+			if kind != kindOne && kind != kindMany && kind != kindExec {
+				return nil, fmt.Errorf("%s:%d: unknown query kind %q", filename, lineNo, kind)
+			}
+			cur = &query{Name: parts[0], Kind: kind}
+			continue
+		}
+
+		if cur == nil {
+			continue
+		}
+
+		if rest, ok := annotation(trimmed, "param:"); ok {
+			f, err := parseField(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", filename, lineNo, err)
+			}
+			cur.Params = append(cur.Params, f)
+			continue
+		}
+		if rest, ok := annotation(trimmed, "column:"); ok {
+			f, err := parseField(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", filename, lineNo, err)
+			}
+			cur.Columns = append(cur.Columns, f)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "--") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		body = append(body, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+func annotation(line, key string) (string, bool) {
+	if !strings.HasPrefix(line, "--") {
+		return "", false
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(line, "--"))
+	if !strings.HasPrefix(rest, key) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(rest, key)), true
+}
+
+func parseField(s string) (field, error) {
+	parts := strings.Fields(s)
+	switch len(parts) {
+	case 1:
+		return field{Name: parts[0], Type: "any"}, nil
+	case 2:
+		return field{Name: parts[0], Type: parts[1]}, nil
+	default:
+		return field{}, fmt.Errorf("expected \"<name> [type]\", got %q", s)
+	}
+}
+
+// bindParams checks the declared parameters against the $name references in
+// the statement, adding untyped parameters for undeclared references.
+func bindParams(q *query) error {
+	refs := gwp.StatementParams(q.Statement)
+	declared := make(map[string]bool, len(q.Params))
+	for _, p := range q.Params {
+		declared[p.Name] = true
+	}
+	used := make(map[string]bool, len(refs))
+	for _, name := range refs {
+		used[name] = true
+		if !declared[name] {
+			q.Params = append(q.Params, field{Name: name, Type: "any"})
+			declared[name] = true
+		}
+	}
+	for _, p := range q.Params {
+		if !used[p.Name] {
+			return fmt.Errorf("query %s declares parameter %q that the statement does not use", q.Name, p.Name)
+		}
+	}
+	return nil
+}
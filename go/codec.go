@@ -0,0 +1,83 @@
+package gwp
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ValueCodec converts between an application type and the values this
+// client sends and receives.
+type ValueCodec interface {
+	// ToValue converts a value of the registered type to a value the client
+	// can send as a parameter, such as a string, int64, []byte or []any.
+	ToValue(v any) any
+	// FromValue converts a decoded result value to the registered type.
+	FromValue(v any) (any, error)
+}
+
+// ValueCodecFuncs adapts a pair of functions to a ValueCodec.
+type ValueCodecFuncs struct {
+	To   func(v any) any
+	From func(v any) (any, error)
+}
+
+// ToValue calls f.To.
+func (f ValueCodecFuncs) ToValue(v any) any {
+	return f.To(v)
+}
+
+// FromValue calls f.From.
+func (f ValueCodecFuncs) FromValue(v any) (any, error) {
+	return f.From(v)
+}
+
+var codecs = struct {
+	sync.RWMutex
+	byType map[reflect.Type]ValueCodec
+}{byType: make(map[reflect.Type]ValueCodec)}
+
+// RegisterValueCodec registers codec for values of type t. Parameters of
+// type t are converted with codec.ToValue before they are sent, and
+// DecodeValue uses codec.FromValue to produce t from result values.
+//
+// Codecs only apply to types the client does not convert natively.
+// Registering a codec for an already registered type replaces it; a nil
+// codec removes it.
+func RegisterValueCodec(t reflect.Type, codec ValueCodec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	if codec == nil {
+		delete(codecs.byType, t)
+		return
+	}
+	codecs.byType[t] = codec
+}
+
+func lookupValueCodec(t reflect.Type) ValueCodec {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	return codecs.byType[t]
+}
+
+// DecodeValue converts a result value to T, using the codec registered for
+// T if v is not already a T.
+func DecodeValue[T any](v any) (T, error) {
+	var zero T
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
+	typ := reflect.TypeFor[T]()
+	codec := lookupValueCodec(typ)
+	if codec == nil {
+		return zero, &GqlError{Message: "no value codec registered for " + typ.String()}
+	}
+	out, err := codec.FromValue(v)
+	if err != nil {
+		return zero, err
+	}
+	t, ok := out.(T)
+	if !ok {
+		return zero, &GqlError{Message: "value codec for " + typ.String() + " returned " + reflect.TypeOf(out).String()}
+	}
+	return t, nil
+}
@@ -0,0 +1,73 @@
+package gwp
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testUUID [4]byte
+
+func (u testUUID) String() string {
+	return fmt.Sprintf("%x", u[:])
+}
+
+var testUUIDCodec = ValueCodecFuncs{
+	To: func(v any) any { return v.(testUUID).String() },
+	From: func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", v)
+		}
+		b, err := hex.DecodeString(s)
+		if err != nil || len(b) != 4 {
+			return nil, fmt.Errorf("invalid id %q", s)
+		}
+		return testUUID(b), nil
+	},
+}
+
+func TestValueCodec(t *testing.T) {
+	typ := reflect.TypeFor[testUUID]()
+	RegisterValueCodec(typ, testUUIDCodec)
+	defer RegisterValueCodec(typ, nil)
+
+	id := testUUID{0xde, 0xad, 0xbe, 0xef}
+	pv := mustValueToProto(id)
+	if pv.GetStringValue() != "deadbeef" {
+		t.Fatalf("encoded = %v", pv)
+	}
+	list := mustValueToProto([]any{id})
+	if list.GetListValue().Elements[0].GetStringValue() != "deadbeef" {
+		t.Fatalf("encoded list = %v", list)
+	}
+
+	got, err := DecodeValue[testUUID](valueFromProto(pv))
+	if err != nil {
+		t.Fatalf("DecodeValue: %v", err)
+	}
+	if got != id {
+		t.Fatalf("decoded = %v, want %v", got, id)
+	}
+
+	if _, err := DecodeValue[testUUID](int64(1)); err == nil {
+		t.Fatal("expected codec error")
+	}
+}
+
+func TestDecodeValueWithoutCodec(t *testing.T) {
+	if s, err := DecodeValue[string]("x"); err != nil || s != "x" {
+		t.Fatalf("DecodeValue[string] = %q, %v", s, err)
+	}
+	_, err := DecodeValue[testUUID]("deadbeef")
+	if err == nil || !strings.Contains(err.Error(), "no value codec") {
+		t.Fatalf("expected missing codec error, got %v", err)
+	}
+	var unsupported *UnsupportedTypeError
+	if _, err := valueToProto(testUUID{}); !errors.As(err, &unsupported) {
+		t.Fatalf("unregistered type = %v, want UnsupportedTypeError", err)
+	}
+}
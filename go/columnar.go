@@ -0,0 +1,228 @@
+package gwp
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+var errColumnarCursor = &GqlError{Message: "cursor decodes columns; use NextColumns"}
+
+// ColumnKind is the Go representation of a column read with NextColumns.
+type ColumnKind int
+
+const (
+	// ColumnValues holds values decoded as by NextRow, for columns whose
+	// declared type is not a primitive or is unknown.
+	ColumnValues ColumnKind = iota
+	ColumnBool
+	ColumnInt64
+	ColumnUint64
+	ColumnFloat64
+	ColumnString
+)
+
+// String returns the name of the kind.
+func (k ColumnKind) String() string {
+	switch k {
+	case ColumnBool:
+		return "bool"
+	case ColumnInt64:
+		return "int64"
+	case ColumnUint64:
+		return "uint64"
+	case ColumnFloat64:
+		return "float64"
+	case ColumnString:
+		return "string"
+	default:
+		return "values"
+	}
+}
+
+// columnKind maps a column's declared type to the buffer it decodes into.
+func columnKind(t *pb.TypeDescriptor) ColumnKind {
+	switch t.GetType() {
+	case pb.GqlType_TYPE_BOOLEAN:
+		return ColumnBool
+	case pb.GqlType_TYPE_INT8, pb.GqlType_TYPE_INT16, pb.GqlType_TYPE_INT32, pb.GqlType_TYPE_INT64:
+		return ColumnInt64
+	case pb.GqlType_TYPE_UINT8, pb.GqlType_TYPE_UINT16, pb.GqlType_TYPE_UINT32, pb.GqlType_TYPE_UINT64:
+		return ColumnUint64
+	case pb.GqlType_TYPE_FLOAT16, pb.GqlType_TYPE_FLOAT32, pb.GqlType_TYPE_FLOAT64:
+		return ColumnFloat64
+	case pb.GqlType_TYPE_STRING:
+		return ColumnString
+	default:
+		return ColumnValues
+	}
+}
+
+// Column is one column of a ColumnBatch. Only the slice matching Kind is
+// filled, with one entry per row; Nulls marks rows whose value is NULL, for
+// which the typed slice holds the zero value.
+type Column struct {
+	Name string
+	Kind ColumnKind
+
+	Bools    []bool
+	Int64s   []int64
+	Uint64s  []uint64
+	Float64s []float64
+	Strings  []string
+	Values   []any
+	Nulls    []bool
+}
+
+func (col *Column) reset() {
+	col.Bools = col.Bools[:0]
+	col.Int64s = col.Int64s[:0]
+	col.Uint64s = col.Uint64s[:0]
+	col.Float64s = col.Float64s[:0]
+	col.Strings = col.Strings[:0]
+	clear(col.Values)
+	col.Values = col.Values[:0]
+	col.Nulls = col.Nulls[:0]
+}
+
+// ColumnBatch is a batch of rows decoded column by column. A batch passed
+// to NextColumns is reused: its buffers are overwritten by the next call.
+type ColumnBatch struct {
+	// Len is the number of rows in the batch.
+	Len     int
+	Columns []Column
+
+	header *pb.ResultHeader
+}
+
+// Column returns the column with the given name, or nil.
+func (b *ColumnBatch) Column(name string) *Column {
+	for i := range b.Columns {
+		if b.Columns[i].Name == name {
+			return &b.Columns[i]
+		}
+	}
+	return nil
+}
+
+// NextColumns decodes the next row batch into dst and reports whether there
+// was one. The cursor must have been created with WithColumnar. Columns
+// declared in the result header as booleans, integers, floats or strings
+// are written into typed slices without boxing each value; other columns
+// are decoded into Values. dst's buffers are reused across calls, so
+// reading a result with a single ColumnBatch allocates only when a batch is
+// larger than any before it.
+func (c *ResultCursor) NextColumns(dst *ColumnBatch) (bool, error) {
+	if !c.columnar {
+		return false, &GqlError{Message: "cursor does not decode columns; execute with WithColumnar"}
+	}
+	if len(c.rawBatches) == 0 {
+		if err := c.consumeUntilRowsOrDone(); err != nil {
+			return false, err
+		}
+	}
+	if len(c.rawBatches) == 0 {
+		return false, nil
+	}
+	batch := c.rawBatches[0]
+	c.rawBatches[0] = nil
+	c.rawBatches = c.rawBatches[1:]
+
+	start := time.Now()
+	if err := c.decodeColumns(dst, batch); err != nil {
+		return false, err
+	}
+	c.recordDecode(len(batch.Rows), start)
+	return true, nil
+}
+
+func (c *ResultCursor) decodeColumns(dst *ColumnBatch, batch *pb.RowBatch) error {
+	if dst.header != c.header || dst.header == nil {
+		dst.header = c.header
+		var columns []*pb.ColumnDescriptor
+		if c.header != nil {
+			columns = c.header.Columns
+		}
+		if cap(dst.Columns) < len(columns) {
+			dst.Columns = make([]Column, len(columns))
+		}
+		dst.Columns = dst.Columns[:len(columns)]
+		for i, col := range columns {
+			dst.Columns[i].Name = col.Name
+			dst.Columns[i].Kind = columnKind(col.Type)
+		}
+	}
+	for i := range dst.Columns {
+		dst.Columns[i].reset()
+	}
+	dst.Len = len(batch.Rows)
+
+	d := valueDecoder{lazy: c.lazyProperties, lenient: !c.strictConversion}
+	for r, row := range batch.Rows {
+		if len(row.Values) != len(dst.Columns) {
+			return &GqlError{Message: fmt.Sprintf("row %d has %d values, header has %d columns", r, len(row.Values), len(dst.Columns))}
+		}
+		for i, v := range row.Values {
+			if err := dst.Columns[i].append(v, &d); err != nil {
+				if unsupported, ok := err.(*UnsupportedTypeError); ok {
+					return c.unsupported(i, unsupported)
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// append decodes v onto the end of the column.
+func (col *Column) append(v *pb.Value, d *valueDecoder) error {
+	_, null := v.GetKind().(*pb.Value_NullValue)
+	null = null || v.GetKind() == nil
+	col.Nulls = append(col.Nulls, null)
+	if col.Kind == ColumnValues {
+		col.Values = append(col.Values, d.decode(v))
+		if d.err != nil {
+			return d.err
+		}
+		return nil
+	}
+
+	ok := null
+	switch col.Kind {
+	case ColumnBool:
+		var b bool
+		if k, isBool := v.Kind.(*pb.Value_BooleanValue); isBool {
+			b, ok = k.BooleanValue, true
+		}
+		col.Bools = append(col.Bools, b)
+	case ColumnInt64:
+		var n int64
+		if k, isInt := v.Kind.(*pb.Value_IntegerValue); isInt {
+			n, ok = k.IntegerValue, true
+		}
+		col.Int64s = append(col.Int64s, n)
+	case ColumnUint64:
+		var n uint64
+		if k, isUint := v.Kind.(*pb.Value_UnsignedIntegerValue); isUint {
+			n, ok = k.UnsignedIntegerValue, true
+		}
+		col.Uint64s = append(col.Uint64s, n)
+	case ColumnFloat64:
+		var f float64
+		if k, isFloat := v.Kind.(*pb.Value_FloatValue); isFloat {
+			f, ok = k.FloatValue, true
+		}
+		col.Float64s = append(col.Float64s, f)
+	case ColumnString:
+		var s string
+		if k, isString := v.Kind.(*pb.Value_StringValue); isString {
+			s, ok = k.StringValue, true
+		}
+		col.Strings = append(col.Strings, s)
+	}
+	if !ok {
+		return &GqlError{Message: fmt.Sprintf("column %q is declared %s but holds %T", col.Name, col.Kind, valueFromProto(v))}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package gwp
+
+import (
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func typedHeaderFrame(columns map[string]pb.GqlType, order ...string) *pb.ExecuteResponse {
+	cols := make([]*pb.ColumnDescriptor, len(order))
+	for i, name := range order {
+		cols[i] = &pb.ColumnDescriptor{Name: name, Type: &pb.TypeDescriptor{Type: columns[name]}}
+	}
+	return &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_Header{Header: &pb.ResultHeader{Columns: cols}}}
+}
+
+func TestNextColumns(t *testing.T) {
+	c := newTestCursor(
+		typedHeaderFrame(map[string]pb.GqlType{
+			"id":    pb.GqlType_TYPE_INT64,
+			"score": pb.GqlType_TYPE_FLOAT64,
+			"name":  pb.GqlType_TYPE_STRING,
+			"tags":  pb.GqlType_TYPE_LIST,
+		}, "id", "score", "name", "tags"),
+		batchFrame(
+			[]any{int64(1), 0.5, "Alice", []any{"a"}},
+			[]any{int64(2), nil, "Bob", nil},
+		),
+		batchFrame([]any{int64(3), 1.5, nil, []any{}}),
+		summaryFrame(Success, 0),
+	)
+	c.columnar = true
+
+	var batch ColumnBatch
+	ok, err := c.NextColumns(&batch)
+	if err != nil || !ok || batch.Len != 2 {
+		t.Fatalf("NextColumns = %v, %v, len %d", ok, err, batch.Len)
+	}
+	id, score, tags := batch.Column("id"), batch.Column("score"), batch.Column("tags")
+	if id.Kind != ColumnInt64 || id.Int64s[0] != 1 || id.Int64s[1] != 2 {
+		t.Fatalf("id = %+v", id)
+	}
+	if score.Kind != ColumnFloat64 || score.Float64s[0] != 0.5 || !score.Nulls[1] || score.Nulls[0] {
+		t.Fatalf("score = %+v", score)
+	}
+	if tags.Kind != ColumnValues || len(tags.Values) != 2 || tags.Values[1] != nil {
+		t.Fatalf("tags = %+v", tags)
+	}
+
+	ok, err = c.NextColumns(&batch)
+	if err != nil || !ok || batch.Len != 1 {
+		t.Fatalf("NextColumns = %v, %v, len %d", ok, err, batch.Len)
+	}
+	if name := batch.Column("name"); len(name.Strings) != 1 || !name.Nulls[0] {
+		t.Fatalf("name = %+v", name)
+	}
+	if ok, err := c.NextColumns(&batch); ok || err != nil {
+		t.Fatalf("NextColumns at end = %v, %v", ok, err)
+	}
+	if _, err := c.NextRow(); err == nil {
+		t.Fatal("NextRow should fail in columnar mode")
+	}
+}
+
+func TestNextColumnsTypeMismatch(t *testing.T) {
+	c := newTestCursor(
+		typedHeaderFrame(map[string]pb.GqlType{"n": pb.GqlType_TYPE_INT64}, "n"),
+		batchFrame([]any{"one"}),
+		summaryFrame(Success, 0),
+	)
+	c.columnar = true
+	if _, err := c.NextColumns(&ColumnBatch{}); err == nil {
+		t.Fatal("expected an error for a string in an INT64 column")
+	}
+}
+
+func TestNextColumnsRequiresColumnar(t *testing.T) {
+	c := newTestCursor(headerFrame("n"), summaryFrame(Success, 0))
+	if _, err := c.NextColumns(&ColumnBatch{}); err == nil {
+		t.Fatal("expected an error without WithColumnar")
+	}
+}
+
+func numericFrames() []*pb.ExecuteResponse {
+	frames := []*pb.ExecuteResponse{typedHeaderFrame(map[string]pb.GqlType{
+		"id":    pb.GqlType_TYPE_INT64,
+		"score": pb.GqlType_TYPE_FLOAT64,
+		"ok":    pb.GqlType_TYPE_BOOLEAN,
+	}, "id", "score", "ok")}
+	for b := 0; b < 100; b++ {
+		rows := make([][]any, 100)
+		for i := range rows {
+			rows[i] = []any{int64(i), float64(i) / 3, i%2 == 0}
+		}
+		frames = append(frames, batchFrame(rows...))
+	}
+	return append(frames, summaryFrame(Success, 0))
+}
+
+func TestNextColumnsDoesNotAllocatePerValue(t *testing.T) {
+	frames := numericFrames()
+	var batch ColumnBatch
+	allocs := testing.AllocsPerRun(10, func() {
+		c := newResultCursor(&fakeStream{frames: frames})
+		c.columnar = true
+		for {
+			ok, err := c.NextColumns(&batch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				return
+			}
+		}
+	})
+	// 10,000 rows of 3 columns: per-value boxing would cost 30,000.
+	if allocs > 200 {
+		t.Fatalf("%.0f allocations per result", allocs)
+	}
+}
+
+func BenchmarkNextColumns(b *testing.B) {
+	frames := numericFrames()
+	var batch ColumnBatch
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := newResultCursor(&fakeStream{frames: frames})
+		c.columnar = true
+		for {
+			ok, err := c.NextColumns(&batch)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if !ok {
+				break
+			}
+		}
+	}
+}
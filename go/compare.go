@@ -0,0 +1,370 @@
+package gwp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompareConfig controls how CompareResults and DiffResults match rows.
+type CompareConfig struct {
+	// Ordered matches rows by position. By default rows are matched
+	// regardless of order, as results without ORDER BY have none.
+	Ordered bool
+	// Key names the columns that identify a row. Unordered rows with equal
+	// keys are compared column by column and reported as changed; without
+	// a key, rows match only if they are equal and others are reported as
+	// present on one side only.
+	Key []string
+}
+
+// ResultDiff is the difference between two results, as compared by Equal.
+type ResultDiff struct {
+	// ColumnsA and ColumnsB are the column names of each result. Rows are
+	// compared by column name.
+	ColumnsA, ColumnsB []string
+	// RowsA and RowsB count the rows of each result.
+	RowsA, RowsB int
+	// OnlyInA and OnlyInB are rows without a match in the other result.
+	OnlyInA, OnlyInB [][]any
+	// Changed are rows matched by position or key whose values differ.
+	Changed []RowDiff
+	// Elements are nodes and edges, found anywhere in the rows, that are
+	// present in one result only or differ between them, by element ID.
+	Elements []ElementDiff
+}
+
+// RowDiff is a pair of matched rows that differ.
+type RowDiff struct {
+	// IndexA and IndexB are the rows' positions in each result.
+	IndexA, IndexB int
+	A, B           []any
+	// Diff describes the differences, one line per differing value, as
+	// Diff does, with paths such as "$.age".
+	Diff string
+}
+
+// ElementDiff is a node or edge that differs between two results.
+type ElementDiff struct {
+	ID ElementID
+	// A and B are the *GqlNode or *GqlEdge in each result, or nil where it
+	// is absent.
+	A, B any
+	Diff string
+}
+
+// Equal reports whether the results have the same columns and rows.
+func (d *ResultDiff) Equal() bool {
+	return columnsEqual(d.ColumnsA, d.ColumnsB) && len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0 &&
+		len(d.Changed) == 0 && len(d.Elements) == 0
+}
+
+// String summarizes the differences, one per line.
+func (d *ResultDiff) String() string {
+	var b strings.Builder
+	if !columnsEqual(d.ColumnsA, d.ColumnsB) {
+		fmt.Fprintf(&b, "columns: %v != %v\n", d.ColumnsA, d.ColumnsB)
+	}
+	for _, row := range d.OnlyInA {
+		fmt.Fprintf(&b, "only in A: %s\n", formatRow(row))
+	}
+	for _, row := range d.OnlyInB {
+		fmt.Fprintf(&b, "only in B: %s\n", formatRow(row))
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "row %d/%d: %s\n", c.IndexA, c.IndexB, strings.ReplaceAll(c.Diff, "\n", "; "))
+	}
+	for _, e := range d.Elements {
+		switch {
+		case e.A == nil:
+			fmt.Fprintf(&b, "only in B: %s\n", formatDiffValue(e.B))
+		case e.B == nil:
+			fmt.Fprintf(&b, "only in A: %s\n", formatDiffValue(e.A))
+		default:
+			fmt.Fprintf(&b, "%s: %s\n", formatDiffValue(e.A), strings.ReplaceAll(e.Diff, "\n", "; "))
+		}
+	}
+	return b.String()
+}
+
+func formatRow(row []any) string {
+	parts := make([]string, len(row))
+	for i, v := range row {
+		parts[i] = formatDiffValue(v)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func columnsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareResults runs statement on a and b concurrently, reads both
+// results and returns their differences, for validating a migration or
+// checking a replica against its primary. Both results are held in memory.
+func CompareResults(ctx context.Context, a, b Querier, statement string, params map[string]any, config CompareConfig) (*ResultDiff, error) {
+	type result struct {
+		columns []string
+		rows    [][]any
+		err     error
+	}
+	var (
+		wg      sync.WaitGroup
+		results [2]result
+	)
+	for i, q := range []Querier{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := &results[i]
+			r.columns, r.rows, r.err = collectResult(ctx, q, statement, params)
+		}()
+	}
+	wg.Wait()
+	if err := results[0].err; err != nil {
+		return nil, fmt.Errorf("result A: %w", err)
+	}
+	if err := results[1].err; err != nil {
+		return nil, fmt.Errorf("result B: %w", err)
+	}
+	return DiffResults(results[0].columns, results[0].rows, results[1].columns, results[1].rows, config), nil
+}
+
+func collectResult(ctx context.Context, q Querier, statement string, params map[string]any) ([]string, [][]any, error) {
+	cursor, err := q.Execute(ctx, statement, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	columns, err := cursor.ColumnNames()
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := cursor.CollectRows()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkCursorStatus(cursor); err != nil {
+		return nil, nil, err
+	}
+	return columns, rows, nil
+}
+
+// DiffResults compares two results already read, given as column names
+// and rows.
+func DiffResults(columnsA []string, rowsA [][]any, columnsB []string, rowsB [][]any, config CompareConfig) *ResultDiff {
+	d := &ResultDiff{ColumnsA: columnsA, ColumnsB: columnsB, RowsA: len(rowsA), RowsB: len(rowsB)}
+	c := rowComparer{columnsA: columnsA, columnsB: columnsB}
+
+	switch {
+	case config.Ordered:
+		for i := 0; i < min(len(rowsA), len(rowsB)); i++ {
+			if diff := c.diff(rowsA[i], rowsB[i]); diff != "" {
+				d.Changed = append(d.Changed, RowDiff{IndexA: i, IndexB: i, A: rowsA[i], B: rowsB[i], Diff: diff})
+			}
+		}
+		d.OnlyInA = append(d.OnlyInA, rowsA[min(len(rowsA), len(rowsB)):]...)
+		d.OnlyInB = append(d.OnlyInB, rowsB[min(len(rowsA), len(rowsB)):]...)
+	default:
+		key := config.Key
+		if len(key) == 0 {
+			key = columnsA
+		}
+		buckets := make(map[string][]int, len(rowsB))
+		for j, row := range rowsB {
+			k := c.bucket(columnsB, row, key)
+			buckets[k] = append(buckets[k], j)
+		}
+		matched := make([]bool, len(rowsB))
+		for i, row := range rowsA {
+			k := c.bucket(columnsA, row, key)
+			j := -1
+			for n, candidate := range buckets[k] {
+				if c.keysEqual(row, rowsB[candidate], key) {
+					j = candidate
+					buckets[k] = append(buckets[k][:n:n], buckets[k][n+1:]...)
+					break
+				}
+			}
+			if j < 0 {
+				d.OnlyInA = append(d.OnlyInA, row)
+				continue
+			}
+			matched[j] = true
+			if diff := c.diff(row, rowsB[j]); diff != "" {
+				d.Changed = append(d.Changed, RowDiff{IndexA: i, IndexB: j, A: row, B: rowsB[j], Diff: diff})
+			}
+		}
+		for j, row := range rowsB {
+			if !matched[j] {
+				d.OnlyInB = append(d.OnlyInB, row)
+			}
+		}
+	}
+
+	d.Elements = diffElements(rowsA, rowsB)
+	return d
+}
+
+// rowComparer compares rows of two results by column name.
+type rowComparer struct {
+	columnsA, columnsB []string
+}
+
+func (c rowComparer) record(columns []string, row []any) map[string]any {
+	m := make(map[string]any, len(columns))
+	for i, name := range columns {
+		if i < len(row) {
+			m[name] = row[i]
+		}
+	}
+	return m
+}
+
+func (c rowComparer) diff(a, b []any) string {
+	var d differ
+	d.maps("$", c.record(c.columnsA, a), c.record(c.columnsB, b))
+	return strings.Join(d.lines, "\n")
+}
+
+func (c rowComparer) keysEqual(a, b []any, key []string) bool {
+	ra, rb := c.record(c.columnsA, a), c.record(c.columnsB, b)
+	d := differ{limit: 1}
+	for _, k := range key {
+		va, ok := ra[k]
+		if !ok {
+			va = missing{}
+		}
+		vb, ok := rb[k]
+		if !ok {
+			vb = missing{}
+		}
+		d.diffOrMissing("$."+k, va, vb)
+	}
+	return len(d.lines) == 0
+}
+
+// bucket returns a coarse fingerprint of the row's key columns: rows whose
+// keys are Equal always share a bucket.
+func (c rowComparer) bucket(columns []string, row []any, key []string) string {
+	r := c.record(columns, row)
+	var b strings.Builder
+	for _, k := range key {
+		b.WriteString(fingerprint(r[k]))
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func fingerprint(v any) string {
+	v = normalizeValue(v)
+	if i, f, u, kind := number(v); kind != 0 {
+		return strconv.FormatFloat(toFloat(i, f, u, kind), 'g', -1, 64)
+	}
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(x)
+	case bool:
+		return strconv.FormatBool(x)
+	case *GqlNode, *GqlEdge:
+		return formatDiffValue(x)
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// diffElements compares the nodes and edges in two results by element ID.
+func diffElements(rowsA, rowsB [][]any) []ElementDiff {
+	a, b := collectElements(rowsA), collectElements(rowsB)
+	keys := make([]elementKey, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].edge != keys[j].edge {
+			return !keys[i].edge
+		}
+		return keys[i].id < keys[j].id
+	})
+
+	var diffs []ElementDiff
+	for _, k := range keys {
+		ea, eb := a[k], b[k]
+		switch {
+		case ea == nil:
+			diffs = append(diffs, ElementDiff{ID: k.id, B: eb})
+		case eb == nil:
+			diffs = append(diffs, ElementDiff{ID: k.id, A: ea})
+		default:
+			if diff := Diff(ea, eb); diff != "" {
+				diffs = append(diffs, ElementDiff{ID: k.id, A: ea, B: eb, Diff: diff})
+			}
+		}
+	}
+	return diffs
+}
+
+// elementKey identifies a node or edge. Nodes and edges are keyed apart,
+// as their IDs may overlap.
+type elementKey struct {
+	edge bool
+	id   ElementID
+}
+
+// collectElements returns the nodes and edges in rows by element ID.
+func collectElements(rows [][]any) map[elementKey]any {
+	elements := make(map[elementKey]any)
+	var add func(v any)
+	add = func(v any) {
+		switch x := v.(type) {
+		case *GqlNode:
+			elements[elementKey{id: x.ElementID()}] = x
+		case *GqlEdge:
+			elements[elementKey{edge: true, id: x.ElementID()}] = x
+		case *GqlPath:
+			for _, n := range x.Nodes {
+				add(n)
+			}
+			for _, e := range x.Edges {
+				add(e)
+			}
+		case *GqlRecord:
+			for _, f := range x.Fields {
+				add(f.Value)
+			}
+		case []any:
+			for _, e := range x {
+				add(e)
+			}
+		case map[string]any:
+			for _, e := range x {
+				add(e)
+			}
+		}
+	}
+	for _, row := range rows {
+		for _, v := range row {
+			add(v)
+		}
+	}
+	return elements
+}
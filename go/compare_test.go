@@ -0,0 +1,93 @@
+package gwp
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDiffResultsUnordered(t *testing.T) {
+	columns := []string{"name", "age"}
+	a := [][]any{{"Alice", int64(30)}, {"Bob", int64(25)}, {"Carol", int64(41)}}
+	b := [][]any{{"Bob", 25.0}, {"Alice", int64(30)}, {"Dave", int64(50)}}
+
+	d := DiffResults(columns, a, columns, b, CompareConfig{})
+	if d.Equal() || len(d.OnlyInA) != 1 || len(d.OnlyInB) != 1 || len(d.Changed) != 0 {
+		t.Fatalf("diff = %+v", d)
+	}
+	if d.OnlyInA[0][0] != "Carol" || d.OnlyInB[0][0] != "Dave" {
+		t.Fatalf("diff = %s", d)
+	}
+	if same := DiffResults(columns, a, []string{"age", "name"}, [][]any{{int64(41), "Carol"}, {int64(30), "Alice"}, {int64(25), "Bob"}}, CompareConfig{}); !same.Equal() {
+		t.Fatalf("reordered rows and columns should be equal:\n%s", same)
+	}
+}
+
+func TestDiffResultsKeyed(t *testing.T) {
+	columns := []string{"name", "age"}
+	a := [][]any{{"Alice", int64(30)}, {"Bob", int64(25)}}
+	b := [][]any{{"Bob", int64(26)}, {"Alice", int64(30)}}
+
+	d := DiffResults(columns, a, columns, b, CompareConfig{Key: []string{"name"}})
+	if len(d.Changed) != 1 || len(d.OnlyInA)+len(d.OnlyInB) != 0 {
+		t.Fatalf("diff = %s", d)
+	}
+	c := d.Changed[0]
+	if c.IndexA != 1 || c.IndexB != 0 || c.Diff != "$.age: 25 != 26" {
+		t.Fatalf("changed = %+v", c)
+	}
+}
+
+func TestDiffResultsOrderedAndElements(t *testing.T) {
+	alice := &GqlNode{ID: []byte{1}, Labels: []string{"Person"}, Properties: map[string]any{"age": int64(30)}}
+	older := &GqlNode{ID: []byte{1}, Labels: []string{"Person"}, Properties: map[string]any{"age": int64(31)}}
+	bob := &GqlNode{ID: []byte{2}, Labels: []string{"Person"}}
+
+	d := DiffResults([]string{"p"}, [][]any{{alice}, {bob}}, []string{"p"}, [][]any{{older}}, CompareConfig{Ordered: true})
+	if len(d.Changed) != 1 || len(d.OnlyInA) != 1 || d.RowsA != 2 || d.RowsB != 1 {
+		t.Fatalf("diff = %s", d)
+	}
+	if len(d.Elements) != 2 {
+		t.Fatalf("elements = %+v", d.Elements)
+	}
+	if e := d.Elements[0]; e.ID != alice.ElementID() || e.Diff != "$.properties.age: 30 != 31" {
+		t.Fatalf("changed element = %+v", e)
+	}
+	if e := d.Elements[1]; e.ID != bob.ElementID() || e.B != nil {
+		t.Fatalf("missing element = %+v", e)
+	}
+	if s := d.String(); !strings.Contains(s, "only in A: node 02") {
+		t.Fatalf("String = %q", s)
+	}
+}
+
+func TestCompareResults(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	a, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close(ctx)
+	b, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close(ctx)
+
+	d, err := CompareResults(ctx, a, b, "MATCH (n) RETURN n", nil, CompareConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Equal() || d.RowsA == 0 {
+		t.Fatalf("diff = %+v", d)
+	}
+	if _, err := CompareResults(ctx, a, b, "ERROR", nil, CompareConfig{}); err == nil || !strings.HasPrefix(err.Error(), "result A: ") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package gwp
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+)
+
+func TestZstdCompressorRoundTrip(t *testing.T) {
+	c := encoding.GetCompressor(CompressionZstd)
+	if c == nil {
+		t.Fatal("zstd compressor not registered")
+	}
+	if encoding.GetCompressor(CompressionGzip) == nil {
+		t.Fatal("gzip compressor not registered")
+	}
+
+	payload := []byte(strings.Repeat("MATCH (n:Person) RETURN n.name; ", 200))
+	for i := 0; i < 3; i++ { // exercise pooled encoders and decoders
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		if err != nil {
+			t.Fatalf("Compress: %v", err)
+		}
+		if _, err := w.Write(payload); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if buf.Len() >= len(payload) {
+			t.Fatalf("compressed size %d not smaller than %d", buf.Len(), len(payload))
+		}
+
+		r, err := c.Decompress(&buf)
+		if err != nil {
+			t.Fatalf("Decompress: %v", err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Fatal("round trip mismatch")
+		}
+	}
+}
+
+func TestWithCompression(t *testing.T) {
+	o := newExecuteOptions([]ExecuteOption{WithCompression(CompressionZstd)})
+	if len(o.callOpts) != 1 {
+		t.Fatalf("expected one call option, got %d", len(o.callOpts))
+	}
+}
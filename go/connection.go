@@ -2,9 +2,15 @@ package gwp
 
 import (
 	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 
 	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
 )
@@ -12,33 +18,308 @@ import (
 // GqlConnection is a connection to a GWP server.
 type GqlConnection struct {
 	conn          *grpc.ClientConn
+	config        ConnectionConfig
 	sessionClient pb.SessionServiceClient
 	gqlClient     pb.GqlServiceClient
+	catalogClient pb.CatalogServiceClient
+	adminClient   pb.AdminServiceClient
+	searchClient  pb.SearchServiceClient
+	healthClient  healthpb.HealthClient
+	tokens        *tokenCache
+	affinity      *endpointAffinity
+	limiter       *limiter
+
+	mu           sync.Mutex
+	sessions     map[*GqlSession]struct{}
+	closed       bool
+	draining     bool
+	inFlight     int
+	drained      chan struct{}
+	cursors      map[*ResultCursor]struct{}
+	transactions map[*Transaction]struct{}
+	breakers     map[string]*circuitBreaker
+
+	queriesMu sync.RWMutex
+	queries   map[string]string
+}
+
+// ConnectionConfig holds configuration for a connection.
+type ConnectionConfig struct {
+	// DialOptions are passed to grpc.NewClient after the options derived
+	// from the other fields. If empty, insecure transport credentials are
+	// used.
+	DialOptions []grpc.DialOption
+
+	// UnaryInterceptors and StreamInterceptors are added to the
+	// connection's RPCs alongside the client's own interceptors, which run
+	// first, in this order: trace metadata, bearer-token refresh, and
+	// endpoint affinity for ConnectEndpoints. The given interceptors run
+	// next, in slice order, so they see the outgoing metadata the client
+	// has added and each attempt of an RPC the client retries.
+	// Interceptors chained through DialOptions run last.
+	UnaryInterceptors  []grpc.UnaryClientInterceptor
+	StreamInterceptors []grpc.StreamClientInterceptor
+
+	// KeepaliveTime is the interval of gRPC keepalive pings on an idle
+	// transport. Zero disables client keepalive.
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is how long to wait for a keepalive ack before
+	// closing the transport. Defaults to 20 seconds.
+	KeepaliveTimeout time.Duration
+
+	// Dialer, if set, creates the transport connections in place of the
+	// default TCP dialer, for example to reach an in-process server.
+	Dialer func(ctx context.Context, addr string) (net.Conn, error)
+
+	// Proxy is the URL of an HTTP CONNECT proxy ("http://" or "https://")
+	// or SOCKS5 proxy ("socks5://" or "socks5h://"), optionally with
+	// "user:password@" credentials, through which servers are reached.
+	Proxy string
+	// ProxyFromEnvironment, when Proxy is empty, takes the proxy from the
+	// HTTPS_PROXY or ALL_PROXY environment variable, honoring NO_PROXY.
+	// Proxies cannot be combined with Dialer.
+	ProxyFromEnvironment bool
+
+	// LoadBalancing is the policy ConnectEndpoints uses to spread
+	// sessions across endpoints: BalanceRoundRobin, the default, or
+	// BalanceLeastInFlight.
+	LoadBalancing string
+	// EndpointHealthChecks makes ConnectEndpoints watch each endpoint's
+	// grpc.health.v1 status and skip endpoints that are not serving.
+	EndpointHealthChecks bool
+
+	// ServiceRefreshInterval is how often the SRV records of a
+	// "gwp+srv://" target are looked up again to follow membership
+	// changes. Defaults to 30 seconds.
+	ServiceRefreshInterval time.Duration
+
+	// MaxRecvMsgSize is the largest response message, such as a row batch
+	// holding long strings or byte values, the client accepts. Defaults
+	// to gRPC's 4 MB. Larger frames fail with codes.ResourceExhausted.
+	MaxRecvMsgSize int
+	// MaxSendMsgSize is the largest request message, such as a statement
+	// with large parameters, the client sends. Defaults to gRPC's limit.
+	MaxSendMsgSize int
+
+	// Compression names the compressor used for requests, such as
+	// CompressionGzip or CompressionZstd. Responses are compressed if the
+	// server supports the same compressor. Empty disables compression.
+	Compression string
+
+	// Interceptors observe or alter every statement executed on sessions
+	// created by the connection, in order.
+	Interceptors []StatementInterceptor
+
+	// HeartbeatInterval, if non-zero, makes every session created on the
+	// connection Ping the server whenever it has been idle for this long,
+	// so the server's idle-session reaper does not close it.
+	HeartbeatInterval time.Duration
+	// OnSessionLost is called once per session when a heartbeat, Ping or
+	// statement finds that the server no longer knows the session. The
+	// heartbeat stops afterwards.
+	OnSessionLost func(session *GqlSession, err error)
+
+	// SessionLeakTimeout, if non-zero, enables leak detection: the stack
+	// of every CreateSession call is recorded, and a session still open
+	// after this long is passed to OnSessionLeak, which defaults to a
+	// warning through slog.Default. Use PanicOnSessionLeak to fail tests.
+	SessionLeakTimeout time.Duration
+	OnSessionLeak      func(SessionLeak)
+
+	// CircuitBreaker, if set, puts a circuit breaker in front of each
+	// endpoint: after repeated failures statements to the endpoint fail at
+	// once with a *CircuitOpenError instead of waiting for their deadlines,
+	// until probe statements succeed again.
+	CircuitBreaker *CircuitBreakerConfig
+
+	// ResultCache, if set, serves statements executed WithCache from
+	// memory.
+	ResultCache *ResultCache
+
+	// Admission, if set, limits the statements in flight and started per
+	// second across the connection's sessions. Sessions can have limits
+	// of their own with WithAdmission.
+	Admission *AdmissionConfig
+
+	// ParamLimits bounds the nesting and size of statement parameters.
+	// The zero value applies the defaults.
+	ParamLimits ParamLimits
+
+	// StrictConversion fails with an *UnsupportedTypeError on result
+	// values of kinds the package does not know and on values
+	// CollectSpilled cannot write. By default these decode to nil and are
+	// spilled as NULL; strict conversion becomes the default in the next
+	// major version.
+	StrictConversion bool
+
+	// Listeners receive connectivity state changes, reconnect attempts and
+	// lost sessions, and circuit breaker state changes if they implement
+	// BreakerListener.
+	Listeners []ConnectionListener
+
+	// MaxTransactionAttempts bounds how often ExecuteRead and ExecuteWrite
+	// run their function when it fails with a transient error. Defaults
+	// to 3; 1 disables replay.
+	MaxTransactionAttempts int
+	// OnTransactionRetry, if set, is called before a managed transaction
+	// is replayed, with the number of the failed attempt and its error.
+	OnTransactionRetry func(attempt int, err error)
+
+	// TokenSource, if set, supplies bearer tokens that are presented in
+	// the handshake and attached to every RPC. A token is refreshed when it
+	// expires within TokenRefreshWindow, which defaults to one minute, and
+	// after the server rejects an RPC as UNAUTHENTICATED, which is then
+	// retried once.
+	TokenSource        TokenSource
+	TokenRefreshWindow time.Duration
+	// AllowInsecureTokens permits sending tokens over a connection without
+	// transport security, such as a Unix socket or a test server.
+	AllowInsecureTokens bool
+
+	// MaxProtocolVersion caps the protocol version offered in the
+	// handshake, to pin sessions to an older version. Zero or a value above
+	// the package's MaxProtocolVersion means MaxProtocolVersion.
+	MaxProtocolVersion uint32
+
+	// TraceMetadata returns the trace headers to send with an RPC made
+	// with ctx, such as "traceparent" and "tracestate" taken from a tracing
+	// library's span. It defaults to the trace context set with
+	// ContextWithTraceParent. Headers already in the outgoing metadata are
+	// not replaced.
+	TraceMetadata func(ctx context.Context) map[string]string
 }
 
 // Connect creates a new connection to a GWP server.
+//
+// The target is a host:port, or a gRPC target URI such as
+// "unix:///run/gwp.sock" for a Unix domain socket or "passthrough:///addr"
+// to skip name resolution. An absolute file path is treated as a Unix
+// domain socket. A "gwp+srv://name" target spreads sessions across the
+// servers listed in the DNS SRV records of name, as ConnectEndpoints does
+// for a static list.
 func Connect(ctx context.Context, target string, opts ...grpc.DialOption) (*GqlConnection, error) {
-	if len(opts) == 0 {
+	return ConnectWithConfig(ctx, target, ConnectionConfig{DialOptions: opts})
+}
+
+// ConnectWithDialer creates a new connection whose transport connections are
+// created by dialer, which receives target unchanged. It suits in-process
+// listeners such as bufconn.
+func ConnectWithDialer(ctx context.Context, target string, dialer func(ctx context.Context, addr string) (net.Conn, error), opts ...grpc.DialOption) (*GqlConnection, error) {
+	return ConnectWithConfig(ctx, target, ConnectionConfig{Dialer: dialer, DialOptions: opts})
+}
+
+// ConnectWithConfig creates a new connection to a GWP server using the given
+// configuration.
+func ConnectWithConfig(ctx context.Context, target string, config ConnectionConfig) (*GqlConnection, error) {
+	if isSRVTarget(target) {
+		return connectSRV(ctx, target, config)
+	}
+	return connect(ctx, target, config, nil)
+}
+
+// connect creates a connection with the dial options derived from config,
+// followed by extra.
+func connect(ctx context.Context, target string, config ConnectionConfig, extra []grpc.DialOption) (*GqlConnection, error) {
+	var opts []grpc.DialOption
+	dialer := config.Dialer
+	if config.Proxy != "" || config.ProxyFromEnvironment {
+		if dialer != nil {
+			return nil, &GqlError{Message: "a proxy cannot be combined with a custom dialer"}
+		}
+		var err error
+		if dialer, err = proxyDialer(config); err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithNoProxy())
+	}
+	target = normalizeTarget(target, dialer != nil)
+	if dialer != nil {
+		opts = append(opts, grpc.WithContextDialer(dialer))
+	}
+	if config.KeepaliveTime > 0 {
+		timeout := config.KeepaliveTimeout
+		if timeout == 0 {
+			timeout = 20 * time.Second
+		}
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                config.KeepaliveTime,
+			Timeout:             timeout,
+			PermitWithoutStream: true,
+		}))
+	}
+	if config.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(config.MaxRecvMsgSize)))
+	}
+	if config.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(config.MaxSendMsgSize)))
+	}
+	if config.Compression != "" {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(config.Compression)))
+	}
+	opts = append(opts, traceDialOptions(config.TraceMetadata)...)
+	var tokens *tokenCache
+	if config.TokenSource != nil {
+		tokens = newTokenCache(config)
+		opts = append(opts, tokens.dialOptions()...)
+	}
+	if len(config.DialOptions) == 0 {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
+	opts = append(opts, extra...)
+	if len(config.UnaryInterceptors) > 0 {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(config.UnaryInterceptors...))
+	}
+	if len(config.StreamInterceptors) > 0 {
+		opts = append(opts, grpc.WithChainStreamInterceptor(config.StreamInterceptors...))
+	}
+	opts = append(opts, config.DialOptions...)
 
 	conn, err := grpc.NewClient(target, opts...)
 	if err != nil {
 		return nil, &GqlError{Message: "failed to connect: " + err.Error()}
 	}
 
-	return &GqlConnection{
+	c := &GqlConnection{
 		conn:          conn,
+		config:        config,
 		sessionClient: pb.NewSessionServiceClient(conn),
 		gqlClient:     pb.NewGqlServiceClient(conn),
-	}, nil
+		catalogClient: pb.NewCatalogServiceClient(conn),
+		adminClient:   pb.NewAdminServiceClient(conn),
+		searchClient:  pb.NewSearchServiceClient(conn),
+		healthClient:  healthpb.NewHealthClient(conn),
+		sessions:      make(map[*GqlSession]struct{}),
+		tokens:        tokens,
+	}
+	if config.Admission != nil {
+		c.limiter = newLimiter(*config.Admission)
+	}
+	if len(config.Listeners) > 0 {
+		go c.watchState()
+	}
+	return c, nil
+}
+
+// normalizeTarget maps absolute socket paths to unix targets and, when a
+// custom dialer is used, passes scheme-less targets to it unresolved.
+func normalizeTarget(target string, customDialer bool) string {
+	if strings.HasPrefix(target, "/") {
+		return "unix://" + target
+	}
+	if customDialer && !strings.Contains(target, ":///") && !strings.HasPrefix(target, "unix:") && !isSRVTarget(target) {
+		return "passthrough:///" + target
+	}
+	return target
 }
 
 // CreateSession performs a handshake and returns a new session.
-func (c *GqlConnection) CreateSession(ctx context.Context) (*GqlSession, error) {
-	resp, err := c.sessionClient.Handshake(ctx, &pb.HandshakeRequest{
-		ProtocolVersion: 1,
-	})
+func (c *GqlConnection) CreateSession(ctx context.Context, opts ...SessionOption) (*GqlSession, error) {
+	o := newSessionOptions(opts)
+	if c.isDraining() {
+		return nil, errShuttingDown
+	}
+
+	resp, version, err := c.handshake(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -47,11 +328,37 @@ func (c *GqlConnection) CreateSession(ctx context.Context) (*GqlSession, error)
 		return nil, &SessionError{Message: "server returned empty session ID"}
 	}
 
-	return &GqlSession{
+	s := &GqlSession{
 		sessionID:     resp.SessionId,
 		sessionClient: c.sessionClient,
 		gqlClient:     c.gqlClient,
-	}, nil
+		catalogClient: c.catalogClient,
+		adminClient:   c.adminClient,
+		searchClient:  c.searchClient,
+		features:      resp.GetServerInfo().GetFeatures(),
+		onClose:       c.untrack,
+		conn:          c,
+	}
+	s.protocolVersion = version
+	if o.admission != nil {
+		s.limiter = newLimiter(*o.admission)
+	}
+	if n := len(c.config.Interceptors) + len(o.interceptors); n > 0 {
+		s.interceptors = make(interceptorChain, 0, n)
+		s.interceptors = append(s.interceptors, c.config.Interceptors...)
+		s.interceptors = append(s.interceptors, o.interceptors...)
+	}
+	c.mu.Lock()
+	c.sessions[s] = struct{}{}
+	c.mu.Unlock()
+	s.touch()
+	if c.config.HeartbeatInterval > 0 {
+		s.startHeartbeat(c.config.HeartbeatInterval)
+	}
+	if c.config.SessionLeakTimeout > 0 {
+		c.watchLeak(s)
+	}
+	return s, nil
 }
 
 // CreateCatalogClient creates a new catalog management client for schemas, graphs, and graph types.
@@ -59,7 +366,54 @@ func (c *GqlConnection) CreateCatalogClient() *CatalogClient {
 	return NewCatalogClient(c.conn)
 }
 
-// Close closes the underlying gRPC connection.
-func (c *GqlConnection) Close() error {
-	return c.conn.Close()
+// ConnectionStats describes the state of a connection.
+type ConnectionStats struct {
+	Open         bool
+	OpenSessions int
+}
+
+// Stats returns whether the connection is open and how many of its sessions
+// are open.
+func (c *GqlConnection) Stats() ConnectionStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ConnectionStats{Open: !c.closed, OpenSessions: len(c.sessions)}
+}
+
+// isDraining reports whether Shutdown has been called.
+func (c *GqlConnection) isDraining() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.draining
+}
+
+// untrack forgets a session once it has been closed.
+func (c *GqlConnection) untrack(s *GqlSession) {
+	c.mu.Lock()
+	delete(c.sessions, s)
+	c.mu.Unlock()
+}
+
+// Close closes every session created on this connection that is still open,
+// then closes the underlying gRPC connection. The connection is closed even
+// if closing a session fails; the first error is returned.
+func (c *GqlConnection) Close(ctx context.Context) error {
+	c.mu.Lock()
+	c.closed = true
+	sessions := make([]*GqlSession, 0, len(c.sessions))
+	for s := range c.sessions {
+		sessions = append(sessions, s)
+	}
+	c.mu.Unlock()
+
+	var firstErr error
+	for _, s := range sessions {
+		if err := s.Close(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := c.conn.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
 }
@@ -0,0 +1,143 @@
+package gwp
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type handshakeServer struct {
+	pb.UnimplementedSessionServiceServer
+}
+
+func (handshakeServer) Handshake(ctx context.Context, r *pb.HandshakeRequest) (*pb.HandshakeResponse, error) {
+	return &pb.HandshakeResponse{ProtocolVersion: 1, SessionId: "in-process"}, nil
+}
+
+func (handshakeServer) Close(ctx context.Context, r *pb.CloseRequest) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
+
+func (handshakeServer) Ping(ctx context.Context, r *pb.PingRequest) (*pb.PongResponse, error) {
+	return &pb.PongResponse{Timestamp: 1}, nil
+}
+
+func (handshakeServer) Configure(ctx context.Context, r *pb.ConfigureRequest) (*pb.ConfigureResponse, error) {
+	return &pb.ConfigureResponse{}, nil
+}
+
+func (handshakeServer) Reset(ctx context.Context, r *pb.ResetRequest) (*pb.ResetResponse, error) {
+	return &pb.ResetResponse{}, nil
+}
+
+func TestNormalizeTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		dialer bool
+		want   string
+	}{
+		{"localhost:50051", false, "localhost:50051"},
+		{"/run/gwp.sock", false, "unix:///run/gwp.sock"},
+		{"unix:///run/gwp.sock", true, "unix:///run/gwp.sock"},
+		{"bufnet", true, "passthrough:///bufnet"},
+		{"dns:///db:50051", true, "dns:///db:50051"},
+		{"gwp+srv://gwp.service.consul", true, "gwp+srv://gwp.service.consul"},
+	}
+	for _, tt := range tests {
+		if got := normalizeTarget(tt.target, tt.dialer); got != tt.want {
+			t.Fatalf("normalizeTarget(%q, %v) = %q, want %q", tt.target, tt.dialer, got, tt.want)
+		}
+	}
+}
+
+func TestConnectWithDialer(t *testing.T) {
+	lis := serveBufconn(t, handshakeServer{})
+
+	ctx := context.Background()
+	conn, err := ConnectWithDialer(ctx, "bufnet", bufDialer(lis))
+	if err != nil {
+		t.Fatalf("ConnectWithDialer: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	s, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if s.SessionID() != "in-process" {
+		t.Fatalf("SessionID = %q", s.SessionID())
+	}
+}
+
+func TestConnectUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gwp.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	serve(t, lis, handshakeServer{})
+
+	ctx := context.Background()
+	for _, target := range []string{"unix://" + path, path} {
+		conn, err := Connect(ctx, target)
+		if err != nil {
+			t.Fatalf("Connect(%q): %v", target, err)
+		}
+		if _, err := conn.CreateSession(ctx); err != nil {
+			t.Fatalf("CreateSession via %q: %v", target, err)
+		}
+		conn.Close(ctx)
+	}
+}
+
+// largeRowServer returns a single row holding a 5 MB string.
+type largeRowServer struct {
+	handshakeServer
+	pb.UnimplementedGqlServiceServer
+}
+
+func (largeRowServer) Execute(r *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	if err := stream.Send(batchFrame([]any{strings.Repeat("x", 5<<20)})); err != nil {
+		return err
+	}
+	return stream.Send(summaryFrame(Success, 0))
+}
+
+func TestMaxRecvMsgSize(t *testing.T) {
+	lis := serveBufconn(t, largeRowServer{})
+
+	ctx := context.Background()
+	session := func(config ConnectionConfig) *GqlSession {
+		s, err := connectBufconn(t, lis, config).CreateSession(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	read := func(s *GqlSession, opts ...ExecuteOption) error {
+		cursor, err := s.Execute(ctx, "MATCH (d:Doc) RETURN d.body", nil, opts...)
+		if err != nil {
+			return err
+		}
+		_, err = cursor.CollectRows()
+		return err
+	}
+
+	small := session(ConnectionConfig{})
+	if err := read(small); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("default limit: err = %v, want ResourceExhausted", err)
+	}
+	if err := read(small, WithMaxRecvMsgSize(8<<20)); err != nil {
+		t.Fatalf("per-statement limit: %v", err)
+	}
+	if err := read(session(ConnectionConfig{MaxRecvMsgSize: 8 << 20})); err != nil {
+		t.Fatalf("connection limit: %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package gwp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ConstraintKind identifies the kind of a graph constraint.
+type ConstraintKind string
+
+// Constraint kinds.
+const (
+	ConstraintUnique ConstraintKind = "UNIQUE"
+	ConstraintExists ConstraintKind = "EXISTS"
+	ConstraintKey    ConstraintKind = "KEY"
+)
+
+// ConstraintState is the lifecycle state of a constraint on the server.
+type ConstraintState string
+
+// Constraint states.
+const (
+	ConstraintPending ConstraintState = "PENDING"
+	ConstraintOnline  ConstraintState = "ONLINE"
+	ConstraintFailed  ConstraintState = "FAILED"
+)
+
+// ConstraintDefinition describes a constraint to create.
+type ConstraintDefinition struct {
+	Name        string
+	Kind        ConstraintKind
+	Label       string
+	Properties  []string
+	OnEdge      bool
+	IfNotExists bool
+}
+
+// ConstraintInfo holds information about an existing constraint.
+type ConstraintInfo struct {
+	Name       string
+	Kind       ConstraintKind
+	Label      string
+	Properties []string
+	OnEdge     bool
+	State      ConstraintState
+}
+
+// CreateConstraint creates a constraint from the given definition.
+func (s *GqlSession) CreateConstraint(ctx context.Context, def ConstraintDefinition) error {
+	stmt, err := createConstraintStatement(def)
+	if err != nil {
+		return err
+	}
+	return s.executeDDL(ctx, stmt)
+}
+
+// DropConstraint drops the named constraint.
+func (s *GqlSession) DropConstraint(ctx context.Context, name string, ifExists bool) error {
+	stmt := "DROP CONSTRAINT " + EscapeIdentifier(name)
+	if ifExists {
+		stmt += " IF EXISTS"
+	}
+	return s.executeDDL(ctx, stmt)
+}
+
+// ListConstraints returns all constraints on the current graph.
+func (s *GqlSession) ListConstraints(ctx context.Context) ([]ConstraintInfo, error) {
+	rows, err := s.queryCatalogRows(ctx, "SHOW CONSTRAINTS")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ConstraintInfo, 0, len(rows))
+	for _, row := range rows {
+		kind, _ := row["type"].(string)
+		if kind == "" {
+			kind, _ = row["kind"].(string)
+		}
+		label := firstString(row["label"])
+		if label == "" {
+			label = firstString(row["labelsortypes"])
+		}
+		entity, _ := row["entitytype"].(string)
+		state, _ := row["state"].(string)
+		name, _ := row["name"].(string)
+		result = append(result, ConstraintInfo{
+			Name:       name,
+			Kind:       ConstraintKind(strings.ToUpper(kind)),
+			Label:      label,
+			Properties: stringList(row["properties"]),
+			OnEdge:     strings.EqualFold(entity, "EDGE") || strings.EqualFold(entity, "RELATIONSHIP"),
+			State:      ConstraintState(strings.ToUpper(state)),
+		})
+	}
+	return result, nil
+}
+
+func createConstraintStatement(def ConstraintDefinition) (string, error) {
+	if def.Label == "" {
+		return "", &GqlError{Message: "constraint label is required"}
+	}
+	if len(def.Properties) == 0 {
+		return "", &GqlError{Message: "constraint requires at least one property"}
+	}
+
+	var requirement string
+	switch def.Kind {
+	case ConstraintUnique:
+		requirement = "IS UNIQUE"
+	case ConstraintExists:
+		if len(def.Properties) != 1 {
+			return "", &GqlError{Message: "existence constraint requires exactly one property"}
+		}
+		requirement = "IS NOT NULL"
+	case ConstraintKey:
+		requirement = "IS KEY"
+	default:
+		return "", &GqlError{Message: fmt.Sprintf("unknown constraint kind %q", def.Kind)}
+	}
+
+	var b strings.Builder
+	b.WriteString("CREATE CONSTRAINT")
+	if def.Name != "" {
+		b.WriteString(" ")
+		b.WriteString(EscapeIdentifier(def.Name))
+	}
+	if def.IfNotExists {
+		b.WriteString(" IF NOT EXISTS")
+	}
+	variable := "n"
+	if def.OnEdge {
+		variable = "r"
+		b.WriteString(" FOR ()-[r:" + EscapeIdentifier(def.Label) + "]-()")
+	} else {
+		b.WriteString(" FOR (n:" + EscapeIdentifier(def.Label) + ")")
+	}
+	b.WriteString(" REQUIRE ")
+	props := make([]string, len(def.Properties))
+	for i, p := range def.Properties {
+		props[i] = variable + "." + EscapeIdentifier(p)
+	}
+	if len(props) == 1 {
+		b.WriteString(props[0])
+	} else {
+		b.WriteString("(" + strings.Join(props, ", ") + ")")
+	}
+	b.WriteString(" " + requirement)
+	return b.String(), nil
+}
+
+// executeDDL executes a statement that produces no rows and converts an
+// exception status in the summary into an error.
+func (s *GqlSession) executeDDL(ctx context.Context, statement string) error {
+	cursor, err := s.Execute(ctx, statement, nil)
+	if err != nil {
+		return err
+	}
+	return checkCursorStatus(cursor)
+}
+
+// checkCursorStatus consumes the cursor and returns an error if the summary
+// carries an exception status.
+func checkCursorStatus(c *ResultCursor) error {
+	summary, err := c.Summary()
+	if err != nil || summary == nil {
+		return err
+	}
+	return summary.Err()
+}
+
+// queryCatalogRows executes a catalog statement and returns its rows keyed by
+// lower-cased column name.
+func (s *GqlSession) queryCatalogRows(ctx context.Context, statement string) ([]map[string]any, error) {
+	cursor, err := s.Execute(ctx, statement, nil)
+	if err != nil {
+		return nil, err
+	}
+	names, err := cursor.ColumnNames()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := cursor.CollectRows()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCursorStatus(cursor); err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		m := make(map[string]any, len(names))
+		for j, name := range names {
+			if j < len(row) {
+				m[strings.ToLower(name)] = row[j]
+			}
+		}
+		result[i] = m
+	}
+	return result, nil
+}
+
+func firstString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []any:
+		if len(t) > 0 {
+			s, _ := t[0].(string)
+			return s
+		}
+	}
+	return ""
+}
+
+func stringList(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package gwp
+
+import "testing"
+
+func TestCreateConstraintStatement(t *testing.T) {
+	tests := []struct {
+		def  ConstraintDefinition
+		want string
+	}{
+		{
+			ConstraintDefinition{Name: "person_email", Kind: ConstraintUnique, Label: "Person", Properties: []string{"email"}},
+			"CREATE CONSTRAINT person_email FOR (n:Person) REQUIRE n.email IS UNIQUE",
+		},
+		{
+			ConstraintDefinition{Kind: ConstraintExists, Label: "knows", Properties: []string{"since"}, OnEdge: true, IfNotExists: true},
+			"CREATE CONSTRAINT IF NOT EXISTS FOR ()-[r:knows]-() REQUIRE r.since IS NOT NULL",
+		},
+		{
+			ConstraintDefinition{Kind: ConstraintKey, Label: "Odd`Person", Properties: []string{"first", "order"}},
+			"CREATE CONSTRAINT FOR (n:`Odd``Person`) REQUIRE (n.first, n.`order`) IS KEY",
+		},
+	}
+	for _, tt := range tests {
+		got, err := createConstraintStatement(tt.def)
+		if err != nil {
+			t.Fatalf("createConstraintStatement: %v", err)
+		}
+		if got != tt.want {
+			t.Fatalf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCreateConstraintStatementInvalid(t *testing.T) {
+	if _, err := createConstraintStatement(ConstraintDefinition{Kind: ConstraintUnique, Properties: []string{"x"}}); err == nil {
+		t.Fatal("expected error for missing label")
+	}
+	if _, err := createConstraintStatement(ConstraintDefinition{Kind: ConstraintExists, Label: "A", Properties: []string{"x", "y"}}); err == nil {
+		t.Fatal("expected error for multi-property existence constraint")
+	}
+	if _, err := createConstraintStatement(ConstraintDefinition{Kind: "CHECK", Label: "A", Properties: []string{"x"}}); err == nil {
+		t.Fatal("expected error for unknown kind")
+	}
+}
@@ -1,11 +1,43 @@
 package gwp
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
 	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
 )
 
-// valueFromProto converts a protobuf Value to a native Go value.
+// valueFromProto converts a protobuf Value to a native Go value. Values of
+// unknown kinds become nil.
 func valueFromProto(v *pb.Value) any {
+	return decodeValue(v, false)
+}
+
+// decodeValue converts a protobuf Value to a native Go value, decoding
+// values of unknown kinds to nil. With lazy set, node and edge properties
+// are left undecoded until first accessed.
+func decodeValue(v *pb.Value, lazy bool) any {
+	d := valueDecoder{lazy: lazy, lenient: true}
+	return d.decode(v)
+}
+
+// valueDecoder converts protobuf Values to native Go values.
+type valueDecoder struct {
+	// lazy leaves node and edge properties undecoded until first accessed.
+	lazy bool
+	// lenient decodes values of unknown kinds to nil. Otherwise the first
+	// is reported in err.
+	lenient bool
+	err     *UnsupportedTypeError
+}
+
+func (d *valueDecoder) decode(v *pb.Value) any {
 	if v == nil {
 		return nil
 	}
@@ -19,15 +51,26 @@ func valueFromProto(v *pb.Value) any {
 		return k.IntegerValue
 	case *pb.Value_UnsignedIntegerValue:
 		return k.UnsignedIntegerValue
+	case *pb.Value_BigIntegerValue:
+		return bigIntegerFromProto(k.BigIntegerValue)
 	case *pb.Value_FloatValue:
 		return k.FloatValue
+	case *pb.Value_BigFloatValue:
+		f, ok := bigFloatFromProto(k.BigFloatValue)
+		if !ok {
+			d.unsupported(bigFloatKind(k.BigFloatValue))
+			return nil
+		}
+		return f
+	case *pb.Value_DecimalValue:
+		return &GqlDecimal{Unscaled: fromTwosComplement(k.DecimalValue.Unscaled), Scale: k.DecimalValue.Scale}
 	case *pb.Value_StringValue:
 		return k.StringValue
 	case *pb.Value_BytesValue:
 		return k.BytesValue
 	case *pb.Value_DateValue:
-		d := k.DateValue
-		return &GqlDate{Year: d.Year, Month: d.Month, Day: d.Day}
+		dv := k.DateValue
+		return &GqlDate{Year: dv.Year, Month: dv.Month, Day: dv.Day}
 	case *pb.Value_LocalTimeValue:
 		t := k.LocalTimeValue
 		return &GqlLocalTime{
@@ -73,87 +116,464 @@ func valueFromProto(v *pb.Value) any {
 	case *pb.Value_ListValue:
 		elems := make([]any, len(k.ListValue.Elements))
 		for i, e := range k.ListValue.Elements {
-			elems[i] = valueFromProto(e)
+			elems[i] = d.decode(e)
 		}
 		return elems
 	case *pb.Value_RecordValue:
 		fields := make([]GqlField, len(k.RecordValue.Fields))
 		for i, f := range k.RecordValue.Fields {
-			fields[i] = GqlField{Name: f.Name, Value: valueFromProto(f.Value)}
+			fields[i] = GqlField{Name: f.Name, Value: d.decode(f.Value)}
 		}
 		return &GqlRecord{Fields: fields}
 	case *pb.Value_NodeValue:
-		n := k.NodeValue
-		props := make(map[string]any, len(n.Properties))
-		for key, pv := range n.Properties {
-			props[key] = valueFromProto(pv)
-		}
-		return &GqlNode{ID: n.Id, Labels: n.Labels, Properties: props}
+		return d.node(k.NodeValue)
 	case *pb.Value_EdgeValue:
-		e := k.EdgeValue
-		props := make(map[string]any, len(e.Properties))
-		for key, pv := range e.Properties {
-			props[key] = valueFromProto(pv)
-		}
-		return &GqlEdge{
-			ID: e.Id, Labels: e.Labels,
-			SourceNodeID: e.SourceNodeId, TargetNodeID: e.TargetNodeId,
-			Undirected: e.Undirected, Properties: props,
-		}
+		return d.edge(k.EdgeValue)
 	case *pb.Value_PathValue:
 		p := k.PathValue
 		nodes := make([]*GqlNode, len(p.Nodes))
 		for i, n := range p.Nodes {
-			props := make(map[string]any, len(n.Properties))
-			for key, pv := range n.Properties {
-				props[key] = valueFromProto(pv)
-			}
-			nodes[i] = &GqlNode{ID: n.Id, Labels: n.Labels, Properties: props}
+			nodes[i] = d.node(n)
 		}
 		edges := make([]*GqlEdge, len(p.Edges))
 		for i, e := range p.Edges {
-			props := make(map[string]any, len(e.Properties))
-			for key, pv := range e.Properties {
-				props[key] = valueFromProto(pv)
-			}
-			edges[i] = &GqlEdge{
-				ID: e.Id, Labels: e.Labels,
-				SourceNodeID: e.SourceNodeId, TargetNodeID: e.TargetNodeId,
-				Undirected: e.Undirected, Properties: props,
-			}
+			edges[i] = d.edge(e)
 		}
 		return &GqlPath{Nodes: nodes, Edges: edges}
+	case nil:
+		// Unset, or set to a kind added in a later protocol version, which
+		// is kept as an unknown field.
+		if len(v.ProtoReflect().GetUnknown()) == 0 {
+			return nil
+		}
+		d.unsupported("unknown value kind")
+		return nil
 	default:
+		d.unsupported(string(v.ProtoReflect().WhichOneof(valueKindOneof).Name()))
 		return nil
 	}
 }
 
-// valueToProto converts a native Go value to a protobuf Value.
-func valueToProto(value any) *pb.Value {
+var valueKindOneof = (&pb.Value{}).ProtoReflect().Descriptor().Oneofs().ByName("kind")
+
+// unsupported records a value of an unsupported kind, unless d is lenient.
+func (d *valueDecoder) unsupported(kind string) {
+	if !d.lenient && d.err == nil {
+		d.err = &UnsupportedTypeError{Type: kind}
+	}
+}
+
+func (d *valueDecoder) node(n *pb.Node) *GqlNode {
+	if d.lazy {
+		return &GqlNode{ID: n.Id, Labels: n.Labels, rawProperties: n.Properties}
+	}
+	return &GqlNode{ID: n.Id, Labels: n.Labels, Properties: d.properties(n.Properties)}
+}
+
+func (d *valueDecoder) edge(e *pb.Edge) *GqlEdge {
+	edge := &GqlEdge{
+		ID: e.Id, Labels: e.Labels,
+		SourceNodeID: e.SourceNodeId, TargetNodeID: e.TargetNodeId,
+		Undirected: e.Undirected,
+	}
+	if d.lazy {
+		edge.rawProperties = e.Properties
+	} else {
+		edge.Properties = d.properties(e.Properties)
+	}
+	return edge
+}
+
+func (d *valueDecoder) properties(m map[string]*pb.Value) map[string]any {
+	props := make(map[string]any, len(m))
+	for key, pv := range m {
+		props[key] = d.decode(pv)
+	}
+	return props
+}
+
+// propertiesFromProto decodes lazily decoded properties, decoding values of
+// unknown kinds to nil.
+func propertiesFromProto(m map[string]*pb.Value) map[string]any {
+	d := valueDecoder{lenient: true}
+	return d.properties(m)
+}
+
+// valueToProto converts a native Go value to a protobuf Value. Values that
+// cannot be converted fail with an *UnsupportedTypeError; parameters are
+// converted with encodeParams, which also locates them.
+func valueToProto(value any) (*pb.Value, error) {
+	v, err := newParamEncoder(ParamLimits{}).encode(value, "", 0)
+	if err != nil {
+		var unsupported *UnsupportedTypeError
+		if errors.As(err, &unsupported) {
+			return nil, unsupported
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// encodeParams converts statement parameters to protobuf Values within
+// limits.
+func encodeParams(params map[string]any, limits ParamLimits) (map[string]*pb.Value, error) {
+	e := newParamEncoder(limits)
+	out := make(map[string]*pb.Value, len(params))
+	for k, v := range params {
+		pv, err := e.encode(v, "$"+k, 0)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = pv
+	}
+	return out, nil
+}
+
+func nullValue() *pb.Value {
+	return &pb.Value{Kind: &pb.Value_NullValue{NullValue: &pb.NullValue{}}}
+}
+
+// encode converts a native Go value at the given nesting depth to a
+// protobuf Value. path locates the value in errors.
+func (e *paramEncoder) encode(value any, path string, depth int) (*pb.Value, error) {
+	if err := e.count(path, 1, 0); err != nil {
+		return nil, err
+	}
 	if value == nil {
-		return &pb.Value{Kind: &pb.Value_NullValue{NullValue: &pb.NullValue{}}}
+		return nullValue(), nil
 	}
 
 	switch v := value.(type) {
 	case bool:
-		return &pb.Value{Kind: &pb.Value_BooleanValue{BooleanValue: v}}
+		return &pb.Value{Kind: &pb.Value_BooleanValue{BooleanValue: v}}, nil
 	case int64:
-		return &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: v}}
+		return integerValue(v), nil
 	case int:
-		return &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: int64(v)}}
+		return integerValue(int64(v)), nil
+	case int32:
+		return integerValue(int64(v)), nil
+	case int16:
+		return integerValue(int64(v)), nil
+	case int8:
+		return integerValue(int64(v)), nil
+	case uint64:
+		return unsignedValue(v), nil
+	case uint:
+		return unsignedValue(uint64(v)), nil
+	case uint32:
+		return integerValue(int64(v)), nil
+	case uint16:
+		return integerValue(int64(v)), nil
+	case uint8:
+		return integerValue(int64(v)), nil
 	case float64:
-		return &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: v}}
+		return &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: v}}, nil
+	case float32:
+		return &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: float64(v)}}, nil
+	case *big.Int:
+		if v == nil {
+			return nullValue(), nil
+		}
+		return bigIntegerToProto(v), nil
+	case *big.Float:
+		if v == nil {
+			return nullValue(), nil
+		}
+		return bigFloatToProto(v), nil
+	case GqlDecimal:
+		return decimalToProto(v), nil
+	case *GqlDecimal:
+		if v == nil {
+			return nullValue(), nil
+		}
+		return decimalToProto(*v), nil
+	case GqlPoint:
+		return pointToProto(&v), nil
+	case *GqlPoint:
+		if v == nil {
+			return nullValue(), nil
+		}
+		return pointToProto(v), nil
+	case GqlVector:
+		if err := e.count(path, len(v), 0); err != nil {
+			return nil, err
+		}
+		return vectorToProto(v), nil
+	case []float32:
+		if err := e.count(path, len(v), 0); err != nil {
+			return nil, err
+		}
+		return vectorToProto(v), nil
+	case []float64:
+		if err := e.count(path, len(v), 0); err != nil {
+			return nil, err
+		}
+		elems := make([]*pb.Value, len(v))
+		for i, f := range v {
+			elems[i] = &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: f}}
+		}
+		return &pb.Value{Kind: &pb.Value_ListValue{ListValue: &pb.GqlList{Elements: elems}}}, nil
 	case string:
-		return &pb.Value{Kind: &pb.Value_StringValue{StringValue: v}}
+		if err := e.count(path, 0, len(v)); err != nil {
+			return nil, err
+		}
+		return &pb.Value{Kind: &pb.Value_StringValue{StringValue: v}}, nil
 	case []byte:
-		return &pb.Value{Kind: &pb.Value_BytesValue{BytesValue: v}}
+		if err := e.count(path, 0, len(v)); err != nil {
+			return nil, err
+		}
+		return &pb.Value{Kind: &pb.Value_BytesValue{BytesValue: v}}, nil
+	case ElementID:
+		return &pb.Value{Kind: &pb.Value_BytesValue{BytesValue: v.Bytes()}}, nil
 	case []any:
+		if err := e.enter(reflect.ValueOf(v), path, depth); err != nil {
+			return nil, err
+		}
+		defer e.leave(reflect.ValueOf(v))
 		elems := make([]*pb.Value, len(v))
-		for i, e := range v {
-			elems[i] = valueToProto(e)
+		for i, elem := range v {
+			pv, err := e.encode(elem, path+"["+strconv.Itoa(i)+"]", depth+1)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = pv
 		}
-		return &pb.Value{Kind: &pb.Value_ListValue{ListValue: &pb.GqlList{Elements: elems}}}
-	default:
-		return &pb.Value{Kind: &pb.Value_NullValue{NullValue: &pb.NullValue{}}}
+		return &pb.Value{Kind: &pb.Value_ListValue{ListValue: &pb.GqlList{Elements: elems}}}, nil
+	case map[string]any:
+		if err := e.enter(reflect.ValueOf(v), path, depth); err != nil {
+			return nil, err
+		}
+		defer e.leave(reflect.ValueOf(v))
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fields := make([]*pb.Field, len(names))
+		for i, name := range names {
+			pv, err := e.encode(v[name], path+"."+name, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = &pb.Field{Name: name, Value: pv}
+		}
+		return &pb.Value{Kind: &pb.Value_RecordValue{RecordValue: &pb.Record{Fields: fields}}}, nil
+	case GqlRecord:
+		return e.encodeRecord(v.Fields, path, depth)
+	case *GqlRecord:
+		if v == nil {
+			return nullValue(), nil
+		}
+		return e.encodeRecord(v.Fields, path, depth)
+	case GqlDate:
+		return &pb.Value{Kind: &pb.Value_DateValue{DateValue: dateToProto(v)}}, nil
+	case GqlLocalTime:
+		return &pb.Value{Kind: &pb.Value_LocalTimeValue{LocalTimeValue: localTimeToProto(v)}}, nil
+	case GqlZonedTime:
+		return &pb.Value{Kind: &pb.Value_ZonedTimeValue{ZonedTimeValue: &pb.ZonedTime{
+			Time: localTimeToProto(v.Time), OffsetMinutes: v.OffsetMinutes,
+		}}}, nil
+	case GqlLocalDateTime:
+		return &pb.Value{Kind: &pb.Value_LocalDatetimeValue{LocalDatetimeValue: &pb.LocalDateTime{
+			Date: dateToProto(v.Date), Time: localTimeToProto(v.Time),
+		}}}, nil
+	case GqlZonedDateTime:
+		return &pb.Value{Kind: &pb.Value_ZonedDatetimeValue{ZonedDatetimeValue: &pb.ZonedDateTime{
+			Date: dateToProto(v.Date), Time: localTimeToProto(v.Time), OffsetMinutes: v.OffsetMinutes,
+		}}}, nil
+	case GqlDuration:
+		return &pb.Value{Kind: &pb.Value_DurationValue{DurationValue: &pb.Duration{
+			Months: v.Months, Nanoseconds: v.Nanoseconds,
+		}}}, nil
+	case *GqlNode:
+		if v == nil {
+			return nullValue(), nil
+		}
+		return &pb.Value{Kind: &pb.Value_NodeValue{NodeValue: nodeReference(v)}}, nil
+	case *GqlEdge:
+		if v == nil {
+			return nullValue(), nil
+		}
+		return &pb.Value{Kind: &pb.Value_EdgeValue{EdgeValue: edgeReference(v)}}, nil
+	case *GqlPath:
+		if v == nil {
+			return nullValue(), nil
+		}
+		return e.encodePath(v, path)
+	case time.Time:
+		return timeToProto(v), nil
+	case nullValuer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nullValue(), nil
+		}
+		return e.encode(v.nullValue(), path, depth)
+	}
+
+	if codec := lookupValueCodec(reflect.TypeOf(value)); codec != nil {
+		return e.encode(codec.ToValue(value), path, depth)
+	}
+	return e.encodeReflect(reflect.ValueOf(value), path, depth)
+}
+
+// encodeReflect converts pointers, slices, maps with string keys and named
+// types of the values encode handles.
+func (e *paramEncoder) encodeReflect(rv reflect.Value, path string, depth int) (*pb.Value, error) {
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nullValue(), nil
+		}
+		if err := e.enter(rv, path, depth); err != nil {
+			return nil, err
+		}
+		defer e.leave(rv)
+		return e.encode(rv.Elem().Interface(), path, depth)
+	case reflect.Bool:
+		return &pb.Value{Kind: &pb.Value_BooleanValue{BooleanValue: rv.Bool()}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return integerValue(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return unsignedValue(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: rv.Float()}}, nil
+	case reflect.String:
+		if err := e.count(path, 0, rv.Len()); err != nil {
+			return nil, err
+		}
+		return &pb.Value{Kind: &pb.Value_StringValue{StringValue: rv.String()}}, nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nullValue(), nil
+		}
+		if err := e.enter(rv, path, depth); err != nil {
+			return nil, err
+		}
+		defer e.leave(rv)
+		elems := make([]*pb.Value, rv.Len())
+		for i := range elems {
+			pv, err := e.encode(rv.Index(i).Interface(), path+"["+strconv.Itoa(i)+"]", depth+1)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = pv
+		}
+		return &pb.Value{Kind: &pb.Value_ListValue{ListValue: &pb.GqlList{Elements: elems}}}, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			return nullValue(), nil
+		}
+		if err := e.enter(rv, path, depth); err != nil {
+			return nil, err
+		}
+		defer e.leave(rv)
+		m := make(map[string]any, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			m[it.Key().String()] = it.Value().Interface()
+		}
+		return e.encode(m, path, depth)
+	}
+	return nil, &ParamError{
+		Path:    path,
+		Message: fmt.Sprintf("unsupported type %s", rv.Type()),
+		Err:     &UnsupportedTypeError{Path: path, Type: rv.Type().String()},
+	}
+}
+
+// encodeRecord converts record fields, keeping their order.
+func (e *paramEncoder) encodeRecord(fields []GqlField, path string, depth int) (*pb.Value, error) {
+	rv := reflect.ValueOf(fields)
+	if err := e.enter(rv, path, depth); err != nil {
+		return nil, err
+	}
+	defer e.leave(rv)
+	out := make([]*pb.Field, len(fields))
+	for i, f := range fields {
+		pv, err := e.encode(f.Value, path+"."+f.Name, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = &pb.Field{Name: f.Name, Value: pv}
+	}
+	return &pb.Value{Kind: &pb.Value_RecordValue{RecordValue: &pb.Record{Fields: out}}}, nil
+}
+
+// nodeReference converts a node to a reference: its ID and labels, without
+// its properties, which the server looks up by ID.
+func nodeReference(n *GqlNode) *pb.Node {
+	return &pb.Node{Id: n.ID, Labels: n.Labels}
+}
+
+// edgeReference converts an edge to a reference like nodeReference, keeping
+// its endpoints and direction.
+func edgeReference(e *GqlEdge) *pb.Edge {
+	return &pb.Edge{
+		Id: e.ID, Labels: e.Labels,
+		SourceNodeId: e.SourceNodeID, TargetNodeId: e.TargetNodeID,
+		Undirected: e.Undirected,
+	}
+}
+
+// encodePath converts a path to references to its nodes and edges.
+func (e *paramEncoder) encodePath(p *GqlPath, path string) (*pb.Value, error) {
+	if err := e.count(path, len(p.Nodes)+len(p.Edges), 0); err != nil {
+		return nil, err
+	}
+	out := &pb.Path{Nodes: make([]*pb.Node, len(p.Nodes)), Edges: make([]*pb.Edge, len(p.Edges))}
+	for i, n := range p.Nodes {
+		if n == nil {
+			return nil, &ParamError{Path: path + ".nodes[" + strconv.Itoa(i) + "]", Message: "nil node in path"}
+		}
+		out.Nodes[i] = nodeReference(n)
+	}
+	for i, edge := range p.Edges {
+		if edge == nil {
+			return nil, &ParamError{Path: path + ".edges[" + strconv.Itoa(i) + "]", Message: "nil edge in path"}
+		}
+		out.Edges[i] = edgeReference(edge)
 	}
+	return &pb.Value{Kind: &pb.Value_PathValue{PathValue: out}}, nil
+}
+
+func integerValue(n int64) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: n}}
+}
+
+// unsignedValue sends n as an integer if it fits in int64, and as an
+// unsigned integer otherwise.
+func unsignedValue(n uint64) *pb.Value {
+	if n > math.MaxInt64 {
+		return &pb.Value{Kind: &pb.Value_UnsignedIntegerValue{UnsignedIntegerValue: n}}
+	}
+	return integerValue(int64(n))
+}
+
+// timeToProto encodes t as a zoned date-time in the offset of its location.
+func timeToProto(t time.Time) *pb.Value {
+	_, offset := t.Zone()
+	return &pb.Value{Kind: &pb.Value_ZonedDatetimeValue{ZonedDatetimeValue: &pb.ZonedDateTime{
+		Date: &pb.Date{Year: int32(t.Year()), Month: uint32(t.Month()), Day: uint32(t.Day())},
+		Time: &pb.LocalTime{
+			Hour: uint32(t.Hour()), Minute: uint32(t.Minute()),
+			Second: uint32(t.Second()), Nanosecond: uint32(t.Nanosecond()),
+		},
+		OffsetMinutes: int32(offset / 60),
+	}}}
+}
+
+func dateToProto(d GqlDate) *pb.Date {
+	return &pb.Date{Year: d.Year, Month: d.Month, Day: d.Day}
+}
+
+func localTimeToProto(t GqlLocalTime) *pb.LocalTime {
+	return &pb.LocalTime{Hour: t.Hour, Minute: t.Minute, Second: t.Second, Nanosecond: t.Nanosecond}
+}
+
+func decimalToProto(d GqlDecimal) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_DecimalValue{DecimalValue: &pb.Decimal{
+		Unscaled: twosComplement(d.unscaled()),
+		Scale:    d.Scale,
+	}}}
 }
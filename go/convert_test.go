@@ -0,0 +1,260 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type celsius float32
+
+// encodeValue converts v with the default parameter limits.
+// mustValueToProto converts a value the test knows to be supported.
+func mustValueToProto(v any) *pb.Value {
+	pv, err := valueToProto(v)
+	if err != nil {
+		panic(err)
+	}
+	return pv
+}
+
+func encodeValue(v any, path string) (*pb.Value, error) {
+	return newParamEncoder(ParamLimits{}).encode(v, path, 0)
+}
+
+func TestEncodeNumericParams(t *testing.T) {
+	seven := int32(7)
+	for _, tc := range []struct {
+		in   any
+		want any
+	}{
+		{int8(-8), int64(-8)},
+		{int16(-16), int64(-16)},
+		{int32(-32), int64(-32)},
+		{uint8(8), int64(8)},
+		{uint16(16), int64(16)},
+		{uint32(math.MaxUint32), int64(math.MaxUint32)},
+		{uint(42), int64(42)},
+		{uint64(math.MaxInt64), int64(math.MaxInt64)},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{float32(1.5), 1.5},
+		{celsius(2.5), 2.5},
+		{&seven, int64(7)},
+		{(*int64)(nil), nil},
+	} {
+		v, err := encodeValue(tc.in, "$p")
+		if err != nil {
+			t.Errorf("encodeValue(%T) = %v", tc.in, err)
+			continue
+		}
+		if got := valueFromProto(v); got != tc.want {
+			t.Errorf("encodeValue(%T %v) decodes to %#v, want %#v", tc.in, tc.in, got, tc.want)
+		}
+	}
+
+	v, err := encodeValue([]uint16{1, 2}, "$p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list, ok := valueFromProto(v).([]any); !ok || len(list) != 2 || list[1] != int64(2) {
+		t.Fatalf("[]uint16 decodes to %#v", valueFromProto(v))
+	}
+}
+
+func TestEncodeUnsupportedParam(t *testing.T) {
+	_, err := encodeParams(map[string]any{"ids": []any{int64(1), complex(1, 2)}}, ParamLimits{})
+	var pe *ParamError
+	if !errors.As(err, &pe) || pe.Path != "$ids[1]" {
+		t.Fatalf("encodeParams = %v, want ParamError at $ids[1]", err)
+	}
+	var unsupported *UnsupportedTypeError
+	if _, err := valueToProto(complex(1, 2)); !errors.As(err, &unsupported) || unsupported.Type != "complex128" {
+		t.Fatalf("valueToProto(complex) = %v, want UnsupportedTypeError", err)
+	}
+
+	client := &fakeGqlClient{}
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+	if _, err := s.Execute(context.Background(), "RETURN $c", map[string]any{"c": make(chan int)}); !errors.As(err, &pe) {
+		t.Fatalf("Execute = %v, want ParamError", err)
+	}
+	if client.lastReq != nil {
+		t.Fatal("statement with an unsupported parameter was sent")
+	}
+}
+
+func TestEncodeMapParams(t *testing.T) {
+	v, err := encodeValue(map[string]any{
+		"name": "Alice",
+		"age":  30,
+		"tags": map[string]int{"b": 2, "a": 1},
+	}, "$props")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, ok := valueFromProto(v).(*GqlRecord)
+	if !ok || len(rec.Fields) != 3 {
+		t.Fatalf("map decodes to %#v", valueFromProto(v))
+	}
+	if rec.Fields[0].Name != "age" || rec.Fields[0].Value != int64(30) || rec.Fields[1].Value != "Alice" {
+		t.Fatalf("fields = %+v, want sorted by name", rec.Fields)
+	}
+	tags, ok := rec.Fields[2].Value.(*GqlRecord)
+	if !ok || len(tags.Fields) != 2 || tags.Fields[0].Name != "a" || tags.Fields[0].Value != int64(1) {
+		t.Fatalf("tags = %#v", rec.Fields[2].Value)
+	}
+
+	v, err = encodeValue(&GqlRecord{Fields: []GqlField{{"z", true}, {"a", nil}}}, "$r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := valueFromProto(v).(*GqlRecord); rec.Fields[0].Name != "z" || rec.Fields[1].Value != nil {
+		t.Fatalf("record decodes to %+v, want fields in order", rec.Fields)
+	}
+
+	_, err = encodeParams(map[string]any{"props": map[string]any{"bad": struct{}{}}}, ParamLimits{})
+	var pe *ParamError
+	if !errors.As(err, &pe) || pe.Path != "$props.bad" {
+		t.Fatalf("encodeParams = %v, want ParamError at $props.bad", err)
+	}
+	if _, err := encodeValue(map[int]string{1: "x"}, "$m"); !errors.As(err, &pe) {
+		t.Fatalf("map[int]string = %v, want ParamError", err)
+	}
+}
+
+func TestParamLimits(t *testing.T) {
+	var pe *ParamError
+	nested := any("leaf")
+	for range 5 {
+		nested = []any{nested}
+	}
+	if _, err := encodeParams(map[string]any{"p": nested}, ParamLimits{MaxDepth: 5}); err != nil {
+		t.Fatalf("depth 5 within MaxDepth 5: %v", err)
+	}
+	if _, err := encodeParams(map[string]any{"p": []any{nested}}, ParamLimits{MaxDepth: 5}); !errors.As(err, &pe) || pe.Path != "$p[0][0][0][0][0]" {
+		t.Fatalf("depth 6 = %v", err)
+	}
+
+	if _, err := encodeParams(map[string]any{"v": make([]float32, 10)}, ParamLimits{MaxValues: 10}); !errors.As(err, &pe) {
+		t.Fatalf("11 values with MaxValues 10 = %v", err)
+	}
+	if _, err := encodeParams(map[string]any{"a": "abc", "b": []byte("de")}, ParamLimits{MaxBytes: 4}); !errors.As(err, &pe) {
+		t.Fatalf("5 bytes with MaxBytes 4 = %v", err)
+	}
+
+	list := []any{int64(1), nil}
+	list[1] = list
+	if _, err := encodeParams(map[string]any{"l": list}, ParamLimits{}); !errors.As(err, &pe) || pe.Path != "$l[1]" {
+		t.Fatalf("self-referential list = %v", err)
+	}
+	m := map[string]any{}
+	m["self"] = m
+	if _, err := encodeParams(map[string]any{"m": m}, ParamLimits{}); !errors.As(err, &pe) || pe.Path != "$m.self" {
+		t.Fatalf("self-referential map = %v", err)
+	}
+	p := new(any)
+	*p = p
+	if _, err := encodeParams(map[string]any{"p": p}, ParamLimits{}); !errors.As(err, &pe) {
+		t.Fatalf("self-referential pointer = %v", err)
+	}
+
+	shared := []any{int64(1)}
+	if _, err := encodeParams(map[string]any{"a": []any{shared, shared}}, ParamLimits{}); err != nil {
+		t.Fatalf("shared sublist rejected: %v", err)
+	}
+}
+
+func TestStrictConversion(t *testing.T) {
+	malformed := &pb.Value{Kind: &pb.Value_BigFloatValue{BigFloatValue: &pb.BigFloat{Value: []byte{1, 2, 3}, Width: 96}}}
+	future := &pb.Value{}
+	future.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 99, protowire.VarintType), 1))
+
+	for _, v := range []*pb.Value{malformed, future} {
+		c := newTestCursor(headerFrame("n"), &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{
+			RowBatch: &pb.RowBatch{Rows: []*pb.Row{{Values: []*pb.Value{v}}}},
+		}}, summaryFrame(Success, 0))
+		c.strictConversion = true
+		_, err := c.NextRow()
+		var unsupported *UnsupportedTypeError
+		if !errors.As(err, &unsupported) || unsupported.Path != "n" {
+			t.Fatalf("NextRow = %v, want UnsupportedTypeError at column n", err)
+		}
+		if _, err := c.Summary(); !errors.As(err, &unsupported) {
+			t.Errorf("Summary() error = %v", err)
+		}
+
+		c = newTestCursor(headerFrame("n"), &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{
+			RowBatch: &pb.RowBatch{Rows: []*pb.Row{{Values: []*pb.Value{v}}}},
+		}}, summaryFrame(Success, 0))
+		if row, err := c.NextRow(); err != nil || row[0] != nil {
+			t.Fatalf("default NextRow = %v, %v; want [nil]", row, err)
+		}
+	}
+
+	if got := valueFromProto(&pb.Value{}); got != nil {
+		t.Errorf("unset value = %v, want nil", got)
+	}
+
+	_, err := encodeParams(map[string]any{"ch": make(chan int)}, ParamLimits{})
+	var unsupported *UnsupportedTypeError
+	if !errors.As(err, &unsupported) || unsupported.Path != "$ch" || unsupported.Type != "chan int" {
+		t.Fatalf("encodeParams = %v, want UnsupportedTypeError", err)
+	}
+}
+
+func TestEncodeGraphValues(t *testing.T) {
+	node := &GqlNode{ID: []byte{1}, Labels: []string{"Person"}, Properties: map[string]any{"name": "Alice"}}
+	other := &GqlNode{ID: []byte{2}, Labels: []string{"Person"}}
+	edge := &GqlEdge{ID: []byte{3}, Labels: []string{"KNOWS"}, SourceNodeID: node.ID, TargetNodeID: other.ID}
+	for _, tc := range []struct {
+		in   any
+		want any
+	}{
+		{GqlDate{Year: 2024, Month: 2, Day: 29}, &GqlDate{Year: 2024, Month: 2, Day: 29}},
+		{&GqlDate{Year: 1, Month: 1, Day: 1}, &GqlDate{Year: 1, Month: 1, Day: 1}},
+		{GqlLocalTime{Hour: 23, Nanosecond: 5}, &GqlLocalTime{Hour: 23, Nanosecond: 5}},
+		{GqlZonedTime{Time: GqlLocalTime{Hour: 1}, OffsetMinutes: -60}, &GqlZonedTime{Time: GqlLocalTime{Hour: 1}, OffsetMinutes: -60}},
+		{&GqlLocalDateTime{Date: GqlDate{Year: 2000, Month: 1, Day: 2}, Time: GqlLocalTime{Minute: 3}},
+			&GqlLocalDateTime{Date: GqlDate{Year: 2000, Month: 1, Day: 2}, Time: GqlLocalTime{Minute: 3}}},
+		{GqlZonedDateTime{Date: GqlDate{Year: 2000, Month: 1, Day: 2}, OffsetMinutes: 120},
+			&GqlZonedDateTime{Date: GqlDate{Year: 2000, Month: 1, Day: 2}, OffsetMinutes: 120}},
+		{&GqlDuration{Months: 14, Nanoseconds: -1}, &GqlDuration{Months: 14, Nanoseconds: -1}},
+		{(*GqlDuration)(nil), nil},
+		{(*GqlNode)(nil), nil},
+	} {
+		v, err := encodeValue(tc.in, "$v")
+		if err != nil {
+			t.Fatalf("%#v: %v", tc.in, err)
+		}
+		if got := valueFromProto(v); !Equal(got, tc.want) {
+			t.Errorf("%#v decodes to %#v, want %#v", tc.in, got, tc.want)
+		}
+	}
+
+	v, err := encodeValue(node, "$n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := valueFromProto(v).(*GqlNode)
+	if string(n.ID) != "\x01" || !n.HasLabel("Person") || len(n.Properties) != 0 {
+		t.Errorf("node reference = %+v, want ID and labels only", n)
+	}
+
+	v, err = encodeValue(&GqlPath{Nodes: []*GqlNode{node, other}, Edges: []*GqlEdge{edge}}, "$p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := valueFromProto(v).(*GqlPath)
+	if len(p.Nodes) != 2 || len(p.Edges) != 1 || string(p.Edges[0].TargetNodeID) != "\x02" {
+		t.Errorf("path reference = %+v", p)
+	}
+
+	_, err = encodeValue(&GqlPath{Nodes: []*GqlNode{node, nil}}, "$p")
+	var pe *ParamError
+	if !errors.As(err, &pe) || pe.Path != "$p.nodes[1]" {
+		t.Errorf("path with nil node = %v, want ParamError at $p.nodes[1]", err)
+	}
+}
@@ -0,0 +1,95 @@
+package gwp
+
+import "time"
+
+// Summary counter keys reported by the server.
+const (
+	CounterNodesCreated       = "nodes_created"
+	CounterNodesDeleted       = "nodes_deleted"
+	CounterEdgesCreated       = "edges_created"
+	CounterEdgesDeleted       = "edges_deleted"
+	CounterPropertiesSet      = "properties_set"
+	CounterLabelsAdded        = "labels_added"
+	CounterLabelsRemoved      = "labels_removed"
+	CounterIndexesAdded       = "indexes_added"
+	CounterIndexesRemoved     = "indexes_removed"
+	CounterConstraintsAdded   = "constraints_added"
+	CounterConstraintsRemoved = "constraints_removed"
+	CounterCompileTimeNanos   = "compile_time_ns"
+	CounterExecuteTimeNanos   = "execution_time_ns"
+)
+
+// Counters holds the update statistics of an executed statement.
+type Counters struct {
+	NodesCreated       int64
+	NodesDeleted       int64
+	EdgesCreated       int64
+	EdgesDeleted       int64
+	PropertiesSet      int64
+	LabelsAdded        int64
+	LabelsRemoved      int64
+	IndexesAdded       int64
+	IndexesRemoved     int64
+	ConstraintsAdded   int64
+	ConstraintsRemoved int64
+}
+
+// ContainsUpdates reports whether the statement changed any data.
+func (c Counters) ContainsUpdates() bool {
+	return c.NodesCreated != 0 || c.NodesDeleted != 0 ||
+		c.EdgesCreated != 0 || c.EdgesDeleted != 0 ||
+		c.PropertiesSet != 0 || c.LabelsAdded != 0 || c.LabelsRemoved != 0
+}
+
+// ContainsSchemaUpdates reports whether the statement changed indexes or
+// constraints.
+func (c Counters) ContainsSchemaUpdates() bool {
+	return c.IndexesAdded != 0 || c.IndexesRemoved != 0 ||
+		c.ConstraintsAdded != 0 || c.ConstraintsRemoved != 0
+}
+
+// Timings holds server-side durations of an executed statement. A zero value
+// means the server did not report it.
+type Timings struct {
+	Compile   time.Duration
+	Execution time.Duration
+}
+
+// Total returns the sum of compile and execution time.
+func (t Timings) Total() time.Duration {
+	return t.Compile + t.Execution
+}
+
+// Counters returns the typed update counters from the summary.
+func (s *ResultSummary) Counters() Counters {
+	m := s.proto.Counters
+	return Counters{
+		NodesCreated:       m[CounterNodesCreated],
+		NodesDeleted:       m[CounterNodesDeleted],
+		EdgesCreated:       m[CounterEdgesCreated],
+		EdgesDeleted:       m[CounterEdgesDeleted],
+		PropertiesSet:      m[CounterPropertiesSet],
+		LabelsAdded:        m[CounterLabelsAdded],
+		LabelsRemoved:      m[CounterLabelsRemoved],
+		IndexesAdded:       m[CounterIndexesAdded],
+		IndexesRemoved:     m[CounterIndexesRemoved],
+		ConstraintsAdded:   m[CounterConstraintsAdded],
+		ConstraintsRemoved: m[CounterConstraintsRemoved],
+	}
+}
+
+// Counter returns the raw value of a named summary counter and whether the
+// server reported it.
+func (s *ResultSummary) Counter(name string) (int64, bool) {
+	v, ok := s.proto.Counters[name]
+	return v, ok
+}
+
+// Timings returns the server-side compile and execution durations.
+func (s *ResultSummary) Timings() Timings {
+	m := s.proto.Counters
+	return Timings{
+		Compile:   time.Duration(m[CounterCompileTimeNanos]),
+		Execution: time.Duration(m[CounterExecuteTimeNanos]),
+	}
+}
@@ -0,0 +1,31 @@
+package gwp
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func TestSummaryCounters(t *testing.T) {
+	s := &ResultSummary{proto: &pb.ResultSummary{Counters: map[string]int64{
+		CounterNodesCreated:     2,
+		CounterPropertiesSet:    4,
+		CounterExecuteTimeNanos: int64(3 * time.Millisecond),
+		"custom":                7,
+	}}}
+
+	c := s.Counters()
+	if c.NodesCreated != 2 || c.PropertiesSet != 4 {
+		t.Fatalf("unexpected counters: %+v", c)
+	}
+	if !c.ContainsUpdates() || c.ContainsSchemaUpdates() {
+		t.Fatal("expected data updates only")
+	}
+	if v, ok := s.Counter("custom"); !ok || v != 7 {
+		t.Fatalf("Counter(custom) = %d, %v", v, ok)
+	}
+	if tm := s.Timings(); tm.Execution != 3*time.Millisecond || tm.Compile != 0 {
+		t.Fatalf("unexpected timings: %+v", tm)
+	}
+}
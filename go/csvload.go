@@ -0,0 +1,371 @@
+package gwp
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CSVType is the type a CSV column is converted to before insertion.
+type CSVType int
+
+const (
+	// CSVInfer converts "true" and "false" to booleans and plain decimal
+	// literals, such as 42, -7 or 3.25, to integers or floats, and keeps
+	// every other field as a string. Fields with leading zeros, exponents,
+	// a sign of "+", or spellings of infinity or NaN stay strings, so codes
+	// such as "007" or "1e5" and names such as "Nan" are kept as written.
+	CSVInfer CSVType = iota
+	CSVString
+	CSVInt
+	CSVFloat
+	CSVBool
+)
+
+// CSVColumn maps a CSV column to a property.
+type CSVColumn struct {
+	// Property is the property key. Empty means the column's header.
+	Property string
+	Type     CSVType
+	// Ignore skips the column.
+	Ignore bool
+}
+
+// CSVEndpoint locates an edge's source or target node: the node with Label
+// whose Key property equals the value of Column.
+type CSVEndpoint struct {
+	Column string
+	Label  string
+	Key    string
+	// Type converts the column's values like CSVColumn.Type.
+	Type CSVType
+}
+
+// CSVConfig controls LoadCSVNodes and LoadCSVEdges. The first CSV record is
+// the header. Columns not listed in Columns become properties named after
+// their header with inferred types; empty fields are left unset.
+type CSVConfig struct {
+	Columns map[string]CSVColumn
+	// From and To locate the endpoints of each edge for LoadCSVEdges.
+	// Their columns are not stored as properties.
+	From, To CSVEndpoint
+	// BatchSize is the number of rows inserted per statement. Zero means
+	// 1000.
+	BatchSize int
+	// MaxErrors is the number of lines with conversion errors skipped
+	// before loading stops. Zero stops at the first.
+	MaxErrors int
+	// Comma is the field delimiter. Zero means a comma.
+	Comma rune
+}
+
+// CSVLineError is a CSV line that could not be converted.
+type CSVLineError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *CSVLineError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: column %q: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *CSVLineError) Unwrap() error {
+	return e.Err
+}
+
+// CSVLoadResult reports the outcome of LoadCSVNodes or LoadCSVEdges.
+type CSVLoadResult struct {
+	// Rows is the number of lines inserted.
+	Rows int64
+	// RowsAffected sums the rows affected reported for each batch.
+	RowsAffected int64
+	// Errors lists the lines skipped, at most MaxErrors.
+	Errors []*CSVLineError
+}
+
+// LoadCSVNodes inserts a node with label for each record of r, converting
+// columns to properties as config describes. Records are inserted in
+// batches of parameterized statements. Lines that fail to convert are
+// skipped and reported in the result until more than config.MaxErrors have
+// failed; then loading stops with that line's error. A failing batch stops
+// loading with an error naming its lines.
+func (s *GqlSession) LoadCSVNodes(ctx context.Context, r io.Reader, label string, config CSVConfig) (*CSVLoadResult, error) {
+	if err := validateIdentifier(label); err != nil {
+		return nil, err
+	}
+	l, err := newCSVLoader(r, config, false)
+	if err != nil {
+		return nil, err
+	}
+	statement := "FOR row IN $rows INSERT (:" + EscapeIdentifier(label) + l.propertyMap() + ")"
+	return l.load(ctx, s, statement)
+}
+
+// LoadCSVEdges inserts an edge of edgeType for each record of r between the
+// nodes config.From and config.To locate, converting the other columns to
+// properties. Records whose endpoints are not found insert nothing. It
+// reports errors like LoadCSVNodes.
+func (s *GqlSession) LoadCSVEdges(ctx context.Context, r io.Reader, edgeType string, config CSVConfig) (*CSVLoadResult, error) {
+	for _, name := range []string{edgeType, config.From.Column, config.From.Label, config.From.Key,
+		config.To.Column, config.To.Label, config.To.Key} {
+		if err := validateIdentifier(name); err != nil {
+			return nil, &GqlError{Message: "LoadCSVEdges requires an edge type and the column, label and key of both endpoints"}
+		}
+	}
+	l, err := newCSVLoader(r, config, true)
+	if err != nil {
+		return nil, err
+	}
+	statement := fmt.Sprintf("FOR row IN $rows MATCH (a:%s {%s: row.from}), (b:%s {%s: row.to}) INSERT (a)-[:%s%s]->(b)",
+		EscapeIdentifier(config.From.Label), EscapeIdentifier(config.From.Key),
+		EscapeIdentifier(config.To.Label), EscapeIdentifier(config.To.Key),
+		EscapeIdentifier(edgeType), l.propertyMap())
+	return l.load(ctx, s, statement)
+}
+
+// csvLoader converts CSV records to parameter rows.
+type csvLoader struct {
+	reader *csv.Reader
+	config CSVConfig
+	edges  bool
+	// props are the property columns in header order.
+	props    []csvProperty
+	from, to int
+}
+
+type csvProperty struct {
+	index  int
+	header string
+	name   string
+	typ    CSVType
+}
+
+func newCSVLoader(r io.Reader, config CSVConfig, edges bool) (*csvLoader, error) {
+	reader := csv.NewReader(r)
+	if config.Comma != 0 {
+		reader.Comma = config.Comma
+	}
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &GqlError{Message: fmt.Sprintf("read CSV header: %v", err)}
+	}
+
+	l := &csvLoader{reader: reader, config: config, edges: edges, from: -1, to: -1}
+	seen := make(map[string]bool, len(header))
+	for i, h := range header {
+		if edges && h == config.From.Column {
+			l.from = i
+			continue
+		}
+		if edges && h == config.To.Column {
+			l.to = i
+			continue
+		}
+		col := config.Columns[h]
+		if col.Ignore {
+			continue
+		}
+		name := col.Property
+		if name == "" {
+			name = h
+		}
+		if err := validateIdentifier(name); err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			return nil, &GqlError{Message: fmt.Sprintf("CSV columns map to property %q more than once", name)}
+		}
+		seen[name] = true
+		l.props = append(l.props, csvProperty{index: i, header: h, name: name, typ: col.Type})
+	}
+	if edges && (l.from < 0 || l.to < 0) {
+		return nil, &GqlError{Message: fmt.Sprintf("CSV header lacks endpoint columns %q and %q", config.From.Column, config.To.Column)}
+	}
+	return l, nil
+}
+
+// propertyMap returns the statement's property specification, reading each
+// property from the row's props record.
+func (l *csvLoader) propertyMap() string {
+	if len(l.props) == 0 {
+		return ""
+	}
+	names := make([]string, len(l.props))
+	for i, p := range l.props {
+		names[i] = p.name
+	}
+	sort.Strings(names)
+	fields := make([]string, len(names))
+	for i, name := range names {
+		id := EscapeIdentifier(name)
+		fields[i] = id + ": row.props." + id
+	}
+	return " {" + strings.Join(fields, ", ") + "}"
+}
+
+func (l *csvLoader) load(ctx context.Context, s *GqlSession, statement string) (*CSVLoadResult, error) {
+	size := l.config.BatchSize
+	if size <= 0 {
+		size = 1000
+	}
+	result := &CSVLoadResult{}
+	batch := make([]any, 0, size)
+	firstLine, lastLine := 0, 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		affected, err := s.executeStreamBatch(ctx, statement, map[string]any{"rows": batch}, nil)
+		if err != nil {
+			return fmt.Errorf("lines %d-%d: %w", firstLine, lastLine, err)
+		}
+		result.Rows += int64(len(batch))
+		result.RowsAffected += affected
+		batch = make([]any, 0, size)
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		record, err := l.reader.Read()
+		if err == io.EOF {
+			return result, flush()
+		}
+		var (
+			row  map[string]any
+			line int
+		)
+		if pe, ok := err.(*csv.ParseError); ok {
+			err = &CSVLineError{Line: pe.StartLine, Err: pe.Err}
+		} else if err == nil {
+			line, _ = l.reader.FieldPos(0)
+			row, err = l.row(record, line)
+		}
+		if err != nil {
+			lineErr, ok := err.(*CSVLineError)
+			if !ok || len(result.Errors) >= l.config.MaxErrors {
+				return result, err
+			}
+			result.Errors = append(result.Errors, lineErr)
+			continue
+		}
+		if len(batch) == 0 {
+			firstLine = line
+		}
+		lastLine = line
+		batch = append(batch, row)
+		if len(batch) == size {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+}
+
+// row converts a record to a parameter row.
+func (l *csvLoader) row(record []string, line int) (map[string]any, error) {
+	props := make(map[string]any, len(l.props))
+	for _, p := range l.props {
+		field := record[p.index]
+		if field == "" {
+			continue
+		}
+		v, err := csvConvert(field, p.typ)
+		if err != nil {
+			return nil, &CSVLineError{Line: line, Column: p.header, Err: err}
+		}
+		props[p.name] = v
+	}
+	row := map[string]any{"props": props}
+	if l.edges {
+		for _, end := range []struct {
+			key      string
+			index    int
+			endpoint CSVEndpoint
+		}{{"from", l.from, l.config.From}, {"to", l.to, l.config.To}} {
+			v, err := csvConvert(record[end.index], end.endpoint.Type)
+			if err == nil && record[end.index] == "" {
+				err = fmt.Errorf("empty endpoint")
+			}
+			if err != nil {
+				return nil, &CSVLineError{Line: line, Column: end.endpoint.Column, Err: err}
+			}
+			row[end.key] = v
+		}
+	}
+	return row, nil
+}
+
+// csvConvert converts a CSV field to typ.
+func csvConvert(field string, typ CSVType) (any, error) {
+	switch typ {
+	case CSVString:
+		return field, nil
+	case CSVInt:
+		return strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+	case CSVFloat:
+		return strconv.ParseFloat(strings.TrimSpace(field), 64)
+	case CSVBool:
+		return strconv.ParseBool(strings.TrimSpace(field))
+	default:
+		switch field {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		isInt, ok := decimalLiteral(field)
+		if !ok {
+			return field, nil
+		}
+		if isInt {
+			if n, err := strconv.ParseInt(field, 10, 64); err == nil {
+				return n, nil
+			}
+			return field, nil
+		}
+		if f, err := strconv.ParseFloat(field, 64); err == nil {
+			return f, nil
+		}
+		return field, nil
+	}
+}
+
+// decimalLiteral reports whether s is a plain decimal number: an optional
+// minus sign, an integer part without leading zeros, and an optional
+// fraction of at least one digit. isInt reports whether it has no fraction.
+func decimalLiteral(s string) (isInt, ok bool) {
+	s = strings.TrimPrefix(s, "-")
+	digits := func(s string) int {
+		n := 0
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		return n
+	}
+	n := digits(s)
+	if n == 0 || (n > 1 && s[0] == '0') {
+		return false, false
+	}
+	s = s[n:]
+	if s == "" {
+		return true, true
+	}
+	if s[0] != '.' {
+		return false, false
+	}
+	s = s[1:]
+	n = digits(s)
+	return false, n > 0 && n == len(s)
+}
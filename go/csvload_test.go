@@ -0,0 +1,126 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadCSVNodes(t *testing.T) {
+	client := &batchClient{}
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+	data := "name,age,zip,note\n" +
+		"Alice,30,01234,\n" +
+		"Bob,old,02134,x\n" +
+		"Carol,41,10001,y\n"
+
+	result, err := s.LoadCSVNodes(context.Background(), strings.NewReader(data), "Person", CSVConfig{
+		Columns: map[string]CSVColumn{
+			"age":  {Type: CSVInt},
+			"zip":  {Property: "postcode", Type: CSVString},
+			"note": {Ignore: true},
+		},
+		MaxErrors: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rows != 2 || len(result.Errors) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	if e := result.Errors[0]; e.Line != 3 || e.Column != "age" {
+		t.Fatalf("line error = %v", e)
+	}
+
+	req := client.reqs[0]
+	if want := "FOR row IN $rows INSERT (:Person {age: row.props.age, name: row.props.name, postcode: row.props.postcode})"; req.Statement != want {
+		t.Fatalf("statement = %q", req.Statement)
+	}
+	rows := valueFromProto(req.Parameters["rows"]).([]any)
+	alice := rows[0].(*GqlRecord).Get("props").(*GqlRecord)
+	if alice.Get("age") != int64(30) || alice.Get("postcode") != "01234" || len(alice.Fields) != 3 {
+		t.Fatalf("first row = %v", alice)
+	}
+}
+
+func TestLoadCSVNodesStopsAfterMaxErrors(t *testing.T) {
+	s := &GqlSession{sessionID: "s1", gqlClient: &batchClient{}}
+	data := "name,age\nAlice,x\n\"Bob,30\n"
+	_, err := s.LoadCSVNodes(context.Background(), strings.NewReader(data), "Person", CSVConfig{
+		Columns: map[string]CSVColumn{"age": {Type: CSVInt}},
+	})
+	var lineErr *CSVLineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 {
+		t.Fatalf("err = %v, want an error on line 2", err)
+	}
+}
+
+func TestLoadCSVEdges(t *testing.T) {
+	client := &batchClient{}
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+	data := "src,dst,since\n1,2,2020\n2,3,2021\n3,,2022\n"
+
+	result, err := s.LoadCSVEdges(context.Background(), strings.NewReader(data), "KNOWS", CSVConfig{
+		From:      CSVEndpoint{Column: "src", Label: "Person", Key: "id", Type: CSVInt},
+		To:        CSVEndpoint{Column: "dst", Label: "Person", Key: "id", Type: CSVInt},
+		BatchSize: 1,
+		MaxErrors: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rows != 2 || len(client.reqs) != 2 || len(result.Errors) != 1 || result.Errors[0].Column != "dst" {
+		t.Fatalf("result = %+v", result)
+	}
+	want := "FOR row IN $rows MATCH (a:Person {id: row.from}), (b:Person {id: row.to}) INSERT (a)-[:KNOWS {since: row.props.since}]->(b)"
+	if client.reqs[0].Statement != want {
+		t.Fatalf("statement = %q", client.reqs[0].Statement)
+	}
+
+	if _, err := s.LoadCSVEdges(context.Background(), strings.NewReader(data), "KNOWS", CSVConfig{}); err == nil {
+		t.Fatal("expected an error without endpoints")
+	}
+}
+
+func TestLoadCSVBatchError(t *testing.T) {
+	s := &GqlSession{sessionID: "s1", gqlClient: &batchClient{failAt: 2}}
+	data := "name\na\nb\nc\nd\n"
+	result, err := s.LoadCSVNodes(context.Background(), strings.NewReader(data), "N", CSVConfig{BatchSize: 2})
+	if err == nil || !strings.Contains(err.Error(), "lines 4-5") || result.Rows != 2 {
+		t.Fatalf("LoadCSVNodes = %+v, %v", result, err)
+	}
+}
+
+func TestCSVInfer(t *testing.T) {
+	tests := []struct {
+		field string
+		want  any
+	}{
+		{"42", int64(42)},
+		{"-7", int64(-7)},
+		{"0", int64(0)},
+		{"3.25", 3.25},
+		{"-0.5", -0.5},
+		{"true", true},
+		{"007", "007"},
+		{"0.", "0."},
+		{".5", ".5"},
+		{"+1", "+1"},
+		{"1e5", "1e5"},
+		{"Nan", "Nan"},
+		{"NaN", "NaN"},
+		{"inf", "inf"},
+		{"-Infinity", "-Infinity"},
+		{"0x1F", "0x1F"},
+		{"1_000", "1_000"},
+		{"99999999999999999999", "99999999999999999999"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := csvConvert(tt.field, CSVInfer)
+		if err != nil || got != tt.want {
+			t.Errorf("csvConvert(%q) = %#v, %v; want %#v", tt.field, got, err, tt.want)
+		}
+	}
+}
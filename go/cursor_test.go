@@ -0,0 +1,166 @@
+package gwp
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// fakeStream replays a fixed sequence of frames, then returns err (io.EOF
+// by default).
+type fakeStream struct {
+	frames []*pb.ExecuteResponse
+	err    error
+}
+
+func (s *fakeStream) Recv() (*pb.ExecuteResponse, error) {
+	if len(s.frames) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	f := s.frames[0]
+	s.frames = s.frames[1:]
+	return f, nil
+}
+
+func headerFrame(columns ...string) *pb.ExecuteResponse {
+	cols := make([]*pb.ColumnDescriptor, len(columns))
+	for i, c := range columns {
+		cols[i] = &pb.ColumnDescriptor{Name: c}
+	}
+	return &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_Header{Header: &pb.ResultHeader{Columns: cols}}}
+}
+
+func batchFrame(rows ...[]any) *pb.ExecuteResponse {
+	batch := &pb.RowBatch{}
+	for _, row := range rows {
+		values := make([]*pb.Value, len(row))
+		for i, v := range row {
+			values[i] = mustValueToProto(v)
+		}
+		batch.Rows = append(batch.Rows, &pb.Row{Values: values})
+	}
+	return &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: batch}}
+}
+
+func summaryFrame(code string, rowsAffected int64) *pb.ExecuteResponse {
+	return &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_Summary{Summary: &pb.ResultSummary{
+		Status:       &pb.GqlStatus{Code: code},
+		RowsAffected: rowsAffected,
+	}}}
+}
+
+func newTestCursor(frames ...*pb.ExecuteResponse) *ResultCursor {
+	return newResultCursor(&fakeStream{frames: frames})
+}
+
+func TestCursorRows(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("name", "age"),
+		batchFrame([]any{"Alice", int64(30)}),
+		batchFrame([]any{"Bob", int64(25)}),
+		summaryFrame(Success, 2),
+	)
+	rows, err := c.CollectRows()
+	if err != nil {
+		t.Fatalf("CollectRows: %v", err)
+	}
+	if len(rows) != 2 || rows[1][0] != "Bob" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+	n, err := c.RowsAffected()
+	if err != nil || n != 2 {
+		t.Fatalf("RowsAffected = %d, %v", n, err)
+	}
+}
+
+func TestCursorSingle(t *testing.T) {
+	row, err := newTestCursor(
+		headerFrame("name", "age"),
+		batchFrame([]any{"Alice", int64(30)}),
+		summaryFrame(Success, 0),
+	).Single()
+	if err != nil || row[0] != "Alice" {
+		t.Fatalf("Single = %v, %v", row, err)
+	}
+
+	if _, err := newTestCursor(headerFrame("n"), summaryFrame(Success, 0)).Single(); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("Single on empty result = %v", err)
+	}
+	two := newTestCursor(headerFrame("n"), batchFrame([]any{int64(1)}, []any{int64(2)}), summaryFrame(Success, 0))
+	if _, err := two.Single(); err == nil {
+		t.Fatal("expected error for two rows")
+	}
+	failed := newTestCursor(headerFrame("n"), batchFrame([]any{int64(1)}), summaryFrame("42001", 0))
+	if _, err := failed.Single(); err == nil {
+		t.Fatal("expected status error")
+	}
+}
+
+func TestCursorScalars(t *testing.T) {
+	n, err := newTestCursor(headerFrame("count"), batchFrame([]any{int64(42)}), summaryFrame(Success, 0)).ScalarInt()
+	if err != nil || n != 42 {
+		t.Fatalf("ScalarInt = %d, %v", n, err)
+	}
+	f, err := newTestCursor(headerFrame("avg"), batchFrame([]any{int64(3)}), summaryFrame(Success, 0)).ScalarFloat()
+	if err != nil || f != 3 {
+		t.Fatalf("ScalarFloat = %v, %v", f, err)
+	}
+	s, err := newTestCursor(headerFrame("name"), batchFrame([]any{"Alice"}), summaryFrame(Success, 0)).ScalarString()
+	if err != nil || s != "Alice" {
+		t.Fatalf("ScalarString = %q, %v", s, err)
+	}
+	b, err := newTestCursor(headerFrame("ok"), batchFrame([]any{true}), summaryFrame(Success, 0)).ScalarBool()
+	if err != nil || !b {
+		t.Fatalf("ScalarBool = %v, %v", b, err)
+	}
+	if _, err := newTestCursor(headerFrame("name"), batchFrame([]any{"x"}), summaryFrame(Success, 0)).ScalarInt(); err == nil {
+		t.Fatal("expected type error")
+	}
+}
+
+func TestCursorIncompleteResult(t *testing.T) {
+	cause := errors.New("connection reset")
+	c := newResultCursor(&fakeStream{
+		frames: []*pb.ExecuteResponse{
+			headerFrame("n"),
+			batchFrame([]any{int64(1)}, []any{int64(2)}),
+		},
+		err: cause,
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := c.NextRow(); err != nil {
+			t.Fatalf("row %d: %v", i, err)
+		}
+	}
+	_, err := c.NextRow()
+	var incomplete *IncompleteResultError
+	if !errors.As(err, &incomplete) {
+		t.Fatalf("got %v, want IncompleteResultError", err)
+	}
+	if incomplete.Rows != 2 || !errors.Is(err, cause) {
+		t.Errorf("got rows %d, cause %v", incomplete.Rows, incomplete.Cause)
+	}
+	summary, err := c.Summary()
+	if summary != nil || !errors.As(err, &incomplete) {
+		t.Errorf("Summary() = %v, %v; want the incomplete result error", summary, err)
+	}
+}
+
+func TestCursorTruncatedStream(t *testing.T) {
+	c := newTestCursor(headerFrame("n"), batchFrame([]any{int64(1)}))
+	rows, err := c.CollectRows()
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("got %v, want io.ErrUnexpectedEOF", err)
+	}
+	if len(rows) != 1 {
+		t.Errorf("got %d rows", len(rows))
+	}
+	if _, err := c.Summary(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Summary() error = %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package gwp
+
+import (
+	"math/big"
+	"strings"
+)
+
+// GqlDecimal is an exact decimal number: Unscaled × 10^-Scale.
+//
+// Use it for parameters that must not lose precision through float64. Types
+// from third-party decimal packages can be sent by registering a ValueCodec
+// that converts them with ParseDecimal.
+type GqlDecimal struct {
+	Unscaled *big.Int
+	Scale    int32
+}
+
+// NewDecimal returns the decimal unscaled × 10^-scale.
+func NewDecimal(unscaled int64, scale int32) GqlDecimal {
+	return GqlDecimal{Unscaled: big.NewInt(unscaled), Scale: scale}
+}
+
+// ParseDecimal parses a decimal literal such as "-12.50". The scale is the
+// number of digits after the decimal point.
+func ParseDecimal(s string) (GqlDecimal, error) {
+	digits := s
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+	intPart, fracPart, hasPoint := strings.Cut(digits, ".")
+	if intPart == "" && fracPart == "" || hasPoint && strings.Contains(fracPart, ".") {
+		return GqlDecimal{}, &GqlError{Message: "invalid decimal: " + s}
+	}
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return GqlDecimal{}, &GqlError{Message: "invalid decimal: " + s}
+		}
+	}
+	unscaled, _ := new(big.Int).SetString(intPart+fracPart, 10)
+	if strings.HasPrefix(s, "-") {
+		unscaled.Neg(unscaled)
+	}
+	return GqlDecimal{Unscaled: unscaled, Scale: int32(len(fracPart))}, nil
+}
+
+func (d GqlDecimal) unscaled() *big.Int {
+	if d.Unscaled == nil {
+		return new(big.Int)
+	}
+	return d.Unscaled
+}
+
+// String formats the decimal with exactly Scale digits after the point.
+func (d GqlDecimal) String() string {
+	u := d.unscaled()
+	if d.Scale <= 0 {
+		v := new(big.Int).Mul(u, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-d.Scale)), nil))
+		return v.String()
+	}
+	digits := new(big.Int).Abs(u).String()
+	scale := int(d.Scale)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	s := digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	if u.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// Rat returns the decimal as an exact rational number.
+func (d GqlDecimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.unscaled())
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(d.Scale))), nil)
+	if d.Scale >= 0 {
+		return r.Quo(r, new(big.Rat).SetInt(pow))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(pow))
+}
+
+// Float64 returns the nearest float64 value.
+func (d GqlDecimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Cmp compares d and o numerically, returning -1, 0 or +1.
+func (d GqlDecimal) Cmp(o GqlDecimal) int {
+	return d.Rat().Cmp(o.Rat())
+}
+
+func abs32(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// twosComplement encodes x as big-endian two's complement in the fewest bytes.
+func twosComplement(x *big.Int) []byte {
+	if x.Sign() >= 0 {
+		b := x.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	// For negative x, the length is that of the magnitude of x+1 plus a sign bit.
+	n := new(big.Int).Add(x, big.NewInt(1))
+	size := n.Neg(n).BitLen()/8 + 1
+	v := new(big.Int).Lsh(big.NewInt(1), uint(8*size))
+	v.Add(v, x)
+	b := v.Bytes()
+	for len(b) < size {
+		b = append([]byte{0xff}, b...)
+	}
+	return b
+}
+
+// fromTwosComplement decodes big-endian two's complement bytes.
+func fromTwosComplement(b []byte) *big.Int {
+	x := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return x
+}
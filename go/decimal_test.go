@@ -0,0 +1,89 @@
+package gwp
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in       string
+		unscaled int64
+		scale    int32
+		out      string
+	}{
+		{"12.50", 1250, 2, "12.50"},
+		{"-0.05", -5, 2, "-0.05"},
+		{"42", 42, 0, "42"},
+		{"+.5", 5, 1, "0.5"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Fatalf("ParseDecimal(%q): %v", tt.in, err)
+		}
+		if d.Unscaled.Int64() != tt.unscaled || d.Scale != tt.scale {
+			t.Fatalf("ParseDecimal(%q) = %v scale %d", tt.in, d.Unscaled, d.Scale)
+		}
+		if d.String() != tt.out {
+			t.Fatalf("String() = %q, want %q", d.String(), tt.out)
+		}
+	}
+	for _, bad := range []string{"", "-", "1.2.3", "1e5", "abc"} {
+		if _, err := ParseDecimal(bad); err == nil {
+			t.Fatalf("ParseDecimal(%q): expected error", bad)
+		}
+	}
+}
+
+func TestDecimalArithmeticViews(t *testing.T) {
+	d := NewDecimal(1250, 2)
+	if d.Rat().Cmp(big.NewRat(25, 2)) != 0 {
+		t.Fatalf("Rat() = %v", d.Rat())
+	}
+	if d.Float64() != 12.5 {
+		t.Fatalf("Float64() = %v", d.Float64())
+	}
+	if NewDecimal(125, 1).Cmp(d) != 0 {
+		t.Fatal("12.5 should equal 12.50")
+	}
+	if NewDecimal(12, -2).String() != "1200" {
+		t.Fatalf("negative scale String() = %q", NewDecimal(12, -2).String())
+	}
+}
+
+func TestTwosComplement(t *testing.T) {
+	tests := []struct {
+		v    int64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{1250, []byte{0x04, 0xe2}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x00, 0x80}},
+		{-1, []byte{0xff}},
+		{-128, []byte{0x80}},
+		{-129, []byte{0xff, 0x7f}},
+	}
+	for _, tt := range tests {
+		got := twosComplement(big.NewInt(tt.v))
+		if string(got) != string(tt.want) {
+			t.Fatalf("twosComplement(%d) = %x, want %x", tt.v, got, tt.want)
+		}
+		if back := fromTwosComplement(got); back.Int64() != tt.v {
+			t.Fatalf("fromTwosComplement(%x) = %v", got, back)
+		}
+	}
+}
+
+func TestDecimalRoundTrip(t *testing.T) {
+	huge, _ := ParseDecimal("-123456789012345678901234567890.123456789")
+	pv := mustValueToProto(huge)
+	got, ok := valueFromProto(pv).(*GqlDecimal)
+	if !ok {
+		t.Fatalf("decoded %T", valueFromProto(pv))
+	}
+	if got.String() != huge.String() || got.Scale != huge.Scale {
+		t.Fatalf("round trip = %s, want %s", got, huge)
+	}
+}
@@ -0,0 +1,35 @@
+package gwp
+
+import "context"
+
+// Discard skips the remaining rows of the current result set and returns
+// its summary, for callers that only need RowsAffected or the status of a
+// statement with a large result.
+//
+// The protocol streams results without a pull-based fetch or server-side
+// discard, so the rows still cross the wire; Discard avoids decoding and
+// buffering them. ctx is checked between frames; to stop the server from
+// producing rows, cancel the context passed to Execute instead.
+func (c *ResultCursor) Discard(ctx context.Context) (*ResultSummary, error) {
+	c.skipBuffered()
+	for !c.done {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := c.consumeUntilRowsOrDone(); err != nil {
+			return nil, err
+		}
+	}
+	return c.Summary()
+}
+
+// skipBuffered drops rows already read and stops decoding further row
+// batches of the current result set.
+func (c *ResultCursor) skipBuffered() {
+	c.discard = true
+	c.bufferedRows = nil
+	c.rowIndex = 0
+	c.rawBatches = nil
+	c.leaseIndex = 0
+	c.releaseLeasedRow()
+}
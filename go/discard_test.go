@@ -0,0 +1,54 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCursorDiscard(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("n"),
+		batchFrame([]any{int64(1)}, []any{int64(2)}),
+		batchFrame([]any{int64(3)}),
+		summaryFrame(Success, 3),
+	)
+	if row, err := c.NextRow(); err != nil || row[0] != int64(1) {
+		t.Fatalf("NextRow = %v, %v", row, err)
+	}
+	s, err := c.Discard(context.Background())
+	if err != nil {
+		t.Fatalf("Discard: %v", err)
+	}
+	if s.RowsAffected() != 3 {
+		t.Fatalf("RowsAffected = %d, want 3", s.RowsAffected())
+	}
+	if c.rowsReceived != 3 {
+		t.Fatalf("rowsReceived = %d, want 3", c.rowsReceived)
+	}
+	if row, err := c.NextRow(); err != nil || row != nil {
+		t.Fatalf("NextRow after Discard = %v, %v", row, err)
+	}
+}
+
+func TestCursorDiscardSkipsDecoding(t *testing.T) {
+	c := newTestCursor(headerFrame("n"), batchFrame([]any{"a"}, []any{"b"}), summaryFrame(Success, 0))
+	if _, err := c.ColumnNames(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Discard(context.Background()); err != nil {
+		t.Fatalf("Discard: %v", err)
+	}
+	if len(c.bufferedRows) != 0 || len(c.rawBatches) != 0 {
+		t.Fatalf("rows were buffered: %v %v", c.bufferedRows, c.rawBatches)
+	}
+}
+
+func TestCursorDiscardContext(t *testing.T) {
+	c := newTestCursor(headerFrame("n"), batchFrame([]any{int64(1)}), summaryFrame(Success, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Discard(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Discard error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,142 @@
+package gwp
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// srvScheme is the target scheme resolved with DNS SRV records.
+const srvScheme = "gwp+srv"
+
+// defaultServiceRefreshInterval is how often SRV records are looked up
+// again to follow membership changes.
+const defaultServiceRefreshInterval = 30 * time.Second
+
+// lookupSRV looks up the SRV records of a name; tests replace it.
+var lookupSRV = func(ctx context.Context, name string) ([]*net.SRV, error) {
+	_, addrs, err := net.DefaultResolver.LookupSRV(ctx, "", "", name)
+	return addrs, err
+}
+
+// isSRVTarget reports whether target names a service to discover with
+// SRV records, such as "gwp+srv://gwp.service.consul".
+func isSRVTarget(target string) bool {
+	return strings.HasPrefix(target, srvScheme+"://")
+}
+
+// connectSRV creates a connection spreading sessions across the servers
+// listed in the SRV records of the target's name, following changes to
+// them.
+func connectSRV(ctx context.Context, target string, config ConnectionConfig) (*GqlConnection, error) {
+	interval := config.ServiceRefreshInterval
+	if interval <= 0 {
+		interval = defaultServiceRefreshInterval
+	}
+	return connectBalanced(ctx, target, config, &srvResolverBuilder{interval: interval})
+}
+
+type srvResolverBuilder struct {
+	interval time.Duration
+}
+
+func (b *srvResolverBuilder) Scheme() string {
+	return srvScheme
+}
+
+func (b *srvResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	name := target.URL.Host
+	if name == "" {
+		name = target.Endpoint()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &srvResolver{
+		name:     name,
+		cc:       cc,
+		interval: b.interval,
+		cancel:   cancel,
+		now:      make(chan struct{}, 1),
+	}
+	r.wg.Add(1)
+	go r.watch(ctx)
+	return r, nil
+}
+
+// srvResolver looks up SRV records when built, on ResolveNow and
+// periodically, and updates the connection's endpoints when they change.
+type srvResolver struct {
+	name     string
+	cc       resolver.ClientConn
+	interval time.Duration
+	cancel   context.CancelFunc
+	now      chan struct{}
+	wg       sync.WaitGroup
+}
+
+func (r *srvResolver) watch(ctx context.Context) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	var last []string
+	for {
+		addrs, err := r.lookup(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			r.cc.ReportError(err)
+		case !slices.Equal(addrs, last):
+			last = addrs
+			r.cc.UpdateState(endpointsState(addrs))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.now:
+		}
+	}
+}
+
+// lookup returns the addresses of the most preferred (lowest priority)
+// SRV records, sorted.
+func (r *srvResolver) lookup(ctx context.Context) ([]string, error) {
+	records, err := lookupSRV(ctx, r.name)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, &GqlError{Message: "no SRV records for " + r.name}
+	}
+	best := records[0].Priority
+	for _, rec := range records {
+		best = min(best, rec.Priority)
+	}
+	var addrs []string
+	for _, rec := range records {
+		if rec.Priority == best {
+			host := strings.TrimSuffix(rec.Target, ".")
+			addrs = append(addrs, net.JoinHostPort(host, strconv.Itoa(int(rec.Port))))
+		}
+	}
+	slices.Sort(addrs)
+	return addrs, nil
+}
+
+func (r *srvResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.now <- struct{}{}:
+	default:
+	}
+}
+
+func (r *srvResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
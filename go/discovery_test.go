@@ -0,0 +1,73 @@
+package gwp
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConnectSRV(t *testing.T) {
+	dialer, _ := serveEndpoints(t, "a", "b")
+
+	var mu sync.Mutex
+	records := []*net.SRV{
+		{Target: "a.", Port: 1, Priority: 10},
+		{Target: "b.", Port: 1, Priority: 20},
+	}
+	var looked string
+	old := lookupSRV
+	lookupSRV = func(ctx context.Context, name string) ([]*net.SRV, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		looked = name
+		return records, nil
+	}
+	t.Cleanup(func() { lookupSRV = old })
+
+	ctx := context.Background()
+	conn, err := ConnectWithConfig(ctx, "gwp+srv://gwp.service.consul", ConnectionConfig{
+		Dialer: func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer(ctx, strings.TrimSuffix(addr, ":1"))
+		},
+		ServiceRefreshInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+
+	// Only the preferred priority is used.
+	for i := 0; i < 5; i++ {
+		s, err := conn.CreateSession(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(s.SessionID(), "a-") {
+			t.Fatalf("session %s created on a lower-priority endpoint", s.SessionID())
+		}
+	}
+	mu.Lock()
+	if looked != "gwp.service.consul" {
+		t.Fatalf("looked up %q", looked)
+	}
+	mu.Unlock()
+
+	// Membership changes are picked up without reconnecting.
+	mu.Lock()
+	records = []*net.SRV{{Target: "b.", Port: 1}}
+	mu.Unlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s, err := conn.CreateSession(ctx)
+		if err == nil && strings.HasPrefix(s.SessionID(), "b-") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sessions still created on the old endpoint: %v, %v", s, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
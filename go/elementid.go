@@ -0,0 +1,92 @@
+package gwp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// ElementID identifies a node or edge. It holds the raw ID bytes, so IDs
+// can be compared with == and used as map keys, and it prints and
+// marshals as lowercase hex. ElementIDs can be passed as statement
+// parameters, where they are sent as byte strings.
+type ElementID string
+
+// NewElementID returns the ElementID of raw ID bytes.
+func NewElementID(b []byte) ElementID {
+	return ElementID(b)
+}
+
+// ParseElementID parses the hex encoding returned by String.
+func ParseElementID(s string) (ElementID, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return "", &GqlError{Message: "invalid element ID " + s + ": " + err.Error()}
+	}
+	return ElementID(b), nil
+}
+
+// ParseElementIDBase64 parses the encoding returned by Base64.
+func ParseElementIDBase64(s string) (ElementID, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return "", &GqlError{Message: "invalid element ID " + s + ": " + err.Error()}
+	}
+	return ElementID(b), nil
+}
+
+// Bytes returns the raw ID bytes.
+func (id ElementID) Bytes() []byte {
+	return []byte(id)
+}
+
+// String returns the ID in lowercase hex.
+func (id ElementID) String() string {
+	return hex.EncodeToString([]byte(id))
+}
+
+// Base64 returns the ID in unpadded URL-safe base64, a shorter encoding
+// for URLs and logs.
+func (id ElementID) Base64() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// IsZero reports whether the ID is empty.
+func (id ElementID) IsZero() bool {
+	return id == ""
+}
+
+// MarshalText implements encoding.TextMarshaler, so that ElementIDs encode
+// as hex in JSON, including as map keys.
+func (id ElementID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (id *ElementID) UnmarshalText(text []byte) error {
+	parsed, err := ParseElementID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// ElementID returns the node's ID as an ElementID.
+func (n *GqlNode) ElementID() ElementID {
+	return ElementID(n.ID)
+}
+
+// ElementID returns the edge's ID as an ElementID.
+func (e *GqlEdge) ElementID() ElementID {
+	return ElementID(e.ID)
+}
+
+// SourceElementID returns the ID of the edge's source node.
+func (e *GqlEdge) SourceElementID() ElementID {
+	return ElementID(e.SourceNodeID)
+}
+
+// TargetElementID returns the ID of the edge's target node.
+func (e *GqlEdge) TargetElementID() ElementID {
+	return ElementID(e.TargetNodeID)
+}
@@ -0,0 +1,42 @@
+package gwp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestElementID(t *testing.T) {
+	node := &GqlNode{ID: []byte{0x01, 0xab, 0xff}}
+	id := node.ElementID()
+	if id.String() != "01abff" || id.Base64() != "Aav_" {
+		t.Fatalf("String = %q, Base64 = %q", id.String(), id.Base64())
+	}
+	if parsed, err := ParseElementID("01abff"); err != nil || parsed != id {
+		t.Fatalf("ParseElementID = %v, %v", parsed, err)
+	}
+	if parsed, err := ParseElementIDBase64("Aav_"); err != nil || parsed != id {
+		t.Fatalf("ParseElementIDBase64 = %v, %v", parsed, err)
+	}
+	if _, err := ParseElementID("xyz"); err == nil {
+		t.Fatal("expected error for invalid hex")
+	}
+
+	edge := &GqlEdge{ID: []byte{9}, SourceNodeID: node.ID, TargetNodeID: []byte{2}}
+	byID := map[ElementID]string{id: "alice"}
+	if byID[edge.SourceElementID()] != "alice" || edge.TargetElementID() != NewElementID([]byte{2}) {
+		t.Fatal("edge endpoints do not match node IDs")
+	}
+
+	data, err := json.Marshal(map[ElementID]ElementID{id: edge.ElementID()})
+	if err != nil || string(data) != `{"01abff":"09"}` {
+		t.Fatalf("json = %s, %v", data, err)
+	}
+	var decoded map[ElementID]ElementID
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded[id] != edge.ElementID() {
+		t.Fatalf("decoded = %v, %v", decoded, err)
+	}
+
+	if v := valueFromProto(mustValueToProto(id)); string(v.([]byte)) != string(node.ID) {
+		t.Fatalf("parameter round trip = %v", v)
+	}
+}
@@ -0,0 +1,379 @@
+package gwp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Equal reports whether two result values are equal. Nodes and edges are
+// compared by ID, labels in any order and properties; paths element by
+// element; zoned temporal values by the instant they denote; decimals and
+// numbers by value, whatever their Go type; and lists, records and maps
+// deeply. Temporal and decimal values compare equal to pointers to them.
+func Equal(a, b any) bool {
+	d := differ{limit: 1}
+	d.diff("$", a, b)
+	return len(d.lines) == 0
+}
+
+// Diff describes how two result values differ, as compared by Equal: one
+// line per difference, each starting with the path to the differing value,
+// such as "$.properties.age: 30 != 31". It returns "" if the values are
+// equal.
+func Diff(a, b any) string {
+	var d differ
+	d.diff("$", a, b)
+	return strings.Join(d.lines, "\n")
+}
+
+// missing stands for a map key or record field present on one side only.
+type missing struct{}
+
+// differ collects differences, stopping after limit if it is non-zero.
+type differ struct {
+	lines []string
+	limit int
+}
+
+func (d *differ) full() bool {
+	return d.limit > 0 && len(d.lines) >= d.limit
+}
+
+func (d *differ) add(path string, a, b any) {
+	d.lines = append(d.lines, path+": "+formatDiffValue(a)+" != "+formatDiffValue(b))
+}
+
+func (d *differ) diff(path string, a, b any) {
+	if d.full() {
+		return
+	}
+	a, b = normalizeValue(a), normalizeValue(b)
+	if a == nil || b == nil {
+		if a != nil || b != nil {
+			d.add(path, a, b)
+		}
+		return
+	}
+	if eq, ok := numbersEqual(a, b); ok {
+		if !eq {
+			d.add(path, a, b)
+		}
+		return
+	}
+
+	switch x := a.(type) {
+	case *GqlNode:
+		y, ok := b.(*GqlNode)
+		if !ok {
+			d.add(path, a, b)
+			return
+		}
+		d.ids(path+".id", x.ID, y.ID)
+		d.labels(path+".labels", x.Labels, y.Labels)
+		d.maps(path+".properties", x.PropertyMap(), y.PropertyMap())
+	case *GqlEdge:
+		y, ok := b.(*GqlEdge)
+		if !ok {
+			d.add(path, a, b)
+			return
+		}
+		d.ids(path+".id", x.ID, y.ID)
+		d.labels(path+".labels", x.Labels, y.Labels)
+		d.ids(path+".source", x.SourceNodeID, y.SourceNodeID)
+		d.ids(path+".target", x.TargetNodeID, y.TargetNodeID)
+		if x.Undirected != y.Undirected {
+			d.add(path+".undirected", x.Undirected, y.Undirected)
+		}
+		d.maps(path+".properties", x.PropertyMap(), y.PropertyMap())
+	case *GqlPath:
+		y, ok := b.(*GqlPath)
+		if !ok {
+			d.add(path, a, b)
+			return
+		}
+		d.lists(path+".nodes", toAnys(x.Nodes), toAnys(y.Nodes))
+		d.lists(path+".edges", toAnys(x.Edges), toAnys(y.Edges))
+	case *GqlRecord:
+		y, ok := b.(*GqlRecord)
+		if !ok {
+			d.add(path, a, b)
+			return
+		}
+		for i := 0; i < max(len(x.Fields), len(y.Fields)); i++ {
+			var fa, fb GqlField
+			va, vb := any(missing{}), any(missing{})
+			if i < len(x.Fields) {
+				fa, va = x.Fields[i], x.Fields[i].Value
+			}
+			if i < len(y.Fields) {
+				fb, vb = y.Fields[i], y.Fields[i].Value
+			}
+			if fa.Name != fb.Name && i < len(x.Fields) && i < len(y.Fields) {
+				d.add(path+"["+strconv.Itoa(i)+"].name", fa.Name, fb.Name)
+				continue
+			}
+			name := fa.Name
+			if i >= len(x.Fields) {
+				name = fb.Name
+			}
+			d.diffOrMissing(path+"."+name, va, vb)
+		}
+	case []any:
+		y, ok := b.([]any)
+		if !ok {
+			d.add(path, a, b)
+			return
+		}
+		d.lists(path, x, y)
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok {
+			d.add(path, a, b)
+			return
+		}
+		d.maps(path, x, y)
+	case []byte:
+		if y, ok := b.([]byte); !ok || !bytes.Equal(x, y) {
+			d.add(path, a, b)
+		}
+	case GqlDecimal:
+		if y, ok := b.(GqlDecimal); !ok || x.Cmp(y) != 0 {
+			d.add(path, a, b)
+		}
+	case GqlZonedDateTime:
+		if y, ok := b.(GqlZonedDateTime); !ok || !zonedDateTimeInstant(x).Equal(zonedDateTimeInstant(y)) {
+			d.add(path, a, b)
+		}
+	case GqlZonedTime:
+		if y, ok := b.(GqlZonedTime); !ok || zonedTimeInstant(x) != zonedTimeInstant(y) {
+			d.add(path, a, b)
+		}
+	default:
+		if !reflect.DeepEqual(a, b) {
+			d.add(path, a, b)
+		}
+	}
+}
+
+// diffOrMissing compares values that may be missing on one side.
+func (d *differ) diffOrMissing(path string, a, b any) {
+	_, ma := a.(missing)
+	_, mb := b.(missing)
+	if ma || mb {
+		d.add(path, a, b)
+		return
+	}
+	d.diff(path, a, b)
+}
+
+func (d *differ) ids(path string, a, b []byte) {
+	if !bytes.Equal(a, b) {
+		d.lines = append(d.lines, path+": "+hex.EncodeToString(a)+" != "+hex.EncodeToString(b))
+	}
+}
+
+func (d *differ) labels(path string, a, b []string) {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	if !slices.Equal(a, b) {
+		d.add(path, a, b)
+	}
+}
+
+func (d *differ) lists(path string, a, b []any) {
+	if len(a) != len(b) {
+		d.lines = append(d.lines, fmt.Sprintf("%s: length %d != %d", path, len(a), len(b)))
+	}
+	for i := 0; i < min(len(a), len(b)); i++ {
+		d.diff(path+"["+strconv.Itoa(i)+"]", a[i], b[i])
+	}
+}
+
+func (d *differ) maps(path string, a, b map[string]any) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		va, ok := a[k]
+		if !ok {
+			va = missing{}
+		}
+		vb, ok := b[k]
+		if !ok {
+			vb = missing{}
+		}
+		d.diffOrMissing(path+"."+k, va, vb)
+	}
+}
+
+func toAnys[T any](s []T) []any {
+	out := make([]any, len(s))
+	for i, v := range s {
+		out[i] = v
+	}
+	return out
+}
+
+// normalizeValue dereferences pointers to temporal and decimal values, so
+// that they compare equal to the values themselves, and maps nil pointers
+// to nil.
+func normalizeValue(v any) any {
+	switch x := v.(type) {
+	case *GqlDecimal:
+		if x != nil {
+			return *x
+		}
+	case *GqlDate:
+		if x != nil {
+			return *x
+		}
+	case *GqlLocalTime:
+		if x != nil {
+			return *x
+		}
+	case *GqlZonedTime:
+		if x != nil {
+			return *x
+		}
+	case *GqlLocalDateTime:
+		if x != nil {
+			return *x
+		}
+	case *GqlZonedDateTime:
+		if x != nil {
+			return *x
+		}
+	case *GqlDuration:
+		if x != nil {
+			return *x
+		}
+	case *GqlNode:
+		if x != nil {
+			return x
+		}
+	case *GqlEdge:
+		if x != nil {
+			return x
+		}
+	case *GqlPath:
+		if x != nil {
+			return x
+		}
+	case *GqlRecord:
+		if x != nil {
+			return x
+		}
+	default:
+		return v
+	}
+	return nil
+}
+
+// numbersEqual compares two numbers exactly, across Go integer and float
+// types. ok is false unless both are numbers.
+func numbersEqual(a, b any) (eq, ok bool) {
+	ia, fa, ua, kindA := number(a)
+	ib, fb, ub, kindB := number(b)
+	if kindA == 0 || kindB == 0 {
+		return false, false
+	}
+	switch {
+	case kindA == 'f' || kindB == 'f':
+		return toFloat(ia, fa, ua, kindA) == toFloat(ib, fb, ub, kindB), true
+	case kindA == 'i' && kindB == 'i':
+		return ia == ib, true
+	case kindA == 'u' && kindB == 'u':
+		return ua == ub, true
+	case kindA == 'i':
+		return ia >= 0 && uint64(ia) == ub, true
+	default:
+		return ib >= 0 && uint64(ib) == ua, true
+	}
+}
+
+func number(v any) (i int64, f float64, u uint64, kind byte) {
+	switch x := v.(type) {
+	case int:
+		return int64(x), 0, 0, 'i'
+	case int32:
+		return int64(x), 0, 0, 'i'
+	case int64:
+		return x, 0, 0, 'i'
+	case uint64:
+		return 0, 0, x, 'u'
+	case float32:
+		return 0, float64(x), 0, 'f'
+	case float64:
+		return 0, x, 0, 'f'
+	}
+	return 0, 0, 0, 0
+}
+
+func toFloat(i int64, f float64, u uint64, kind byte) float64 {
+	switch kind {
+	case 'i':
+		return float64(i)
+	case 'u':
+		return float64(u)
+	}
+	return f
+}
+
+func zonedDateTimeInstant(t GqlZonedDateTime) time.Time {
+	return time.Date(int(t.Date.Year), time.Month(t.Date.Month), int(t.Date.Day),
+		int(t.Time.Hour), int(t.Time.Minute), int(t.Time.Second), int(t.Time.Nanosecond),
+		time.FixedZone("", int(t.OffsetMinutes)*60))
+}
+
+// zonedTimeInstant returns the UTC time of day of t in nanoseconds.
+func zonedTimeInstant(t GqlZonedTime) int64 {
+	const day = int64(24 * time.Hour)
+	ns := int64(t.Time.Hour)*int64(time.Hour) + int64(t.Time.Minute)*int64(time.Minute) +
+		int64(t.Time.Second)*int64(time.Second) + int64(t.Time.Nanosecond) -
+		int64(t.OffsetMinutes)*int64(time.Minute)
+	return ((ns % day) + day) % day
+}
+
+// formatDiffValue renders a value for Diff output.
+func formatDiffValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case missing:
+		return "<missing>"
+	case string:
+		return strconv.Quote(x)
+	case []byte:
+		return "0x" + hex.EncodeToString(x)
+	case *GqlNode:
+		return "node " + hex.EncodeToString(x.ID)
+	case *GqlEdge:
+		return "edge " + hex.EncodeToString(x.ID)
+	case *GqlPath:
+		return fmt.Sprintf("path of length %d", x.Len())
+	case *GqlRecord:
+		return fmt.Sprintf("record of %d fields", len(x.Fields))
+	case []any:
+		return fmt.Sprintf("list of %d", len(x))
+	case map[string]any:
+		return fmt.Sprintf("map of %d", len(x))
+	case GqlDecimal:
+		return x.String()
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,64 @@
+package gwp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	node := func(labels []string, props map[string]any) *GqlNode {
+		return &GqlNode{ID: []byte{1}, Labels: labels, Properties: props}
+	}
+	tests := []struct {
+		a, b any
+		want bool
+	}{
+		{int64(1), 1, true},
+		{int64(1), 1.0, true},
+		{uint64(1), int64(-1), false},
+		{"a", "a", true},
+		{nil, (*GqlNode)(nil), true},
+		{[]byte{1}, []byte{1}, true},
+		{NewDecimal(10, 1), &GqlDecimal{Unscaled: NewDecimal(100, 2).Unscaled, Scale: 2}, true},
+		{&GqlDate{Year: 2024, Month: 1, Day: 2}, GqlDate{Year: 2024, Month: 1, Day: 2}, true},
+		{
+			&GqlZonedDateTime{Date: GqlDate{Year: 2024, Month: 1, Day: 1}, Time: GqlLocalTime{Hour: 12}, OffsetMinutes: 60},
+			&GqlZonedDateTime{Date: GqlDate{Year: 2024, Month: 1, Day: 1}, Time: GqlLocalTime{Hour: 11}},
+			true,
+		},
+		{&GqlZonedTime{Time: GqlLocalTime{Hour: 0}, OffsetMinutes: 60}, &GqlZonedTime{Time: GqlLocalTime{Hour: 23}}, true},
+		{node([]string{"A", "B"}, map[string]any{"n": int64(1)}), node([]string{"B", "A"}, map[string]any{"n": 1.0}), true},
+		{node([]string{"A"}, nil), node([]string{"B"}, nil), false},
+		{[]any{int64(1), "x"}, []any{int64(1), "x"}, true},
+		{[]any{int64(1)}, []any{int64(1), int64(2)}, false},
+		{&GqlRecord{Fields: []GqlField{{"a", int64(1)}}}, &GqlRecord{Fields: []GqlField{{"a", int64(1)}}}, true},
+		{map[string]any{"a": nil}, map[string]any{}, false},
+	}
+	for i, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("%d: Equal(%v, %v) = %v\n%s", i, tt.a, tt.b, got, Diff(tt.a, tt.b))
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := &GqlPath{
+		Nodes: []*GqlNode{{ID: []byte{1}, Labels: []string{"Person"}, Properties: map[string]any{"name": "Alice", "age": int64(30)}}},
+		Edges: []*GqlEdge{{ID: []byte{9}, SourceNodeID: []byte{1}, TargetNodeID: []byte{2}}},
+	}
+	b := &GqlPath{
+		Nodes: []*GqlNode{{ID: []byte{1}, Labels: []string{"Person"}, Properties: map[string]any{"name": "Alice", "age": int64(31), "city": "Oslo"}}},
+		Edges: []*GqlEdge{{ID: []byte{9}, SourceNodeID: []byte{1}, TargetNodeID: []byte{3}}},
+	}
+	want := strings.Join([]string{
+		"$.nodes[0].properties.age: 30 != 31",
+		`$.nodes[0].properties.city: <missing> != "Oslo"`,
+		"$.edges[0].target: 02 != 03",
+	}, "\n")
+	if got := Diff(a, b); got != want {
+		t.Fatalf("Diff =\n%s\nwant\n%s", got, want)
+	}
+	if got := Diff(a, a); got != "" {
+		t.Fatalf("Diff of equal values = %q", got)
+	}
+}
@@ -1,6 +1,12 @@
 package gwp
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
 // GqlError is the base error type for GWP operations.
 type GqlError struct {
@@ -21,6 +27,68 @@ func (e *GqlStatusError) Error() string {
 	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
 }
 
+// TransientError is a GQL status error whose transaction was rolled back by
+// the server (GQLSTATUS class 40), such as a serialization conflict or
+// deadlock. Running the transaction again may succeed. It unwraps to the
+// equivalent *GqlStatusError.
+type TransientError struct {
+	Code    string
+	Message string
+}
+
+func (e *TransientError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+func (e *TransientError) Unwrap() error {
+	return &GqlStatusError{Code: e.Code, Message: e.Message}
+}
+
+// newStatusError returns the error for an exception status: a
+// *TransientError for class 40 and a *GqlStatusError otherwise.
+func newStatusError(code, message string) error {
+	if IsTransactionRollback(code) {
+		return &TransientError{Code: code, Message: message}
+	}
+	return &GqlStatusError{Code: code, Message: message}
+}
+
+// IsTransient reports whether err means the transaction was rolled back by
+// the server and may succeed if replayed: a *TransientError, a
+// *GqlStatusError of class 40, or a gRPC Aborted status.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var te *TransientError
+	if errors.As(err, &te) {
+		return true
+	}
+	var se *GqlStatusError
+	if errors.As(err, &se) && IsTransactionRollback(se.Code) {
+		return true
+	}
+	return status.Code(err) == codes.Aborted
+}
+
+// IncompleteResultError reports that a result stream ended after its header
+// but before its summary, so the rows read are only part of the result. It
+// unwraps to Cause: the stream's error, or io.ErrUnexpectedEOF if the
+// stream ended without one.
+type IncompleteResultError struct {
+	// Rows is the number of rows received before the stream ended.
+	Rows  int64
+	Cause error
+}
+
+func (e *IncompleteResultError) Error() string {
+	return fmt.Sprintf("result incomplete after %d rows: %v", e.Rows, e.Cause)
+}
+
+func (e *IncompleteResultError) Unwrap() error {
+	return e.Cause
+}
+
 // SessionError represents a session-level error.
 type SessionError struct {
 	Message string
@@ -30,6 +98,10 @@ func (e *SessionError) Error() string {
 	return e.Message
 }
 
+var errSessionClosed = &SessionError{Message: "session is closed"}
+
+var errTransactionAbandoned = &TransactionError{Message: "transaction was abandoned and rolled back"}
+
 // TransactionError represents a transaction-level error.
 type TransactionError struct {
 	Message string
@@ -38,3 +110,41 @@ type TransactionError struct {
 func (e *TransactionError) Error() string {
 	return e.Message
 }
+
+// ParamError reports a parameter value that cannot be sent.
+type ParamError struct {
+	// Path locates the value, such as "$ids[2]".
+	Path    string
+	Message string
+	// Err is the underlying error, if any, such as an
+	// *UnsupportedTypeError.
+	Err error
+}
+
+func (e *ParamError) Error() string {
+	return "parameter " + e.Path + ": " + e.Message
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedTypeError reports a value that cannot be converted: a Go value
+// of a type with no GQL equivalent, or a result value of a kind this package
+// does not know, such as one added in a later protocol version. Result
+// values are reported only with ConnectionConfig.StrictConversion;
+// otherwise they decode to nil.
+type UnsupportedTypeError struct {
+	// Path locates the value: a parameter path such as "$ids[2]" or a
+	// result column name. It is empty if unknown.
+	Path string
+	// Type is the Go type or the protobuf value kind.
+	Type string
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Path == "" {
+		return "unsupported type " + e.Type
+	}
+	return e.Path + ": unsupported type " + e.Type
+}
@@ -0,0 +1,130 @@
+package gwp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// reservedWords are GQL keywords that cannot be used as regular
+// identifiers.
+var reservedWords = map[string]bool{
+	"ALL": true, "AND": true, "ANY": true, "AS": true, "ASC": true,
+	"AT": true, "BY": true, "CALL": true, "CASE": true, "CREATE": true,
+	"DELETE": true, "DESC": true, "DETACH": true, "DISTINCT": true,
+	"DROP": true, "ELSE": true, "END": true, "EXISTS": true,
+	"FALSE": true, "FOR": true, "GROUP": true, "HAVING": true, "IN": true,
+	"INSERT": true, "IS": true, "LET": true, "LIMIT": true, "MATCH": true,
+	"NEXT": true, "NOT": true, "NULL": true, "OFFSET": true,
+	"OPTIONAL": true, "OR": true, "ORDER": true, "REMOVE": true,
+	"RETURN": true, "SET": true, "THEN": true, "TRUE": true,
+	"UNION": true, "USE": true, "WHEN": true, "WHERE": true, "WITH": true,
+	"XOR": true, "YIELD": true,
+}
+
+// EscapeIdentifier returns name as a GQL identifier for a label, edge
+// type, property key, variable, graph or schema: unchanged if it is a
+// regular identifier, and otherwise delimited with backticks, doubling any
+// backticks in it. Use it where statement parameters cannot be, such as in
+// DDL; values should always be passed as parameters.
+func EscapeIdentifier(name string) string {
+	if isRegularIdentifier(name) {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func isRegularIdentifier(name string) bool {
+	if name == "" || reservedWords[strings.ToUpper(name)] {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isIdentByte(name[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateIdentifier rejects names that cannot be identifiers even when
+// delimited.
+func validateIdentifier(name string) error {
+	switch {
+	case name == "":
+		return &GqlError{Message: "empty identifier"}
+	case !utf8.ValidString(name):
+		return &GqlError{Message: fmt.Sprintf("identifier %q is not valid UTF-8", name)}
+	case strings.ContainsRune(name, 0):
+		return &GqlError{Message: fmt.Sprintf("identifier %q contains a NUL character", name)}
+	}
+	return nil
+}
+
+// SafeStatement formats a statement whose identifiers are only known at
+// run time, escaping each with EscapeIdentifier. The verbs are:
+//
+//	%I  an identifier, from a string
+//	%d  an integer, such as a LIMIT
+//	%%  a percent sign
+//
+// Any other verb is an error, so values cannot be spliced into the text;
+// pass them as parameters instead.
+//
+//	stmt, err := gwp.SafeStatement("CREATE GRAPH %I ANY", name)
+func SafeStatement(format string, args ...any) (string, error) {
+	var b strings.Builder
+	b.Grow(len(format))
+	next := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(format) {
+			return "", &GqlError{Message: "SafeStatement: format ends with %"}
+		}
+		i++
+		verb := format[i]
+		if verb == '%' {
+			b.WriteByte('%')
+			continue
+		}
+		if next >= len(args) {
+			return "", &GqlError{Message: fmt.Sprintf("SafeStatement: missing argument for %%%c", verb)}
+		}
+		arg := args[next]
+		next++
+		switch verb {
+		case 'I':
+			name, ok := arg.(string)
+			if !ok {
+				return "", &GqlError{Message: fmt.Sprintf("SafeStatement: %%I needs a string, got %T", arg)}
+			}
+			if err := validateIdentifier(name); err != nil {
+				return "", err
+			}
+			b.WriteString(EscapeIdentifier(name))
+		case 'd':
+			switch n := arg.(type) {
+			case int:
+				b.WriteString(strconv.Itoa(n))
+			case int64:
+				b.WriteString(strconv.FormatInt(n, 10))
+			case int32:
+				b.WriteString(strconv.FormatInt(int64(n), 10))
+			case uint64:
+				b.WriteString(strconv.FormatUint(n, 10))
+			default:
+				return "", &GqlError{Message: fmt.Sprintf("SafeStatement: %%d needs an integer, got %T", arg)}
+			}
+		default:
+			return "", &GqlError{Message: fmt.Sprintf("SafeStatement: unsupported verb %%%c", verb)}
+		}
+	}
+	if next < len(args) {
+		return "", &GqlError{Message: fmt.Sprintf("SafeStatement: %d unused arguments", len(args)-next)}
+	}
+	return b.String(), nil
+}
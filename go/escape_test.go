@@ -0,0 +1,55 @@
+package gwp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscapeIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"Person":      "Person",
+		"_tmp2":       "_tmp2",
+		"2fast":       "`2fast`",
+		"Two Words":   "`Two Words`",
+		"back`tick":   "`back``tick`",
+		"order":       "`order`",
+		"Ünïcode":     "`Ünïcode`",
+		"x) DELETE n": "`x) DELETE n`",
+		"":            "``",
+	}
+	for in, want := range tests {
+		if got := EscapeIdentifier(in); got != want {
+			t.Errorf("EscapeIdentifier(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestSafeStatement(t *testing.T) {
+	stmt, err := SafeStatement("CREATE GRAPH %I ANY; MATCH (n:%I) RETURN n LIMIT %d -- 100%%", "social net", "Person", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "CREATE GRAPH `social net` ANY; MATCH (n:Person) RETURN n LIMIT 10 -- 100%"; stmt != want {
+		t.Fatalf("got %s, want %s", stmt, want)
+	}
+
+	bad := []struct {
+		format string
+		args   []any
+		want   string
+	}{
+		{"MATCH (n {name: '%s'})", []any{"x"}, "unsupported verb"},
+		{"MATCH (n:%I)", nil, "missing argument"},
+		{"MATCH (n:%I)", []any{"A", "B"}, "unused"},
+		{"MATCH (n:%I)", []any{42}, "needs a string"},
+		{"MATCH (n:%I)", []any{""}, "empty identifier"},
+		{"MATCH (n:%I)", []any{"a\x00b"}, "NUL"},
+		{"LIMIT %d", []any{"10"}, "needs an integer"},
+		{"100%", nil, "ends with"},
+	}
+	for _, tt := range bad {
+		if _, err := SafeStatement(tt.format, tt.args...); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("SafeStatement(%q, %v) error = %v, want %q", tt.format, tt.args, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// Package fixtures loads graph fixtures for integration tests.
+//
+// A fixture lists nodes, edges between them and, optionally, statements
+// that remove them again:
+//
+//	nodes:
+//	  - id: alice
+//	    labels: [Person]
+//	    properties: {name: Alice, age: 30}
+//	  - id: bob
+//	    labels: [Person]
+//	    properties: {name: Bob}
+//	edges:
+//	  - from: alice
+//	    to: bob
+//	    type: KNOWS
+//	    properties: {since: 2020}
+//	teardown:
+//	  - MATCH (p:Person) DETACH DELETE p
+//
+// JSON fixtures have the same shape. A CSV fixture holds either nodes, with
+// :id and :labels columns (labels separated by semicolons), or edges, with
+// :from, :to and :type columns; the remaining columns are properties.
+//
+// InTransaction inserts fixtures in a transaction that is rolled back when
+// the test ends. Commit commits them and runs their teardown statements
+// when the test ends instead, for code under test that opens its own
+// sessions.
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// Fixture is a set of nodes and edges to insert.
+type Fixture struct {
+	Nodes []Node `json:"nodes" yaml:"nodes"`
+	Edges []Edge `json:"edges" yaml:"edges"`
+	// Teardown statements remove the fixture's data after a committed
+	// load.
+	Teardown []string `json:"teardown" yaml:"teardown"`
+}
+
+// Node is a fixture node. ID names the node for edges in the same fixture
+// and is not stored.
+type Node struct {
+	ID         string         `json:"id" yaml:"id"`
+	Labels     []string       `json:"labels" yaml:"labels"`
+	Properties map[string]any `json:"properties" yaml:"properties"`
+}
+
+// Edge is a fixture edge between two nodes identified by their IDs.
+type Edge struct {
+	From       string         `json:"from" yaml:"from"`
+	To         string         `json:"to" yaml:"to"`
+	Type       string         `json:"type" yaml:"type"`
+	Properties map[string]any `json:"properties" yaml:"properties"`
+}
+
+// Load reads fixture files from fsys and merges them into one fixture, so
+// that edges in one file can refer to nodes in another. The format follows
+// the extension: .yaml, .yml, .json or .csv.
+func Load(fsys fs.FS, names ...string) (*Fixture, error) {
+	merged := &Fixture{}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := Parse(name, data)
+		if err != nil {
+			return nil, err
+		}
+		merged.Nodes = append(merged.Nodes, f.Nodes...)
+		merged.Edges = append(merged.Edges, f.Edges...)
+		merged.Teardown = append(merged.Teardown, f.Teardown...)
+	}
+	return merged, nil
+}
+
+// Parse decodes a fixture in the format given by the extension of name.
+func Parse(name string, data []byte) (*Fixture, error) {
+	f := &Fixture{}
+	var err error
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, f)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(f)
+	case ".csv":
+		f, err = parseCSV(data)
+	default:
+		return nil, fmt.Errorf("fixtures: %s: unknown format", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %s: %w", name, err)
+	}
+	return f, nil
+}
+
+func parseCSV(data []byte) (*Fixture, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	column := make(map[string]int, len(header))
+	for i, h := range header {
+		column[h] = i
+	}
+	_, edges := column[":from"]
+
+	f := &Fixture{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return f, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		props := make(map[string]any)
+		for i, h := range header {
+			if strings.HasPrefix(h, ":") || record[i] == "" {
+				continue
+			}
+			props[h] = csvValue(record[i])
+		}
+		get := func(name string) string {
+			if i, ok := column[name]; ok {
+				return record[i]
+			}
+			return ""
+		}
+		if edges {
+			f.Edges = append(f.Edges, Edge{From: get(":from"), To: get(":to"), Type: get(":type"), Properties: props})
+			continue
+		}
+		var labels []string
+		if l := get(":labels"); l != "" {
+			labels = strings.Split(l, ";")
+		}
+		f.Nodes = append(f.Nodes, Node{ID: get(":id"), Labels: labels, Properties: props})
+	}
+}
+
+// csvValue infers the type of a CSV field: boolean, integer, float or
+// string.
+func csvValue(s string) any {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// Insert adds the nodes and edges of f in one INSERT statement executed in
+// tx.
+func Insert(ctx context.Context, tx *gwp.Transaction, f *Fixture) error {
+	stmt, params, err := insertStatement(f)
+	if err != nil || stmt == "" {
+		return err
+	}
+	return execute(ctx, tx.Execute, stmt, params)
+}
+
+// InTransaction begins a transaction on session, inserts the fixtures and
+// returns the transaction for the test to run its statements in. The
+// transaction is rolled back when the test ends.
+func InTransaction(tb testing.TB, session *gwp.GqlSession, fixtures ...*Fixture) *gwp.Transaction {
+	tb.Helper()
+	ctx := context.Background()
+	tx, err := session.BeginTransaction(ctx, false)
+	if err != nil {
+		tb.Fatalf("fixtures: begin transaction: %v", err)
+	}
+	tb.Cleanup(func() { tx.Rollback(context.Background()) })
+	for _, f := range fixtures {
+		if err := Insert(ctx, tx, f); err != nil {
+			tb.Fatalf("fixtures: %v", err)
+		}
+	}
+	return tx
+}
+
+// Commit inserts the fixtures in a transaction and commits it. When the
+// test ends their teardown statements run on session, in reverse fixture
+// order. Every fixture must have teardown statements.
+func Commit(tb testing.TB, session *gwp.GqlSession, fixtures ...*Fixture) {
+	tb.Helper()
+	for _, f := range fixtures {
+		if len(f.Teardown) == 0 {
+			tb.Fatalf("fixtures: committed fixture has no teardown statements")
+		}
+	}
+
+	ctx := context.Background()
+	tx, err := session.BeginTransaction(ctx, false)
+	if err != nil {
+		tb.Fatalf("fixtures: begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	for _, f := range fixtures {
+		if err := Insert(ctx, tx, f); err != nil {
+			tb.Fatalf("fixtures: %v", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		tb.Fatalf("fixtures: commit: %v", err)
+	}
+
+	tb.Cleanup(func() {
+		ctx := context.Background()
+		for i := len(fixtures) - 1; i >= 0; i-- {
+			for _, stmt := range fixtures[i].Teardown {
+				if err := execute(ctx, session.Execute, stmt, nil); err != nil {
+					tb.Errorf("fixtures: teardown %q: %v", stmt, err)
+				}
+			}
+		}
+	})
+}
+
+type executeFunc func(ctx context.Context, statement string, params map[string]any, opts ...gwp.ExecuteOption) (*gwp.ResultCursor, error)
+
+// execute runs a statement to completion and returns an exception status
+// as an error.
+func execute(ctx context.Context, exec executeFunc, statement string, params map[string]any) error {
+	cursor, err := exec(ctx, statement, params)
+	if err != nil {
+		return err
+	}
+	summary, err := cursor.Summary()
+	if err != nil {
+		return err
+	}
+	if summary != nil && gwp.IsException(summary.StatusCode()) {
+		return &gwp.GqlStatusError{Code: summary.StatusCode(), Message: summary.Message()}
+	}
+	return nil
+}
+
+// insertStatement builds a single INSERT for the fixture, with property
+// values passed as parameters.
+func insertStatement(f *Fixture) (string, map[string]any, error) {
+	if len(f.Nodes) == 0 && len(f.Edges) == 0 {
+		return "", nil, nil
+	}
+	params := make(map[string]any)
+	properties := func(props map[string]any) (string, error) {
+		if len(props) == 0 {
+			return "", nil
+		}
+		keys := make([]string, 0, len(props))
+		for k := range props {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, k := range keys {
+			v, err := paramValue(props[k])
+			if err != nil {
+				return "", fmt.Errorf("property %s: %w", k, err)
+			}
+			name := fmt.Sprintf("p%d", len(params))
+			params[name] = v
+			fields[i] = gwp.EscapeIdentifier(k) + ": $" + name
+		}
+		return " {" + strings.Join(fields, ", ") + "}", nil
+	}
+
+	vars := make(map[string]string, len(f.Nodes))
+	patterns := make([]string, 0, len(f.Nodes)+len(f.Edges))
+	for i, n := range f.Nodes {
+		v := fmt.Sprintf("n%d", i)
+		if n.ID != "" {
+			if _, dup := vars[n.ID]; dup {
+				return "", nil, fmt.Errorf("duplicate node id %q", n.ID)
+			}
+			vars[n.ID] = v
+		}
+		props, err := properties(n.Properties)
+		if err != nil {
+			return "", nil, fmt.Errorf("node %q: %w", n.ID, err)
+		}
+		var labels strings.Builder
+		for _, l := range n.Labels {
+			labels.WriteString(":" + gwp.EscapeIdentifier(l))
+		}
+		patterns = append(patterns, "("+v+labels.String()+props+")")
+	}
+	for _, e := range f.Edges {
+		from, ok := vars[e.From]
+		if !ok {
+			return "", nil, fmt.Errorf("edge %s->%s: unknown node %q", e.From, e.To, e.From)
+		}
+		to, ok := vars[e.To]
+		if !ok {
+			return "", nil, fmt.Errorf("edge %s->%s: unknown node %q", e.From, e.To, e.To)
+		}
+		if e.Type == "" {
+			return "", nil, fmt.Errorf("edge %s->%s: missing type", e.From, e.To)
+		}
+		props, err := properties(e.Properties)
+		if err != nil {
+			return "", nil, fmt.Errorf("edge %s->%s: %w", e.From, e.To, err)
+		}
+		patterns = append(patterns, "("+from+")-[:"+gwp.EscapeIdentifier(e.Type)+props+"]->("+to+")")
+	}
+	return "INSERT " + strings.Join(patterns, ", "), params, nil
+}
+
+// paramValue converts decoded JSON and YAML values into parameter values.
+func paramValue(v any) (any, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	case int:
+		return int64(v), nil
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			var err error
+			if out[i], err = paramValue(e); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case nil, bool, int64, float64, string:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v of type %T", v, v)
+	}
+}
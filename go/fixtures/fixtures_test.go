@@ -0,0 +1,189 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+const yamlFixture = `
+nodes:
+  - id: alice
+    labels: [Person]
+    properties: {name: Alice, age: 30, tags: [a, b]}
+  - id: bob
+    labels: [Person, "Two Words"]
+edges:
+  - from: alice
+    to: bob
+    type: KNOWS
+    properties: {since: 2020}
+teardown:
+  - MATCH (p:Person) DETACH DELETE p
+`
+
+const jsonFixture = `{
+  "nodes": [{"id": "alice", "labels": ["Person"], "properties": {"name": "Alice", "age": 30, "score": 1.5}}]
+}`
+
+func TestParseFormats(t *testing.T) {
+	y, err := Parse("people.yaml", []byte(yamlFixture))
+	if err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	if len(y.Nodes) != 2 || len(y.Edges) != 1 || len(y.Teardown) != 1 || y.Edges[0].Type != "KNOWS" {
+		t.Fatalf("unexpected yaml fixture: %+v", y)
+	}
+
+	j, err := Parse("people.json", []byte(jsonFixture))
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if len(j.Nodes) != 1 || j.Nodes[0].Properties["name"] != "Alice" {
+		t.Fatalf("unexpected json fixture: %+v", j)
+	}
+
+	if _, err := Parse("people.xml", nil); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestLoadCSV(t *testing.T) {
+	fsys := fstest.MapFS{
+		"people.csv": {Data: []byte(":id,:labels,name,age,active\nalice,Person;Admin,Alice,30,true\nbob,Person,Bob,,false\n")},
+		"knows.csv":  {Data: []byte(":from,:to,:type,weight\nalice,bob,KNOWS,0.5\n")},
+	}
+	f, err := Load(fsys, "people.csv", "knows.csv")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	alice := f.Nodes[0]
+	if alice.ID != "alice" || !reflect.DeepEqual(alice.Labels, []string{"Person", "Admin"}) {
+		t.Fatalf("unexpected node: %+v", alice)
+	}
+	want := map[string]any{"name": "Alice", "age": int64(30), "active": true}
+	if !reflect.DeepEqual(alice.Properties, want) {
+		t.Fatalf("properties = %v, want %v", alice.Properties, want)
+	}
+	if _, ok := f.Nodes[1].Properties["age"]; ok {
+		t.Fatal("empty CSV field should be omitted")
+	}
+	if len(f.Edges) != 1 || f.Edges[0].Properties["weight"] != 0.5 {
+		t.Fatalf("unexpected edges: %+v", f.Edges)
+	}
+}
+
+func TestInsertStatement(t *testing.T) {
+	f, err := Parse("people.yaml", []byte(yamlFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, params, err := insertStatement(f)
+	if err != nil {
+		t.Fatalf("insertStatement: %v", err)
+	}
+	wantStmt := "INSERT (n0:Person {age: $p0, name: $p1, tags: $p2}), (n1:Person:`Two Words`), (n0)-[:KNOWS {since: $p3}]->(n1)"
+	if stmt != wantStmt {
+		t.Fatalf("statement:\n%s\nwant:\n%s", stmt, wantStmt)
+	}
+	wantParams := map[string]any{"p0": int64(30), "p1": "Alice", "p2": []any{"a", "b"}, "p3": int64(2020)}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Fatalf("params = %v, want %v", params, wantParams)
+	}
+}
+
+func TestInsertStatementErrors(t *testing.T) {
+	tests := []struct {
+		fixture Fixture
+		want    string
+	}{
+		{Fixture{Nodes: []Node{{ID: "a"}, {ID: "a"}}}, "duplicate node id"},
+		{Fixture{Nodes: []Node{{ID: "a"}}, Edges: []Edge{{From: "a", To: "b", Type: "R"}}}, `unknown node "b"`},
+		{Fixture{Nodes: []Node{{ID: "a"}}, Edges: []Edge{{From: "a", To: "a"}}}, "missing type"},
+		{Fixture{Nodes: []Node{{ID: "a", Properties: map[string]any{"m": map[string]any{}}}}}, "unsupported value"},
+	}
+	for _, tt := range tests {
+		if _, _, err := insertStatement(&tt.fixture); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("insertStatement(%+v) error = %v, want %q", tt.fixture, err, tt.want)
+		}
+	}
+}
+
+// startTestServer runs the test server binary, skipping the test if it has
+// not been built.
+func startTestServer(t *testing.T) string {
+	t.Helper()
+	binary := filepath.Join("..", "..", "target", "release", "gwp-test-server")
+	if _, err := os.Stat(binary); err != nil {
+		t.Skip("gwp-test-server not built")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	cmd := exec.Command(binary, fmt.Sprint(port))
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	ctx := context.Background()
+	conn, err := gwp.Connect(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	if err := conn.WaitUntilReady(ctx, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func TestInTransactionAndCommit(t *testing.T) {
+	addr := startTestServer(t)
+	ctx := context.Background()
+	conn, err := gwp.Connect(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Parse("people.yaml", []byte(yamlFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("InTransaction", func(t *testing.T) {
+		tx := InTransaction(t, session, f)
+		cursor, err := tx.Execute(ctx, "MATCH (p:Person) RETURN p.name", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rows, err := cursor.CollectRows(); err != nil || len(rows) == 0 {
+			t.Fatalf("rows = %v, %v", rows, err)
+		}
+	})
+	t.Run("Commit", func(t *testing.T) {
+		Commit(t, session, f)
+	})
+}
@@ -0,0 +1,101 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// StopRows is returned by a ForEach callback to stop reading rows without
+// reporting an error.
+var StopRows = errors.New("gwp: stop rows")
+
+// ForEach calls fn for each remaining row of the current result set as
+// frames arrive. If fn returns an error, or ctx is done, ForEach cancels
+// the stream so the server stops producing rows and returns the error, or
+// nil for StopRows. The cursor is finished afterwards and has no summary;
+// cancelling a statement may abort its transaction.
+//
+// With WithRowLease the record's values are only valid until fn returns.
+func (c *ResultCursor) ForEach(ctx context.Context, fn func(*Record) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			c.stop(err)
+			return err
+		}
+		r, err := c.NextRecord()
+		if err != nil {
+			return err
+		}
+		if r == nil {
+			return nil
+		}
+		if err := fn(r); err != nil {
+			c.stop(context.Canceled)
+			if errors.Is(err, StopRows) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// stop abandons the rest of the stream, cancelling it, and runs the
+// completion hooks with err.
+func (c *ResultCursor) stop(err error) {
+	if c.done && c.pending == nil {
+		return
+	}
+	c.skipBuffered()
+	c.pending = nil
+	c.pendingErr = nil
+	c.done = true
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.finish(err)
+}
+
+// Chan streams the remaining rows of the current result set over a channel
+// with the given buffer size, for feeding goroutine pipelines. Rows are read
+// from the stream only as the consumer keeps up, so at most buffer rows
+// and one frame are held at a time.
+//
+// The error channel receives the stream error, or ctx.Err() if ctx is done
+// before the rows are delivered, in which case the stream is cancelled. Both
+// channels are closed when delivery ends; the cursor must not be used until
+// then. Rows are copied in WithRowLease mode.
+func (c *ResultCursor) Chan(ctx context.Context, buffer int) (<-chan []any, <-chan error) {
+	rows := make(chan []any, buffer)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(rows)
+		for {
+			if err := ctx.Err(); err != nil {
+				c.stop(err)
+				errc <- err
+				return
+			}
+			row, err := c.NextRow()
+			if err != nil {
+				errc <- err
+				return
+			}
+			if row == nil {
+				return
+			}
+			if c.lease {
+				row = slices.Clone(row)
+			}
+			select {
+			case rows <- row:
+			case <-ctx.Done():
+				c.stop(ctx.Err())
+				errc <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return rows, errc
+}
@@ -0,0 +1,118 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func forEachFrames() *fakeStream {
+	return &fakeStream{frames: []*pb.ExecuteResponse{
+		headerFrame("name"),
+		batchFrame([]any{"Alice"}, []any{"Bob"}),
+		batchFrame([]any{"Carol"}),
+		summaryFrame(Success, 0),
+	}}
+}
+
+func TestForEach(t *testing.T) {
+	ctx := context.Background()
+	var names []any
+	c := newResultCursor(forEachFrames())
+	err := c.ForEach(ctx, func(r *Record) error {
+		names = append(names, r.MustGet("name"))
+		return nil
+	})
+	if err != nil || len(names) != 3 || names[2] != "Carol" {
+		t.Fatalf("ForEach = %v, %v", names, err)
+	}
+	if s, err := c.Summary(); err != nil || s == nil {
+		t.Fatalf("Summary = %v, %v", s, err)
+	}
+}
+
+func TestForEachStop(t *testing.T) {
+	ctx := context.Background()
+	boom := errors.New("boom")
+	for _, stopWith := range []error{StopRows, boom} {
+		stream := forEachFrames()
+		c := newResultCursor(stream)
+		cancelled := false
+		c.cancel = func() { cancelled = true }
+		var finished error
+		c.onDone = append(c.onDone, func(err error) { finished = err })
+
+		seen := 0
+		err := c.ForEach(ctx, func(r *Record) error {
+			seen++
+			return stopWith
+		})
+		if stopWith == StopRows {
+			if err != nil {
+				t.Fatalf("ForEach with StopRows = %v", err)
+			}
+		} else if !errors.Is(err, boom) {
+			t.Fatalf("ForEach = %v, want %v", err, boom)
+		}
+		if seen != 1 || !cancelled || !errors.Is(finished, context.Canceled) {
+			t.Fatalf("seen = %d, cancelled = %v, finished = %v", seen, cancelled, finished)
+		}
+		if len(stream.frames) != 2 {
+			t.Fatalf("%d frames left unread, want 2", len(stream.frames))
+		}
+		if row, err := c.NextRow(); row != nil || err != nil {
+			t.Fatalf("NextRow after stop = %v, %v", row, err)
+		}
+	}
+}
+
+func TestForEachContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newResultCursor(forEachFrames())
+	err := c.ForEach(ctx, func(r *Record) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ForEach = %v, want context.Canceled", err)
+	}
+}
+
+func TestChan(t *testing.T) {
+	ctx := context.Background()
+	c := newResultCursor(forEachFrames())
+	c.lease = true
+	rows, errc := c.Chan(ctx, 1)
+	var got [][]any
+	for row := range rows {
+		got = append(got, row)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0][0] != "Alice" || got[2][0] != "Carol" {
+		t.Fatalf("rows = %v", got)
+	}
+}
+
+func TestChanBackpressure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := forEachFrames()
+	c := newResultCursor(stream)
+	rows, errc := c.Chan(ctx, 0)
+
+	if row := <-rows; row[0] != "Alice" {
+		t.Fatalf("first row = %v", row)
+	}
+	cancel()
+	for range rows {
+	}
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if len(stream.frames) != 2 {
+		t.Fatalf("%d frames left unread, want 2", len(stream.frames))
+	}
+}
@@ -0,0 +1,250 @@
+// Package gonumgraph adapts query results to gonum.org/v1/gonum/graph, so
+// gonum's centrality, community and path algorithms run client-side on the
+// nodes and edges a query returned.
+//
+//	rows, err := cursor.CollectRows()
+//	if err != nil {
+//	    return err
+//	}
+//	nodes, edges := gonumgraph.Elements(rows)
+//	g, err := gonumgraph.Directed(nodes, edges, gonumgraph.Options{
+//	    Weight: gonumgraph.WeightProperty("cost", 1),
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	ranks := network.PageRank(g, 0.85, 1e-6)
+//
+// Nodes get gonum IDs in the order given; NodeID maps a GQL element ID to
+// its gonum ID and Node recovers the GqlNode behind a gonum node.
+package gonumgraph
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// Options control how edges become gonum edges.
+type Options struct {
+	// Weight returns the weight of an edge. Nil weighs every edge 1.
+	Weight func(*gwp.GqlEdge) float64
+}
+
+// WeightProperty returns a Weight function reading the named numeric
+// property, or fallback where it is missing or not a number.
+func WeightProperty(name string, fallback float64) func(*gwp.GqlEdge) float64 {
+	return func(e *gwp.GqlEdge) float64 {
+		v, _ := e.Property(name)
+		switch v := v.(type) {
+		case int64:
+			return float64(v)
+		case uint64:
+			return float64(v)
+		case float64:
+			return v
+		default:
+			return fallback
+		}
+	}
+}
+
+// Node is a gonum node carrying the GQL node it was built from.
+type Node struct {
+	*gwp.GqlNode
+	id int64
+}
+
+// ID returns the node's gonum ID.
+func (n *Node) ID() int64 {
+	return n.id
+}
+
+// Edge is a gonum edge carrying the GQL edges between its endpoints. GQL
+// allows several edges between two nodes and gonum graphs do not, so they
+// share one Edge whose weight is the sum of theirs.
+type Edge struct {
+	F, T  *Node
+	Edges []*gwp.GqlEdge
+	W     float64
+}
+
+// From returns the edge's source node.
+func (e *Edge) From() graph.Node { return e.F }
+
+// To returns the edge's target node.
+func (e *Edge) To() graph.Node { return e.T }
+
+// ReversedEdge returns the edge with its endpoints swapped.
+func (e *Edge) ReversedEdge() graph.Edge {
+	return &Edge{F: e.T, T: e.F, Edges: e.Edges, W: e.W}
+}
+
+// Weight returns the edge's weight.
+func (e *Edge) Weight() float64 { return e.W }
+
+// DirectedGraph is a weighted directed gonum graph of query results.
+// Undirected GQL edges are added in both directions.
+type DirectedGraph struct {
+	*simple.WeightedDirectedGraph
+	ids map[gwp.ElementID]int64
+}
+
+// UndirectedGraph is a weighted undirected gonum graph of query results.
+type UndirectedGraph struct {
+	*simple.WeightedUndirectedGraph
+	ids map[gwp.ElementID]int64
+}
+
+// NodeID returns the gonum ID of the node with the given element ID.
+func (g *DirectedGraph) NodeID(id []byte) (int64, bool) {
+	n, ok := g.ids[gwp.NewElementID(id)]
+	return n, ok
+}
+
+// NodeID returns the gonum ID of the node with the given element ID.
+func (g *UndirectedGraph) NodeID(id []byte) (int64, bool) {
+	n, ok := g.ids[gwp.NewElementID(id)]
+	return n, ok
+}
+
+// Directed builds a directed graph of nodes and the edges between them.
+// It fails if an edge ends at a node not in nodes. Self-loops are dropped,
+// as gonum's graphs do not hold them.
+func Directed(nodes []*gwp.GqlNode, edges []*gwp.GqlEdge, opts Options) (*DirectedGraph, error) {
+	g := &DirectedGraph{WeightedDirectedGraph: simple.NewWeightedDirectedGraph(0, 0)}
+	set := func(e *Edge) {
+		if existing, ok := g.WeightedEdge(e.F.id, e.T.id).(*Edge); ok {
+			existing.Edges = append(existing.Edges, e.Edges...)
+			existing.W += e.W
+			return
+		}
+		g.SetWeightedEdge(e)
+	}
+	var err error
+	g.ids, err = build(nodes, edges, opts, g.AddNode, func(e *Edge, undirected bool) {
+		set(e)
+		if undirected {
+			set(&Edge{F: e.T, T: e.F, Edges: e.Edges, W: e.W})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Undirected builds an undirected graph of nodes and the edges between
+// them, ignoring edge direction. It fails like Directed.
+func Undirected(nodes []*gwp.GqlNode, edges []*gwp.GqlEdge, opts Options) (*UndirectedGraph, error) {
+	g := &UndirectedGraph{WeightedUndirectedGraph: simple.NewWeightedUndirectedGraph(0, 0)}
+	var err error
+	g.ids, err = build(nodes, edges, opts, g.AddNode, func(e *Edge, _ bool) {
+		// Store edges from the lower ID so that WeightedEdge returns the
+		// stored edge rather than a reversed copy.
+		if e.F.id > e.T.id {
+			e.F, e.T = e.T, e.F
+		}
+		if existing, ok := g.WeightedEdge(e.F.id, e.T.id).(*Edge); ok {
+			existing.Edges = append(existing.Edges, e.Edges...)
+			existing.W += e.W
+			return
+		}
+		g.SetWeightedEdge(e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// build numbers nodes, adds them with addNode and passes each edge that is
+// not a self-loop to addEdge.
+func build(nodes []*gwp.GqlNode, edges []*gwp.GqlEdge, opts Options,
+	addNode func(graph.Node), addEdge func(e *Edge, undirected bool)) (map[gwp.ElementID]int64, error) {
+	ids := make(map[gwp.ElementID]int64, len(nodes))
+	byID := make(map[gwp.ElementID]*Node, len(nodes))
+	for _, n := range nodes {
+		eid := n.ElementID()
+		if _, dup := byID[eid]; dup {
+			continue
+		}
+		node := &Node{GqlNode: n, id: int64(len(ids))}
+		ids[eid] = node.id
+		byID[eid] = node
+		addNode(node)
+	}
+	for _, e := range edges {
+		from, ok := byID[e.SourceElementID()]
+		if !ok {
+			return nil, fmt.Errorf("gonumgraph: edge %s starts at unknown node %s", e.ElementID(), e.SourceElementID())
+		}
+		to, ok := byID[e.TargetElementID()]
+		if !ok {
+			return nil, fmt.Errorf("gonumgraph: edge %s ends at unknown node %s", e.ElementID(), e.TargetElementID())
+		}
+		if from == to {
+			continue
+		}
+		w := 1.0
+		if opts.Weight != nil {
+			w = opts.Weight(e)
+		}
+		addEdge(&Edge{F: from, T: to, Edges: []*gwp.GqlEdge{e}, W: w}, e.Undirected)
+	}
+	return ids, nil
+}
+
+// Elements collects the distinct nodes and edges in result rows, including
+// those inside paths and lists, in the order they first appear.
+func Elements(rows [][]any) ([]*gwp.GqlNode, []*gwp.GqlEdge) {
+	c := &collector{
+		nodes: make(map[gwp.ElementID]bool),
+		edges: make(map[gwp.ElementID]bool),
+	}
+	for _, row := range rows {
+		for _, v := range row {
+			c.add(v)
+		}
+	}
+	return c.nodeList, c.edgeList
+}
+
+type collector struct {
+	nodes, edges map[gwp.ElementID]bool
+	nodeList     []*gwp.GqlNode
+	edgeList     []*gwp.GqlEdge
+}
+
+func (c *collector) add(v any) {
+	switch v := v.(type) {
+	case *gwp.GqlNode:
+		if id := v.ElementID(); !c.nodes[id] {
+			c.nodes[id] = true
+			c.nodeList = append(c.nodeList, v)
+		}
+	case *gwp.GqlEdge:
+		if id := v.ElementID(); !c.edges[id] {
+			c.edges[id] = true
+			c.edgeList = append(c.edgeList, v)
+		}
+	case *gwp.GqlPath:
+		for _, n := range v.Nodes {
+			c.add(n)
+		}
+		for _, e := range v.Edges {
+			c.add(e)
+		}
+	case []any:
+		for _, e := range v {
+			c.add(e)
+		}
+	case *gwp.GqlRecord:
+		for _, f := range v.Fields {
+			c.add(f.Value)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package gonumgraph
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/network"
+	"gonum.org/v1/gonum/graph/path"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+func node(id byte) *gwp.GqlNode {
+	return &gwp.GqlNode{ID: []byte{id}, Labels: []string{"City"}}
+}
+
+func edge(id, from, to byte, cost any) *gwp.GqlEdge {
+	return &gwp.GqlEdge{
+		ID: []byte{id}, Labels: []string{"ROAD"},
+		SourceNodeID: []byte{from}, TargetNodeID: []byte{to},
+		Properties: map[string]any{"cost": cost},
+	}
+}
+
+var (
+	_ graph.Directed   = (*DirectedGraph)(nil)
+	_ graph.Weighted   = (*DirectedGraph)(nil)
+	_ graph.Undirected = (*UndirectedGraph)(nil)
+)
+
+func TestDirectedShortestPath(t *testing.T) {
+	a, b, c := node(1), node(2), node(3)
+	rows := [][]any{
+		{a, edge(10, 1, 2, int64(1)), b},
+		{b, edge(11, 2, 3, 2.5), c},
+		{&gwp.GqlPath{Nodes: []*gwp.GqlNode{a, c}, Edges: []*gwp.GqlEdge{edge(12, 1, 3, int64(10))}}},
+		{[]any{edge(13, 3, 3, int64(1))}},
+	}
+	nodes, edges := Elements(rows)
+	if len(nodes) != 3 || len(edges) != 4 {
+		t.Fatalf("Elements = %d nodes, %d edges", len(nodes), len(edges))
+	}
+
+	g, err := Directed(nodes, edges, Options{Weight: WeightProperty("cost", 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, _ := g.NodeID(a.ID)
+	to, _ := g.NodeID(c.ID)
+	route, weight := path.DijkstraFrom(g.Node(from), g).To(to)
+	if weight != 3.5 || len(route) != 3 {
+		t.Fatalf("route = %v, weight %v", route, weight)
+	}
+	if mid := route[1].(*Node); !mid.HasLabel("City") || mid.ID() != 1 {
+		t.Fatalf("middle node = %+v", mid)
+	}
+	if g.HasEdgeFromTo(to, from) {
+		t.Fatal("directed graph should not have the reverse edge")
+	}
+}
+
+func TestParallelAndUndirectedEdges(t *testing.T) {
+	a, b := node(1), node(2)
+	back := edge(12, 2, 1, int64(5))
+	back.Undirected = true
+	edges := []*gwp.GqlEdge{edge(10, 1, 2, int64(1)), edge(11, 1, 2, int64(2)), back}
+
+	g, err := Directed([]*gwp.GqlNode{a, b}, edges, Options{Weight: WeightProperty("cost", 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := g.WeightedEdge(0, 1).(*Edge)
+	if len(e.Edges) != 3 || e.Weight() != 8 {
+		t.Fatalf("a->b = %d edges, weight %v", len(e.Edges), e.Weight())
+	}
+	if !g.HasEdgeFromTo(1, 0) {
+		t.Fatal("undirected edge should be added in both directions")
+	}
+
+	u, err := Undirected([]*gwp.GqlNode{a, b}, edges, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, ok := u.Weight(0, 1); !ok || w != 3 {
+		t.Fatalf("undirected weight = %v, %v", w, ok)
+	}
+	if ranks := network.PageRank(g, 0.85, 1e-6); len(ranks) != 2 {
+		t.Fatalf("PageRank = %v", ranks)
+	}
+}
+
+func TestUnknownEndpoint(t *testing.T) {
+	if _, err := Directed([]*gwp.GqlNode{node(1)}, []*gwp.GqlEdge{edge(10, 1, 2, nil)}, Options{}); err == nil {
+		t.Fatal("expected an error for an edge to a missing node")
+	}
+}
@@ -0,0 +1,219 @@
+// Package gqlresolve resolves GraphQL queries against a graph served over
+// GWP: it turns a GraphQL field selection into a single GQL statement that
+// returns only the selected properties, and batches the lookups of related
+// objects, so that a GraphQL API does not issue one statement per object.
+//
+//	schema := &gqlresolve.Schema{Types: map[string]*gqlresolve.Type{
+//	    "Person": {
+//	        Label: "Person",
+//	        Key:   "id",
+//	        Relations: map[string]*gqlresolve.Relation{
+//	            "friends": {EdgeType: "KNOWS", Target: "Person"},
+//	        },
+//	    },
+//	}}
+//
+//	// Query.people
+//	people, err := schema.Resolve(ctx, session, "Person", selection)
+//
+//	// Person.friends, with one loader per GraphQL request
+//	friends, err := loader.Load(ctx, person[gqlresolve.KeyField])
+//
+// The package depends on no GraphQL library. Resolvers convert the field
+// being resolved to a Selection: with gqlgen from graphql.CollectFields, and
+// with graphql-go from the field ASTs of ResolveInfo.
+package gqlresolve
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// KeyField holds the key property of each object returned by Resolve and
+// Loader.Load, whether or not it was selected, for loading its relations.
+const KeyField = "__key"
+
+// Executor runs statements. *gwp.GqlSession and *gwp.Transaction implement
+// it.
+type Executor interface {
+	Execute(ctx context.Context, statement string, params map[string]any, opts ...gwp.ExecuteOption) (*gwp.ResultCursor, error)
+}
+
+// Selection is a GraphQL field and the fields selected from its result.
+type Selection struct {
+	Name string
+	// Alias is the name of the field in the response. Empty means Name.
+	Alias string
+	// Args are the field's arguments. For Resolve they filter the objects
+	// by the equally named properties.
+	Args       map[string]any
+	Selections []Selection
+}
+
+// ResponseName returns the name of the field in the response.
+func (s Selection) ResponseName() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// Schema maps GraphQL object types to the nodes they are read from.
+type Schema struct {
+	// Types are keyed by GraphQL type name.
+	Types map[string]*Type
+}
+
+// Type maps a GraphQL object type to nodes with a label. Its fields are
+// relations if listed in Relations and otherwise properties.
+type Type struct {
+	Label string
+	// Key is the property identifying a node. Its values must be strings,
+	// numbers or booleans.
+	Key string
+	// Properties maps field names to property keys. Fields not listed read
+	// the property of the same name.
+	Properties map[string]string
+	// Relations are the fields holding related objects, keyed by field
+	// name. They are resolved with a Loader.
+	Relations map[string]*Relation
+}
+
+// Direction is the direction of a relation's edges.
+type Direction int
+
+const (
+	// Outgoing edges lead from the object to the related ones.
+	Outgoing Direction = iota
+	Incoming
+	// Either follows edges in both directions.
+	Either
+)
+
+// Relation is a field holding the objects connected by edges of a type.
+type Relation struct {
+	EdgeType  string
+	Direction Direction
+	// Target is the GraphQL type of the related objects.
+	Target string
+}
+
+func (s *Schema) lookup(typeName string) (*Type, error) {
+	t, ok := s.Types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("gqlresolve: unknown type %s", typeName)
+	}
+	if t.Label == "" || t.Key == "" {
+		return nil, fmt.Errorf("gqlresolve: type %s needs a label and a key", typeName)
+	}
+	return t, nil
+}
+
+func (t *Type) property(field string) string {
+	if p, ok := t.Properties[field]; ok {
+		return p
+	}
+	return field
+}
+
+// returnItems returns the RETURN items of the node variable v projected to
+// the properties selected in fields, with the key first.
+func (t *Type) returnItems(v string, fields []Selection) []string {
+	items := []string{v + "." + gwp.EscapeIdentifier(t.Key) + " AS " + gwp.EscapeIdentifier(KeyField)}
+	seen := map[string]bool{KeyField: true}
+	for _, f := range fields {
+		name := f.ResponseName()
+		if _, isRelation := t.Relations[f.Name]; isRelation || strings.HasPrefix(f.Name, "__") || seen[name] {
+			continue
+		}
+		seen[name] = true
+		items = append(items, v+"."+gwp.EscapeIdentifier(t.property(f.Name))+" AS "+gwp.EscapeIdentifier(name))
+	}
+	return items
+}
+
+// Query returns the statement reading the objects of typeName that sel
+// selects: the nodes whose properties equal sel's arguments, projected to
+// the selected properties. Relation fields are left to a Loader.
+func (s *Schema) Query(typeName string, sel Selection) (string, map[string]any, error) {
+	t, err := s.lookup(typeName)
+	if err != nil {
+		return "", nil, err
+	}
+	args := make([]string, 0, len(sel.Args))
+	for name := range sel.Args {
+		args = append(args, name)
+	}
+	sort.Strings(args)
+	params := make(map[string]any, len(args))
+	filters := make([]string, len(args))
+	for i, name := range args {
+		param := fmt.Sprintf("a%d", i)
+		filters[i] = gwp.EscapeIdentifier(t.property(name)) + ": $" + param
+		params[param] = sel.Args[name]
+	}
+	var b strings.Builder
+	b.WriteString("MATCH (n:" + gwp.EscapeIdentifier(t.Label))
+	if len(filters) > 0 {
+		b.WriteString(" {" + strings.Join(filters, ", ") + "}")
+	}
+	b.WriteString(") RETURN " + strings.Join(t.returnItems("n", sel.Selections), ", "))
+	return b.String(), params, nil
+}
+
+// Resolve runs the statement Query returns and returns one object per
+// node, holding the selected properties by response name and the key in
+// KeyField.
+func (s *Schema) Resolve(ctx context.Context, ex Executor, typeName string, sel Selection) ([]map[string]any, error) {
+	statement, params, err := s.Query(typeName, sel)
+	if err != nil {
+		return nil, err
+	}
+	columns, rows, err := query(ctx, ex, statement, params)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		objects[i] = object(columns, row, 0)
+	}
+	return objects, nil
+}
+
+// object converts the columns of row from start to an object.
+func object(columns []string, row []any, start int) map[string]any {
+	o := make(map[string]any, len(columns)-start)
+	for i := start; i < len(columns) && i < len(row); i++ {
+		o[columns[i]] = row[i]
+	}
+	return o
+}
+
+func query(ctx context.Context, ex Executor, statement string, params map[string]any) ([]string, [][]any, error) {
+	cursor, err := ex.Execute(ctx, statement, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	columns, err := cursor.ColumnNames()
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := cursor.CollectRows()
+	if err != nil {
+		return nil, nil, err
+	}
+	summary, err := cursor.Summary()
+	if err != nil {
+		return nil, nil, err
+	}
+	if summary != nil {
+		if err := summary.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return columns, rows, nil
+}
@@ -0,0 +1,219 @@
+package gqlresolve
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// tableServer records the statements it receives and answers each with the
+// columns and rows respond returns.
+type tableServer struct {
+	pb.UnimplementedSessionServiceServer
+	pb.UnimplementedGqlServiceServer
+
+	respond func(statement string, params map[string]*pb.Value) ([]string, [][]*pb.Value)
+
+	mu         sync.Mutex
+	statements []string
+}
+
+func (s *tableServer) Handshake(ctx context.Context, r *pb.HandshakeRequest) (*pb.HandshakeResponse, error) {
+	return &pb.HandshakeResponse{ProtocolVersion: 1, SessionId: "s1"}, nil
+}
+
+func (s *tableServer) Close(ctx context.Context, r *pb.CloseRequest) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
+
+func (s *tableServer) Execute(r *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	s.mu.Lock()
+	s.statements = append(s.statements, r.Statement)
+	s.mu.Unlock()
+
+	columns, rows := s.respond(r.Statement, r.Parameters)
+	header := &pb.ResultHeader{}
+	for _, c := range columns {
+		header.Columns = append(header.Columns, &pb.ColumnDescriptor{Name: c})
+	}
+	batch := &pb.RowBatch{}
+	for _, row := range rows {
+		batch.Rows = append(batch.Rows, &pb.Row{Values: row})
+	}
+	for _, f := range []*pb.ExecuteResponse{
+		{Frame: &pb.ExecuteResponse_Header{Header: header}},
+		{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: batch}},
+		{Frame: &pb.ExecuteResponse_Summary{Summary: &pb.ResultSummary{Status: &pb.GqlStatus{Code: gwp.Success}}}},
+	} {
+		if err := stream.Send(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func session(t *testing.T, srv *tableServer) *gwp.GqlSession {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	pb.RegisterSessionServiceServer(g, srv)
+	pb.RegisterGqlServiceServer(g, srv)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	ctx := context.Background()
+	conn, err := gwp.ConnectWithConfig(ctx, "bufnet", gwp.ConnectionConfig{
+		Dialer: func(ctx context.Context, addr string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+	s, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func str(s string) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_StringValue{StringValue: s}}
+}
+
+func integer(n int64) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: n}}
+}
+
+var schema = &Schema{Types: map[string]*Type{
+	"Person": {
+		Label:      "Person",
+		Key:        "id",
+		Properties: map[string]string{"fullName": "name"},
+		Relations: map[string]*Relation{
+			"friends":  {EdgeType: "KNOWS", Target: "Person"},
+			"employer": {EdgeType: "EMPLOYS", Direction: Incoming, Target: "Company"},
+		},
+	},
+	"Company": {Label: "Company", Key: "name"},
+}}
+
+func TestQuery(t *testing.T) {
+	statement, params, err := schema.Query("Person", Selection{
+		Name: "people",
+		Args: map[string]any{"fullName": "Alice"},
+		Selections: []Selection{
+			{Name: "__typename"},
+			{Name: "fullName", Alias: "name"},
+			{Name: "age"},
+			{Name: "friends", Selections: []Selection{{Name: "age"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "MATCH (n:Person {name: $a0}) RETURN n.id AS __key, n.name AS name, n.age AS age"
+	if statement != want {
+		t.Errorf("statement = %q, want %q", statement, want)
+	}
+	if params["a0"] != "Alice" {
+		t.Errorf("params = %v", params)
+	}
+	if _, _, err := schema.Query("Robot", Selection{}); err == nil {
+		t.Error("unknown type accepted")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	srv := &tableServer{respond: func(string, map[string]*pb.Value) ([]string, [][]*pb.Value) {
+		return []string{"__key", "name"}, [][]*pb.Value{{integer(1), str("Alice")}, {integer(2), str("Bob")}}
+	}}
+	people, err := schema.Resolve(context.Background(), session(t, srv), "Person",
+		Selection{Name: "people", Selections: []Selection{{Name: "name"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 2 || people[1]["name"] != "Bob" || people[1][KeyField] != int64(2) {
+		t.Fatalf("people = %v", people)
+	}
+}
+
+func TestLoaderBatches(t *testing.T) {
+	// Person n knows persons 10n and 10n+1.
+	srv := &tableServer{respond: func(statement string, params map[string]*pb.Value) ([]string, [][]*pb.Value) {
+		var rows [][]*pb.Value
+		for _, k := range params["keys"].GetListValue().GetElements() {
+			n := k.GetIntegerValue()
+			rows = append(rows, []*pb.Value{integer(n), integer(10 * n)}, []*pb.Value{integer(n), integer(10*n + 1)})
+		}
+		return []string{"__parent", "__key"}, rows
+	}}
+	s := session(t, srv)
+	loader, err := schema.NewLoader(s, "Person", "friends", Selection{Name: "friends"}, LoaderConfig{MaxBatch: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]map[string]any, 5)
+	errs := make([]error, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = loader.Load(context.Background(), i+1)
+		}()
+	}
+	wg.Wait()
+	for i, friends := range results {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if len(friends) != 2 || friends[1][KeyField] != int64(10*(i+1)+1) {
+			t.Errorf("friends of %d = %v", i+1, friends)
+		}
+	}
+	if len(srv.statements) != 1 {
+		t.Fatalf("ran %d statements, want 1", len(srv.statements))
+	}
+	want := "MATCH (p:Person)-[:KNOWS]->(n:Person) WHERE p.id IN $keys RETURN p.id AS __parent, n.id AS __key"
+	if srv.statements[0] != want {
+		t.Errorf("statement = %q, want %q", srv.statements[0], want)
+	}
+
+	if _, err := loader.Load(context.Background(), int64(3)); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.statements) != 1 {
+		t.Error("cached key read again")
+	}
+}
+
+func TestLoaderIncoming(t *testing.T) {
+	srv := &tableServer{respond: func(string, map[string]*pb.Value) ([]string, [][]*pb.Value) {
+		return []string{"__parent", "__key"}, nil
+	}}
+	loader, err := schema.NewLoader(session(t, srv), "Person", "employer", Selection{Name: "employer"}, LoaderConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	employer, err := loader.Load(context.Background(), "x")
+	if err != nil || len(employer) != 0 {
+		t.Fatalf("Load = %v, %v", employer, err)
+	}
+	want := "MATCH (p:Person)<-[:EMPLOYS]-(n:Company) WHERE p.id IN $keys RETURN p.id AS __parent, n.name AS __key"
+	if srv.statements[0] != want {
+		t.Errorf("statement = %q, want %q", srv.statements[0], want)
+	}
+	if _, err := loader.Load(context.Background(), []byte("x")); err == nil {
+		t.Error("unsupported key type accepted")
+	}
+}
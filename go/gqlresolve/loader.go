@@ -0,0 +1,195 @@
+package gqlresolve
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// parentField holds the key of the object a related object was loaded for.
+const parentField = "__parent"
+
+// LoaderConfig controls a Loader.
+type LoaderConfig struct {
+	// Wait is how long a Loader collects keys before reading the objects
+	// related to them. Defaults to one millisecond.
+	Wait time.Duration
+	// MaxBatch is the number of keys read at once, without waiting
+	// further. Defaults to 100.
+	MaxBatch int
+}
+
+// Loader reads a relation of many objects with one statement: the keys
+// passed to Load by concurrent resolvers within LoaderConfig.Wait are read
+// together, and the results are kept, so that each key is read once. Use
+// one Loader per GraphQL request, as results are not refreshed.
+type Loader struct {
+	ex        Executor
+	statement string
+	config    LoaderConfig
+
+	mu      sync.Mutex
+	pending *batch
+	cache   map[any][]map[string]any
+}
+
+type batch struct {
+	// ctx is the context of the first Load, without its cancellation, so
+	// that a cancelled resolver does not fail the others.
+	ctx   context.Context
+	keys  []any
+	seen  map[any]bool
+	timer *time.Timer
+	once  sync.Once
+	done  chan struct{}
+	// results are set before done is closed.
+	results map[any][]map[string]any
+	err     error
+}
+
+// NewLoader returns a Loader reading the relation field of typeName,
+// projected to the fields sel selects from the related objects.
+func (s *Schema) NewLoader(ex Executor, typeName, field string, sel Selection, config LoaderConfig) (*Loader, error) {
+	parent, err := s.lookup(typeName)
+	if err != nil {
+		return nil, err
+	}
+	rel, ok := parent.Relations[field]
+	if !ok {
+		return nil, fmt.Errorf("gqlresolve: type %s has no relation %s", typeName, field)
+	}
+	target, err := s.lookup(rel.Target)
+	if err != nil {
+		return nil, err
+	}
+	if config.Wait <= 0 {
+		config.Wait = time.Millisecond
+	}
+	if config.MaxBatch <= 0 {
+		config.MaxBatch = 100
+	}
+
+	edge := "[:" + gwp.EscapeIdentifier(rel.EdgeType) + "]"
+	switch rel.Direction {
+	case Incoming:
+		edge = "<-" + edge + "-"
+	case Either:
+		edge = "-" + edge + "-"
+	default:
+		edge = "-" + edge + "->"
+	}
+	items := append([]string{"p." + gwp.EscapeIdentifier(parent.Key) + " AS " + gwp.EscapeIdentifier(parentField)},
+		target.returnItems("n", sel.Selections)...)
+	statement := fmt.Sprintf("MATCH (p:%s)%s(n:%s) WHERE p.%s IN $keys RETURN %s",
+		gwp.EscapeIdentifier(parent.Label), edge, gwp.EscapeIdentifier(target.Label),
+		gwp.EscapeIdentifier(parent.Key), strings.Join(items, ", "))
+	return &Loader{ex: ex, statement: statement, config: config, cache: make(map[any][]map[string]any)}, nil
+}
+
+// Load returns the objects related to the object with key, each holding
+// the selected properties by response name and its own key in KeyField.
+func (l *Loader) Load(ctx context.Context, key any) ([]map[string]any, error) {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	if objects, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		return objects, nil
+	}
+	b := l.pending
+	if b == nil {
+		b = &batch{ctx: context.WithoutCancel(ctx), seen: make(map[any]bool), done: make(chan struct{})}
+		b.timer = time.AfterFunc(l.config.Wait, func() { l.dispatch(b) })
+		l.pending = b
+	}
+	if !b.seen[key] {
+		b.seen[key] = true
+		b.keys = append(b.keys, key)
+	}
+	full := len(b.keys) >= l.config.MaxBatch
+	l.mu.Unlock()
+	if full {
+		b.timer.Stop()
+		go l.dispatch(b)
+	}
+
+	select {
+	case <-b.done:
+		if b.err != nil {
+			return nil, b.err
+		}
+		return b.results[key], nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// dispatch reads the objects related to the keys of b, once.
+func (l *Loader) dispatch(b *batch) {
+	b.once.Do(func() {
+		l.mu.Lock()
+		if l.pending == b {
+			l.pending = nil
+		}
+		keys := b.keys
+		l.mu.Unlock()
+
+		b.results, b.err = l.read(b.ctx, keys)
+		if b.err == nil {
+			l.mu.Lock()
+			for _, key := range keys {
+				l.cache[key] = b.results[key]
+			}
+			l.mu.Unlock()
+		}
+		close(b.done)
+	})
+}
+
+func (l *Loader) read(ctx context.Context, keys []any) (map[any][]map[string]any, error) {
+	columns, rows, err := query(ctx, l.ex, l.statement, map[string]any{"keys": keys})
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[any][]map[string]any, len(keys))
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		parent, err := normalizeKey(row[0])
+		if err != nil {
+			return nil, err
+		}
+		results[parent] = append(results[parent], object(columns, row, 1))
+	}
+	return results, nil
+}
+
+// normalizeKey converts a key to the type it is read back as, so that keys
+// passed to Load match those in results.
+func normalizeKey(key any) (any, error) {
+	v := reflect.ValueOf(key)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := v.Uint(); n <= 1<<63-1 {
+			return int64(n), nil
+		}
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	}
+	return nil, fmt.Errorf("gqlresolve: unsupported key type %T", key)
+}
@@ -12,5 +12,15 @@
 //	    fmt.Println(row)
 //	}
 //	session.Close(ctx)
-//	conn.Close()
+//	conn.Close(ctx)
+//
+// # Compression
+//
+// Importing this package registers gRPC compressors for CompressionGzip and
+// CompressionZstd. gRPC keeps one compressor per name for the whole process,
+// so they are also used by, and advertised to servers on, every other gRPC
+// connection and server in the program. A "zstd" compressor registered before
+// this package is initialized is kept; one registered later, for example in
+// the application's own init function, replaces this package's for all
+// connections, including GWP ones.
 package gwp
@@ -0,0 +1,59 @@
+package gwp
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// HealthCheck reports whether the server is serving, returning nil if it
+// is. It queries the standard grpc.health.v1 service and, for servers that
+// do not implement it, falls back to pinging a short-lived session.
+func (c *GqlConnection) HealthCheck(ctx context.Context) error {
+	resp, err := c.healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return c.pingCheck(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return &GqlError{Message: "server is not serving: " + resp.Status.String()}
+	}
+	return nil
+}
+
+func (c *GqlConnection) pingCheck(ctx context.Context) error {
+	s, err := c.CreateSession(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close(ctx)
+	_, err = s.Ping(ctx)
+	return err
+}
+
+// WaitUntilReady polls HealthCheck until the server is serving, backing off
+// from 50ms to 1s between attempts. It gives up after timeout, or when ctx
+// is done, returning the last health check error.
+func (c *GqlConnection) WaitUntilReady(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delay := 50 * time.Millisecond
+	for {
+		err := c.HealthCheck(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return &GqlError{Message: "server not ready after " + timeout.String() + ": " + err.Error()}
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, time.Second)
+	}
+}
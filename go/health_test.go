@@ -0,0 +1,53 @@
+package gwp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestHealthCheck(t *testing.T) {
+	hs := health.NewServer()
+	ctx := context.Background()
+	conn := connectBufconn(t, serveBufconn(t, hs), ConnectionConfig{})
+	if err := conn.HealthCheck(ctx); err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	if err := conn.HealthCheck(ctx); err == nil || !strings.Contains(err.Error(), "NOT_SERVING") {
+		t.Fatalf("HealthCheck error = %v, want NOT_SERVING", err)
+	}
+}
+
+func TestHealthCheckFallsBackToPing(t *testing.T) {
+	conn := connectBufconn(t, serveBufconn(t, handshakeServer{}), ConnectionConfig{})
+	if err := conn.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+	if n := conn.Stats().OpenSessions; n != 0 {
+		t.Fatalf("ping session left open: %d sessions", n)
+	}
+}
+
+func TestWaitUntilReady(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	conn := connectBufconn(t, lis, ConnectionConfig{})
+
+	ctx := context.Background()
+	if err := conn.WaitUntilReady(ctx, 100*time.Millisecond); err == nil {
+		t.Fatal("WaitUntilReady succeeded without a server")
+	}
+
+	time.AfterFunc(200*time.Millisecond, func() {
+		serve(t, lis, handshakeServer{}, health.NewServer())
+	})
+	if err := conn.WaitUntilReady(ctx, 5*time.Second); err != nil {
+		t.Fatalf("WaitUntilReady: %v", err)
+	}
+}
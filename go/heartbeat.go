@@ -0,0 +1,56 @@
+package gwp
+
+import (
+	"context"
+	"time"
+)
+
+// touch records activity on the session, postponing the next heartbeat.
+func (s *GqlSession) touch() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+// startHeartbeat pings the server whenever the session has been idle for
+// interval. If the server reports the session as unknown, Ping reports it
+// as lost and the heartbeat stops. Transient errors are retried on the next
+// tick.
+func (s *GqlSession) startHeartbeat(interval time.Duration) {
+	stop := make(chan struct{})
+	s.heartbeatStop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			idle := time.Since(time.Unix(0, s.lastActivity.Load()))
+			if idle < interval {
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			_, err := s.Ping(ctx)
+			cancel()
+			if err == nil {
+				s.touch()
+				continue
+			}
+			if isSessionLost(err) {
+				return
+			}
+		}
+	}()
+}
+
+// stopHeartbeat stops the session's heartbeat, if any.
+func (s *GqlSession) stopHeartbeat() {
+	if s.heartbeatStop != nil {
+		close(s.heartbeatStop)
+		s.heartbeatStop = nil
+	}
+}
@@ -0,0 +1,141 @@
+package gwp
+
+import (
+	"context"
+	"time"
+)
+
+// hedgeCloseTimeout bounds closing the session of a hedged attempt.
+const hedgeCloseTimeout = 10 * time.Second
+
+// HedgeConfig configures ExecuteHedged.
+type HedgeConfig struct {
+	// Delay is how long to wait for a response before sending the
+	// statement to another endpoint as well. Defaults to 100 milliseconds.
+	Delay time.Duration
+	// MaxAttempts is the number of endpoints the statement is sent to at
+	// most, counting the first. Defaults to 2.
+	MaxAttempts int
+	// SessionOptions configure the session created for each attempt.
+	SessionOptions []SessionOption
+}
+
+// ExecuteHedged executes a read-only statement on an endpoint chosen for
+// reads and, if it has not responded after config.Delay, sends it to the
+// next endpoint as well. The cursor of the first attempt to respond is
+// returned and the others are cancelled. An attempt that fails starts the
+// next one at once; if all fail, the last error is returned.
+//
+// The statement may run more than once, so it must not write. Each attempt
+// runs on a session of its own, which is closed when the cursor has been
+// read to the end.
+func (c *GqlCluster) ExecuteHedged(ctx context.Context, statement string, params map[string]any, config HedgeConfig, opts ...ExecuteOption) (*ResultCursor, error) {
+	if config.Delay <= 0 {
+		config.Delay = 100 * time.Millisecond
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 2
+	}
+	members := c.candidates(AccessRead)
+	if len(members) == 0 {
+		return nil, &SessionError{Message: "no available endpoint for requested access mode"}
+	}
+	if len(members) > config.MaxAttempts {
+		members = members[:config.MaxAttempts]
+	}
+
+	results := make(chan hedgeResult, len(members))
+	cancels := make([]context.CancelFunc, 0, len(members))
+	launch := func() {
+		i := len(cancels)
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		go func() {
+			cursor, err := c.hedgeAttempt(attemptCtx, cancel, members[i], statement, params, config.SessionOptions, opts)
+			results <- hedgeResult{i, cursor, err}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(config.Delay)
+	defer timer.Stop()
+	pending := 1
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if len(cancels) < len(members) {
+				launch()
+				pending++
+				timer.Reset(config.Delay)
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				for i, cancel := range cancels {
+					if i != r.index {
+						cancel()
+					}
+				}
+				go abandonHedged(results, pending)
+				return r.cursor, nil
+			}
+			lastErr = r.err
+			if ctx.Err() == nil && len(cancels) < len(members) {
+				launch()
+				pending++
+				timer.Reset(config.Delay)
+			}
+		}
+	}
+	return nil, lastErr
+}
+
+// hedgeAttempt creates a session on m and executes the statement on it,
+// waiting for the first response. The session is closed and cancel called
+// once the cursor is done.
+func (c *GqlCluster) hedgeAttempt(ctx context.Context, cancel context.CancelFunc, m *clusterMember, statement string, params map[string]any, sessionOpts []SessionOption, opts []ExecuteOption) (*ResultCursor, error) {
+	s, err := m.conn.CreateSession(ctx, sessionOpts...)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.markFailed(m)
+		}
+		cancel()
+		return nil, err
+	}
+	c.markHealthy(m)
+	done := func(error) {
+		cancel()
+		closeCtx, closeCancel := context.WithTimeout(context.WithoutCancel(ctx), hedgeCloseTimeout)
+		defer closeCancel()
+		s.Close(closeCtx)
+	}
+
+	cursor, err := s.Execute(ctx, statement, params, opts...)
+	if err != nil {
+		done(err)
+		return nil, err
+	}
+	cursor.onDone = append(cursor.onDone, done)
+	if _, err := cursor.ColumnNames(); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+type hedgeResult struct {
+	index  int
+	cursor *ResultCursor
+	err    error
+}
+
+// abandonHedged waits for the n attempts still running after another
+// attempt won, and stops the cursors of those that responded anyway, which
+// closes their sessions.
+func abandonHedged(results <-chan hedgeResult, n int) {
+	for i := 0; i < n; i++ {
+		if r := <-results; r.cursor != nil {
+			r.cursor.stop(context.Canceled)
+		}
+	}
+}
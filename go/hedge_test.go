@@ -0,0 +1,113 @@
+package gwp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// hedgeServer answers statements with its name after delay, or fails them
+// with Unavailable if fail is set.
+type hedgeServer struct {
+	handshakeServer
+	pb.UnimplementedGqlServiceServer
+	name      string
+	delay     time.Duration
+	fail      bool
+	executed  atomic.Int32
+	cancelled chan struct{}
+}
+
+func (s *hedgeServer) Execute(r *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	s.executed.Add(1)
+	if s.fail {
+		return status.Error(codes.Unavailable, "down")
+	}
+	select {
+	case <-time.After(s.delay):
+	case <-stream.Context().Done():
+		close(s.cancelled)
+		return stream.Context().Err()
+	}
+	stream.Send(headerFrame("server"))
+	stream.Send(batchFrame([]any{s.name}))
+	return stream.Send(summaryFrame(Success, 0))
+}
+
+func hedgeCluster(t *testing.T, servers ...*hedgeServer) *GqlCluster {
+	t.Helper()
+	c := &GqlCluster{policy: RoutingPolicy{RetryAfter: time.Minute}}
+	for _, server := range servers {
+		server.cancelled = make(chan struct{})
+		conn := connectBufconn(t, serveBufconn(t, server), ConnectionConfig{})
+		c.members = append(c.members, &clusterMember{target: server.name, role: RoleReplica, conn: conn})
+	}
+	return c
+}
+
+func hedgedServer(t *testing.T, c *GqlCluster, config HedgeConfig) string {
+	t.Helper()
+	cursor, err := c.ExecuteHedged(context.Background(), "MATCH (n) RETURN n", nil, config)
+	if err != nil {
+		t.Fatalf("ExecuteHedged: %v", err)
+	}
+	name, err := cursor.ScalarString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestExecuteHedged(t *testing.T) {
+	slow := &hedgeServer{name: "slow", delay: 5 * time.Second}
+	fast := &hedgeServer{name: "fast"}
+	c := hedgeCluster(t, slow, fast)
+	c.next = 0
+
+	if got := hedgedServer(t, c, HedgeConfig{Delay: 20 * time.Millisecond}); got != "fast" {
+		t.Fatalf("answered by %q, want fast", got)
+	}
+	select {
+	case <-slow.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("losing attempt was not cancelled")
+	}
+
+	// A response within the delay is not hedged.
+	c.next = 1
+	before := slow.executed.Load()
+	if got := hedgedServer(t, c, HedgeConfig{Delay: time.Second}); got != "fast" {
+		t.Fatalf("answered by %q, want fast", got)
+	}
+	if slow.executed.Load() != before {
+		t.Fatal("statement hedged although the first endpoint answered in time")
+	}
+	if open := c.members[1].conn.Stats().OpenSessions; open != 0 {
+		t.Fatalf("%d sessions left open", open)
+	}
+}
+
+func TestExecuteHedgedFailure(t *testing.T) {
+	down := &hedgeServer{name: "down", fail: true}
+	up := &hedgeServer{name: "up"}
+	c := hedgeCluster(t, down, up)
+
+	start := time.Now()
+	if got := hedgedServer(t, c, HedgeConfig{Delay: time.Minute}); got != "up" {
+		t.Fatalf("answered by %q, want up", got)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Fatal("failed attempt did not start the next one at once")
+	}
+
+	c = hedgeCluster(t, &hedgeServer{name: "a", fail: true}, &hedgeServer{name: "b", fail: true})
+	if _, err := c.ExecuteHedged(context.Background(), "MATCH (n) RETURN n", nil, HedgeConfig{}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("all attempts failed: err = %v", err)
+	}
+}
@@ -0,0 +1,485 @@
+// Package httpgw exposes read-only query access over HTTP, for tools and
+// services that should query a graph without a GWP client of their own.
+//
+//	pool := gwp.NewPool(conn, gwp.PoolConfig{MaxSessions: 16, MaxIdle: 16})
+//	http.Handle("/query", httpgw.NewHandler(pool, httpgw.Config{Timeout: 30 * time.Second}))
+//
+// A request POSTs a JSON object with the statement and its parameters:
+//
+//	{"statement": "MATCH (p:Person {name: $name}) RETURN p.age AS age", "parameters": {"name": "Alice"}}
+//
+// The statement runs in a read-only transaction on a session from the pool.
+// Rows are streamed back as newline-delimited JSON objects keyed by column
+// name, or as CSV with a header line if the request accepts text/csv.
+//
+// Failures before the first row set the HTTP status with StatusCode and
+// return a JSON error object. Once rows have been sent the status can no
+// longer change, so the outcome is sent in the Gql-Status and Gql-Error
+// trailers, and newline-delimited JSON ends with an error object if the
+// statement failed.
+//
+// A WebSocketHandler streams results to browsers instead, with several
+// statements running at once on one connection.
+package httpgw
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// Trailers of a streamed result: StatusTrailer holds its GQLSTATUS, and
+// ErrorTrailer the error message if it failed, with or without a GQLSTATUS.
+const (
+	StatusTrailer = "Gql-Status"
+	ErrorTrailer  = "Gql-Error"
+)
+
+// Config controls a Handler.
+type Config struct {
+	// MaxBodyBytes limits the size of a request. Defaults to 1 MiB.
+	MaxBodyBytes int64
+	// Timeout bounds each statement, including the time its rows take to
+	// send. Zero means no limit beyond the request's context.
+	Timeout time.Duration
+	// FlushRows is the number of rows written between flushes to the
+	// client. Defaults to 100.
+	FlushRows int
+}
+
+// Handler is an http.Handler running the statements it receives on
+// sessions from a pool.
+type Handler struct {
+	pool   *gwp.Pool
+	config Config
+}
+
+// NewHandler returns a Handler running statements on sessions from pool.
+func NewHandler(pool *gwp.Pool, config Config) *Handler {
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 1 << 20
+	}
+	if config.FlushRows <= 0 {
+		config.FlushRows = 100
+	}
+	return &Handler{pool: pool, config: config}
+}
+
+// Request is the JSON body of a query request.
+type Request struct {
+	Statement  string         `json:"statement"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+}
+
+// errorBody is the JSON error object of a failed request.
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	// Code is the GQLSTATUS, if the server reported one.
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// badRequestError is a request that could not be read.
+type badRequestError struct {
+	status int
+	msg    string
+}
+
+func (e *badRequestError) Error() string {
+	return e.msg
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, &badRequestError{status: http.StatusMethodNotAllowed, msg: "only POST is allowed"})
+		return
+	}
+	req, err := readRequest(r, h.config.MaxBodyBytes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	if h.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.config.Timeout)
+		defer cancel()
+	}
+	session, err := h.pool.Acquire(ctx)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer h.pool.Release(context.WithoutCancel(ctx), session)
+	tx, err := session.BeginTransaction(ctx, true)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	cursor, err := tx.Execute(ctx, req.Statement, req.Parameters)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cursor.Close()
+	h.stream(w, cursor, acceptsCSV(r))
+}
+
+// readRequest decodes the request body, keeping integer parameters as
+// integers.
+func readRequest(r *http.Request, limit int64) (*Request, error) {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mt, _, _ := mime.ParseMediaType(ct); mt != "application/json" {
+			return nil, &badRequestError{status: http.StatusUnsupportedMediaType, msg: "request body must be application/json"}
+		}
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, limit))
+	dec.UseNumber()
+	var req Request
+	if err := dec.Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, &badRequestError{status: http.StatusRequestEntityTooLarge, msg: err.Error()}
+		}
+		return nil, &badRequestError{status: http.StatusBadRequest, msg: "invalid request: " + err.Error()}
+	}
+	if strings.TrimSpace(req.Statement) == "" {
+		return nil, &badRequestError{status: http.StatusBadRequest, msg: "invalid request: no statement"}
+	}
+	for name, v := range req.Parameters {
+		req.Parameters[name] = paramValue(v)
+	}
+	return &req, nil
+}
+
+// paramValue converts JSON numbers to int64 where they are integers and to
+// float64 otherwise.
+func paramValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i, e := range v {
+			v[i] = paramValue(e)
+		}
+		return v
+	case map[string]any:
+		for k, e := range v {
+			v[k] = paramValue(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func acceptsCSV(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mt, _, _ := mime.ParseMediaType(strings.TrimSpace(part)); mt == "text/csv" {
+			return true
+		}
+	}
+	return false
+}
+
+// stream writes the rows of cursor. The status is decided by the first row,
+// or by the summary if there are none.
+func (h *Handler) stream(w http.ResponseWriter, cursor *gwp.ResultCursor, csvOutput bool) {
+	columns, err := cursor.ColumnNames()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	row, err := cursor.NextRow()
+	if err == nil && row == nil {
+		err = summaryErr(cursor)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Trailer", StatusTrailer+", "+ErrorTrailer)
+	var out rowWriter
+	if csvOutput {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		out = newCSVWriter(w, columns)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		out = &ndjsonWriter{w: bufio.NewWriter(w), columns: columns}
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher := http.NewResponseController(w)
+
+	for n := 1; row != nil; n++ {
+		if err = out.row(row); err != nil {
+			var encodeErr *encodeError
+			if errors.As(err, &encodeErr) {
+				break
+			}
+			// The client has gone.
+			return
+		}
+		if n%h.config.FlushRows == 0 {
+			if out.flush() != nil || flusher.Flush() != nil {
+				return
+			}
+		}
+		if row, err = cursor.NextRow(); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = summaryErr(cursor)
+	}
+	if err != nil {
+		out.error(err)
+	}
+	out.flush()
+	if err == nil {
+		w.Header().Set(StatusTrailer, gwp.Success)
+	} else {
+		if code := statusOf(err); code != "" {
+			w.Header().Set(StatusTrailer, code)
+		}
+		w.Header().Set(ErrorTrailer, errorMessage(err))
+	}
+}
+
+func summaryErr(cursor *gwp.ResultCursor) error {
+	summary, err := cursor.Summary()
+	if err != nil || summary == nil {
+		return err
+	}
+	return summary.Err()
+}
+
+// statusOf returns the GQLSTATUS of err, or "" if it has none.
+func statusOf(err error) string {
+	var statusErr *gwp.GqlStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code
+	}
+	return ""
+}
+
+// StatusCode maps an error running a statement to an HTTP status: client
+// errors for statements the server rejects, 403 for writes refused in the
+// read-only transaction, 503 for transient failures and unavailable
+// servers, 504 for timeouts and 502 for other server failures.
+func StatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var bad *badRequestError
+	if errors.As(err, &bad) {
+		return bad.status
+	}
+	var paramErr *gwp.ParamError
+	if errors.As(err, &paramErr) {
+		return http.StatusBadRequest
+	}
+	var breakerErr *gwp.CircuitOpenError
+	var transient *gwp.TransientError
+	if errors.As(err, &breakerErr) || errors.As(err, &transient) {
+		return http.StatusServiceUnavailable
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	if code := statusOf(err); code != "" {
+		switch gwp.StatusClass(code) {
+		case "22", "42", "G2":
+			// Data exceptions, syntax errors and access rule violations,
+			// and graph type violations.
+			return http.StatusBadRequest
+		case "25":
+			// Invalid transaction state, such as a write in a read-only
+			// transaction.
+			return http.StatusForbidden
+		case "08":
+			return http.StatusServiceUnavailable
+		default:
+			return http.StatusInternalServerError
+		}
+	}
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(StatusCode(err))
+	json.NewEncoder(w).Encode(errorBody{Error: errorDetail{Code: statusOf(err), Message: errorMessage(err)}})
+}
+
+func errorMessage(err error) string {
+	var statusErr *gwp.GqlStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Message
+	}
+	return err.Error()
+}
+
+// rowWriter writes result rows in one output format.
+type rowWriter interface {
+	// row writes a row. It returns an *encodeError, having written
+	// nothing, if a value cannot be encoded, and other errors if the
+	// client has gone.
+	row(values []any) error
+	// error reports a failure after rows have been written.
+	error(err error)
+	flush() error
+}
+
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	columns []string
+	encoded [][]byte
+}
+
+// encodeError reports a result that cannot be encoded as JSON.
+type encodeError struct {
+	what string
+	err  error
+}
+
+func (e *encodeError) Error() string {
+	return "cannot encode " + e.what + " as JSON: " + e.err.Error()
+}
+
+func (e *encodeError) Unwrap() error {
+	return e.err
+}
+
+// row writes an object with the values in column order.
+func (n *ndjsonWriter) row(values []any) error {
+	if cap(n.encoded) < len(n.columns) {
+		n.encoded = make([][]byte, len(n.columns))
+	}
+	encoded := n.encoded[:len(n.columns)]
+	for i, name := range n.columns {
+		var v any
+		if i < len(values) {
+			v = values[i]
+		}
+		data, err := json.Marshal(jsonValue(v))
+		if err != nil {
+			return &encodeError{what: "column " + name, err: err}
+		}
+		encoded[i] = data
+	}
+	n.w.WriteByte('{')
+	for i, name := range n.columns {
+		if i > 0 {
+			n.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		n.w.Write(key)
+		n.w.WriteByte(':')
+		n.w.Write(encoded[i])
+	}
+	_, err := n.w.WriteString("}\n")
+	return err
+}
+
+func (n *ndjsonWriter) error(err error) {
+	data, _ := json.Marshal(errorBody{Error: errorDetail{Code: statusOf(err), Message: errorMessage(err)}})
+	n.w.Write(data)
+	n.w.WriteByte('\n')
+}
+
+func (n *ndjsonWriter) flush() error {
+	return n.w.Flush()
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) *csvWriter {
+	c := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	c.w.Write(columns)
+	return c
+}
+
+func (c *csvWriter) row(values []any) error {
+	for i := range c.record {
+		c.record[i] = ""
+		if i < len(values) {
+			c.record[i] = csvValue(values[i])
+		}
+	}
+	return c.w.Write(c.record)
+}
+
+// error leaves the failure to the trailer, as CSV has no place for it.
+func (c *csvWriter) error(error) {}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvValue formats a value as a CSV field: strings and numbers as they are,
+// null as an empty field and other values as JSON.
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	j := jsonValue(v)
+	if s, ok := j.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,286 @@
+package httpgw
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// graphServer answers statements with the frames of respond, recording the
+// transaction modes begun.
+type graphServer struct {
+	pb.UnimplementedSessionServiceServer
+	pb.UnimplementedGqlServiceServer
+
+	respond func(r *pb.ExecuteRequest) []*pb.ExecuteResponse
+
+	mu    sync.Mutex
+	modes []pb.TransactionMode
+}
+
+func (s *graphServer) Handshake(ctx context.Context, r *pb.HandshakeRequest) (*pb.HandshakeResponse, error) {
+	return &pb.HandshakeResponse{ProtocolVersion: 1, SessionId: "s1"}, nil
+}
+
+func (s *graphServer) Close(ctx context.Context, r *pb.CloseRequest) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
+
+func (s *graphServer) BeginTransaction(ctx context.Context, r *pb.BeginRequest) (*pb.BeginResponse, error) {
+	s.mu.Lock()
+	s.modes = append(s.modes, r.Mode)
+	s.mu.Unlock()
+	return &pb.BeginResponse{TransactionId: "t1", Status: &pb.GqlStatus{Code: gwp.Success}}, nil
+}
+
+func (s *graphServer) Rollback(ctx context.Context, r *pb.RollbackRequest) (*pb.RollbackResponse, error) {
+	return &pb.RollbackResponse{Status: &pb.GqlStatus{Code: gwp.Success}}, nil
+}
+
+func (s *graphServer) Execute(r *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	for _, f := range s.respond(r) {
+		if err := stream.Send(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newHandler(t *testing.T, srv *graphServer) *Handler {
+	t.Helper()
+	return NewHandler(newPool(t, srv), Config{FlushRows: 1})
+}
+
+func newPool(t *testing.T, srv *graphServer) *gwp.Pool {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	pb.RegisterSessionServiceServer(g, srv)
+	pb.RegisterGqlServiceServer(g, srv)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	ctx := context.Background()
+	conn, err := gwp.ConnectWithConfig(ctx, "bufnet", gwp.ConnectionConfig{
+		Dialer: func(ctx context.Context, addr string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+	return gwp.NewPool(conn, gwp.PoolConfig{MaxIdle: 1})
+}
+
+func header(columns ...string) *pb.ExecuteResponse {
+	h := &pb.ResultHeader{}
+	for _, c := range columns {
+		h.Columns = append(h.Columns, &pb.ColumnDescriptor{Name: c})
+	}
+	return &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_Header{Header: h}}
+}
+
+func rows(rows ...[]*pb.Value) *pb.ExecuteResponse {
+	batch := &pb.RowBatch{}
+	for _, r := range rows {
+		batch.Rows = append(batch.Rows, &pb.Row{Values: r})
+	}
+	return &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: batch}}
+}
+
+func summary(code, message string) *pb.ExecuteResponse {
+	return &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_Summary{Summary: &pb.ResultSummary{
+		Status: &pb.GqlStatus{Code: code, Message: message},
+	}}}
+}
+
+func str(s string) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_StringValue{StringValue: s}}
+}
+
+func integer(n int64) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: n}}
+}
+
+func float(f float64) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: f}}
+}
+
+func post(h http.Handler, body, accept string) *http.Response {
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func TestHandlerNDJSON(t *testing.T) {
+	var params map[string]*pb.Value
+	srv := &graphServer{respond: func(r *pb.ExecuteRequest) []*pb.ExecuteResponse {
+		params = r.Parameters
+		return []*pb.ExecuteResponse{
+			header("name", "age"),
+			rows([]*pb.Value{str("Alice"), integer(30)}, []*pb.Value{str("Bob"), integer(25)}),
+			summary(gwp.Success, ""),
+		}
+	}}
+	h := newHandler(t, srv)
+	resp := post(h, `{"statement": "MATCH (p:Person) WHERE p.age > $min RETURN p.name AS name, p.age AS age", "parameters": {"min": 18}}`, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if got := params["min"].GetIntegerValue(); got != 18 {
+		t.Errorf("min sent as %v, want integer 18", params["min"])
+	}
+	if len(srv.modes) != 1 || srv.modes[0] != pb.TransactionMode_READ_ONLY {
+		t.Errorf("transaction modes = %v, want one read-only", srv.modes)
+	}
+
+	var lines []string
+	for sc := bufio.NewScanner(resp.Body); sc.Scan(); {
+		lines = append(lines, sc.Text())
+	}
+	want := []string{`{"name":"Alice","age":30}`, `{"name":"Bob","age":25}`}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("body = %q, want %q", lines, want)
+	}
+	if got := resp.Trailer.Get(StatusTrailer); got != gwp.Success {
+		t.Errorf("status trailer = %q", got)
+	}
+}
+
+func TestHandlerNonFiniteFloats(t *testing.T) {
+	srv := &graphServer{respond: func(*pb.ExecuteRequest) []*pb.ExecuteResponse {
+		return []*pb.ExecuteResponse{
+			header("x"),
+			rows([]*pb.Value{float(math.NaN())}, []*pb.Value{float(math.Inf(1))}, []*pb.Value{float(math.Inf(-1))}, []*pb.Value{float(1.5)}),
+			summary(gwp.Success, ""),
+		}
+	}}
+	resp := post(newHandler(t, srv), `{"statement": "RETURN x"}`, "")
+	var lines []string
+	for sc := bufio.NewScanner(resp.Body); sc.Scan(); {
+		lines = append(lines, sc.Text())
+	}
+	want := []string{`{"x":"NaN"}`, `{"x":"Infinity"}`, `{"x":"-Infinity"}`, `{"x":1.5}`}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("body = %q, want %q", lines, want)
+	}
+	if got := resp.Trailer.Get(StatusTrailer); got != gwp.Success {
+		t.Errorf("status trailer = %q", got)
+	}
+}
+
+func TestHandlerCSV(t *testing.T) {
+	srv := &graphServer{respond: func(*pb.ExecuteRequest) []*pb.ExecuteResponse {
+		return []*pb.ExecuteResponse{
+			header("name", "tags"),
+			rows([]*pb.Value{str("Alice, A."), {Kind: &pb.Value_ListValue{ListValue: &pb.GqlList{Elements: []*pb.Value{str("x")}}}}}),
+			summary(gwp.Success, ""),
+		}
+	}}
+	resp := post(newHandler(t, srv), `{"statement": "MATCH (p) RETURN p.name AS name, p.tags AS tags"}`, "text/csv")
+	var body strings.Builder
+	bufio.NewReader(resp.Body).WriteTo(&body)
+	if want := "name,tags\n\"Alice, A.\",\"[\"\"x\"\"]\"\n"; body.String() != want {
+		t.Errorf("body = %q, want %q", body.String(), want)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	srv := &graphServer{respond: func(r *pb.ExecuteRequest) []*pb.ExecuteResponse {
+		switch r.Statement {
+		case "BAD":
+			return []*pb.ExecuteResponse{summary(gwp.InvalidSyntax, "syntax error")}
+		case "INSERT (:N)":
+			return []*pb.ExecuteResponse{summary("25G03", "read-only transaction")}
+		default:
+			// Fails after the first row.
+			return []*pb.ExecuteResponse{header("n"), rows([]*pb.Value{integer(1)}), summary("22012", "division by zero")}
+		}
+	}}
+	h := newHandler(t, srv)
+	for _, tc := range []struct {
+		body   string
+		status int
+		code   string
+	}{
+		{`{"statement": "BAD"}`, http.StatusBadRequest, gwp.InvalidSyntax},
+		{`{"statement": "INSERT (:N)"}`, http.StatusForbidden, "25G03"},
+		{`{"statement": ""}`, http.StatusBadRequest, ""},
+		{`not json`, http.StatusBadRequest, ""},
+	} {
+		resp := post(h, tc.body, "")
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s: status = %d, want %d", tc.body, resp.StatusCode, tc.status)
+		}
+		var e errorBody
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error.Code != tc.code || e.Error.Message == "" {
+			t.Errorf("%s: error body = %+v, %v", tc.body, e, err)
+		}
+	}
+
+	resp := post(h, `{"statement": "RETURN 1 / 0 AS n"}`, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200 once rows are sent", resp.StatusCode)
+	}
+	var lines []string
+	for sc := bufio.NewScanner(resp.Body); sc.Scan(); {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) != 2 || !strings.Contains(lines[1], `"code":"22012"`) {
+		t.Errorf("body = %q, want a row and an error", lines)
+	}
+	if got := resp.Trailer.Get(StatusTrailer); got != "22012" {
+		t.Errorf("status trailer = %q", got)
+	}
+
+	get := httptest.NewRecorder()
+	h.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/query", nil))
+	if get.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d", get.Code)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusOK},
+		{&gwp.TransientError{Code: "40001", Message: "conflict"}, http.StatusServiceUnavailable},
+		{&gwp.GqlStatusError{Code: "G2000", Message: "violation"}, http.StatusBadRequest},
+		{&gwp.GqlStatusError{Code: "XX000", Message: "internal"}, http.StatusInternalServerError},
+		{&gwp.ParamError{Path: "$x", Message: "bad"}, http.StatusBadRequest},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{status.Error(codes.Unavailable, "down"), http.StatusServiceUnavailable},
+		{errors.New("other"), http.StatusBadGateway},
+	} {
+		if got := StatusCode(tc.err); got != tc.want {
+			t.Errorf("StatusCode(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package httpgw
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// jsonValue converts a result value to plain JSON data. Graph elements
+// become objects with their IDs, labels and properties, temporal values
+// ISO 8601 strings, and NaN and infinite floats, which JSON cannot
+// represent, the strings "NaN", "Infinity" and "-Infinity".
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case nil, bool, int64, uint64, string, []byte:
+		return v
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN"
+		case math.IsInf(v, 1):
+			return "Infinity"
+		case math.IsInf(v, -1):
+			return "-Infinity"
+		}
+		return v
+	case *gwp.GqlNode:
+		return map[string]any{"id": v.ID, "labels": v.Labels, "properties": jsonMap(v.PropertyMap())}
+	case *gwp.GqlEdge:
+		return map[string]any{
+			"id": v.ID, "labels": v.Labels, "source": v.SourceNodeID, "target": v.TargetNodeID,
+			"properties": jsonMap(v.PropertyMap()),
+		}
+	case *gwp.GqlPath:
+		nodes := make([]any, len(v.Nodes))
+		for i, n := range v.Nodes {
+			nodes[i] = jsonValue(n)
+		}
+		edges := make([]any, len(v.Edges))
+		for i, e := range v.Edges {
+			edges[i] = jsonValue(e)
+		}
+		return map[string]any{"nodes": nodes, "edges": edges}
+	case *gwp.GqlRecord:
+		out := make(map[string]any, len(v.Fields))
+		for _, f := range v.Fields {
+			out[f.Name] = jsonValue(f.Value)
+		}
+		return out
+	case map[string]any:
+		return jsonMap(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = jsonValue(e)
+		}
+		return out
+	case *gwp.GqlDate:
+		return formatDate(*v)
+	case *gwp.GqlLocalTime:
+		return formatTime(*v)
+	case *gwp.GqlZonedTime:
+		return formatTime(v.Time) + formatOffset(v.OffsetMinutes)
+	case *gwp.GqlLocalDateTime:
+		return formatDate(v.Date) + "T" + formatTime(v.Time)
+	case *gwp.GqlZonedDateTime:
+		return formatDate(v.Date) + "T" + formatTime(v.Time) + formatOffset(v.OffsetMinutes)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func jsonMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = jsonValue(v)
+	}
+	return out
+}
+
+func formatDate(d gwp.GqlDate) string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+func formatTime(t gwp.GqlLocalTime) string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", t.Nanosecond), "0")
+	}
+	return s
+}
+
+func formatOffset(minutes int32) string {
+	sign := "+"
+	if minutes < 0 {
+		sign, minutes = "-", -minutes
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, minutes/60, minutes%60)
+}
@@ -0,0 +1,454 @@
+package httpgw
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// WebSocketConfig controls a WebSocketHandler.
+type WebSocketConfig struct {
+	// Authenticate is called with the upgrade request of each connection
+	// and returns the Authorizer for the statements the connection runs.
+	// An error refuses the connection with 401 Unauthorized. Nil accepts
+	// every connection, and is only allowed for read-only handlers.
+	Authenticate func(r *http.Request) (Authorizer, error)
+	// CheckOrigin reports whether a browser on the request's Origin may
+	// connect. Nil accepts requests without an Origin header and those
+	// whose origin has the request's host.
+	CheckOrigin func(r *http.Request) bool
+	// AllowWrites runs each statement in its own auto-committed
+	// transaction, so clients may change data. Otherwise statements run in
+	// read-only transactions. Writes require Authenticate.
+	AllowWrites bool
+	// Timeout bounds each statement, including the time its rows take to
+	// send. Zero means no limit.
+	Timeout time.Duration
+	// WriteTimeout closes the connection of a client that has not read a
+	// frame for this long. Zero means no limit.
+	WriteTimeout time.Duration
+	// BatchRows is the maximum number of rows per rows frame. Defaults to
+	// 100.
+	BatchRows int
+	// MaxQueries is the number of statements a connection may run at once.
+	// Defaults to 4.
+	MaxQueries int
+	// MaxMessageBytes limits the size of a client message. Defaults to
+	// 1 MiB.
+	MaxMessageBytes int
+}
+
+// Authorizer decides whether a connection may run a statement, returning
+// an error, sent to the client, if not.
+type Authorizer func(ctx context.Context, req Request) error
+
+// WebSocketHandler is an http.Handler streaming the results of statements
+// to browsers over WebSocket, for live query UIs without a server
+// component of their own.
+//
+// Clients send JSON messages naming each statement with an ID of their
+// choice, so that several can run at once on one connection:
+//
+//	{"type": "execute", "id": "q1", "statement": "MATCH (p:Person) RETURN p.name AS name", "credit": 4}
+//	{"type": "credit", "id": "q1", "batches": 4}
+//	{"type": "cancel", "id": "q1"}
+//
+// and receive a header frame with the column names, rows frames of up to
+// BatchRows rows each, and a summary frame, or an error frame at any
+// point:
+//
+//	{"type": "header", "id": "q1", "columns": ["name"]}
+//	{"type": "rows", "id": "q1", "rows": [["Alice"], ["Bob"]]}
+//	{"type": "summary", "id": "q1", "status": "00000", "rowsAffected": 0}
+//	{"type": "error", "id": "q1", "code": "42001", "message": "syntax error"}
+//
+// Rows are read from the server only as fast as the client reads them, so
+// a slow client slows its statements rather than buffering their results.
+// As browsers read every frame they are sent, an execute message may also
+// set a credit: the number of rows frames sent before the statement waits
+// for the client to grant more with credit messages. Without one, frames
+// are sent as fast as the connection allows.
+type WebSocketHandler struct {
+	pool   *gwp.Pool
+	config WebSocketConfig
+}
+
+// NewWebSocketHandler returns a WebSocketHandler running statements on
+// sessions from pool. It panics if config allows writes without
+// Authenticate.
+func NewWebSocketHandler(pool *gwp.Pool, config WebSocketConfig) *WebSocketHandler {
+	if config.AllowWrites && config.Authenticate == nil {
+		panic("httpgw: WebSocketConfig.AllowWrites requires Authenticate")
+	}
+	if config.BatchRows <= 0 {
+		config.BatchRows = 100
+	}
+	if config.MaxQueries <= 0 {
+		config.MaxQueries = 4
+	}
+	if config.MaxMessageBytes <= 0 {
+		config.MaxMessageBytes = 1 << 20
+	}
+	return &WebSocketHandler{pool: pool, config: config}
+}
+
+// clientMessage is a message from the client.
+type clientMessage struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id"`
+	Statement  string         `json:"statement"`
+	Parameters map[string]any `json:"parameters"`
+	// Credit is the number of rows frames an execute message allows
+	// before waiting for more. Zero means no limit.
+	Credit int `json:"credit"`
+	// Batches is the number of rows frames a credit message allows.
+	Batches int `json:"batches"`
+}
+
+// frame is a message to the client.
+type frame struct {
+	Type         string   `json:"type"`
+	ID           string   `json:"id,omitempty"`
+	Columns      []string `json:"columns,omitempty"`
+	Rows         [][]any  `json:"rows,omitempty"`
+	Status       string   `json:"status,omitempty"`
+	RowsAffected *int64   `json:"rowsAffected,omitempty"`
+	Code         string   `json:"code,omitempty"`
+	Message      string   `json:"message,omitempty"`
+}
+
+func errorFrame(id string, err error) frame {
+	return frame{Type: "error", ID: id, Code: statusOf(err), Message: errorMessage(err)}
+}
+
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	checkOrigin := h.config.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		writeError(w, &badRequestError{status: http.StatusForbidden, msg: "origin not allowed"})
+		return
+	}
+	var authorize Authorizer
+	if h.config.Authenticate != nil {
+		var err error
+		if authorize, err = h.config.Authenticate(r); err != nil {
+			writeError(w, &badRequestError{status: http.StatusUnauthorized, msg: err.Error()})
+			return
+		}
+	}
+	server := websocket.Server{
+		// The origin has been checked.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			ws.MaxPayloadBytes = h.config.MaxMessageBytes
+			c := &wsConn{
+				handler:   h,
+				ws:        ws,
+				authorize: authorize,
+				queries:   make(map[string]*wsQuery),
+			}
+			c.serve(r.Context())
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// sameOrigin accepts requests without an Origin header, which come from
+// clients other than browsers, and those whose origin has the request's
+// host.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// wsConn is a WebSocket connection and the statements running on it.
+type wsConn struct {
+	handler   *WebSocketHandler
+	ws        *websocket.Conn
+	authorize Authorizer
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	queries map[string]*wsQuery
+	wg      sync.WaitGroup
+}
+
+// wsQuery is a running statement.
+type wsQuery struct {
+	cancel context.CancelFunc
+
+	mu sync.Mutex
+	// credit is the number of rows frames that may be sent, or negative
+	// for no limit.
+	credit int
+	// more is signalled when credit is granted.
+	more chan struct{}
+}
+
+// serve reads client messages until the connection closes, then cancels
+// the statements still running.
+func (c *wsConn) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		c.wg.Wait()
+	}()
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(c.ws, &data); err != nil {
+			if errors.Is(err, websocket.ErrFrameTooLarge) {
+				c.send(frame{Type: "error", Message: "message too large"})
+				continue
+			}
+			return
+		}
+		msg, err := readMessage(data)
+		if err != nil {
+			c.send(frame{Type: "error", Message: "invalid message: " + err.Error()})
+			continue
+		}
+		switch msg.Type {
+		case "execute":
+			c.execute(ctx, msg)
+		case "credit":
+			if q := c.query(msg.ID); q != nil && msg.Batches > 0 {
+				q.grant(msg.Batches)
+			}
+		case "cancel":
+			if q := c.query(msg.ID); q != nil {
+				q.cancel()
+			}
+		default:
+			c.send(frame{Type: "error", ID: msg.ID, Message: "unknown message type " + msg.Type})
+		}
+	}
+}
+
+// readMessage decodes a client message, keeping integer parameters as
+// integers.
+func readMessage(data []byte) (*clientMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var msg clientMessage
+	if err := dec.Decode(&msg); err != nil {
+		return nil, err
+	}
+	for name, v := range msg.Parameters {
+		msg.Parameters[name] = paramValue(v)
+	}
+	return &msg, nil
+}
+
+func (c *wsConn) query(id string) *wsQuery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.queries[id]
+}
+
+// execute starts the statement of msg, unless the connection runs too many
+// or one with the same ID.
+func (c *wsConn) execute(ctx context.Context, msg *clientMessage) {
+	if msg.ID == "" || strings.TrimSpace(msg.Statement) == "" {
+		c.send(frame{Type: "error", ID: msg.ID, Message: "invalid message: an execute message needs an id and a statement"})
+		return
+	}
+	c.mu.Lock()
+	if _, ok := c.queries[msg.ID]; ok {
+		c.mu.Unlock()
+		c.send(frame{Type: "error", ID: msg.ID, Message: "a statement with this id is running"})
+		return
+	}
+	if len(c.queries) >= c.handler.config.MaxQueries {
+		c.mu.Unlock()
+		c.send(frame{Type: "error", ID: msg.ID, Message: "too many statements running"})
+		return
+	}
+	var cancel context.CancelFunc
+	if c.handler.config.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.handler.config.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	q := &wsQuery{cancel: cancel, credit: -1, more: make(chan struct{}, 1)}
+	if msg.Credit > 0 {
+		q.credit = msg.Credit
+	}
+	c.queries[msg.ID] = q
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.wg.Done()
+		defer func() {
+			c.mu.Lock()
+			delete(c.queries, msg.ID)
+			c.mu.Unlock()
+			cancel()
+		}()
+		if err := c.run(ctx, msg, q); err != nil {
+			c.send(errorFrame(msg.ID, err))
+		}
+	}()
+}
+
+// run runs the statement of msg and sends its results.
+func (c *wsConn) run(ctx context.Context, msg *clientMessage, q *wsQuery) error {
+	req := Request{Statement: msg.Statement, Parameters: msg.Parameters}
+	if c.authorize != nil {
+		if err := c.authorize(ctx, req); err != nil {
+			return err
+		}
+	}
+	h := c.handler
+	session, err := h.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer h.pool.Release(context.WithoutCancel(ctx), session)
+	var target gwp.Querier = session
+	if !h.config.AllowWrites {
+		tx, err := session.BeginTransaction(ctx, true)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(context.WithoutCancel(ctx))
+		target = tx
+	}
+	cursor, err := target.Execute(ctx, req.Statement, req.Parameters)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	columns, err := cursor.ColumnNames()
+	if err != nil {
+		return err
+	}
+	// A statement failing before its first row gets only an error frame.
+	row, err := cursor.NextRow()
+	if err == nil && row == nil {
+		err = summaryErr(cursor)
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.send(frame{Type: "header", ID: msg.ID, Columns: columns}); err != nil {
+		return err
+	}
+
+	batch := make([][]any, 0, h.config.BatchRows)
+	flush := func() error {
+		if err := q.take(ctx); err != nil {
+			return err
+		}
+		return c.send(frame{Type: "rows", ID: msg.ID, Rows: batch})
+	}
+	for ; row != nil; row, err = cursor.NextRow() {
+		values := make([]any, len(row))
+		for i, v := range row {
+			values[i] = jsonValue(v)
+		}
+		batch = append(batch, values)
+		if len(batch) == h.config.BatchRows {
+			if err := flush(); err != nil {
+				return err
+			}
+			batch = make([][]any, 0, h.config.BatchRows)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	summary, err := cursor.Summary()
+	if err != nil {
+		return err
+	}
+	done := frame{Type: "summary", ID: msg.ID, Status: gwp.Success}
+	if summary != nil {
+		if err := summary.Err(); err != nil {
+			return err
+		}
+		if code := summary.StatusCode(); code != "" {
+			done.Status = code
+		}
+		n := summary.RowsAffected()
+		done.RowsAffected = &n
+	}
+	return c.send(done)
+}
+
+// send writes a frame, blocking while the client is not reading. A frame
+// that cannot be encoded is not sent, and leaves the connection open.
+func (c *wsConn) send(f frame) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return &encodeError{what: f.Type + " frame", err: err}
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if d := c.handler.config.WriteTimeout; d > 0 {
+		c.ws.SetWriteDeadline(time.Now().Add(d))
+	}
+	err = websocket.Message.Send(c.ws, string(data))
+	if err != nil {
+		// A client that is not reading is disconnected, ending serve.
+		c.ws.Close()
+	}
+	return err
+}
+
+// grant allows n more rows frames.
+func (q *wsQuery) grant(n int) {
+	q.mu.Lock()
+	if q.credit >= 0 {
+		q.credit += n
+	}
+	q.mu.Unlock()
+	select {
+	case q.more <- struct{}{}:
+	default:
+	}
+}
+
+// take waits until a rows frame may be sent and uses up its credit.
+func (q *wsQuery) take(ctx context.Context) error {
+	for {
+		q.mu.Lock()
+		switch {
+		case q.credit < 0:
+			q.mu.Unlock()
+			return nil
+		case q.credit > 0:
+			q.credit--
+			q.mu.Unlock()
+			return nil
+		}
+		q.mu.Unlock()
+		select {
+		case <-q.more:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
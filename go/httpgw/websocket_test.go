@@ -0,0 +1,215 @@
+package httpgw
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func dialBridge(t *testing.T, h http.Handler, origin string) (*websocket.Conn, error) {
+	t.Helper()
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	if origin == "" {
+		origin = ts.URL
+	}
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), "", origin)
+	if err == nil {
+		t.Cleanup(func() { ws.Close() })
+	}
+	return ws, err
+}
+
+func receive(t *testing.T, ws *websocket.Conn) frame {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var f frame
+	if err := websocket.JSON.Receive(ws, &f); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func peopleServer() *graphServer {
+	return &graphServer{respond: func(r *pb.ExecuteRequest) []*pb.ExecuteResponse {
+		if r.Statement == "BAD" {
+			return []*pb.ExecuteResponse{summary(gwp.InvalidSyntax, "syntax error")}
+		}
+		return []*pb.ExecuteResponse{
+			header("name"),
+			rows([]*pb.Value{str("Alice")}, []*pb.Value{str("Bob")}, []*pb.Value{str("Carol")}),
+			summary(gwp.Success, ""),
+		}
+	}}
+}
+
+func TestWebSocketStream(t *testing.T) {
+	srv := peopleServer()
+	var params map[string]*pb.Value
+	respond := srv.respond
+	srv.respond = func(r *pb.ExecuteRequest) []*pb.ExecuteResponse {
+		params = r.Parameters
+		return respond(r)
+	}
+	h := NewWebSocketHandler(newPool(t, srv), WebSocketConfig{BatchRows: 2})
+	ws, err := dialBridge(t, h, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	websocket.Message.Send(ws, `{"type": "execute", "id": "q1", "statement": "MATCH (p) RETURN p.name AS name LIMIT $n", "parameters": {"n": 3}}`)
+
+	if f := receive(t, ws); f.Type != "header" || f.ID != "q1" || len(f.Columns) != 1 || f.Columns[0] != "name" {
+		t.Fatalf("first frame = %+v, want header", f)
+	}
+	var names []any
+	for range 2 {
+		f := receive(t, ws)
+		if f.Type != "rows" {
+			t.Fatalf("frame = %+v, want rows", f)
+		}
+		for _, row := range f.Rows {
+			names = append(names, row[0])
+		}
+	}
+	if len(names) != 3 || names[0] != "Alice" || names[2] != "Carol" {
+		t.Errorf("rows = %v", names)
+	}
+	if f := receive(t, ws); f.Type != "summary" || f.Status != gwp.Success {
+		t.Errorf("last frame = %+v, want summary", f)
+	}
+	if got := params["n"].GetIntegerValue(); got != 3 {
+		t.Errorf("n sent as %v, want integer 3", params["n"])
+	}
+	if len(srv.modes) != 1 || srv.modes[0] != pb.TransactionMode_READ_ONLY {
+		t.Errorf("transaction modes = %v, want one read-only", srv.modes)
+	}
+
+	websocket.Message.Send(ws, `{"type": "execute", "id": "q2", "statement": "BAD"}`)
+	if f := receive(t, ws); f.Type != "error" || f.ID != "q2" || f.Code != gwp.InvalidSyntax {
+		t.Errorf("frame = %+v, want a syntax error", f)
+	}
+	websocket.Message.Send(ws, `{"type": "execute"`)
+	if f := receive(t, ws); f.Type != "error" || !strings.HasPrefix(f.Message, "invalid message") {
+		t.Errorf("frame = %+v, want an invalid message error", f)
+	}
+}
+
+func TestWebSocketNonFiniteFloats(t *testing.T) {
+	srv := &graphServer{respond: func(*pb.ExecuteRequest) []*pb.ExecuteResponse {
+		return []*pb.ExecuteResponse{header("x"), rows([]*pb.Value{float(math.NaN())}), summary(gwp.Success, "")}
+	}}
+	h := NewWebSocketHandler(newPool(t, srv), WebSocketConfig{})
+	ws, err := dialBridge(t, h, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"q1", "q2"} {
+		websocket.Message.Send(ws, `{"type": "execute", "id": "`+id+`", "statement": "RETURN x"}`)
+		if f := receive(t, ws); f.Type != "header" {
+			t.Fatalf("frame = %+v, want header", f)
+		}
+		if f := receive(t, ws); f.Type != "rows" || len(f.Rows) != 1 || f.Rows[0][0] != "NaN" {
+			t.Fatalf("frame = %+v, want a NaN row", f)
+		}
+		if f := receive(t, ws); f.Type != "summary" {
+			t.Fatalf("frame = %+v, want summary", f)
+		}
+	}
+}
+
+func TestWebSocketCredit(t *testing.T) {
+	h := NewWebSocketHandler(newPool(t, peopleServer()), WebSocketConfig{BatchRows: 1})
+	ws, err := dialBridge(t, h, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	websocket.Message.Send(ws, `{"type": "execute", "id": "q1", "statement": "MATCH (p) RETURN p.name AS name", "credit": 1}`)
+	if f := receive(t, ws); f.Type != "header" {
+		t.Fatalf("frame = %+v, want header", f)
+	}
+	if f := receive(t, ws); f.Type != "rows" || f.Rows[0][0] != "Alice" {
+		t.Fatalf("frame = %+v, want Alice", f)
+	}
+
+	// No more rows are sent until credit is granted.
+	ws.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	var f frame
+	if err := websocket.JSON.Receive(ws, &f); err == nil {
+		t.Fatalf("received %+v without credit", f)
+	}
+	websocket.Message.Send(ws, `{"type": "credit", "id": "q1", "batches": 2}`)
+	for _, want := range []string{"Bob", "Carol"} {
+		if f := receive(t, ws); f.Type != "rows" || f.Rows[0][0] != want {
+			t.Fatalf("frame = %+v, want %s", f, want)
+		}
+	}
+	if f := receive(t, ws); f.Type != "summary" {
+		t.Errorf("frame = %+v, want summary", f)
+	}
+}
+
+func TestWebSocketAuth(t *testing.T) {
+	srv := peopleServer()
+	pool := newPool(t, srv)
+	h := NewWebSocketHandler(pool, WebSocketConfig{
+		AllowWrites: true,
+		Authenticate: func(r *http.Request) (Authorizer, error) {
+			if r.URL.Query().Get("token") != "secret" {
+				return nil, errors.New("bad token")
+			}
+			return func(ctx context.Context, req Request) error {
+				if strings.Contains(req.Statement, "DELETE") {
+					return errors.New("not allowed")
+				}
+				return nil
+			}, nil
+		},
+	})
+	if _, err := dialBridge(t, h, ""); err == nil {
+		t.Fatal("connected without a token")
+	}
+
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/?token=secret", "", ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	websocket.Message.Send(ws, `{"type": "execute", "id": "q1", "statement": "MATCH (n) DELETE n"}`)
+	if f := receive(t, ws); f.Type != "error" || f.Message != "not allowed" {
+		t.Errorf("frame = %+v, want an authorization error", f)
+	}
+	websocket.Message.Send(ws, `{"type": "execute", "id": "q2", "statement": "MATCH (p) RETURN p.name AS name"}`)
+	for f := receive(t, ws); f.Type != "summary"; f = receive(t, ws) {
+		if f.Type == "error" {
+			t.Fatalf("frame = %+v", f)
+		}
+	}
+	if len(srv.modes) != 0 {
+		t.Errorf("transaction modes = %v, want statements outside transactions", srv.modes)
+	}
+
+	if _, err := dialBridge(t, NewWebSocketHandler(pool, WebSocketConfig{}), "http://evil.example"); err == nil {
+		t.Error("connected from another origin")
+	}
+}
+
+func TestWebSocketWritesRequireAuthenticate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("AllowWrites without Authenticate did not panic")
+		}
+	}()
+	NewWebSocketHandler(nil, WebSocketConfig{AllowWrites: true})
+}
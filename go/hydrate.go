@@ -0,0 +1,247 @@
+package gwp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CollectOption configures CollectInto.
+type CollectOption func(*collectOptions)
+
+type collectOptions struct {
+	relations map[string]bool
+}
+
+// WithRelations makes CollectInto populate the relation fields for the
+// given edge types.
+func WithRelations(edgeTypes ...string) CollectOption {
+	return func(o *collectOptions) {
+		for _, t := range edgeTypes {
+			o.relations[t] = true
+		}
+	}
+}
+
+// CollectInto reads all remaining rows and stores one struct per distinct
+// node of the first column (or first node of a path in it) in the slice dst
+// points to, in order of appearance. Properties are mapped as by Collect.
+//
+// With WithRelations, fields tagged with an edge type and a direction, as
+// in `gwp:"FRIENDS_WITH,out"` (or in, or both), are populated from the
+// edges and paths anywhere in the result: a slice of structs or struct
+// pointers receives every node the entity is connected to by such an
+// edge, and a struct pointer the only one. Related nodes are hydrated the
+// same way. Each node becomes one struct, shared through pointers, so
+// relation fields should hold pointers when the graph has cycles.
+//
+//	cursor, err := session.Execute(ctx, "MATCH p = (a:Person)-[:FRIENDS_WITH]->(:Person) RETURN p", nil)
+//	var people []*Person
+//	err = cursor.CollectInto(&people, gwp.WithRelations("FRIENDS_WITH"))
+func (c *ResultCursor) CollectInto(dst any, opts ...CollectOption) error {
+	o := &collectOptions{relations: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(o)
+	}
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return &GqlError{Message: fmt.Sprintf("CollectInto requires a pointer to a slice, got %T", dst)}
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return &GqlError{Message: "CollectInto requires a slice of structs, got " + slice.Type().String()}
+	}
+
+	rows, err := c.CollectRows()
+	if err != nil {
+		return err
+	}
+	if err := checkCursorStatus(c); err != nil {
+		return err
+	}
+
+	h := &hydrator{
+		relations: o.relations,
+		nodes:     make(map[ElementID]*GqlNode),
+		edgeIDs:   make(map[ElementID]bool),
+		built:     make(map[hydratedKey]reflect.Value),
+	}
+	var roots []ElementID
+	seenRoot := make(map[ElementID]bool)
+	for _, row := range rows {
+		for i, v := range row {
+			root := h.add(v)
+			if i == 0 && root != nil && !seenRoot[root.ElementID()] {
+				seenRoot[root.ElementID()] = true
+				roots = append(roots, root.ElementID())
+			}
+		}
+	}
+
+	out := reflect.MakeSlice(slice.Type(), len(roots), len(roots))
+	for i, id := range roots {
+		p, err := h.entity(structType, id)
+		if err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Pointer {
+			out.Index(i).Set(p)
+		} else {
+			out.Index(i).Set(p.Elem())
+		}
+	}
+	slice.Set(out)
+	return nil
+}
+
+type hydratedKey struct {
+	typ reflect.Type
+	id  ElementID
+}
+
+// hydrator builds structs from the nodes and edges of a result.
+type hydrator struct {
+	relations map[string]bool
+	nodes     map[ElementID]*GqlNode
+	edges     []*GqlEdge
+	edgeIDs   map[ElementID]bool
+	// built holds a pointer to the struct made for each node and type.
+	built map[hydratedKey]reflect.Value
+}
+
+// add records the nodes and edges in v and returns the node v starts with.
+func (h *hydrator) add(v any) *GqlNode {
+	switch t := v.(type) {
+	case *GqlNode:
+		if _, ok := h.nodes[t.ElementID()]; !ok {
+			h.nodes[t.ElementID()] = t
+		}
+		return t
+	case *GqlEdge:
+		if !h.edgeIDs[t.ElementID()] {
+			h.edgeIDs[t.ElementID()] = true
+			h.edges = append(h.edges, t)
+		}
+	case *GqlPath:
+		for _, n := range t.Nodes {
+			h.add(n)
+		}
+		for _, e := range t.Edges {
+			h.add(e)
+		}
+		if len(t.Nodes) > 0 {
+			return t.Nodes[0]
+		}
+	case []any:
+		for _, e := range t {
+			h.add(e)
+		}
+	}
+	return nil
+}
+
+// entity returns a pointer to the struct of type t for the node with id,
+// building it and its requested relations on first use.
+func (h *hydrator) entity(t reflect.Type, id ElementID) (reflect.Value, error) {
+	key := hydratedKey{t, id}
+	if p, ok := h.built[key]; ok {
+		return p, nil
+	}
+	p := reflect.New(t)
+	h.built[key] = p
+	if err := assignValue(p.Elem(), h.nodes[id]); err != nil {
+		return p, err
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		edgeType, direction, ok := relationTag(f)
+		if !ok || !f.IsExported() || !h.relations[edgeType] {
+			continue
+		}
+		if err := h.setRelation(p.Elem().Field(i), f, id, edgeType, direction); err != nil {
+			return p, &GqlError{Message: "field " + f.Name + ": " + err.Error()}
+		}
+	}
+	return p, nil
+}
+
+func (h *hydrator) setRelation(field reflect.Value, f reflect.StructField, id ElementID, edgeType, direction string) error {
+	var related []ElementID
+	seen := make(map[ElementID]bool)
+	for _, e := range h.edges {
+		if !e.HasLabel(edgeType) {
+			continue
+		}
+		src, dst := e.SourceElementID(), e.TargetElementID()
+		var other ElementID
+		switch {
+		case (direction == "out" || direction == "both" || e.Undirected) && src == id:
+			other = dst
+		case (direction == "in" || direction == "both" || e.Undirected) && dst == id:
+			other = src
+		default:
+			continue
+		}
+		if _, ok := h.nodes[other]; ok && !seen[other] {
+			seen[other] = true
+			related = append(related, other)
+		}
+	}
+
+	t := f.Type
+	many := t.Kind() == reflect.Slice
+	if many {
+		t = t.Elem()
+	}
+	pointer := t.Kind() == reflect.Pointer
+	if pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || (!many && !pointer) {
+		return &GqlError{Message: "relation field must be a struct pointer or slice, not " + f.Type.String()}
+	}
+
+	if !many {
+		switch len(related) {
+		case 0:
+			field.SetZero()
+			return nil
+		case 1:
+			p, err := h.entity(t, related[0])
+			field.Set(p)
+			return err
+		default:
+			return &GqlError{Message: fmt.Sprintf("%d related nodes for a single-valued field", len(related))}
+		}
+	}
+	out := reflect.MakeSlice(f.Type, len(related), len(related))
+	for i, other := range related {
+		p, err := h.entity(t, other)
+		if err != nil {
+			return err
+		}
+		if pointer {
+			out.Index(i).Set(p)
+		} else {
+			out.Index(i).Set(p.Elem())
+		}
+	}
+	field.Set(out)
+	return nil
+}
+
+// relationTag parses a `gwp:"EDGE_TYPE,direction"` relation field tag.
+func relationTag(f reflect.StructField) (edgeType, direction string, ok bool) {
+	edgeType, direction, _ = strings.Cut(f.Tag.Get("gwp"), ",")
+	switch direction {
+	case "out", "in", "both":
+		return edgeType, direction, edgeType != ""
+	}
+	return "", "", false
+}
@@ -0,0 +1,96 @@
+package gwp
+
+import (
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+type hydratedPerson struct {
+	Name    string
+	Friends []*hydratedPerson `gwp:"FRIENDS_WITH,out"`
+	Fans    []hydratedPerson  `gwp:"FRIENDS_WITH,in"`
+	Company *hydratedCompany  `gwp:"WORKS_AT,out"`
+}
+
+type hydratedCompany struct {
+	Name      string
+	Employees []*hydratedPerson `gwp:"WORKS_AT,in"`
+}
+
+func pbNode(id, label, name string) *pb.Node {
+	return &pb.Node{Id: []byte(id), Labels: []string{label}, Properties: map[string]*pb.Value{
+		"name": {Kind: &pb.Value_StringValue{StringValue: name}},
+	}}
+}
+
+func pbEdge(id, label, from, to string) *pb.Edge {
+	return &pb.Edge{Id: []byte(id), Labels: []string{label}, SourceNodeId: []byte(from), TargetNodeId: []byte(to)}
+}
+
+func pathRow(nodes []*pb.Node, edges []*pb.Edge) *pb.Row {
+	return &pb.Row{Values: []*pb.Value{{Kind: &pb.Value_PathValue{PathValue: &pb.Path{Nodes: nodes, Edges: edges}}}}}
+}
+
+func TestCollectInto(t *testing.T) {
+	alice, bob, carol := pbNode("a", "Person", "Alice"), pbNode("b", "Person", "Bob"), pbNode("c", "Person", "Carol")
+	acme := pbNode("x", "Company", "Acme")
+	rows := &pb.RowBatch{Rows: []*pb.Row{
+		pathRow([]*pb.Node{alice, bob}, []*pb.Edge{pbEdge("e1", "FRIENDS_WITH", "a", "b")}),
+		pathRow([]*pb.Node{alice, carol}, []*pb.Edge{pbEdge("e2", "FRIENDS_WITH", "a", "c")}),
+		pathRow([]*pb.Node{bob, alice}, []*pb.Edge{pbEdge("e3", "FRIENDS_WITH", "b", "a")}),
+		pathRow([]*pb.Node{bob, acme}, []*pb.Edge{pbEdge("e4", "WORKS_AT", "b", "x")}),
+	}}
+	c := newTestCursor(
+		headerFrame("p"),
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: rows}},
+		summaryFrame(Success, 0),
+	)
+
+	var people []*hydratedPerson
+	if err := c.CollectInto(&people, WithRelations("FRIENDS_WITH", "WORKS_AT")); err != nil {
+		t.Fatalf("CollectInto: %v", err)
+	}
+	if len(people) != 2 || people[0].Name != "Alice" || people[1].Name != "Bob" {
+		t.Fatalf("roots = %+v", people)
+	}
+	a, b := people[0], people[1]
+	if len(a.Friends) != 2 || a.Friends[0] != b || a.Friends[1].Name != "Carol" {
+		t.Fatalf("Alice's friends = %+v", a.Friends)
+	}
+	if len(b.Friends) != 1 || b.Friends[0] != a {
+		t.Fatal("Bob's friend should be the same Alice")
+	}
+	if len(a.Fans) != 1 || a.Fans[0].Name != "Bob" {
+		t.Fatalf("Alice's fans = %+v", a.Fans)
+	}
+	if b.Company == nil || b.Company.Name != "Acme" || len(b.Company.Employees) != 1 || b.Company.Employees[0] != b {
+		t.Fatalf("Bob's company = %+v", b.Company)
+	}
+	if a.Company != nil {
+		t.Fatalf("Alice's company = %+v", a.Company)
+	}
+}
+
+func TestCollectIntoWithoutRelations(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("p"),
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: &pb.RowBatch{Rows: []*pb.Row{
+			pathRow([]*pb.Node{pbNode("a", "Person", "Alice"), pbNode("b", "Person", "Bob")},
+				[]*pb.Edge{pbEdge("e1", "FRIENDS_WITH", "a", "b")}),
+		}}}},
+		summaryFrame(Success, 0),
+	)
+	var people []hydratedPerson
+	if err := c.CollectInto(&people); err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 1 || people[0].Name != "Alice" || people[0].Friends != nil {
+		t.Fatalf("people = %+v", people)
+	}
+
+	var names []string
+	if err := newTestCursor(summaryFrame(Success, 0)).CollectInto(&names); err == nil {
+		t.Fatal("expected error for a slice of non-structs")
+	}
+}
@@ -8,12 +8,26 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
 )
 
+// testEndpoint is the address of the test server, or "" if its binary has
+// not been built.
 var testEndpoint string
 
+// requireTestServer skips tests and benchmarks that need the test server
+// when it is not running.
+func requireTestServer(tb testing.TB) {
+	tb.Helper()
+	if testEndpoint == "" {
+		tb.Skip("gwp-test-server is not built")
+	}
+}
+
 func TestMain(m *testing.M) {
 	// Find the test server binary
 	repoRoot := filepath.Join("..", "")
@@ -24,7 +38,7 @@ func TestMain(m *testing.M) {
 
 	if _, err := os.Stat(binary); os.IsNotExist(err) {
 		fmt.Fprintf(os.Stderr, "gwp-test-server not found at %s, skipping integration tests\n", binary)
-		os.Exit(0)
+		os.Exit(m.Run())
 	}
 
 	// Find a free port
@@ -45,18 +59,20 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
+	testEndpoint = fmt.Sprintf("localhost:%d", port)
+
 	// Wait for server to be ready
-	deadline := time.Now().Add(10 * time.Second)
-	for time.Now().Before(deadline) {
-		conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), 500*time.Millisecond)
-		if err == nil {
-			conn.Close()
-			break
-		}
-		time.Sleep(100 * time.Millisecond)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err == nil {
+		err = conn.WaitUntilReady(ctx, 10*time.Second)
+		conn.Close(ctx)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "test server not ready: %v\n", err)
+		cmd.Process.Kill()
+		os.Exit(1)
 	}
-
-	testEndpoint = fmt.Sprintf("localhost:%d", port)
 
 	code := m.Run()
 
@@ -67,12 +83,13 @@ func TestMain(m *testing.M) {
 }
 
 func TestConnectAndCreateSession(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -86,12 +103,13 @@ func TestConnectAndCreateSession(t *testing.T) {
 }
 
 func TestPing(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -109,12 +127,13 @@ func TestPing(t *testing.T) {
 }
 
 func TestSetGraphSchemaTimeZone(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -137,12 +156,13 @@ func TestSetGraphSchemaTimeZone(t *testing.T) {
 }
 
 func TestMatchQuery(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -179,12 +199,13 @@ func TestMatchQuery(t *testing.T) {
 }
 
 func TestDDLOmittedResult(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -207,12 +228,13 @@ func TestDDLOmittedResult(t *testing.T) {
 }
 
 func TestDMLRowsAffected(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -240,12 +262,13 @@ func TestDMLRowsAffected(t *testing.T) {
 }
 
 func TestIsSuccessOnMatch(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -268,12 +291,13 @@ func TestIsSuccessOnMatch(t *testing.T) {
 }
 
 func TestTransactionCommit(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -298,12 +322,13 @@ func TestTransactionCommit(t *testing.T) {
 }
 
 func TestTransactionRollback(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -328,12 +353,13 @@ func TestTransactionRollback(t *testing.T) {
 }
 
 func TestTransactionMatchQuery(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -365,12 +391,13 @@ func TestTransactionMatchQuery(t *testing.T) {
 }
 
 func TestRollbackAfterCommit(t *testing.T) {
+	requireTestServer(t)
 	ctx := context.Background()
 	conn, err := Connect(ctx, testEndpoint)
 	if err != nil {
 		t.Fatalf("Connect: %v", err)
 	}
-	defer conn.Close()
+	defer conn.Close(ctx)
 
 	session, err := conn.CreateSession(ctx)
 	if err != nil {
@@ -392,3 +419,243 @@ func TestRollbackAfterCommit(t *testing.T) {
 		t.Fatalf("Rollback after commit: %v", err)
 	}
 }
+
+func TestIntrospect(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	defer session.Close(ctx)
+
+	desc, err := session.Introspect(ctx)
+	if err != nil {
+		t.Fatalf("Introspect: %v", err)
+	}
+	if len(desc.GraphTypes) != 1 || desc.GraphTypes[0].Name != "PersonGraph" {
+		t.Fatalf("expected graph type PersonGraph, got %v", desc.GraphTypes)
+	}
+}
+
+func TestPoolReusesSessions(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	pool := NewPool(conn, PoolConfig{MaxSessions: 2, MaxIdle: 1})
+	defer pool.Close(ctx)
+
+	s1, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if _, err := s1.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if err := pool.Release(ctx, s1); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	s2, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if s2 != s1 {
+		t.Fatal("expected idle session to be reused")
+	}
+	if err := pool.Release(ctx, s2); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+}
+
+func TestClusterSessions(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	cluster, err := ConnectCluster(ctx, []Endpoint{
+		{Target: testEndpoint, Role: RolePrimary},
+		{Target: testEndpoint},
+	}, RoutingPolicy{})
+	if err != nil {
+		t.Fatalf("ConnectCluster: %v", err)
+	}
+	defer cluster.Close(ctx)
+
+	if eps := cluster.Endpoints(); eps[1].Role != RoleAny {
+		t.Fatalf("expected undiscovered role, got %q", eps[1].Role)
+	}
+
+	for _, mode := range []AccessMode{AccessWrite, AccessRead} {
+		session, err := cluster.CreateSession(ctx, mode)
+		if err != nil {
+			t.Fatalf("CreateSession(%d): %v", mode, err)
+		}
+		session.Close(ctx)
+	}
+}
+
+func TestHeartbeatDetectsSessionLoss(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	lost := make(chan error, 1)
+	conn, err := ConnectWithConfig(ctx, testEndpoint, ConnectionConfig{
+		KeepaliveTime:     time.Minute,
+		HeartbeatInterval: 20 * time.Millisecond,
+		OnSessionLost: func(_ *GqlSession, err error) {
+			lost <- err
+		},
+	})
+	if err != nil {
+		t.Fatalf("ConnectWithConfig: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	defer session.Close(ctx)
+
+	// Simulate the server reaping the session.
+	if _, err := conn.sessionClient.Close(ctx, &pb.CloseRequest{SessionId: session.SessionID()}); err != nil {
+		t.Fatalf("server-side Close: %v", err)
+	}
+
+	select {
+	case <-lost:
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat did not report session loss")
+	}
+}
+
+// TestSessionConcurrentUse is meant to be run with -race.
+func TestSessionConcurrentUse(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	session.SetNotificationHandler(func(Notification) {})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			cursor, err := session.Execute(ctx, "MATCH (n) RETURN n", nil)
+			if err == nil {
+				_, err = cursor.CollectRows()
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			errs <- session.SetSchema(ctx, "default")
+		}()
+		go func() {
+			defer wg.Done()
+			tx, err := session.BeginTransaction(ctx, true)
+			if err == nil {
+				err = tx.Commit(ctx)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent operation: %v", err)
+		}
+	}
+
+	wg.Add(2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer wg.Done()
+			session.Close(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if _, err := session.Execute(ctx, "MATCH (n) RETURN n", nil); err == nil {
+		t.Fatal("expected error executing on closed session")
+	}
+}
+
+func TestConnectionCloseClosesSessions(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	open, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	closed, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if err := closed.Close(ctx); err != nil {
+		t.Fatalf("session Close: %v", err)
+	}
+
+	if err := conn.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !open.isClosed() {
+		t.Fatal("expected open session to be closed with the connection")
+	}
+	if _, err := open.Execute(ctx, "MATCH (n) RETURN n", nil); err == nil {
+		t.Fatal("expected error executing after connection close")
+	}
+}
+
+func TestExecuteRawFrames(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	defer session.Close(ctx)
+
+	cursor, err := session.Execute(ctx, "MATCH (n:Person) RETURN n.name, n.age", nil, WithRawFrames())
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	batch, err := cursor.NextRawBatch()
+	if err != nil {
+		t.Fatalf("NextRawBatch: %v", err)
+	}
+	if batch == nil || batch.Rows[0].Values[0].GetStringValue() != "Alice" {
+		t.Fatalf("unexpected batch: %v", batch)
+	}
+}
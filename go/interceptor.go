@@ -0,0 +1,105 @@
+package gwp
+
+import (
+	"context"
+	"time"
+)
+
+// StatementInfo describes a statement passed to interceptors.
+type StatementInfo struct {
+	SessionID string
+	// TransactionID is empty for statements outside an explicit transaction.
+	TransactionID string
+	// QueryName is the registered name of a statement run with
+	// ExecuteNamed, and empty otherwise.
+	QueryName string
+	// Graph and Schema are the per-statement overrides set with WithGraph
+	// and WithSchema, and empty otherwise.
+	Graph  string
+	Schema string
+	// QueryID is the ID set with WithQueryID, if any.
+	QueryID string
+	// Annotations are the headers set with WithApplicationName,
+	// WithRequestID, WithPriority, WithQueue and WithAnnotation, keyed by
+	// header name. BeforeExecute may change them, as it may Statement
+	// and Params.
+	Annotations map[string]string
+	Statement   string
+	Params      map[string]any
+}
+
+// StatementResult describes how a statement completed.
+type StatementResult struct {
+	// Duration runs from sending the statement until its result stream
+	// completed or failed.
+	Duration time.Duration
+	// Rows is the number of result rows received.
+	Rows int64
+	// Frames is the number of response frames received.
+	Frames int64
+	// Summary is nil if the statement failed before its summary arrived.
+	Summary *ResultSummary
+	Err     error
+}
+
+// StatementInterceptor observes or alters statements executed on a session,
+// for audit logging, query rewriting, parameter scrubbing or metrics.
+type StatementInterceptor interface {
+	// BeforeExecute is called before the statement is sent. It may modify
+	// info.Statement and info.Params, and returns the context to use for
+	// the call. Returning an error aborts the statement.
+	BeforeExecute(ctx context.Context, info *StatementInfo) (context.Context, error)
+	// AfterExecute is called once the statement completes: when its cursor
+	// reaches the end of the result, when the stream fails, when the cursor
+	// is closed or cancelled, or when the statement could not be started. A
+	// cursor closed or cancelled early reports a codes.Canceled error. It is
+	// not called for a cursor that is dropped without being read to the end,
+	// closed or cancelled.
+	AfterExecute(ctx context.Context, info *StatementInfo, result StatementResult)
+}
+
+// StatementInterceptorFuncs adapts a pair of functions to a
+// StatementInterceptor. Either may be nil.
+type StatementInterceptorFuncs struct {
+	Before func(ctx context.Context, info *StatementInfo) (context.Context, error)
+	After  func(ctx context.Context, info *StatementInfo, result StatementResult)
+}
+
+// BeforeExecute calls f.Before, if set.
+func (f StatementInterceptorFuncs) BeforeExecute(ctx context.Context, info *StatementInfo) (context.Context, error) {
+	if f.Before == nil {
+		return ctx, nil
+	}
+	return f.Before(ctx, info)
+}
+
+// AfterExecute calls f.After, if set.
+func (f StatementInterceptorFuncs) AfterExecute(ctx context.Context, info *StatementInfo, result StatementResult) {
+	if f.After != nil {
+		f.After(ctx, info, result)
+	}
+}
+
+// interceptorChain runs interceptors in order before a statement and in
+// reverse order after it.
+type interceptorChain []StatementInterceptor
+
+// before runs BeforeExecute on each interceptor. If one fails, AfterExecute
+// is run for those that already ran and the error is returned.
+func (ch interceptorChain) before(ctx context.Context, info *StatementInfo, start time.Time) (context.Context, error) {
+	for i, ic := range ch {
+		next, err := ic.BeforeExecute(ctx, info)
+		if err != nil {
+			ch[:i].after(ctx, info, StatementResult{Duration: time.Since(start), Err: err})
+			return ctx, err
+		}
+		ctx = next
+	}
+	return ctx, nil
+}
+
+func (ch interceptorChain) after(ctx context.Context, info *StatementInfo, result StatementResult) {
+	for i := len(ch) - 1; i >= 0; i-- {
+		ch[i].AfterExecute(ctx, info, result)
+	}
+}
@@ -0,0 +1,159 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+type recordingInterceptor struct {
+	name  string
+	log   *[]string
+	fail  error
+	after []StatementResult
+}
+
+func (r *recordingInterceptor) BeforeExecute(ctx context.Context, info *StatementInfo) (context.Context, error) {
+	*r.log = append(*r.log, "before "+r.name)
+	return ctx, r.fail
+}
+
+func (r *recordingInterceptor) AfterExecute(ctx context.Context, info *StatementInfo, result StatementResult) {
+	*r.log = append(*r.log, "after "+r.name)
+	r.after = append(r.after, result)
+}
+
+func TestInterceptorsRewriteAndObserve(t *testing.T) {
+	client := &fakeGqlClient{stream: &fakeStream{frames: []*pb.ExecuteResponse{
+		headerFrame("n"), batchFrame([]any{int64(1)}), summaryFrame(Success, 1),
+	}}}
+	var log []string
+	outer := &recordingInterceptor{name: "outer", log: &log}
+	rewrite := StatementInterceptorFuncs{Before: func(ctx context.Context, info *StatementInfo) (context.Context, error) {
+		info.Statement = "/* app */ " + info.Statement
+		info.Params = map[string]any{"secret": "redacted"}
+		return ctx, nil
+	}}
+	inner := &recordingInterceptor{name: "inner", log: &log}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, interceptors: interceptorChain{outer, rewrite, inner}}
+
+	cursor, err := s.Execute(context.Background(), "RETURN 1", map[string]any{"secret": "hunter2"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if client.lastReq.Statement != "/* app */ RETURN 1" {
+		t.Fatalf("statement sent = %q", client.lastReq.Statement)
+	}
+	if client.lastReq.Parameters["secret"].GetStringValue() != "redacted" {
+		t.Fatalf("params sent = %v", client.lastReq.Parameters)
+	}
+	if len(inner.after) != 0 {
+		t.Fatal("AfterExecute should wait for the result to complete")
+	}
+
+	if _, err := cursor.CollectRows(); err != nil {
+		t.Fatalf("CollectRows: %v", err)
+	}
+	want := []string{"before outer", "before inner", "after inner", "after outer"}
+	if len(log) != len(want) {
+		t.Fatalf("log = %v", log)
+	}
+	for i := range want {
+		if log[i] != want[i] {
+			t.Fatalf("log = %v, want %v", log, want)
+		}
+	}
+	result := outer.after[0]
+	if result.Err != nil || result.Summary == nil || result.Summary.RowsAffected() != 1 || result.Duration <= 0 {
+		t.Fatalf("result = %+v", result)
+	}
+}
+
+func TestInterceptorAbort(t *testing.T) {
+	client := &fakeGqlClient{}
+	var log []string
+	first := &recordingInterceptor{name: "first", log: &log}
+	denied := errors.New("denied")
+	guard := &recordingInterceptor{name: "guard", log: &log, fail: denied}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, interceptors: interceptorChain{first, guard}}
+
+	if _, err := s.Execute(context.Background(), "DELETE n", nil); !errors.Is(err, denied) {
+		t.Fatalf("Execute = %v, want %v", err, denied)
+	}
+	if client.lastReq != nil {
+		t.Fatal("aborted statement should not be sent")
+	}
+	if len(first.after) != 1 || !errors.Is(first.after[0].Err, denied) || len(guard.after) != 0 {
+		t.Fatalf("after calls: first=%v guard=%v", first.after, guard.after)
+	}
+}
+
+func TestInterceptorSeesStreamError(t *testing.T) {
+	streamErr := errors.New("stream broken")
+	client := &fakeGqlClient{stream: &fakeStream{err: streamErr}}
+	var log []string
+	rec := &recordingInterceptor{name: "rec", log: &log}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, interceptors: interceptorChain{rec}}
+	tx := &Transaction{session: s, sessionID: "s1", transactionID: "tx9", gqlClient: client}
+
+	cursor, err := tx.Execute(context.Background(), "RETURN 1", nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	cursor.CollectRows()
+	if len(rec.after) != 1 || !errors.Is(rec.after[0].Err, streamErr) || rec.after[0].Summary != nil {
+		t.Fatalf("after = %+v", rec.after)
+	}
+}
+
+func TestInterceptorSeesCloseAndCancel(t *testing.T) {
+	for _, stop := range []func(*ResultCursor){(*ResultCursor).Close, (*ResultCursor).Cancel} {
+		client := &fakeGqlClient{stream: &fakeStream{frames: []*pb.ExecuteResponse{
+			headerFrame("n"), batchFrame([]any{int64(1)}, []any{int64(2)}), summaryFrame(Success, 2),
+		}}}
+		var log []string
+		rec := &recordingInterceptor{name: "rec", log: &log}
+		s := &GqlSession{sessionID: "s1", gqlClient: client, interceptors: interceptorChain{rec}}
+
+		cursor, err := s.Execute(context.Background(), "RETURN 1", nil)
+		if err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		if _, err := cursor.NextRow(); err != nil {
+			t.Fatalf("NextRow: %v", err)
+		}
+		stop(cursor)
+		stop(cursor)
+		if len(rec.after) != 1 || status.Code(rec.after[0].Err) != codes.Canceled || rec.after[0].Rows != 2 {
+			t.Fatalf("after = %+v", rec.after)
+		}
+	}
+}
+
+func TestExecuteGraphAndSchemaOverrides(t *testing.T) {
+	client := &fakeGqlClient{stream: &fakeStream{frames: []*pb.ExecuteResponse{summaryFrame(Success, 0)}}}
+	var seen StatementInfo
+	spy := StatementInterceptorFuncs{Before: func(ctx context.Context, info *StatementInfo) (context.Context, error) {
+		seen = *info
+		return ctx, nil
+	}}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, interceptors: interceptorChain{spy}}
+
+	_, err := s.Execute(context.Background(), "MATCH (n) RETURN n", nil,
+		WithGraph("social`net"), WithSchema("/prod"), WithProfile())
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := "PROFILE AT `/prod` USE `social``net` MATCH (n) RETURN n"
+	if client.lastReq.Statement != want {
+		t.Fatalf("statement sent = %q, want %q", client.lastReq.Statement, want)
+	}
+	if seen.Graph != "social`net" || seen.Schema != "/prod" || seen.Statement != "MATCH (n) RETURN n" {
+		t.Fatalf("interceptor saw %+v", seen)
+	}
+}
@@ -0,0 +1,172 @@
+package gwp
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// PropertyInfo describes a property observed on a node label or edge type.
+type PropertyInfo struct {
+	Name      string
+	Types     []string
+	Mandatory bool
+}
+
+// LabelInfo describes a node label and its properties.
+type LabelInfo struct {
+	Name       string
+	Count      int64
+	Properties []PropertyInfo
+}
+
+// EdgeTypeInfo describes an edge type, the labels it connects, and its properties.
+type EdgeTypeInfo struct {
+	Name         string
+	Count        int64
+	SourceLabels []string
+	TargetLabels []string
+	Properties   []PropertyInfo
+}
+
+// SchemaDescription is the introspected shape of the current graph.
+type SchemaDescription struct {
+	Labels     []LabelInfo
+	EdgeTypes  []EdgeTypeInfo
+	GraphTypes []GraphTypeInfo
+}
+
+// Label returns the label with the given name, or nil if not found.
+func (d *SchemaDescription) Label(name string) *LabelInfo {
+	for i := range d.Labels {
+		if d.Labels[i].Name == name {
+			return &d.Labels[i]
+		}
+	}
+	return nil
+}
+
+// EdgeType returns the edge type with the given name, or nil if not found.
+func (d *SchemaDescription) EdgeType(name string) *EdgeTypeInfo {
+	for i := range d.EdgeTypes {
+		if d.EdgeTypes[i].Name == name {
+			return &d.EdgeTypes[i]
+		}
+	}
+	return nil
+}
+
+// Introspect describes the current graph: node labels and edge types with
+// their properties, plus the graph types in the current schema.
+//
+// Labels and edge types are read with SHOW NODE TYPES and SHOW EDGE TYPES,
+// one row per (type, property) pair. Graph types come from the catalog service.
+func (s *GqlSession) Introspect(ctx context.Context) (*SchemaDescription, error) {
+	nodeRows, err := s.queryCatalogRows(ctx, "SHOW NODE TYPES")
+	if err != nil {
+		return nil, err
+	}
+	edgeRows, err := s.queryCatalogRows(ctx, "SHOW EDGE TYPES")
+	if err != nil {
+		return nil, err
+	}
+
+	desc := &SchemaDescription{}
+
+	labels := make(map[string]*LabelInfo)
+	var labelOrder []string
+	for _, row := range nodeRows {
+		name := firstString(row["label"])
+		if name == "" {
+			continue
+		}
+		info, ok := labels[name]
+		if !ok {
+			info = &LabelInfo{Name: name}
+			labels[name] = info
+			labelOrder = append(labelOrder, name)
+		}
+		if n, ok := row["count"].(int64); ok {
+			info.Count = n
+		}
+		if prop, ok := propertyFromRow(row); ok {
+			info.Properties = append(info.Properties, prop)
+		}
+	}
+	for _, name := range labelOrder {
+		desc.Labels = append(desc.Labels, *labels[name])
+	}
+
+	edges := make(map[string]*EdgeTypeInfo)
+	var edgeOrder []string
+	for _, row := range edgeRows {
+		name := firstString(row["label"])
+		if name == "" {
+			continue
+		}
+		info, ok := edges[name]
+		if !ok {
+			info = &EdgeTypeInfo{Name: name}
+			edges[name] = info
+			edgeOrder = append(edgeOrder, name)
+		}
+		if n, ok := row["count"].(int64); ok {
+			info.Count = n
+		}
+		info.SourceLabels = mergeStrings(info.SourceLabels, stringList(row["source"]))
+		info.TargetLabels = mergeStrings(info.TargetLabels, stringList(row["target"]))
+		if prop, ok := propertyFromRow(row); ok {
+			info.Properties = append(info.Properties, prop)
+		}
+	}
+	for _, name := range edgeOrder {
+		desc.EdgeTypes = append(desc.EdgeTypes, *edges[name])
+	}
+
+	resp, err := s.catalogClient.ListGraphTypes(ctx, &pb.ListGraphTypesRequest{
+		Schema: s.currentSchema(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range resp.GraphTypes {
+		desc.GraphTypes = append(desc.GraphTypes, GraphTypeInfo{Schema: t.Schema, Name: t.Name})
+	}
+
+	return desc, nil
+}
+
+func propertyFromRow(row map[string]any) (PropertyInfo, bool) {
+	name, _ := row["property"].(string)
+	if name == "" {
+		return PropertyInfo{}, false
+	}
+	mandatory, _ := row["mandatory"].(bool)
+	types := stringList(row["types"])
+	if types == nil {
+		types = stringList(row["type"])
+	}
+	for i, t := range types {
+		types[i] = strings.ToUpper(t)
+	}
+	return PropertyInfo{Name: name, Types: types, Mandatory: mandatory}, true
+}
+
+func mergeStrings(dst, src []string) []string {
+	for _, s := range src {
+		found := false
+		for _, d := range dst {
+			if d == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, s)
+		}
+	}
+	sort.Strings(dst)
+	return dst
+}
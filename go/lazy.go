@@ -0,0 +1,70 @@
+package gwp
+
+import (
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// Property returns the named property of the node and whether it is set.
+// For a node read with WithLazyProperties it decodes only that property.
+func (n *GqlNode) Property(name string) (any, bool) {
+	return property(n.Properties, n.rawProperties, name)
+}
+
+// PropertyMap returns the node's properties. For a node read with
+// WithLazyProperties it decodes them into Properties on first call. It is
+// not safe to call concurrently with itself on the same node.
+func (n *GqlNode) PropertyMap() map[string]any {
+	if n.rawProperties != nil {
+		n.Properties = propertiesFromProto(n.rawProperties)
+		n.rawProperties = nil
+	}
+	return n.Properties
+}
+
+// Property returns the named property of the edge and whether it is set.
+// For an edge read with WithLazyProperties it decodes only that property.
+func (e *GqlEdge) Property(name string) (any, bool) {
+	return property(e.Properties, e.rawProperties, name)
+}
+
+// PropertyMap returns the edge's properties. For an edge read with
+// WithLazyProperties it decodes them into Properties on first call. It is
+// not safe to call concurrently with itself on the same edge.
+func (e *GqlEdge) PropertyMap() map[string]any {
+	if e.rawProperties != nil {
+		e.Properties = propertiesFromProto(e.rawProperties)
+		e.rawProperties = nil
+	}
+	return e.Properties
+}
+
+func property(props map[string]any, raw map[string]*pb.Value, name string) (any, bool) {
+	if raw != nil {
+		v, ok := raw[name]
+		if !ok {
+			return nil, false
+		}
+		return valueFromProto(v), true
+	}
+	v, ok := props[name]
+	return v, ok
+}
+
+// decode converts a value of column i read by the cursor, honoring
+// WithLazyProperties and ConnectionConfig.StrictConversion.
+func (c *ResultCursor) decode(i int, v *pb.Value) (any, error) {
+	d := valueDecoder{lazy: c.lazyProperties, lenient: !c.strictConversion}
+	value := d.decode(v)
+	if d.err != nil {
+		return nil, c.unsupported(i, d.err)
+	}
+	return value, nil
+}
+
+// unsupported locates err at column i.
+func (c *ResultCursor) unsupported(i int, err *UnsupportedTypeError) error {
+	if columns := c.header.GetColumns(); i < len(columns) {
+		err.Path = columns[i].GetName()
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package gwp
+
+import (
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func nodeValue(labels []string, props map[string]any) *pb.Value {
+	pbProps := make(map[string]*pb.Value, len(props))
+	for k, v := range props {
+		pbProps[k] = mustValueToProto(v)
+	}
+	return &pb.Value{Kind: &pb.Value_NodeValue{NodeValue: &pb.Node{Id: []byte{1}, Labels: labels, Properties: pbProps}}}
+}
+
+func TestLazyProperties(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("n"),
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: &pb.RowBatch{Rows: []*pb.Row{
+			{Values: []*pb.Value{nodeValue([]string{"Person"}, map[string]any{"name": "Alice", "age": int64(30)})}},
+		}}}},
+		summaryFrame(Success, 0),
+	)
+	c.lazyProperties = true
+
+	row, err := c.NextRow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := row[0].(*GqlNode)
+	if n.Properties != nil || !n.HasLabel("Person") {
+		t.Fatalf("node = %+v, want undecoded properties", n)
+	}
+	if v, ok := n.Property("name"); !ok || v != "Alice" {
+		t.Fatalf("Property(name) = %v, %v", v, ok)
+	}
+	if _, ok := n.Property("missing"); ok {
+		t.Fatal("Property(missing) should not be set")
+	}
+	if n.Properties != nil {
+		t.Fatal("Property should not decode the whole map")
+	}
+
+	props := n.PropertyMap()
+	if props["age"] != int64(30) || n.Properties["name"] != "Alice" {
+		t.Fatalf("PropertyMap = %v", props)
+	}
+	n.Properties["age"] = int64(31)
+	if v, _ := n.Property("age"); v != int64(31) {
+		t.Fatalf("Property after mutation = %v", v)
+	}
+}
+
+func TestLazyPropertiesEqualAndScan(t *testing.T) {
+	v := nodeValue([]string{"Person"}, map[string]any{"name": "Alice"})
+	lazy := decodeValue(v, true).(*GqlNode)
+	eager := valueFromProto(v).(*GqlNode)
+	if !Equal(lazy, eager) {
+		t.Fatalf("Diff = %s", Diff(eager, decodeValue(v, true)))
+	}
+
+	var dst struct{ Name string }
+	if err := ScanValue(&dst, decodeValue(v, true)); err != nil || dst.Name != "Alice" {
+		t.Fatalf("scan = %+v, %v", dst, err)
+	}
+}
+
+func TestEagerPropertiesAccessors(t *testing.T) {
+	e := &GqlEdge{Properties: map[string]any{"since": int64(2020)}}
+	if v, ok := e.Property("since"); !ok || v != int64(2020) {
+		t.Fatalf("Property = %v, %v", v, ok)
+	}
+	if len(e.PropertyMap()) != 1 {
+		t.Fatalf("PropertyMap = %v", e.PropertyMap())
+	}
+}
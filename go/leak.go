@@ -0,0 +1,98 @@
+package gwp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sort"
+	"time"
+)
+
+// SessionLeak describes a session that was not closed within the
+// connection's SessionLeakTimeout.
+type SessionLeak struct {
+	SessionID string
+	Created   time.Time
+	// Stack is the goroutine stack of the CreateSession call.
+	Stack string
+}
+
+func (l SessionLeak) String() string {
+	return fmt.Sprintf("session %s created at %s was not closed\n%s", l.SessionID, l.Created.Format(time.RFC3339), l.Stack)
+}
+
+// PanicOnSessionLeak is an OnSessionLeak handler that panics, for making
+// leaked sessions fail a test run.
+func PanicOnSessionLeak(leak SessionLeak) {
+	panic("gwp: " + leak.String())
+}
+
+// logSessionLeak is the default OnSessionLeak handler.
+func logSessionLeak(leak SessionLeak) {
+	slog.Default().LogAttrs(context.Background(), slog.LevelWarn, "session not closed",
+		slog.String("session_id", leak.SessionID),
+		slog.Time("created", leak.Created),
+		slog.String("stack", leak.Stack),
+	)
+}
+
+// sessionLeakCheck records where a session was created and reports it if
+// it is still open when its timer fires.
+type sessionLeakCheck struct {
+	leak  SessionLeak
+	timer *time.Timer
+}
+
+// watchLeak starts leak detection for a new session.
+func (c *GqlConnection) watchLeak(s *GqlSession) {
+	report := c.config.OnSessionLeak
+	if report == nil {
+		report = logSessionLeak
+	}
+	check := &sessionLeakCheck{leak: SessionLeak{
+		SessionID: s.sessionID,
+		Created:   time.Now(),
+		Stack:     string(debug.Stack()),
+	}}
+	s.mu.Lock()
+	s.leakCheck = check
+	check.timer = time.AfterFunc(c.config.SessionLeakTimeout, func() {
+		if !s.isClosed() {
+			report(check.leak)
+		}
+	})
+	s.mu.Unlock()
+}
+
+// stopLeakCheck stops leak detection for a closed session. s.mu is held.
+func (s *GqlSession) stopLeakCheck() {
+	if s.leakCheck != nil {
+		s.leakCheck.timer.Stop()
+	}
+}
+
+// LeakedSessions returns the sessions open for longer than the
+// connection's SessionLeakTimeout, oldest first, for asserting in tests
+// that no session was leaked. It returns nil unless leak detection is
+// enabled.
+func (c *GqlConnection) LeakedSessions() []SessionLeak {
+	c.mu.Lock()
+	sessions := make([]*GqlSession, 0, len(c.sessions))
+	for s := range c.sessions {
+		sessions = append(sessions, s)
+	}
+	c.mu.Unlock()
+
+	var leaks []SessionLeak
+	for _, s := range sessions {
+		s.mu.Lock()
+		check, closed := s.leakCheck, s.closed
+		s.mu.Unlock()
+		if check != nil && !closed && time.Since(check.leak.Created) >= c.config.SessionLeakTimeout {
+			leaks = append(leaks, check.leak)
+		}
+	}
+	sort.Slice(leaks, func(i, j int) bool { return leaks[i].Created.Before(leaks[j].Created) })
+	return leaks
+}
@@ -0,0 +1,47 @@
+package gwp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionLeakDetection(t *testing.T) {
+	leaks := make(chan SessionLeak, 2)
+	conn := &GqlConnection{
+		config: ConnectionConfig{
+			SessionLeakTimeout: 20 * time.Millisecond,
+			OnSessionLeak:      func(l SessionLeak) { leaks <- l },
+		},
+		sessions: make(map[*GqlSession]struct{}),
+	}
+	leaked := &GqlSession{sessionID: "leaked", sessionClient: &versionedSessionClient{}}
+	closed := &GqlSession{sessionID: "closed", sessionClient: &versionedSessionClient{}}
+	for _, s := range []*GqlSession{leaked, closed} {
+		conn.sessions[s] = struct{}{}
+		conn.watchLeak(s)
+	}
+	if err := closed.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case l := <-leaks:
+		if l.SessionID != "leaked" || !strings.Contains(l.Stack, "TestSessionLeakDetection") {
+			t.Fatalf("leak = %s", l)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("leak not reported")
+	}
+	select {
+	case l := <-leaks:
+		t.Fatalf("closed session reported: %s", l.SessionID)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	got := conn.LeakedSessions()
+	if len(got) != 1 || got[0].SessionID != "leaked" {
+		t.Fatalf("LeakedSessions = %v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package gwp
+
+import (
+	"sync"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// rowPool holds row slices released by cursors in row lease mode.
+var rowPool = sync.Pool{
+	New: func() any {
+		row := make([]any, 0, 16)
+		return &row
+	},
+}
+
+// nextLeasedRow decodes the next row into the cursor's leased slice.
+func (c *ResultCursor) nextLeasedRow() ([]any, error) {
+	for {
+		if len(c.rawBatches) > 0 {
+			batch := c.rawBatches[0]
+			if c.leaseIndex < len(batch.Rows) {
+				return c.decodeLeasedRow(batch.Rows[c.leaseIndex].Values)
+			}
+			c.rawBatches[0] = nil
+			c.rawBatches = c.rawBatches[1:]
+			c.leaseIndex = 0
+			continue
+		}
+		if c.done {
+			c.releaseLeasedRow()
+			return nil, nil
+		}
+		if err := c.consumeUntilRowsOrDone(); err != nil {
+			c.releaseLeasedRow()
+			return nil, err
+		}
+	}
+}
+
+func (c *ResultCursor) decodeLeasedRow(values []*pb.Value) ([]any, error) {
+	if c.leaseRow == nil {
+		c.leaseRow = rowPool.Get().(*[]any)
+	}
+	start := time.Now()
+	row := (*c.leaseRow)[:0]
+	for i, v := range values {
+		value, err := c.decode(i, v)
+		if err != nil {
+			*c.leaseRow = row
+			return nil, err
+		}
+		row = append(row, value)
+	}
+	*c.leaseRow = row
+	c.leaseIndex++
+	c.recordDecode(1, start)
+	return row, nil
+}
+
+// releaseLeasedRow returns the leased slice to the pool once the cursor is
+// exhausted.
+func (c *ResultCursor) releaseLeasedRow() {
+	if c.leaseRow == nil {
+		return
+	}
+	clear((*c.leaseRow)[:cap(*c.leaseRow)])
+	*c.leaseRow = (*c.leaseRow)[:0]
+	rowPool.Put(c.leaseRow)
+	c.leaseRow = nil
+}
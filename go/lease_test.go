@@ -0,0 +1,95 @@
+package gwp
+
+import (
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func TestRowLease(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("name", "age"),
+		batchFrame([]any{"Alice", int64(30)}, []any{"Bob", int64(25)}),
+		batchFrame([]any{"Carol", int64(41)}),
+		summaryFrame(Success, 3),
+	)
+	c.lease = true
+
+	first, err := c.NextRow()
+	if err != nil || first[0] != "Alice" {
+		t.Fatalf("NextRow = %v, %v", first, err)
+	}
+	second, err := c.NextRow()
+	if err != nil || second[0] != "Bob" {
+		t.Fatalf("NextRow = %v, %v", second, err)
+	}
+	if first[0] != "Bob" {
+		t.Fatal("leased rows should share their backing slice")
+	}
+
+	rest, err := c.CollectRows()
+	if err != nil || len(rest) != 1 || rest[0][0] != "Carol" {
+		t.Fatalf("CollectRows = %v, %v", rest, err)
+	}
+	if c.leaseRow != nil {
+		t.Fatal("leased row should be released when the cursor is exhausted")
+	}
+	if n, err := c.RowsAffected(); err != nil || n != 3 {
+		t.Fatalf("RowsAffected = %d, %v", n, err)
+	}
+}
+
+func TestRowLeaseCollectCopies(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("n"),
+		batchFrame([]any{int64(1)}, []any{int64(2)}),
+		summaryFrame(Success, 0),
+	)
+	c.lease = true
+	rows, err := c.CollectRows()
+	if err != nil {
+		t.Fatalf("CollectRows: %v", err)
+	}
+	if rows[0][0] != int64(1) || rows[1][0] != int64(2) {
+		t.Fatalf("CollectRows = %v", rows)
+	}
+}
+
+func benchmarkFrames() []*pb.ExecuteResponse {
+	frames := []*pb.ExecuteResponse{headerFrame("name", "age", "score")}
+	for b := 0; b < 100; b++ {
+		rows := make([][]any, 100)
+		for i := range rows {
+			rows[i] = []any{"name", int64(i), float64(i) / 3}
+		}
+		frames = append(frames, batchFrame(rows...))
+	}
+	return append(frames, summaryFrame(Success, 0))
+}
+
+func benchmarkNextRow(b *testing.B, lease bool) {
+	frames := benchmarkFrames()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := newResultCursor(&fakeStream{frames: frames})
+		c.lease = lease
+		for {
+			row, err := c.NextRow()
+			if err != nil {
+				b.Fatal(err)
+			}
+			if row == nil {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkNextRow(b *testing.B) {
+	benchmarkNextRow(b, false)
+}
+
+func BenchmarkNextRowLease(b *testing.B) {
+	benchmarkNextRow(b, true)
+}
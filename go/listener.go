@@ -0,0 +1,120 @@
+package gwp
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+// ConnectionListener receives events about a connection's health, for
+// example to flip a readiness probe or rebuild caches when the database
+// connection degrades. Methods are called from background goroutines and
+// must not block.
+type ConnectionListener interface {
+	// StateChanged is called when the gRPC connectivity state changes.
+	// Transitions in quick succession may be reported as one change.
+	StateChanged(from, to connectivity.State)
+	// Reconnecting is called when the connection starts to re-establish a
+	// lost or failed transport. attempt counts the attempts since the
+	// connection was last ready, starting at 1.
+	Reconnecting(attempt int)
+	// SessionLost is called once per session when the server reports that
+	// it no longer knows the session, for instance after an idle timeout
+	// or a server restart.
+	SessionLost(session *GqlSession, err error)
+}
+
+// ConnectionListenerFuncs adapts functions to a ConnectionListener and a
+// BreakerListener. Nil functions are skipped.
+type ConnectionListenerFuncs struct {
+	OnStateChange  func(from, to connectivity.State)
+	OnReconnecting func(attempt int)
+	OnSessionLost  func(session *GqlSession, err error)
+
+	OnBreakerStateChange func(endpoint string, from, to BreakerState)
+}
+
+func (f ConnectionListenerFuncs) StateChanged(from, to connectivity.State) {
+	if f.OnStateChange != nil {
+		f.OnStateChange(from, to)
+	}
+}
+
+func (f ConnectionListenerFuncs) Reconnecting(attempt int) {
+	if f.OnReconnecting != nil {
+		f.OnReconnecting(attempt)
+	}
+}
+
+func (f ConnectionListenerFuncs) SessionLost(session *GqlSession, err error) {
+	if f.OnSessionLost != nil {
+		f.OnSessionLost(session, err)
+	}
+}
+
+func (f ConnectionListenerFuncs) BreakerStateChanged(endpoint string, from, to BreakerState) {
+	if f.OnBreakerStateChange != nil {
+		f.OnBreakerStateChange(endpoint, from, to)
+	}
+}
+
+// State returns the gRPC connectivity state of the connection.
+func (c *GqlConnection) State() connectivity.State {
+	return c.conn.GetState()
+}
+
+// watchState reports connectivity state transitions to the listeners until
+// the connection shuts down.
+func (c *GqlConnection) watchState() {
+	state := c.conn.GetState()
+	connected := false
+	attempt := 0
+	for c.conn.WaitForStateChange(context.Background(), state) {
+		next := c.conn.GetState()
+		for _, l := range c.config.Listeners {
+			l.StateChanged(state, next)
+		}
+		switch next {
+		case connectivity.Ready:
+			connected = true
+			attempt = 0
+		case connectivity.Connecting:
+			if connected || state == connectivity.TransientFailure {
+				attempt++
+				for _, l := range c.config.Listeners {
+					l.Reconnecting(attempt)
+				}
+			}
+		case connectivity.Shutdown:
+			return
+		}
+		state = next
+	}
+}
+
+// isSessionLost reports whether err from a session RPC means the server no
+// longer knows the session.
+func isSessionLost(err error) bool {
+	code := status.Code(err)
+	return code == codes.NotFound || code == codes.Unauthenticated
+}
+
+// checkLost reports the session as lost if err says the server no longer
+// knows it.
+func (s *GqlSession) checkLost(err error) {
+	if err == nil || !isSessionLost(err) || s.lost.Swap(true) {
+		return
+	}
+	c := s.conn
+	if c == nil {
+		return
+	}
+	if c.config.OnSessionLost != nil {
+		c.config.OnSessionLost(s, err)
+	}
+	for _, l := range c.config.Listeners {
+		l.SessionLost(s, err)
+	}
+}
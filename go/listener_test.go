@@ -0,0 +1,154 @@
+package gwp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// eventLog is a ConnectionListener recording the events it receives.
+type eventLog struct {
+	mu         sync.Mutex
+	states     []connectivity.State
+	reconnects []int
+	lost       []string
+	changed    chan struct{}
+}
+
+func newEventLog() *eventLog {
+	return &eventLog{changed: make(chan struct{}, 100)}
+}
+
+func (e *eventLog) StateChanged(from, to connectivity.State) {
+	e.mu.Lock()
+	e.states = append(e.states, to)
+	e.mu.Unlock()
+	e.changed <- struct{}{}
+}
+
+func (e *eventLog) Reconnecting(attempt int) {
+	e.mu.Lock()
+	e.reconnects = append(e.reconnects, attempt)
+	e.mu.Unlock()
+}
+
+func (e *eventLog) SessionLost(s *GqlSession, err error) {
+	e.mu.Lock()
+	e.lost = append(e.lost, s.SessionID())
+	e.mu.Unlock()
+}
+
+// waitFor waits until the listener has seen state.
+func (e *eventLog) waitFor(t *testing.T, state connectivity.State) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		e.mu.Lock()
+		for _, s := range e.states {
+			if s == state {
+				e.mu.Unlock()
+				return
+			}
+		}
+		e.mu.Unlock()
+		select {
+		case <-e.changed:
+		case <-timeout:
+			t.Fatalf("state %v not reached, saw %v", state, e.states)
+		}
+	}
+}
+
+// lostSessionServer no longer knows any session it handed out.
+type lostSessionServer struct {
+	handshakeServer
+}
+
+func (lostSessionServer) Ping(ctx context.Context, r *pb.PingRequest) (*pb.PongResponse, error) {
+	return nil, status.Error(codes.NotFound, "session not found")
+}
+
+func TestListenerStateChanges(t *testing.T) {
+	var mu sync.Mutex
+	lis := bufconn.Listen(1 << 20)
+	srv := serve(t, lis, handshakeServer{})
+	dial := func(ctx context.Context, addr string) (net.Conn, error) {
+		mu.Lock()
+		l := lis
+		mu.Unlock()
+		return l.DialContext(ctx)
+	}
+
+	events := newEventLog()
+	ctx := context.Background()
+	conn, err := ConnectWithConfig(ctx, "bufnet", ConnectionConfig{
+		Dialer:    dial,
+		Listeners: []ConnectionListener{events},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+
+	if _, err := conn.CreateSession(ctx); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	events.waitFor(t, connectivity.Ready)
+	if conn.State() != connectivity.Ready {
+		t.Fatalf("State = %v", conn.State())
+	}
+
+	// Drop the transport and bring up a new server.
+	srv.Stop()
+	mu.Lock()
+	lis = bufconn.Listen(1 << 20)
+	mu.Unlock()
+	serve(t, lis, handshakeServer{})
+	events.waitFor(t, connectivity.Idle)
+
+	if _, err := conn.CreateSession(ctx); err != nil {
+		t.Fatalf("CreateSession after reconnect: %v", err)
+	}
+	events.mu.Lock()
+	reconnects := append([]int(nil), events.reconnects...)
+	events.mu.Unlock()
+	if len(reconnects) == 0 || reconnects[0] != 1 {
+		t.Fatalf("reconnects = %v, want first attempt 1", reconnects)
+	}
+
+	conn.Close(ctx)
+	events.waitFor(t, connectivity.Shutdown)
+}
+
+func TestListenerSessionLost(t *testing.T) {
+	events := newEventLog()
+	var callbacks int
+	ctx := context.Background()
+	conn := connectBufconn(t, serveBufconn(t, lostSessionServer{}), ConnectionConfig{
+		Listeners:     []ConnectionListener{events},
+		OnSessionLost: func(*GqlSession, error) { callbacks++ },
+	})
+
+	s, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := s.Ping(ctx); status.Code(err) != codes.NotFound {
+			t.Fatalf("Ping error = %v", err)
+		}
+	}
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	if len(events.lost) != 1 || events.lost[0] != "in-process" || callbacks != 1 {
+		t.Fatalf("lost = %v, callbacks = %d; want one report", events.lost, callbacks)
+	}
+}
@@ -0,0 +1,83 @@
+package gwp
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// defaultMaxTransactionAttempts is used when
+// ConnectionConfig.MaxTransactionAttempts is zero.
+const defaultMaxTransactionAttempts = 3
+
+// TransactionWork is the unit of work run by ExecuteRead and ExecuteWrite.
+// It may be called more than once, so it should not have side effects
+// outside the transaction, and it must consume the cursors it needs before
+// returning.
+type TransactionWork func(ctx context.Context, tx *Transaction) error
+
+// ExecuteRead runs work in a read-only transaction. See ExecuteWrite.
+func (s *GqlSession) ExecuteRead(ctx context.Context, work TransactionWork) error {
+	return s.runTransaction(ctx, true, work)
+}
+
+// ExecuteWrite runs work in a read-write transaction and commits it if work
+// returns nil, or rolls it back otherwise.
+//
+// If work, or the commit, fails with an error for which IsTransient is true,
+// such as a serialization conflict or deadlock, the transaction is rolled
+// back and work is run again in a new one, up to
+// ConnectionConfig.MaxTransactionAttempts times in total with a short
+// backoff in between. The last error is returned. Statements that fail with
+// an exception status only report it in their summary, so work should
+// return ResultSummary.Err for them to be replayed.
+func (s *GqlSession) ExecuteWrite(ctx context.Context, work TransactionWork) error {
+	return s.runTransaction(ctx, false, work)
+}
+
+func (s *GqlSession) runTransaction(ctx context.Context, readOnly bool, work TransactionWork) error {
+	maxAttempts := defaultMaxTransactionAttempts
+	var onRetry func(int, error)
+	if s.conn != nil {
+		if s.conn.config.MaxTransactionAttempts > 0 {
+			maxAttempts = s.conn.config.MaxTransactionAttempts
+		}
+		onRetry = s.conn.config.OnTransactionRetry
+	}
+
+	backoff := 10 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := s.runTransactionOnce(ctx, readOnly, work)
+		if err == nil || !IsTransient(err) || attempt >= maxAttempts || ctx.Err() != nil {
+			return err
+		}
+		if onRetry != nil {
+			onRetry(attempt, err)
+		}
+
+		// Jitter the backoff so conflicting clients do not replay in step.
+		timer := time.NewTimer(backoff/2 + rand.N(backoff))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, time.Second)
+	}
+}
+
+func (s *GqlSession) runTransactionOnce(ctx context.Context, readOnly bool, work TransactionWork) error {
+	tx, err := s.BeginTransaction(ctx, readOnly)
+	if err != nil {
+		return err
+	}
+	// No-op once committed; otherwise also covers a panic in work. The
+	// rollback completes before a retry begins the next attempt.
+	defer tx.rollbackAndWait(ctx)
+
+	if err := work(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,186 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// conflictClient fails the first conflicts commits with a serialization
+// conflict. Like the server, it rejects a transaction begun while another
+// is active on the session.
+type conflictClient struct {
+	pb.GqlServiceClient
+	conflicts int
+	begins    atomic.Int32
+	commits   atomic.Int32
+	rollbacks chan string
+
+	mu     sync.Mutex
+	active string
+}
+
+func (c *conflictClient) BeginTransaction(ctx context.Context, in *pb.BeginRequest, opts ...grpc.CallOption) (*pb.BeginResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active != "" {
+		return &pb.BeginResponse{Status: &pb.GqlStatus{Code: "25001", Message: "transaction already active"}}, nil
+	}
+	c.active = fmt.Sprintf("tx%d", c.begins.Add(1))
+	return &pb.BeginResponse{TransactionId: c.active}, nil
+}
+
+func (c *conflictClient) Commit(ctx context.Context, in *pb.CommitRequest, opts ...grpc.CallOption) (*pb.CommitResponse, error) {
+	c.mu.Lock()
+	c.active = ""
+	c.mu.Unlock()
+	if int(c.commits.Add(1)) <= c.conflicts {
+		return &pb.CommitResponse{Status: &pb.GqlStatus{Code: TransactionRollback, Message: "serialization conflict"}}, nil
+	}
+	return &pb.CommitResponse{}, nil
+}
+
+func (c *conflictClient) Rollback(ctx context.Context, in *pb.RollbackRequest, opts ...grpc.CallOption) (*pb.RollbackResponse, error) {
+	// Slow enough that a replay racing the rollback would find the
+	// transaction still active.
+	time.Sleep(20 * time.Millisecond)
+	c.mu.Lock()
+	if c.active == in.TransactionId {
+		c.active = ""
+	}
+	c.mu.Unlock()
+	c.rollbacks <- in.TransactionId
+	return &pb.RollbackResponse{}, nil
+}
+
+func newConflictSession(conflicts int, config ConnectionConfig) (*GqlSession, *conflictClient) {
+	client := &conflictClient{conflicts: conflicts, rollbacks: make(chan string, 16)}
+	return &GqlSession{sessionID: "s1", gqlClient: client, conn: &GqlConnection{config: config}}, client
+}
+
+func TestExecuteWriteReplaysTransientErrors(t *testing.T) {
+	var retries []int
+	s, client := newConflictSession(2, ConnectionConfig{
+		OnTransactionRetry: func(attempt int, err error) {
+			if !IsTransient(err) {
+				t.Errorf("retry after non-transient error %v", err)
+			}
+			retries = append(retries, attempt)
+		},
+	})
+
+	var runs []string
+	err := s.ExecuteWrite(context.Background(), func(ctx context.Context, tx *Transaction) error {
+		runs = append(runs, tx.TransactionID())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWrite: %v", err)
+	}
+	if fmt.Sprint(runs) != "[tx1 tx2 tx3]" || fmt.Sprint(retries) != "[1 2]" {
+		t.Fatalf("runs = %v, retries = %v", runs, retries)
+	}
+	if client.commits.Load() != 3 {
+		t.Fatalf("commits = %d, want 3", client.commits.Load())
+	}
+}
+
+func TestExecuteWriteRollsBackBeforeReplay(t *testing.T) {
+	s, client := newConflictSession(0, ConnectionConfig{})
+	var runs []string
+	err := s.ExecuteWrite(context.Background(), func(ctx context.Context, tx *Transaction) error {
+		runs = append(runs, tx.TransactionID())
+		if len(runs) == 1 {
+			return &GqlStatusError{Code: TransactionRollback, Message: "deadlock"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWrite: %v", err)
+	}
+	if fmt.Sprint(runs) != "[tx1 tx2]" {
+		t.Fatalf("runs = %v", runs)
+	}
+	if id := <-client.rollbacks; id != "tx1" {
+		t.Fatalf("rolled back %q", id)
+	}
+}
+
+func TestExecuteWriteGivesUp(t *testing.T) {
+	s, client := newConflictSession(5, ConnectionConfig{MaxTransactionAttempts: 2})
+	err := s.ExecuteWrite(context.Background(), func(ctx context.Context, tx *Transaction) error {
+		return nil
+	})
+	var te *TransientError
+	if !errors.As(err, &te) || te.Code != TransactionRollback {
+		t.Fatalf("err = %v, want TransientError", err)
+	}
+	var se *GqlStatusError
+	if !errors.As(err, &se) {
+		t.Fatal("TransientError should unwrap to GqlStatusError")
+	}
+	if client.begins.Load() != 2 {
+		t.Fatalf("attempts = %d, want 2", client.begins.Load())
+	}
+}
+
+func TestExecuteReadDoesNotReplayOtherErrors(t *testing.T) {
+	s, client := newConflictSession(0, ConnectionConfig{})
+	boom := errors.New("boom")
+	err := s.ExecuteRead(context.Background(), func(ctx context.Context, tx *Transaction) error {
+		return boom
+	})
+	if err != boom || client.begins.Load() != 1 {
+		t.Fatalf("err = %v after %d attempts", err, client.begins.Load())
+	}
+	select {
+	case id := <-client.rollbacks:
+		if id != "tx1" {
+			t.Fatalf("rolled back %q", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("transaction was not rolled back")
+	}
+	if client.commits.Load() != 0 {
+		t.Fatal("failed work should not be committed")
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&TransientError{Code: "40003"}, true},
+		{fmt.Errorf("wrapped: %w", &GqlStatusError{Code: TransactionRollback}), true},
+		{&GqlStatusError{Code: InvalidSyntax}, false},
+		{status.Error(codes.Aborted, "conflict"), true},
+		{status.Error(codes.Unavailable, "down"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSummaryErr(t *testing.T) {
+	ok := &ResultSummary{proto: &pb.ResultSummary{Status: &pb.GqlStatus{Code: Success}}}
+	if ok.Err() != nil {
+		t.Fatalf("Err() = %v for success", ok.Err())
+	}
+	failed := &ResultSummary{proto: &pb.ResultSummary{Status: &pb.GqlStatus{Code: TransactionRollback, Message: "deadlock"}}}
+	if !IsTransient(failed.Err()) {
+		t.Fatalf("Err() = %v, want transient", failed.Err())
+	}
+}
@@ -0,0 +1,204 @@
+// Package metrics exports Prometheus metrics for GWP clients.
+//
+//	collector := metrics.NewCollector()
+//	prometheus.MustRegister(collector)
+//	conn, err := gwp.ConnectWithConfig(ctx, target, gwp.ConnectionConfig{
+//	    Interceptors: []gwp.StatementInterceptor{collector},
+//	})
+//	collector.WatchConnection(conn)
+//
+// A statement counts as in flight until its cursor is read to the end,
+// closed or cancelled; close cursors that are not read to completion, or
+// statements_in_flight never comes back down. Statements whose cursor was
+// closed or cancelled early count as errors of class "cancelled".
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+const namespace = "gwp"
+
+// Collector is a prometheus.Collector for GWP client metrics. It observes
+// statements as a gwp.StatementInterceptor, and reports connection and pool
+// state for the connections and pools it watches.
+type Collector struct {
+	inFlight   prometheus.Gauge
+	statements *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	rows       prometheus.Counter
+	retries    prometheus.Counter
+	errors     *prometheus.CounterVec
+
+	openConnections *prometheus.Desc
+	openSessions    *prometheus.Desc
+	poolSessions    *prometheus.Desc
+	breakerState    *prometheus.Desc
+
+	mu          sync.Mutex
+	connections []*gwp.GqlConnection
+	pools       map[string]*gwp.Pool
+}
+
+// NewCollector creates a Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "statements_in_flight",
+			Help: "Statements sent whose results have not completed.",
+		}),
+		statements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "statements_total",
+			Help: "Statements executed, by registered query name (empty for ad hoc statements).",
+		}, []string{"query"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "statement_duration_seconds",
+			Help:    "Time from sending a statement until its result completed, by registered query name.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2.5, 12),
+		}, []string{"query"}),
+		rows: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "rows_streamed_total",
+			Help: "Result rows received.",
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "retries_total",
+			Help: "Operations retried after a failure.",
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "statement_errors_total",
+			Help: "Failed statements by GQLSTATUS class; \"cancelled\" for cancelled statements, \"transport\" for other failures without a GQLSTATUS.",
+		}, []string{"class"}),
+		openConnections: prometheus.NewDesc(namespace+"_open_connections",
+			"Watched connections that are open.", nil, nil),
+		openSessions: prometheus.NewDesc(namespace+"_open_sessions",
+			"Open sessions on watched connections.", nil, nil),
+		poolSessions: prometheus.NewDesc(namespace+"_pool_sessions",
+			"Sessions held by watched pools.", []string{"pool", "state"}, nil),
+		breakerState: prometheus.NewDesc(namespace+"_circuit_breaker_state",
+			"1 for the current circuit breaker state of each endpoint of watched connections, 0 for the others.",
+			[]string{"endpoint", "state"}, nil),
+		pools: make(map[string]*gwp.Pool),
+	}
+}
+
+// WatchConnection reports conn in the connection and session gauges.
+func (c *Collector) WatchConnection(conn *gwp.GqlConnection) {
+	c.mu.Lock()
+	c.connections = append(c.connections, conn)
+	c.mu.Unlock()
+}
+
+// WatchPool reports p's sessions under the given pool label.
+func (c *Collector) WatchPool(name string, p *gwp.Pool) {
+	c.mu.Lock()
+	c.pools[name] = p
+	c.mu.Unlock()
+}
+
+// ObserveRetry counts a retried operation. Call it from retry callbacks.
+func (c *Collector) ObserveRetry() {
+	c.retries.Inc()
+}
+
+// BeforeExecute implements gwp.StatementInterceptor.
+func (c *Collector) BeforeExecute(ctx context.Context, info *gwp.StatementInfo) (context.Context, error) {
+	c.inFlight.Inc()
+	return ctx, nil
+}
+
+// AfterExecute implements gwp.StatementInterceptor.
+func (c *Collector) AfterExecute(ctx context.Context, info *gwp.StatementInfo, result gwp.StatementResult) {
+	c.inFlight.Dec()
+	c.statements.WithLabelValues(info.QueryName).Inc()
+	c.duration.WithLabelValues(info.QueryName).Observe(result.Duration.Seconds())
+	c.rows.Add(float64(result.Rows))
+
+	switch {
+	case result.Summary != nil && gwp.IsException(result.Summary.StatusCode()):
+		c.errors.WithLabelValues(gwp.StatusClass(result.Summary.StatusCode())).Inc()
+	case result.Err != nil:
+		c.errors.WithLabelValues(errorClass(result.Err)).Inc()
+	}
+}
+
+// errorClass returns the GQLSTATUS class of err, "cancelled" for a
+// statement that was cancelled, or "transport".
+func errorClass(err error) string {
+	var statusErr *gwp.GqlStatusError
+	if errors.As(err, &statusErr) {
+		return gwp.StatusClass(statusErr.Code)
+	}
+	if errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled {
+		return "cancelled"
+	}
+	return "transport"
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.inFlight.Describe(ch)
+	c.statements.Describe(ch)
+	c.duration.Describe(ch)
+	c.rows.Describe(ch)
+	c.retries.Describe(ch)
+	c.errors.Describe(ch)
+	ch <- c.openConnections
+	ch <- c.openSessions
+	ch <- c.poolSessions
+	ch <- c.breakerState
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.inFlight.Collect(ch)
+	c.statements.Collect(ch)
+	c.duration.Collect(ch)
+	c.rows.Collect(ch)
+	c.retries.Collect(ch)
+	c.errors.Collect(ch)
+
+	c.mu.Lock()
+	connections := append([]*gwp.GqlConnection(nil), c.connections...)
+	pools := make(map[string]*gwp.Pool, len(c.pools))
+	for name, p := range c.pools {
+		pools[name] = p
+	}
+	c.mu.Unlock()
+
+	var open, sessions int
+	for _, conn := range connections {
+		stats := conn.Stats()
+		if stats.Open {
+			open++
+		}
+		sessions += stats.OpenSessions
+	}
+	ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(open))
+	ch <- prometheus.MustNewConstMetric(c.openSessions, prometheus.GaugeValue, float64(sessions))
+
+	for _, conn := range connections {
+		for endpoint, state := range conn.BreakerStates() {
+			for _, s := range []gwp.BreakerState{gwp.BreakerClosed, gwp.BreakerOpen, gwp.BreakerHalfOpen} {
+				v := 0.0
+				if s == state {
+					v = 1
+				}
+				ch <- prometheus.MustNewConstMetric(c.breakerState, prometheus.GaugeValue, v, endpoint, s.String())
+			}
+		}
+	}
+
+	for name, p := range pools {
+		stats := p.Stats()
+		ch <- prometheus.MustNewConstMetric(c.poolSessions, prometheus.GaugeValue, float64(stats.InUse), name, "in_use")
+		ch <- prometheus.MustNewConstMetric(c.poolSessions, prometheus.GaugeValue, float64(stats.Idle), name, "idle")
+	}
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+func TestCollectorStatements(t *testing.T) {
+	c := NewCollector()
+	ctx := context.Background()
+	info := &gwp.StatementInfo{Statement: "MATCH (n) RETURN n"}
+
+	c.BeforeExecute(ctx, info)
+	if v := testutil.ToFloat64(c.inFlight); v != 1 {
+		t.Fatalf("in flight = %v", v)
+	}
+	c.AfterExecute(ctx, info, gwp.StatementResult{Duration: 20 * time.Millisecond, Rows: 5})
+	c.BeforeExecute(ctx, info)
+	c.AfterExecute(ctx, info, gwp.StatementResult{Err: &gwp.GqlStatusError{Code: "42001", Message: "syntax"}})
+	c.BeforeExecute(ctx, info)
+	c.AfterExecute(ctx, info, gwp.StatementResult{Err: errors.New("connection reset")})
+	c.BeforeExecute(ctx, info)
+	c.AfterExecute(ctx, info, gwp.StatementResult{Err: status.Error(codes.Canceled, "statement cancelled")})
+	c.ObserveRetry()
+
+	if v := testutil.ToFloat64(c.inFlight); v != 0 {
+		t.Fatalf("in flight = %v", v)
+	}
+	named := &gwp.StatementInfo{QueryName: "GetUser", Statement: "MATCH (u:User) RETURN u"}
+	c.BeforeExecute(ctx, named)
+	c.AfterExecute(ctx, named, gwp.StatementResult{Duration: time.Millisecond})
+
+	if v := testutil.ToFloat64(c.statements.WithLabelValues("")); v != 4 {
+		t.Fatalf("statements = %v", v)
+	}
+	if v := testutil.ToFloat64(c.statements.WithLabelValues("GetUser")); v != 1 {
+		t.Fatalf("GetUser statements = %v", v)
+	}
+	if v := testutil.ToFloat64(c.rows); v != 5 {
+		t.Fatalf("rows = %v", v)
+	}
+	if v := testutil.ToFloat64(c.errors.WithLabelValues("42")); v != 1 {
+		t.Fatalf("class 42 errors = %v", v)
+	}
+	if v := testutil.ToFloat64(c.errors.WithLabelValues("transport")); v != 1 {
+		t.Fatalf("transport errors = %v", v)
+	}
+	if v := testutil.ToFloat64(c.errors.WithLabelValues("cancelled")); v != 1 {
+		t.Fatalf("cancelled errors = %v", v)
+	}
+	if v := testutil.ToFloat64(c.retries); v != 1 {
+		t.Fatalf("retries = %v", v)
+	}
+}
+
+func TestCollectorConnectionsAndPools(t *testing.T) {
+	ctx := context.Background()
+	conn, err := gwp.Connect(ctx, "localhost:1")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	c := NewCollector()
+	c.WatchConnection(conn)
+	c.WatchPool("default", gwp.NewPool(conn, gwp.PoolConfig{}))
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+
+	expected := `
+# HELP gwp_open_connections Watched connections that are open.
+# TYPE gwp_open_connections gauge
+gwp_open_connections 1
+# HELP gwp_pool_sessions Sessions held by watched pools.
+# TYPE gwp_pool_sessions gauge
+gwp_pool_sessions{pool="default",state="idle"} 0
+gwp_pool_sessions{pool="default",state="in_use"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "gwp_open_connections", "gwp_pool_sessions"); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Close(ctx)
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP gwp_open_connections Watched connections that are open.
+# TYPE gwp_open_connections gauge
+gwp_open_connections 0
+`), "gwp_open_connections"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,280 @@
+// Package migrate applies versioned GQL migrations to a graph.
+//
+// Migrations are read from pairs of files named VERSION_NAME.up.gql and
+// VERSION_NAME.down.gql, for example 0003_add_email_index.up.gql. The down
+// file is optional. Each file holds one or more statements separated by
+// semicolons.
+//
+//	//go:embed migrations/*.gql
+//	var files embed.FS
+//
+//	migrations, err := migrate.Load(files)
+//	m := migrate.New(session, migrations, migrate.Config{})
+//	applied, err := m.Up(ctx)
+//
+// Applied versions are recorded as nodes in the session's graph, so every
+// graph tracks its own migrations. Each migration runs in its own
+// transaction together with the update of its record; on servers that do
+// not allow catalog or index statements in transactions, keep those in
+// separate migrations so a failure leaves the graph in a known state.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// DefaultLabel is the label of the nodes recording applied migrations.
+const DefaultLabel = "GwpMigration"
+
+// Migration is one versioned schema or data change.
+type Migration struct {
+	Version int64
+	Name    string
+	// Up and Down hold the statements of the migration, separated by
+	// semicolons. Down is empty if the migration cannot be reverted.
+	Up   string
+	Down string
+}
+
+// Config holds configuration for a Migrator.
+type Config struct {
+	// Label of the nodes recording applied migrations. Defaults to
+	// DefaultLabel.
+	Label string
+	// DryRun makes Up and Down report the migrations they would apply or
+	// revert without executing them.
+	DryRun bool
+}
+
+// Migrator applies migrations through a session.
+type Migrator struct {
+	session    *gwp.GqlSession
+	migrations []Migration
+	config     Config
+}
+
+// New creates a Migrator for the graph of session. Migrations are sorted by
+// version.
+func New(session *gwp.GqlSession, migrations []Migration, config Config) *Migrator {
+	if config.Label == "" {
+		config.Label = DefaultLabel
+	}
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return &Migrator{session: session, migrations: sorted, config: config}
+}
+
+// Load reads the migration files in the root directory of fsys. Files that
+// do not end in .up.gql or .down.gql are ignored.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		version, name, up, ok := parseFileName(e.Name())
+		if !ok {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migrate: version %d used by %s and %s", version, m.Name, name)
+		}
+		if up {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migrate: version %d (%s) has no up migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseFileName splits "0003_add_email.up.gql" into its version, name and
+// direction.
+func parseFileName(file string) (version int64, name string, up bool, ok bool) {
+	base := path.Base(file)
+	switch {
+	case strings.HasSuffix(base, ".up.gql"):
+		base, up = strings.TrimSuffix(base, ".up.gql"), true
+	case strings.HasSuffix(base, ".down.gql"):
+		base = strings.TrimSuffix(base, ".down.gql")
+	default:
+		return 0, "", false, false
+	}
+	digits, name, _ := strings.Cut(base, "_")
+	version, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, "", false, false
+	}
+	return version, name, up, true
+}
+
+// Applied returns the versions recorded as applied, in ascending order.
+func (m *Migrator) Applied(ctx context.Context) ([]int64, error) {
+	cursor, err := m.session.Execute(ctx, fmt.Sprintf("MATCH (m:%s) RETURN m.version", gwp.EscapeIdentifier(m.config.Label)), nil)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := gwp.Collect[int64](cursor)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+// Pending returns the migrations that have not been applied, in the order
+// Up applies them.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pending(m.migrations, applied), nil
+}
+
+func pending(migrations []Migration, applied []int64) []Migration {
+	done := make(map[int64]bool, len(applied))
+	for _, v := range applied {
+		done[v] = true
+	}
+	var result []Migration
+	for _, mig := range migrations {
+		if !done[mig.Version] {
+			result = append(result, mig)
+		}
+	}
+	return result
+}
+
+// Up applies all pending migrations in version order and returns them. It
+// stops at the first failure; migrations applied before it stay applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	todo, err := m.Pending(ctx)
+	if err != nil || m.config.DryRun {
+		return todo, err
+	}
+	var applied []Migration
+	for _, mig := range todo {
+		record := fmt.Sprintf("INSERT (:%s {version: $version, name: $name})", gwp.EscapeIdentifier(m.config.Label))
+		if err := m.run(ctx, mig, mig.Up, record); err != nil {
+			return applied, err
+		}
+		applied = append(applied, mig)
+	}
+	return applied, nil
+}
+
+// Down reverts the most recently applied migrations, up to steps of them,
+// in reverse version order and returns them. A migration without a down
+// migration stops Down with an error.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := m.reverting(applied, steps)
+	if err != nil || m.config.DryRun {
+		return todo, err
+	}
+	var reverted []Migration
+	for _, mig := range todo {
+		record := fmt.Sprintf("MATCH (m:%s {version: $version}) DELETE m", gwp.EscapeIdentifier(m.config.Label))
+		if err := m.run(ctx, mig, mig.Down, record); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, mig)
+	}
+	return reverted, nil
+}
+
+// reverting returns the migrations Down reverts for the applied versions.
+func (m *Migrator) reverting(applied []int64, steps int) ([]Migration, error) {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+	var result []Migration
+	for i := len(applied) - 1; i >= 0 && len(result) < steps; i-- {
+		mig, ok := known[applied[i]]
+		if !ok {
+			return nil, fmt.Errorf("migrate: applied version %d has no migration file", applied[i])
+		}
+		if strings.TrimSpace(mig.Down) == "" {
+			return nil, fmt.Errorf("migrate: version %d (%s) has no down migration", mig.Version, mig.Name)
+		}
+		result = append(result, mig)
+	}
+	return result, nil
+}
+
+// run executes the statements of one migration and the statement updating
+// its record in a transaction.
+func (m *Migrator) run(ctx context.Context, mig Migration, script, record string) error {
+	statements, rest := gwp.SplitStatements(script)
+	if rest = strings.TrimSpace(rest); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	tx, err := m.session.BeginTransaction(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, stmt := range statements {
+		if err := execute(ctx, tx, stmt, nil); err != nil {
+			return fmt.Errorf("migrate: version %d (%s): %w", mig.Version, mig.Name, err)
+		}
+	}
+	params := map[string]any{"version": mig.Version, "name": mig.Name}
+	if err := execute(ctx, tx, record, params); err != nil {
+		return fmt.Errorf("migrate: recording version %d: %w", mig.Version, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// execute runs a statement to completion and returns an exception status
+// as an error.
+func execute(ctx context.Context, tx *gwp.Transaction, statement string, params map[string]any) error {
+	cursor, err := tx.Execute(ctx, statement, params)
+	if err != nil {
+		return err
+	}
+	summary, err := cursor.Summary()
+	if err != nil {
+		return err
+	}
+	if summary != nil && gwp.IsException(summary.StatusCode()) {
+		return &gwp.GqlStatusError{Code: summary.StatusCode(), Message: summary.Message()}
+	}
+	return nil
+}
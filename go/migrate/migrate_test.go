@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"0002_add_index.up.gql":    {Data: []byte("CREATE INDEX person_name FOR (p:Person) ON (p.name);")},
+		"0001_people.up.gql":       {Data: []byte("INSERT (:Person {name: 'a'});\nINSERT (:Person {name: 'b'})")},
+		"0001_people.down.gql":     {Data: []byte("MATCH (p:Person) DELETE p")},
+		"0003_no_down.up.gql":      {Data: []byte("RETURN 1")},
+		"README.md":                {Data: []byte("not a migration")},
+		"notes_without_version.up": {Data: []byte("ignored")},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testFiles())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) != 3 {
+		t.Fatalf("got %d migrations, want 3", len(migrations))
+	}
+	first := migrations[0]
+	if first.Version != 1 || first.Name != "people" || !strings.Contains(first.Down, "DELETE") {
+		t.Fatalf("unexpected first migration: %+v", first)
+	}
+	if migrations[1].Version != 2 || migrations[2].Version != 3 {
+		t.Fatalf("migrations out of order: %+v", migrations)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := Load(fstest.MapFS{"0001_only.down.gql": {Data: []byte("RETURN 1")}})
+	if err == nil || !strings.Contains(err.Error(), "no up migration") {
+		t.Fatalf("missing up error = %v", err)
+	}
+	_, err = Load(fstest.MapFS{
+		"0001_a.up.gql": {Data: []byte("RETURN 1")},
+		"0001_b.up.gql": {Data: []byte("RETURN 2")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "version 1") {
+		t.Fatalf("duplicate version error = %v", err)
+	}
+}
+
+func TestPending(t *testing.T) {
+	migrations, err := Load(testFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := pending(migrations, []int64{1, 3})
+	if len(got) != 1 || got[0].Version != 2 {
+		t.Fatalf("pending = %+v", got)
+	}
+}
+
+func TestReverting(t *testing.T) {
+	migrations, err := Load(testFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(nil, migrations, Config{})
+	if m.config.Label != DefaultLabel {
+		t.Fatalf("label = %q", m.config.Label)
+	}
+
+	got, err := m.reverting([]int64{1}, 5)
+	if err != nil || len(got) != 1 || got[0].Version != 1 {
+		t.Fatalf("reverting = %+v, %v", got, err)
+	}
+	if _, err := m.reverting([]int64{1, 3}, 1); err == nil || !strings.Contains(err.Error(), "no down migration") {
+		t.Fatalf("expected missing down error, got %v", err)
+	}
+	if _, err := m.reverting([]int64{9}, 1); err == nil {
+		t.Fatal("expected error for unknown applied version")
+	}
+}
@@ -0,0 +1,105 @@
+package gwp
+
+import (
+	"io"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// recv returns the frame read ahead by peekNextResultSet, if any, or the
+// next frame from the stream.
+func (c *ResultCursor) recv() (*pb.ExecuteResponse, error) {
+	if c.pending != nil {
+		resp := c.pending
+		c.pending = nil
+		return resp, nil
+	}
+	return c.recvFrame()
+}
+
+// peekNextResultSet reads the frame after a summary. Unless the stream has
+// ended, the frame starts another result set and is kept for NextResultSet.
+func (c *ResultCursor) peekNextResultSet() {
+	resp, err := c.recvFrame()
+	switch {
+	case err == io.EOF:
+		return
+	case err != nil:
+		c.pendingErr = err
+	default:
+		c.pending = resp
+	}
+}
+
+// NextResultSet advances to the next result set of a statement that
+// returns several, such as a procedure or multi-statement script. Remaining
+// rows of the current result set are discarded. It returns false when there
+// are no more result sets.
+//
+// Interceptors observe the statement as finished at the end of the first
+// result set.
+func (c *ResultCursor) NextResultSet() (bool, error) {
+	if _, err := c.Summary(); err != nil {
+		return false, err
+	}
+	if c.pendingErr != nil {
+		err := c.pendingErr
+		c.pendingErr = nil
+		return false, err
+	}
+	if c.pending == nil {
+		return false, nil
+	}
+
+	c.header = nil
+	c.summary = nil
+	c.bufferedRows = nil
+	c.rowIndex = 0
+	c.rawBatches = nil
+	c.leaseIndex = 0
+	c.recordColumns = nil
+	c.discard = false
+	c.done = false
+	return true, nil
+}
+
+// ResultSet is one fully read result set.
+type ResultSet struct {
+	Columns []string
+	Rows    [][]any
+	Summary *ResultSummary
+}
+
+// MultiResult holds every result set returned by a statement.
+type MultiResult struct {
+	ResultSets []ResultSet
+}
+
+// CollectResultSets reads all remaining result sets, starting with the
+// current one.
+func (c *ResultCursor) CollectResultSets() (*MultiResult, error) {
+	m := &MultiResult{}
+	for {
+		columns, err := c.ColumnNames()
+		if err != nil {
+			return m, err
+		}
+		rows, err := c.CollectRows()
+		if err != nil {
+			return m, err
+		}
+		summary, err := c.Summary()
+		if err != nil {
+			return m, err
+		}
+		m.ResultSets = append(m.ResultSets, ResultSet{Columns: columns, Rows: rows, Summary: summary})
+
+		more, err := c.NextResultSet()
+		if err != nil {
+			return m, err
+		}
+		if !more {
+			return m, nil
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package gwp
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func TestCursorNextResultSet(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("name"),
+		batchFrame([]any{"Alice"}, []any{"Bob"}),
+		summaryFrame(Success, 0),
+		headerFrame("count"),
+		batchFrame([]any{int64(2)}),
+		summaryFrame(Success, 0),
+	)
+	// Leave the first result set partly read.
+	if row, err := c.NextRow(); err != nil || row[0] != "Alice" {
+		t.Fatalf("NextRow = %v, %v", row, err)
+	}
+	more, err := c.NextResultSet()
+	if err != nil || !more {
+		t.Fatalf("NextResultSet = %v, %v", more, err)
+	}
+
+	names, err := c.ColumnNames()
+	if err != nil || len(names) != 1 || names[0] != "count" {
+		t.Fatalf("ColumnNames = %v, %v", names, err)
+	}
+	n, err := c.ScalarInt()
+	if err != nil || n != 2 {
+		t.Fatalf("ScalarInt = %d, %v", n, err)
+	}
+
+	more, err = c.NextResultSet()
+	if err != nil || more {
+		t.Fatalf("NextResultSet = %v, %v", more, err)
+	}
+}
+
+func TestCursorNextResultSetSingle(t *testing.T) {
+	c := newTestCursor(headerFrame("x"), batchFrame([]any{int64(1)}), summaryFrame(Success, 0))
+	more, err := c.NextResultSet()
+	if err != nil || more {
+		t.Fatalf("NextResultSet = %v, %v", more, err)
+	}
+}
+
+func TestCursorNextResultSetStreamError(t *testing.T) {
+	streamErr := errors.New("stream reset")
+	c := newResultCursor(&fakeStream{
+		frames: []*pb.ExecuteResponse{headerFrame("x"), summaryFrame(Success, 0)},
+		err:    streamErr,
+	})
+	if _, err := c.NextResultSet(); !errors.Is(err, streamErr) {
+		t.Fatalf("NextResultSet error = %v, want %v", err, streamErr)
+	}
+}
+
+func TestCursorCollectResultSets(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("name"),
+		batchFrame([]any{"Alice"}),
+		summaryFrame(Success, 0),
+		headerFrame(),
+		summaryFrame(Success, 3),
+	)
+	m, err := c.CollectResultSets()
+	if err != nil {
+		t.Fatalf("CollectResultSets: %v", err)
+	}
+	if len(m.ResultSets) != 2 {
+		t.Fatalf("got %d result sets, want 2", len(m.ResultSets))
+	}
+	first := m.ResultSets[0]
+	if len(first.Rows) != 1 || first.Rows[0][0] != "Alice" || first.Columns[0] != "name" {
+		t.Fatalf("unexpected first result set: %+v", first)
+	}
+	if m.ResultSets[1].Summary.RowsAffected() != 3 {
+		t.Fatalf("unexpected second summary: %+v", m.ResultSets[1].Summary)
+	}
+}
@@ -0,0 +1,79 @@
+package gwp
+
+import (
+	"strings"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// NotificationSeverity classifies a notification.
+type NotificationSeverity string
+
+// Notification severities.
+const (
+	SeverityWarning     NotificationSeverity = "WARNING"
+	SeverityInformation NotificationSeverity = "INFORMATION"
+)
+
+// Notification is a warning or informational notice raised by the server
+// while executing a statement.
+type Notification struct {
+	Code        string
+	Severity    NotificationSeverity
+	Title       string
+	Description string
+	Operation   string
+}
+
+func notificationFromProto(s *pb.GqlStatus) Notification {
+	n := Notification{
+		Code:     s.Code,
+		Severity: SeverityInformation,
+		Title:    s.Message,
+	}
+	if IsWarning(s.Code) {
+		n.Severity = SeverityWarning
+	}
+
+	var details []string
+	if d := s.Diagnostic; d != nil {
+		n.Operation = d.Operation
+		if d.InvalidReference != nil {
+			details = append(details, "invalid reference: "+*d.InvalidReference)
+		}
+	}
+	for cause := s.Cause; cause != nil; cause = cause.Cause {
+		details = append(details, "caused by ["+cause.Code+"] "+cause.Message)
+	}
+	n.Description = strings.Join(details, "; ")
+	return n
+}
+
+// Notifications returns the warnings and informational notices raised
+// during execution.
+func (s *ResultSummary) Notifications() []Notification {
+	if len(s.proto.Warnings) == 0 {
+		return nil
+	}
+	result := make([]Notification, len(s.proto.Warnings))
+	for i, w := range s.proto.Warnings {
+		result[i] = notificationFromProto(w)
+	}
+	return result
+}
+
+// SetNotificationHandler registers a callback invoked for each notification
+// as result summaries arrive on this session, including statements run in
+// its transactions. The callback runs on the goroutine reading the cursor.
+// Pass nil to remove the handler.
+func (s *GqlSession) SetNotificationHandler(fn func(Notification)) {
+	s.mu.Lock()
+	s.notificationHandler = fn
+	s.mu.Unlock()
+}
+
+func (s *GqlSession) notificationHandlerFunc() func(Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.notificationHandler
+}
@@ -0,0 +1,37 @@
+package gwp
+
+import (
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func TestSummaryNotifications(t *testing.T) {
+	ref := "x"
+	summary := &pb.ResultSummary{
+		Status: &pb.GqlStatus{Code: Success},
+		Warnings: []*pb.GqlStatus{
+			{Code: "01G11", Message: "null value eliminated", Diagnostic: &pb.DiagnosticRecord{Operation: "MATCH STATEMENT", InvalidReference: &ref}},
+			{Code: "03000", Message: "informational", Cause: &pb.GqlStatus{Code: "03001", Message: "detail"}},
+		},
+	}
+
+	var received []Notification
+	c := newTestCursor(headerFrame(), &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_Summary{Summary: summary}})
+	c.session = &GqlSession{notificationHandler: func(n Notification) { received = append(received, n) }}
+
+	s, err := c.Summary()
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	notes := s.Notifications()
+	if len(notes) != 2 || len(received) != 2 {
+		t.Fatalf("expected 2 notifications, got %d (handler %d)", len(notes), len(received))
+	}
+	if notes[0].Severity != SeverityWarning || notes[0].Operation != "MATCH STATEMENT" || notes[0].Description != "invalid reference: x" {
+		t.Fatalf("unexpected warning: %+v", notes[0])
+	}
+	if notes[1].Severity != SeverityInformation || notes[1].Description != "caused by [03001] detail" {
+		t.Fatalf("unexpected notice: %+v", notes[1])
+	}
+}
@@ -0,0 +1,143 @@
+package gwp
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// The Null types hold a result value or parameter that may be NULL, in the
+// manner of database/sql's NullString and friends. Scanned with Collect,
+// One or ScanValue, NULL sets Valid to false instead of failing; sent as a
+// parameter, a value with Valid false is sent as NULL.
+
+// NullString is a string that may be NULL.
+type NullString struct {
+	String string
+	Valid  bool
+}
+
+// NullInt64 is an int64 that may be NULL.
+type NullInt64 struct {
+	Int64 int64
+	Valid bool
+}
+
+// NullFloat64 is a float64 that may be NULL. Integer results are converted.
+type NullFloat64 struct {
+	Float64 float64
+	Valid   bool
+}
+
+// NullBool is a bool that may be NULL.
+type NullBool struct {
+	Bool  bool
+	Valid bool
+}
+
+// NullTime is a time.Time that may be NULL. It scans zoned date-times as
+// the instant they denote, and local date-times and dates as UTC. It is
+// sent as a zoned date-time in the offset of Time's location.
+type NullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+// nullValuer is implemented by the Null types.
+type nullValuer interface {
+	// nullValue returns the value to send, or nil for NULL.
+	nullValue() any
+}
+
+// nullScanner is implemented by pointers to the Null types.
+type nullScanner interface {
+	// scanNull stores the result value v, which may be nil.
+	scanNull(v any) error
+}
+
+var nullScannerType = reflect.TypeFor[nullScanner]()
+
+func (n NullString) nullValue() any {
+	if !n.Valid {
+		return nil
+	}
+	return n.String
+}
+
+func (n *NullString) scanNull(v any) error {
+	n.String, n.Valid = "", v != nil
+	if v == nil {
+		return nil
+	}
+	return assignValue(reflect.ValueOf(&n.String).Elem(), v)
+}
+
+func (n NullInt64) nullValue() any {
+	if !n.Valid {
+		return nil
+	}
+	return n.Int64
+}
+
+func (n *NullInt64) scanNull(v any) error {
+	n.Int64, n.Valid = 0, v != nil
+	if v == nil {
+		return nil
+	}
+	return assignValue(reflect.ValueOf(&n.Int64).Elem(), v)
+}
+
+func (n NullFloat64) nullValue() any {
+	if !n.Valid {
+		return nil
+	}
+	return n.Float64
+}
+
+func (n *NullFloat64) scanNull(v any) error {
+	n.Float64, n.Valid = 0, v != nil
+	if v == nil {
+		return nil
+	}
+	return assignValue(reflect.ValueOf(&n.Float64).Elem(), v)
+}
+
+func (n NullBool) nullValue() any {
+	if !n.Valid {
+		return nil
+	}
+	return n.Bool
+}
+
+func (n *NullBool) scanNull(v any) error {
+	n.Bool, n.Valid = false, v != nil
+	if v == nil {
+		return nil
+	}
+	return assignValue(reflect.ValueOf(&n.Bool).Elem(), v)
+}
+
+func (n NullTime) nullValue() any {
+	if !n.Valid {
+		return nil
+	}
+	return n.Time
+}
+
+func (n *NullTime) scanNull(v any) error {
+	n.Time, n.Valid = time.Time{}, v != nil
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case *GqlZonedDateTime:
+		n.Time = zonedDateTimeInstant(*t)
+	case *GqlLocalDateTime:
+		n.Time = zonedDateTimeInstant(GqlZonedDateTime{Date: t.Date, Time: t.Time}).UTC()
+	case *GqlDate:
+		n.Time = time.Date(int(t.Year), time.Month(t.Month), int(t.Day), 0, 0, 0, 0, time.UTC)
+	default:
+		n.Valid = false
+		return &GqlError{Message: fmt.Sprintf("cannot scan %T into NullTime", v)}
+	}
+	return nil
+}
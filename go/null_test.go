@@ -0,0 +1,71 @@
+package gwp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNullScan(t *testing.T) {
+	var s NullString
+	if err := ScanValue(&s, "x"); err != nil || s != (NullString{"x", true}) {
+		t.Fatalf("NullString = %+v, %v", s, err)
+	}
+	if err := ScanValue(&s, nil); err != nil || s.Valid {
+		t.Fatalf("NullString from NULL = %+v, %v", s, err)
+	}
+	var f NullFloat64
+	if err := ScanValue(&f, int64(3)); err != nil || f != (NullFloat64{3, true}) {
+		t.Fatalf("NullFloat64 = %+v, %v", f, err)
+	}
+	var n NullInt64
+	if err := ScanValue(&n, "3"); err == nil {
+		t.Fatal("expected error scanning string into NullInt64")
+	}
+
+	type person struct {
+		Name NullString
+		Age  NullInt64
+		Seen NullTime
+	}
+	rows, err := Collect[person](newTestCursor(
+		headerFrame("name", "age", "seen"),
+		batchFrame([]any{"Alice", nil, nil}),
+		summaryFrame(Success, 0),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := rows[0]; p.Name != (NullString{"Alice", true}) || p.Age.Valid || p.Seen.Valid {
+		t.Fatalf("row = %+v", p)
+	}
+
+	var tm NullTime
+	zoned := &GqlZonedDateTime{Date: GqlDate{2024, 3, 1}, Time: GqlLocalTime{Hour: 12}, OffsetMinutes: 60}
+	if err := ScanValue(&tm, zoned); err != nil || !tm.Valid || !tm.Time.Equal(time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("NullTime = %+v, %v", tm, err)
+	}
+}
+
+func TestNullParams(t *testing.T) {
+	for _, tc := range []struct {
+		in   any
+		want any
+	}{
+		{NullString{"x", true}, "x"},
+		{NullString{}, nil},
+		{NullInt64{7, true}, int64(7)},
+		{&NullBool{true, true}, true},
+		{(*NullFloat64)(nil), nil},
+		{NullFloat64{Float64: 1}, nil},
+	} {
+		if got := valueFromProto(mustValueToProto(tc.in)); got != tc.want {
+			t.Errorf("%#v sent as %#v, want %#v", tc.in, got, tc.want)
+		}
+	}
+
+	at := time.Date(2024, 3, 1, 12, 30, 0, 5, time.FixedZone("", 90*60))
+	got, ok := valueFromProto(mustValueToProto(NullTime{at, true})).(*GqlZonedDateTime)
+	if !ok || got.OffsetMinutes != 90 || !zonedDateTimeInstant(*got).Equal(at) {
+		t.Fatalf("NullTime sent as %#v", got)
+	}
+}
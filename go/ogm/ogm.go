@@ -0,0 +1,577 @@
+// Package ogm maps Go structs to graph nodes and edges.
+//
+//	type Person struct {
+//		Email   string    `gwp:"email,key"`
+//		Name    string    `gwp:"name"`
+//		Age     *int      `gwp:"age"`
+//		Friends []*Person `gwp:"KNOWS,out"`
+//	}
+//
+//	err := ogm.Save(ctx, session, &Person{Email: "alice@example.com", Name: "Alice"})
+//	alice, err := ogm.Load[Person](ctx, session, "alice@example.com", ogm.With("Friends"))
+//
+// A struct maps to nodes labelled with its type name, or with the label
+// returned by its NodeLabel method. Its exported fields are properties,
+// named by their `gwp` tag or else by the field name; a tag of "-" skips the
+// field. Exactly one property must have the key option; its value
+// identifies the node.
+//
+// A field tagged with an edge type and one of the options out, in or both
+// holds related entities: a pointer to, or slice of pointers to or values
+// of, another mapped struct. Relations are only read when requested with
+// With, or later with LoadRelated.
+//
+// Save upserts with a MATCH ... SET followed, if no node matched, by an
+// INSERT. Run it in a transaction when concurrent writers may save the same
+// key.
+package ogm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// Executor runs statements. *gwp.GqlSession and *gwp.Transaction implement
+// it.
+type Executor interface {
+	Execute(ctx context.Context, statement string, params map[string]any, opts ...gwp.ExecuteOption) (*gwp.ResultCursor, error)
+}
+
+// Labeler is implemented by structs whose node label differs from their
+// type name.
+type Labeler interface {
+	NodeLabel() string
+}
+
+// LoadOption configures Load and Find.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	relations []string
+}
+
+// With loads the named relation fields together with the entities.
+func With(fields ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.relations = append(o.relations, fields...)
+	}
+}
+
+// Save creates or updates the node for entity, a pointer to a mapped
+// struct, and for each entity in its relation fields creates or updates
+// that node and the edge to it. Related entities' own relations are not
+// saved, and edges to entities no longer in a relation field are kept.
+func Save(ctx context.Context, ex Executor, entity any) error {
+	v, info, err := entityValue(entity)
+	if err != nil {
+		return err
+	}
+	if err := saveNode(ctx, ex, v, info); err != nil {
+		return err
+	}
+	for _, rel := range info.relations {
+		for _, target := range rel.targets(v) {
+			if err := saveNode(ctx, ex, target, rel.target); err != nil {
+				return err
+			}
+			if err := link(ctx, ex, v, info, target, rel); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Load returns the T whose key equals key, or gwp.ErrNoRows if there is
+// none.
+func Load[T any](ctx context.Context, ex Executor, key any, opts ...LoadOption) (*T, error) {
+	info, err := infoFor(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	found, err := Find[T](ctx, ex, map[string]any{info.key.name: key}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	switch len(found) {
+	case 0:
+		return nil, gwp.ErrNoRows
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("ogm: %d %s nodes have key %v", len(found), info.label, key)
+	}
+}
+
+// Find returns every T whose properties equal the values in filter. An
+// empty filter matches all nodes with T's label.
+func Find[T any](ctx context.Context, ex Executor, filter map[string]any, opts ...LoadOption) ([]*T, error) {
+	info, err := infoFor(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	o := &loadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	params := make(map[string]any, len(filter))
+	var b strings.Builder
+	fmt.Fprintf(&b, "MATCH (n:%s", gwp.EscapeIdentifier(info.label))
+	if len(filter) > 0 {
+		conds := make([]string, 0, len(filter))
+		for _, k := range sortedKeys(filter) {
+			p := fmt.Sprintf("p%d", len(conds))
+			value, err := paramValue(reflect.ValueOf(filter[k]))
+			if err != nil {
+				return nil, fmt.Errorf("ogm: filter %s: %w", k, err)
+			}
+			params[p] = value
+			conds = append(conds, fmt.Sprintf("%s: $%s", gwp.EscapeIdentifier(k), p))
+		}
+		b.WriteString(" {" + strings.Join(conds, ", ") + "}")
+	}
+	b.WriteString(") RETURN n")
+
+	nodes, err := queryNodes(ctx, ex, b.String(), params)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*T, 0, len(nodes))
+	for _, node := range nodes {
+		t := new(T)
+		if err := gwp.ScanValue(t, node); err != nil {
+			return nil, fmt.Errorf("ogm: %w", err)
+		}
+		if len(o.relations) > 0 {
+			if err := LoadRelated(ctx, ex, t, o.relations...); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// LoadRelated reads the named relation fields of entity, a pointer to a
+// mapped struct, replacing their contents.
+func LoadRelated(ctx context.Context, ex Executor, entity any, fields ...string) error {
+	v, info, err := entityValue(entity)
+	if err != nil {
+		return err
+	}
+	key, err := info.keyValue(v)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		rel := info.relation(field)
+		if rel == nil {
+			return fmt.Errorf("ogm: %s has no relation field %s", info.label, field)
+		}
+		stmt := fmt.Sprintf("MATCH (n:%s {%s: $key})%s(m:%s) RETURN m",
+			gwp.EscapeIdentifier(info.label), gwp.EscapeIdentifier(info.key.name), rel.pattern(), gwp.EscapeIdentifier(rel.target.label))
+		nodes, err := queryNodes(ctx, ex, stmt, map[string]any{"key": key})
+		if err != nil {
+			return err
+		}
+		if err := rel.set(v, nodes); err != nil {
+			return fmt.Errorf("ogm: %s.%s: %w", info.label, field, err)
+		}
+	}
+	return nil
+}
+
+// Delete removes the node for entity, a pointer to a mapped struct, and
+// its edges. Related nodes are kept.
+func Delete(ctx context.Context, ex Executor, entity any) error {
+	v, info, err := entityValue(entity)
+	if err != nil {
+		return err
+	}
+	key, err := info.keyValue(v)
+	if err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf("MATCH (n:%s {%s: $key}) DETACH DELETE n", gwp.EscapeIdentifier(info.label), gwp.EscapeIdentifier(info.key.name))
+	return run(ctx, ex, stmt, map[string]any{"key": key})
+}
+
+// saveNode updates the properties of the node v maps to, or inserts it.
+func saveNode(ctx context.Context, ex Executor, v reflect.Value, info *entityInfo) error {
+	key, err := info.keyValue(v)
+	if err != nil {
+		return err
+	}
+	params := map[string]any{"key": key}
+	var sets, props []string
+	for i, p := range info.properties {
+		if p == info.key {
+			continue
+		}
+		value, err := paramValue(v.FieldByIndex(p.index))
+		if err != nil {
+			return fmt.Errorf("ogm: %s.%s: %w", info.label, p.field, err)
+		}
+		name := fmt.Sprintf("p%d", i)
+		params[name] = value
+		sets = append(sets, fmt.Sprintf("n.%s = $%s", gwp.EscapeIdentifier(p.name), name))
+		if value != nil {
+			props = append(props, fmt.Sprintf("%s: $%s", gwp.EscapeIdentifier(p.name), name))
+		}
+	}
+
+	match := fmt.Sprintf("MATCH (n:%s {%s: $key})", gwp.EscapeIdentifier(info.label), gwp.EscapeIdentifier(info.key.name))
+	if len(sets) > 0 {
+		match += " SET " + strings.Join(sets, ", ")
+	}
+	nodes, err := queryNodes(ctx, ex, match+" RETURN n", params)
+	if err != nil {
+		return err
+	}
+	if len(nodes) > 0 {
+		return nil
+	}
+	props = append([]string{fmt.Sprintf("%s: $key", gwp.EscapeIdentifier(info.key.name))}, props...)
+	insert := fmt.Sprintf("INSERT (:%s {%s})", gwp.EscapeIdentifier(info.label), strings.Join(props, ", "))
+	return run(ctx, ex, insert, params)
+}
+
+// link creates the edge of rel between v and target unless it exists.
+func link(ctx context.Context, ex Executor, v reflect.Value, info *entityInfo, target reflect.Value, rel *relation) error {
+	from, err := info.keyValue(v)
+	if err != nil {
+		return err
+	}
+	to, err := rel.target.keyValue(target)
+	if err != nil {
+		return err
+	}
+	pattern := rel.pattern()
+	if rel.direction == "both" {
+		// An inserted edge needs a direction; store it outgoing.
+		pattern = fmt.Sprintf("-[:%s]->", gwp.EscapeIdentifier(rel.edgeType))
+	}
+	stmt := fmt.Sprintf("MATCH (a:%s {%s: $from}), (b:%s {%s: $to}) WHERE NOT EXISTS { MATCH (a)%s(b) } INSERT (a)%s(b)",
+		gwp.EscapeIdentifier(info.label), gwp.EscapeIdentifier(info.key.name),
+		gwp.EscapeIdentifier(rel.target.label), gwp.EscapeIdentifier(rel.target.key.name),
+		rel.pattern(), pattern)
+	return run(ctx, ex, stmt, map[string]any{"from": from, "to": to})
+}
+
+func run(ctx context.Context, ex Executor, stmt string, params map[string]any) error {
+	cursor, err := ex.Execute(ctx, stmt, params)
+	if err != nil {
+		return err
+	}
+	summary, err := cursor.Summary()
+	if err != nil || summary == nil {
+		return err
+	}
+	return summary.Err()
+}
+
+// queryNodes returns the first column of every row of the statement.
+func queryNodes(ctx context.Context, ex Executor, stmt string, params map[string]any) ([]any, error) {
+	cursor, err := ex.Execute(ctx, stmt, params)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := cursor.CollectRows()
+	if err != nil {
+		return nil, err
+	}
+	summary, err := cursor.Summary()
+	if err != nil {
+		return nil, err
+	}
+	if summary != nil {
+		if err := summary.Err(); err != nil {
+			return nil, err
+		}
+	}
+	nodes := make([]any, 0, len(rows))
+	for _, row := range rows {
+		if len(row) > 0 {
+			nodes = append(nodes, row[0])
+		}
+	}
+	return nodes, nil
+}
+
+// entityInfo is the mapping of a struct type.
+type entityInfo struct {
+	typ        reflect.Type
+	label      string
+	key        *property
+	properties []*property
+	relations  []*relation
+}
+
+type property struct {
+	field string
+	name  string
+	index []int
+}
+
+type relation struct {
+	field     string
+	edgeType  string
+	direction string
+	index     []int
+	target    *entityInfo
+	// many is set for slice fields, and pointers for elements (or the
+	// field) holding pointers.
+	many     bool
+	pointers bool
+}
+
+var infos sync.Map // reflect.Type -> *entityInfo
+
+func entityValue(entity any) (reflect.Value, *entityInfo, error) {
+	v := reflect.ValueOf(entity)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("ogm: expected a non-nil struct pointer, got %T", entity)
+	}
+	info, err := infoFor(v.Elem().Type())
+	return v.Elem(), info, err
+}
+
+func infoFor(t reflect.Type) (*entityInfo, error) {
+	if info, ok := infos.Load(t); ok {
+		return info.(*entityInfo), nil
+	}
+	info, err := buildInfo(t, map[reflect.Type]*entityInfo{})
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := infos.LoadOrStore(t, info)
+	return actual.(*entityInfo), nil
+}
+
+// buildInfo maps t, using building for types being mapped further up the
+// stack so self-referencing structs terminate.
+func buildInfo(t reflect.Type, building map[reflect.Type]*entityInfo) (*entityInfo, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ogm: %s is not a struct", t)
+	}
+	if info, ok := building[t]; ok {
+		return info, nil
+	}
+	info := &entityInfo{typ: t, label: t.Name()}
+	if l, ok := reflect.New(t).Interface().(Labeler); ok {
+		info.label = l.NodeLabel()
+	}
+	building[t] = info
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("gwp")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		switch opts {
+		case "", "key":
+			p := &property{field: f.Name, name: name, index: f.Index}
+			info.properties = append(info.properties, p)
+			if opts == "key" {
+				if info.key != nil {
+					return nil, fmt.Errorf("ogm: %s has two key fields, %s and %s", t, info.key.field, f.Name)
+				}
+				info.key = p
+			}
+		case "out", "in", "both":
+			rel, err := buildRelation(f, name, opts, building)
+			if err != nil {
+				return nil, err
+			}
+			info.relations = append(info.relations, rel)
+		default:
+			return nil, fmt.Errorf("ogm: %s.%s: unknown tag option %q", t, f.Name, opts)
+		}
+	}
+	if info.key == nil {
+		return nil, fmt.Errorf("ogm: %s has no field tagged with the key option", t)
+	}
+	return info, nil
+}
+
+func buildRelation(f reflect.StructField, edgeType, direction string, building map[reflect.Type]*entityInfo) (*relation, error) {
+	rel := &relation{field: f.Name, edgeType: edgeType, direction: direction, index: f.Index}
+	t := f.Type
+	if t.Kind() == reflect.Slice {
+		rel.many = true
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Pointer {
+		rel.pointers = true
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || (!rel.many && !rel.pointers) {
+		return nil, fmt.Errorf("ogm: relation field %s must be a struct pointer or slice, not %s", f.Name, f.Type)
+	}
+	target, err := buildInfo(t, building)
+	if err != nil {
+		return nil, err
+	}
+	rel.target = target
+	return rel, nil
+}
+
+func (info *entityInfo) relation(field string) *relation {
+	for _, rel := range info.relations {
+		if rel.field == field {
+			return rel
+		}
+	}
+	return nil
+}
+
+func (info *entityInfo) keyValue(v reflect.Value) (any, error) {
+	key, err := paramValue(v.FieldByIndex(info.key.index))
+	if err != nil {
+		return nil, fmt.Errorf("ogm: %s key: %w", info.label, err)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("ogm: %s key %s is nil", info.label, info.key.field)
+	}
+	return key, nil
+}
+
+// pattern returns the edge pattern from the entity to its related nodes.
+func (rel *relation) pattern() string {
+	switch rel.direction {
+	case "out":
+		return fmt.Sprintf("-[:%s]->", gwp.EscapeIdentifier(rel.edgeType))
+	case "in":
+		return fmt.Sprintf("<-[:%s]-", gwp.EscapeIdentifier(rel.edgeType))
+	default:
+		return fmt.Sprintf("-[:%s]-", gwp.EscapeIdentifier(rel.edgeType))
+	}
+}
+
+// targets returns the non-nil related structs held by the field.
+func (rel *relation) targets(v reflect.Value) []reflect.Value {
+	field := v.FieldByIndex(rel.index)
+	var elems []reflect.Value
+	if rel.many {
+		for i := 0; i < field.Len(); i++ {
+			elems = append(elems, field.Index(i))
+		}
+	} else {
+		elems = []reflect.Value{field}
+	}
+	var out []reflect.Value
+	for _, e := range elems {
+		if rel.pointers {
+			if e.IsNil() {
+				continue
+			}
+			e = e.Elem()
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// set stores the related nodes in the field.
+func (rel *relation) set(v reflect.Value, nodes []any) error {
+	field := v.FieldByIndex(rel.index)
+	if !rel.many {
+		if len(nodes) > 1 {
+			return fmt.Errorf("%d related nodes for a single-valued field", len(nodes))
+		}
+		field.SetZero()
+		if len(nodes) == 1 {
+			return gwp.ScanValue(field.Addr().Interface(), nodes[0])
+		}
+		return nil
+	}
+	out := reflect.MakeSlice(field.Type(), len(nodes), len(nodes))
+	for i, node := range nodes {
+		if err := gwp.ScanValue(out.Index(i).Addr().Interface(), node); err != nil {
+			return err
+		}
+	}
+	field.Set(out)
+	return nil
+}
+
+// paramValue converts a field value to a statement parameter value.
+func paramValue(v reflect.Value) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Struct {
+			// Value types such as *gwp.GqlPoint are encoded as is.
+			return v.Interface(), nil
+		}
+		return paramValue(v.Elem())
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("value %d overflows int64", v.Uint())
+		}
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Kind() == reflect.Slice {
+				return v.Bytes(), nil
+			}
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return b, nil
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			e, err := paramValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = e
+		}
+		return out, nil
+	case reflect.Struct:
+		// Temporal, decimal and codec types are encoded by the client.
+		return v.Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
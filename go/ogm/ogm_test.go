@@ -0,0 +1,275 @@
+package ogm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type Person struct {
+	Email   string    `gwp:"email,key"`
+	Name    string    `gwp:"name"`
+	Age     *int      `gwp:"age"`
+	Tags    []string  `gwp:"tags"`
+	Secret  string    `gwp:"-"`
+	Friends []*Person `gwp:"KNOWS,out"`
+	Manager *Person   `gwp:"MANAGES,in"`
+}
+
+type Company struct {
+	ID   int64 `gwp:"id,key"`
+	Name string
+}
+
+func (Company) NodeLabel() string { return "Org Unit" }
+
+// graphServer records the statements it receives and answers each with the
+// nodes respond returns.
+type graphServer struct {
+	pb.UnimplementedSessionServiceServer
+	pb.UnimplementedGqlServiceServer
+
+	respond func(statement string, params map[string]*pb.Value) []*pb.Node
+
+	mu         sync.Mutex
+	statements []string
+	params     []map[string]*pb.Value
+}
+
+func (s *graphServer) Handshake(ctx context.Context, r *pb.HandshakeRequest) (*pb.HandshakeResponse, error) {
+	return &pb.HandshakeResponse{ProtocolVersion: 1, SessionId: "s1"}, nil
+}
+
+func (s *graphServer) Execute(r *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	s.mu.Lock()
+	s.statements = append(s.statements, r.Statement)
+	s.params = append(s.params, r.Parameters)
+	s.mu.Unlock()
+
+	batch := &pb.RowBatch{}
+	if s.respond != nil {
+		for _, n := range s.respond(r.Statement, r.Parameters) {
+			batch.Rows = append(batch.Rows, &pb.Row{Values: []*pb.Value{{Kind: &pb.Value_NodeValue{NodeValue: n}}}})
+		}
+	}
+	frames := []*pb.ExecuteResponse{
+		{Frame: &pb.ExecuteResponse_Header{Header: &pb.ResultHeader{Columns: []*pb.ColumnDescriptor{{Name: "n"}}}}},
+		{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: batch}},
+		{Frame: &pb.ExecuteResponse_Summary{Summary: &pb.ResultSummary{Status: &pb.GqlStatus{Code: gwp.Success}}}},
+	}
+	for _, f := range frames {
+		if err := stream.Send(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startGraphServer(t *testing.T, respond func(string, map[string]*pb.Value) []*pb.Node) (*graphServer, *gwp.GqlSession) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	gs := &graphServer{respond: respond}
+	pb.RegisterSessionServiceServer(srv, gs)
+	pb.RegisterGqlServiceServer(srv, gs)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	ctx := context.Background()
+	conn, err := gwp.ConnectWithDialer(ctx, "bufnet", func(ctx context.Context, addr string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gs, session
+}
+
+func personNode(email, name string) *pb.Node {
+	return &pb.Node{
+		Id:     []byte(email),
+		Labels: []string{"Person"},
+		Properties: map[string]*pb.Value{
+			"email": {Kind: &pb.Value_StringValue{StringValue: email}},
+			"name":  {Kind: &pb.Value_StringValue{StringValue: name}},
+		},
+	}
+}
+
+func TestMapping(t *testing.T) {
+	info, err := infoFor(reflect.TypeFor[Person]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var props []string
+	for _, p := range info.properties {
+		props = append(props, p.name)
+	}
+	if info.label != "Person" || info.key.name != "email" || strings.Join(props, ",") != "email,name,age,tags" {
+		t.Fatalf("label %q, key %q, properties %v", info.label, info.key.name, props)
+	}
+	if len(info.relations) != 2 || info.relations[0].target != info || info.relations[1].many {
+		t.Fatalf("unexpected relations %+v", info.relations)
+	}
+
+	company, err := infoFor(reflect.TypeFor[Company]())
+	if err != nil || company.label != "Org Unit" || company.properties[1].name != "Name" {
+		t.Fatalf("Company mapping = %+v, %v", company, err)
+	}
+
+	type noKey struct{ Name string }
+	type badRelation struct {
+		ID    string `gwp:"id,key"`
+		Owner string `gwp:"OWNS,out"`
+	}
+	type badOption struct {
+		ID string `gwp:"id,primary"`
+	}
+	for _, typ := range []reflect.Type{reflect.TypeFor[noKey](), reflect.TypeFor[badRelation](), reflect.TypeFor[badOption]()} {
+		if _, err := infoFor(typ); err == nil {
+			t.Errorf("expected mapping error for %s", typ)
+		}
+	}
+}
+
+func TestParamValue(t *testing.T) {
+	age := 30
+	type level uint8
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{&age, int64(30)},
+		{(*int)(nil), nil},
+		{level(3), int64(3)},
+		{float32(1.5), float64(1.5)},
+		{[]string{"a", "b"}, []any{"a", "b"}},
+		{[]byte("raw"), []byte("raw")},
+		{[]string(nil), nil},
+		{gwp.GqlDate{Year: 2024, Month: 1, Day: 2}, gwp.GqlDate{Year: 2024, Month: 1, Day: 2}},
+	}
+	for _, tt := range tests {
+		got, err := paramValue(reflect.ValueOf(tt.in))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("paramValue(%#v) = %#v, %v; want %#v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := paramValue(reflect.ValueOf(map[string]int{})); err == nil {
+		t.Error("expected error for a map")
+	}
+}
+
+func TestSave(t *testing.T) {
+	existing := map[string]bool{"bob@example.com": true}
+	gs, session := startGraphServer(t, func(stmt string, params map[string]*pb.Value) []*pb.Node {
+		key := params["key"].GetStringValue()
+		if strings.HasSuffix(stmt, "RETURN n") && existing[key] {
+			return []*pb.Node{personNode(key, "Bob")}
+		}
+		return nil
+	})
+
+	age := 30
+	alice := &Person{Email: "alice@example.com", Name: "Alice", Age: &age, Secret: "x",
+		Friends: []*Person{{Email: "bob@example.com", Name: "Bob"}, nil}}
+	if err := Save(context.Background(), session, alice); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	want := []string{
+		"MATCH (n:Person {email: $key}) SET n.name = $p1, n.age = $p2, n.tags = $p3 RETURN n",
+		"INSERT (:Person {email: $key, name: $p1, age: $p2})",
+		"MATCH (n:Person {email: $key}) SET n.name = $p1, n.age = $p2, n.tags = $p3 RETURN n",
+		"MATCH (a:Person {email: $from}), (b:Person {email: $to}) WHERE NOT EXISTS { MATCH (a)-[:KNOWS]->(b) } INSERT (a)-[:KNOWS]->(b)",
+	}
+	if !reflect.DeepEqual(gs.statements, want) {
+		t.Fatalf("statements:\n%s\nwant:\n%s", strings.Join(gs.statements, "\n"), strings.Join(want, "\n"))
+	}
+	if gs.params[1]["p2"].GetIntegerValue() != 30 || gs.params[3]["to"].GetStringValue() != "bob@example.com" {
+		t.Fatalf("unexpected params %v", gs.params)
+	}
+
+	if err := Save(context.Background(), session, Person{}); err == nil {
+		t.Fatal("expected error for a non-pointer entity")
+	}
+	if err := Save(context.Background(), session, &Company{}); err != nil {
+		t.Fatalf("Save with zero key: %v", err)
+	}
+}
+
+func TestLoadWithRelations(t *testing.T) {
+	gs, session := startGraphServer(t, func(stmt string, params map[string]*pb.Value) []*pb.Node {
+		switch {
+		case strings.Contains(stmt, "-[:KNOWS]->"):
+			return []*pb.Node{personNode("bob@example.com", "Bob"), personNode("carol@example.com", "Carol")}
+		case strings.Contains(stmt, "<-[:MANAGES]-"):
+			return []*pb.Node{personNode("dana@example.com", "Dana")}
+		case params["p0"].GetStringValue() == "alice@example.com":
+			return []*pb.Node{personNode("alice@example.com", "Alice")}
+		}
+		return nil
+	})
+	ctx := context.Background()
+
+	alice, err := Load[Person](ctx, session, "alice@example.com", With("Friends", "Manager"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if alice.Name != "Alice" || len(alice.Friends) != 2 || alice.Friends[1].Name != "Carol" || alice.Manager.Name != "Dana" {
+		t.Fatalf("loaded %+v", alice)
+	}
+	wantStmt := "MATCH (n:Person {email: $key})-[:KNOWS]->(m:Person) RETURN m"
+	if gs.statements[1] != wantStmt {
+		t.Fatalf("relation statement = %q, want %q", gs.statements[1], wantStmt)
+	}
+
+	lazy, err := Load[Person](ctx, session, "alice@example.com")
+	if err != nil || lazy.Friends != nil {
+		t.Fatalf("Load without relations = %+v, %v", lazy, err)
+	}
+	if err := LoadRelated(ctx, session, lazy, "Friends"); err != nil || len(lazy.Friends) != 2 {
+		t.Fatalf("LoadRelated = %+v, %v", lazy.Friends, err)
+	}
+	if err := LoadRelated(ctx, session, lazy, "Enemies"); err == nil {
+		t.Fatal("expected error for an unknown relation")
+	}
+
+	if _, err := Load[Company](ctx, session, int64(1)); !errors.Is(err, gwp.ErrNoRows) {
+		t.Fatalf("Load of missing node error = %v", err)
+	}
+}
+
+func TestFindAndDelete(t *testing.T) {
+	gs, session := startGraphServer(t, nil)
+	ctx := context.Background()
+	if _, err := Find[Company](ctx, session, map[string]any{"Name": "Acme", "id": 7}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(ctx, session, &Company{ID: 7}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"MATCH (n:`Org Unit` {Name: $p0, id: $p1}) RETURN n",
+		"MATCH (n:`Org Unit` {id: $key}) DETACH DELETE n",
+	}
+	if !reflect.DeepEqual(gs.statements, want) {
+		t.Fatalf("statements = %q, want %q", gs.statements, want)
+	}
+	if gs.params[0]["p1"].GetIntegerValue() != 7 {
+		t.Fatalf("params = %v", gs.params[0])
+	}
+}
@@ -0,0 +1,215 @@
+package gwp
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+)
+
+// SessionOption configures a session created by CreateSession.
+type SessionOption func(*sessionOptions)
+
+type sessionOptions struct {
+	interceptors []StatementInterceptor
+	admission    *AdmissionConfig
+}
+
+func newSessionOptions(opts []SessionOption) *sessionOptions {
+	o := &sessionOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithInterceptors adds statement interceptors to the session. They run
+// after the connection's interceptors, in the order given.
+func WithInterceptors(interceptors ...StatementInterceptor) SessionOption {
+	return func(o *sessionOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// ExecuteOption configures a single Execute call.
+type ExecuteOption func(*executeOptions)
+
+type executeOptions struct {
+	profile   bool
+	rawFrames bool
+	rowLease  bool
+	columnar  bool
+	lazyProps bool
+	callOpts  []grpc.CallOption
+	queryName string
+	graph     string
+	schema    string
+	queryID   string
+
+	annotations map[string]string
+	metadata    []string
+
+	cache               bool
+	readOnlyTransaction bool
+}
+
+func newExecuteOptions(opts []ExecuteOption) *executeOptions {
+	o := &executeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithProfile executes the statement under PROFILE. The server runs the
+// statement and returns its profiled query plan in place of the statement's
+// rows. The protocol does not define the shape of the plan; read it from the
+// cursor's rows as the server returns them.
+func WithProfile() ExecuteOption {
+	return func(o *executeOptions) {
+		o.profile = true
+	}
+}
+
+// WithRawFrames returns row batches as protobuf messages without converting
+// values, for consumers such as exporters and proxies that forward or
+// re-encode them. Read the rows with ResultCursor.NextRawBatch; NextRow and
+// the methods built on it return an error.
+func WithRawFrames() ExecuteOption {
+	return func(o *executeOptions) {
+		o.rawFrames = true
+	}
+}
+
+// WithRowLease decodes each row into a slice reused across NextRow calls, so
+// a row is only valid until the next call to NextRow or NextRecord. Rows are
+// decoded one at a time as they are read instead of a batch at once, which
+// cuts allocation and GC pressure when streaming large results. Copy values
+// that must outlive the next call. CollectRows copies each row.
+func WithRowLease() ExecuteOption {
+	return func(o *executeOptions) {
+		o.rowLease = true
+	}
+}
+
+// WithColumnar decodes row batches column by column into typed buffers, for
+// numeric-heavy analytical reads where boxing each value into an interface
+// dominates decode time. Read the rows with ResultCursor.NextColumns; NextRow
+// and the methods built on it return an error.
+func WithColumnar() ExecuteOption {
+	return func(o *executeOptions) {
+		o.columnar = true
+	}
+}
+
+// WithLazyProperties leaves node and edge properties undecoded until they
+// are read, for results where callers mostly need IDs and labels. The
+// Properties field of such nodes and edges is nil; read properties with
+// Property or PropertyMap, which decodes them into Properties on first
+// call. Without this option properties are decoded eagerly, which suits
+// callers that read or mutate Properties directly.
+func WithLazyProperties() ExecuteOption {
+	return func(o *executeOptions) {
+		o.lazyProps = true
+	}
+}
+
+// WithCompression overrides the connection's compressor for this statement,
+// for example CompressionZstd for a bulk export or CompressionNone for a
+// small latency-sensitive query.
+func WithCompression(name string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.callOpts = append(o.callOpts, grpc.UseCompressor(name))
+	}
+}
+
+// WithMaxRecvMsgSize overrides ConnectionConfig.MaxRecvMsgSize for this
+// statement, for a query known to return large property values.
+func WithMaxRecvMsgSize(bytes int) ExecuteOption {
+	return func(o *executeOptions) {
+		o.callOpts = append(o.callOpts, grpc.MaxCallRecvMsgSize(bytes))
+	}
+}
+
+// WithGraph runs the statement against the named graph instead of the
+// session's current graph, without changing the session. Unlike calling
+// SetGraph around the statement, this is safe on a session shared between
+// goroutines.
+//
+// The wire protocol has no per-request graph field, so the statement is
+// prefixed with a GQL USE clause; the server must support USE for the
+// statement kind.
+func WithGraph(name string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.graph = name
+	}
+}
+
+// WithSchema resolves the statement's graph and type references in the
+// named schema instead of the session's current schema, by prefixing it
+// with a GQL AT clause. Like WithGraph, it leaves the session unchanged.
+func WithSchema(name string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.schema = name
+	}
+}
+
+// WithQueryID tags the statement with an ID chosen by the application, for
+// interceptors, CancelQuery and ResultSummary.QueryID. The ID is also sent
+// in the "gwp-query-id" request header for proxies and servers that log it,
+// but the protocol does not define that header and the server may ignore
+// it.
+func WithQueryID(id string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.queryID = id
+	}
+}
+
+// WithCallMetadata sends the given key-value pairs as request headers with
+// the statement, such as a tenant header or a routing cookie required by a
+// proxy in front of the server. Keys are lowercased, as gRPC requires. It
+// panics if kv has an odd number of elements.
+func WithCallMetadata(kv ...string) ExecuteOption {
+	checkMetadataPairs(kv)
+	return func(o *executeOptions) {
+		o.metadata = append(o.metadata, kv...)
+	}
+}
+
+// withQueryName records the registered name of the statement for
+// interceptors.
+func withQueryName(name string) ExecuteOption {
+	return func(o *executeOptions) {
+		o.queryName = name
+	}
+}
+
+// TransactionOption configures a transaction started by BeginTransaction.
+type TransactionOption func(*transactionOptions)
+
+type transactionOptions struct {
+	metadata []string
+}
+
+func newTransactionOptions(opts []TransactionOption) *transactionOptions {
+	o := &transactionOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTransactionMetadata sends the given key-value pairs as request
+// headers with every RPC of the transaction: its begin, its statements, and
+// its commit or rollback. It panics if kv has an odd number of elements.
+func WithTransactionMetadata(kv ...string) TransactionOption {
+	checkMetadataPairs(kv)
+	return func(o *transactionOptions) {
+		o.metadata = append(o.metadata, kv...)
+	}
+}
+
+func checkMetadataPairs(kv []string) {
+	if len(kv)%2 == 1 {
+		panic(fmt.Sprintf("gwp: got an odd number of metadata key-value strings: %d", len(kv)))
+	}
+}
@@ -0,0 +1,288 @@
+package gwp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/protobuf/proto"
+)
+
+// PageQuery is a query read a page at a time by a Paginator. The Paginator
+// renders it as
+//
+//	<Match> [FILTER <keyset condition>] RETURN <Return> ORDER BY <OrderBy> [OFFSET n] LIMIT n
+//
+// so Match and Return must not order or limit the results themselves.
+type PageQuery struct {
+	// Match is the statement up to its RETURN clause, such as
+	// "MATCH (p:Person WHERE p.age > $min)".
+	Match string
+	// Return lists the returned items, such as "p.id AS id, p.name AS name".
+	Return string
+	Params map[string]any
+	// OrderBy orders the results. Pages are stable only if the keys
+	// together identify a row.
+	OrderBy []PageKey
+	// Keyset continues each page after the keys of the previous page's
+	// last row, instead of skipping the rows before it with OFFSET, so
+	// that late pages cost no more than early ones and rows inserted or
+	// deleted meanwhile do not shift the pages. Every key needs a Column,
+	// and rows whose keys are null are never returned.
+	Keyset bool
+	// PageSize is the number of rows per page. Defaults to 100.
+	PageSize int
+	Options  []ExecuteOption
+}
+
+// PageKey is an ORDER BY key of a PageQuery.
+type PageKey struct {
+	// Expr is the ordering expression, such as "p.name".
+	Expr string
+	// Column is the result column holding the value of Expr, which keyset
+	// pagination reads from each page's last row.
+	Column     string
+	Descending bool
+}
+
+// Page is one page of results read by a Paginator.
+type Page struct {
+	Columns []string
+	Rows    [][]any
+	// Token resumes reading at the next page, as passed to NewPaginator,
+	// and is empty after the last page.
+	Token string
+}
+
+// Paginator reads a query a page at a time, for APIs that return results
+// in pages and hand clients an opaque token for the next one.
+type Paginator struct {
+	q     Querier
+	query PageQuery
+	// hash identifies the query and its parameters in tokens, so that a
+	// token is not used to resume a different query.
+	hash   []byte
+	offset int64
+	keys   []any
+	done   bool
+}
+
+// NewPaginator returns a Paginator reading query on q, from the first page
+// if token is empty and otherwise from the page the token was returned
+// for. A token is valid only for the query it was returned for.
+func NewPaginator(q Querier, query PageQuery, token string) (*Paginator, error) {
+	if query.PageSize <= 0 {
+		query.PageSize = 100
+	}
+	if query.Keyset {
+		if len(query.OrderBy) == 0 {
+			return nil, &GqlError{Message: "keyset pagination requires OrderBy"}
+		}
+		for i, k := range query.OrderBy {
+			if k.Column == "" {
+				return nil, &GqlError{Message: fmt.Sprintf("keyset pagination requires a column for order key %q", k.Expr)}
+			}
+			if _, ok := query.Params[pageParam(i)]; ok {
+				return nil, &ParamError{Path: "$" + pageParam(i), Message: "reserved for keyset pagination"}
+			}
+		}
+	}
+	hash, err := query.hash()
+	if err != nil {
+		return nil, err
+	}
+	p := &Paginator{q: q, query: query, hash: hash}
+	if token != "" {
+		if err := p.decodeToken(token); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Done reports whether the last page has been read.
+func (p *Paginator) Done() bool {
+	return p.done
+}
+
+// NextPage reads the next page. After the last page it returns nil.
+func (p *Paginator) NextPage(ctx context.Context) (*Page, error) {
+	if p.done {
+		return nil, nil
+	}
+	statement, params := p.statement()
+	cursor, err := p.q.Execute(ctx, statement, params, p.query.Options...)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := cursor.ColumnNames()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := cursor.CollectRows()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCursorStatus(cursor); err != nil {
+		return nil, err
+	}
+
+	page := &Page{Columns: columns, Rows: rows}
+	// One row more than a page is read to learn whether another follows.
+	if len(rows) <= p.query.PageSize {
+		p.done = true
+		return page, nil
+	}
+	page.Rows = rows[:p.query.PageSize]
+	if p.query.Keyset {
+		last := page.Rows[len(page.Rows)-1]
+		p.keys = make([]any, len(p.query.OrderBy))
+		for i, k := range p.query.OrderBy {
+			j := columnIndex(columns, k.Column)
+			if j < 0 || j >= len(last) {
+				return nil, &GqlError{Message: fmt.Sprintf("keyset pagination: result has no column %q", k.Column)}
+			}
+			p.keys[i] = last[j]
+		}
+	} else {
+		p.offset += int64(p.query.PageSize)
+	}
+	if page.Token, err = p.encodeToken(); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func columnIndex(columns []string, name string) int {
+	for i, c := range columns {
+		if c == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func pageParam(i int) string {
+	return "gwpPage" + strconv.Itoa(i)
+}
+
+// statement renders the statement reading the next page.
+func (p *Paginator) statement() (string, map[string]any) {
+	var b strings.Builder
+	b.WriteString(p.query.Match)
+	params := p.query.Params
+	if p.keys != nil {
+		params = maps.Clone(p.query.Params)
+		if params == nil {
+			params = make(map[string]any, len(p.keys))
+		}
+		// Rows after the last one: (k0 > $0) OR (k0 = $0 AND k1 > $1) ...
+		var or []string
+		for i, k := range p.query.OrderBy {
+			params[pageParam(i)] = p.keys[i]
+			var and []string
+			for j, prev := range p.query.OrderBy[:i] {
+				and = append(and, prev.Expr+" = $"+pageParam(j))
+			}
+			op := " > "
+			if k.Descending {
+				op = " < "
+			}
+			and = append(and, k.Expr+op+"$"+pageParam(i))
+			or = append(or, "("+strings.Join(and, " AND ")+")")
+		}
+		b.WriteString(" FILTER " + strings.Join(or, " OR "))
+	}
+	b.WriteString(" RETURN " + p.query.Return)
+	if len(p.query.OrderBy) > 0 {
+		keys := make([]string, len(p.query.OrderBy))
+		for i, k := range p.query.OrderBy {
+			keys[i] = k.Expr
+			if k.Descending {
+				keys[i] += " DESC"
+			}
+		}
+		b.WriteString(" ORDER BY " + strings.Join(keys, ", "))
+	}
+	if p.offset > 0 {
+		fmt.Fprintf(&b, " OFFSET %d", p.offset)
+	}
+	fmt.Fprintf(&b, " LIMIT %d", p.query.PageSize+1)
+	return b.String(), params
+}
+
+func (q *PageQuery) hash() ([]byte, error) {
+	h := sha256.New()
+	parts := []string{q.Match, q.Return, strconv.FormatBool(q.Keyset), strconv.Itoa(q.PageSize)}
+	for _, k := range q.OrderBy {
+		parts = append(parts, k.Expr, k.Column, strconv.FormatBool(k.Descending))
+	}
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	if err := hashParams(h, q.Params); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil)[:8], nil
+}
+
+// encodeToken encodes the position of the next page as a record of the
+// query's hash and the offset or keys, in base64.
+func (p *Paginator) encodeToken() (string, error) {
+	fields := []GqlField{{Name: "query", Value: p.hash}}
+	if p.keys != nil {
+		fields = append(fields, GqlField{Name: "keys", Value: p.keys})
+	} else {
+		fields = append(fields, GqlField{Name: "offset", Value: p.offset})
+	}
+	v, err := valueToProto(&GqlRecord{Fields: fields})
+	if err != nil {
+		return "", fmt.Errorf("page token: %w", err)
+	}
+	data, err := proto.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+var errInvalidPageToken = &GqlError{Message: "invalid page token"}
+
+func (p *Paginator) decodeToken(token string) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return errInvalidPageToken
+	}
+	var v pb.Value
+	if err := proto.Unmarshal(data, &v); err != nil {
+		return errInvalidPageToken
+	}
+	d := valueDecoder{}
+	record, ok := d.decode(&v).(*GqlRecord)
+	if !ok || d.err != nil {
+		return errInvalidPageToken
+	}
+	if hash, _ := record.Get("query").([]byte); string(hash) != string(p.hash) {
+		return &GqlError{Message: "page token is for a different query"}
+	}
+	if p.query.Keyset {
+		keys, ok := record.Get("keys").([]any)
+		if !ok || len(keys) != len(p.query.OrderBy) {
+			return errInvalidPageToken
+		}
+		p.keys = keys
+		return nil
+	}
+	offset, ok := record.Get("offset").(int64)
+	if !ok || offset < 0 {
+		return errInvalidPageToken
+	}
+	p.offset = offset
+	return nil
+}
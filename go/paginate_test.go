@@ -0,0 +1,126 @@
+package gwp
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// tableQuerier answers paged statements over the ids 1 to n, honoring the
+// OFFSET, LIMIT and keyset parameter of the statement.
+type tableQuerier struct {
+	n          int64
+	statements []string
+}
+
+var (
+	offsetPattern = regexp.MustCompile(`OFFSET (\d+)`)
+	limitPattern  = regexp.MustCompile(`LIMIT (\d+)`)
+)
+
+func (q *tableQuerier) Execute(ctx context.Context, statement string, params map[string]any, opts ...ExecuteOption) (*ResultCursor, error) {
+	q.statements = append(q.statements, statement)
+	first := int64(1)
+	if m := offsetPattern.FindStringSubmatch(statement); m != nil {
+		offset, _ := strconv.ParseInt(m[1], 10, 64)
+		first += offset
+	}
+	if after, ok := params["gwpPage0"].(int64); ok {
+		first = after + 1
+	}
+	limit, _ := strconv.ParseInt(limitPattern.FindStringSubmatch(statement)[1], 10, 64)
+	var rows [][]any
+	for id := first; id <= q.n && int64(len(rows)) < limit; id++ {
+		rows = append(rows, []any{id})
+	}
+	return newTestCursor(headerFrame("id"), batchFrame(rows...), summaryFrame(Success, 0)), nil
+}
+
+func readPages(t *testing.T, q Querier, query PageQuery) []int {
+	t.Helper()
+	var sizes []int
+	token := ""
+	for {
+		p, err := NewPaginator(q, query, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := p.NextPage(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(page.Rows))
+		if page.Token == "" {
+			if !p.Done() {
+				t.Error("Done() = false after the last page")
+			}
+			return sizes
+		}
+		token = page.Token
+	}
+}
+
+func TestPaginatorOffset(t *testing.T) {
+	q := &tableQuerier{n: 25}
+	query := PageQuery{Match: "MATCH (n:N)", Return: "n.id AS id", OrderBy: []PageKey{{Expr: "n.id"}}, PageSize: 10}
+	if got := readPages(t, q, query); len(got) != 3 || got[2] != 5 {
+		t.Fatalf("page sizes = %v, want [10 10 5]", got)
+	}
+	if want := "MATCH (n:N) RETURN n.id AS id ORDER BY n.id OFFSET 10 LIMIT 11"; q.statements[1] != want {
+		t.Errorf("statement = %q, want %q", q.statements[1], want)
+	}
+}
+
+func TestPaginatorKeyset(t *testing.T) {
+	q := &tableQuerier{n: 20}
+	query := PageQuery{
+		Match: "MATCH (n:N)", Return: "n.id AS id",
+		OrderBy: []PageKey{{Expr: "n.id", Column: "id"}}, Keyset: true, PageSize: 10,
+	}
+	if got := readPages(t, q, query); len(got) != 2 || got[1] != 10 {
+		t.Fatalf("page sizes = %v, want [10 10]", got)
+	}
+	if want := "MATCH (n:N) FILTER (n.id > $gwpPage0) RETURN n.id AS id ORDER BY n.id LIMIT 11"; q.statements[1] != want {
+		t.Errorf("statement = %q, want %q", q.statements[1], want)
+	}
+}
+
+func TestPaginatorKeysetCondition(t *testing.T) {
+	p := &Paginator{query: PageQuery{
+		Match: "MATCH (p:Person)", Return: "p.name AS name, p.id AS id", PageSize: 5, Keyset: true,
+		OrderBy: []PageKey{{Expr: "p.name", Column: "name", Descending: true}, {Expr: "p.id", Column: "id"}},
+	}, keys: []any{"Bob", int64(7)}}
+	statement, params := p.statement()
+	want := "MATCH (p:Person) FILTER (p.name < $gwpPage0) OR (p.name = $gwpPage0 AND p.id > $gwpPage1)" +
+		" RETURN p.name AS name, p.id AS id ORDER BY p.name DESC, p.id LIMIT 6"
+	if statement != want {
+		t.Errorf("statement = %q, want %q", statement, want)
+	}
+	if params["gwpPage0"] != "Bob" || params["gwpPage1"] != int64(7) {
+		t.Errorf("params = %v", params)
+	}
+}
+
+func TestPaginatorToken(t *testing.T) {
+	q := &tableQuerier{n: 25}
+	query := PageQuery{Match: "MATCH (n:N)", Return: "n.id AS id", PageSize: 10}
+	p, _ := NewPaginator(q, query, "")
+	page, err := p.NextPage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := query
+	other.Match = "MATCH (m:M)"
+	if _, err := NewPaginator(q, other, page.Token); err == nil {
+		t.Error("token accepted for a different query")
+	}
+	other = query
+	other.Params = map[string]any{"min": int64(3)}
+	if _, err := NewPaginator(q, other, page.Token); err == nil {
+		t.Error("token accepted for different parameters")
+	}
+	if _, err := NewPaginator(q, query, "not a token!"); err == nil {
+		t.Error("invalid token accepted")
+	}
+}
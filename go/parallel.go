@@ -0,0 +1,130 @@
+package gwp
+
+import (
+	"context"
+	"sync"
+)
+
+// Statement is a statement with its parameters and options, for running
+// several at once with ExecuteParallel.
+type Statement struct {
+	Statement string
+	Params    map[string]any
+	Options   []ExecuteOption
+}
+
+// ParallelConfig controls ExecuteParallel.
+type ParallelConfig struct {
+	// Concurrency limits the number of statements running at once. Zero
+	// means the pool's MaxSessions, or all statements at once if the pool
+	// has no limit.
+	Concurrency int
+	// FailFast cancels the remaining statements after the first failure.
+	// By default every statement runs and each result records its own
+	// error.
+	FailFast bool
+}
+
+// ParallelResult is the fully read result of one statement run by
+// ExecuteParallel.
+type ParallelResult struct {
+	Columns []string
+	Rows    [][]any
+	Summary *ResultSummary
+	// Err is the statement's error, including an exception status in its
+	// summary. With FailFast, statements cancelled or never started after
+	// a failure report the context error.
+	Err error
+}
+
+// ExecuteParallel runs independent statements concurrently on sessions
+// from pool and reads their results. Results are returned in statement
+// order. The error is the first failure in statement order, or with
+// FailFast the failure that stopped the run.
+func ExecuteParallel(ctx context.Context, pool *Pool, statements []Statement, config ParallelConfig) ([]ParallelResult, error) {
+	limit := config.Concurrency
+	if limit <= 0 {
+		limit = pool.config.MaxSessions
+	}
+	if limit <= 0 || limit > len(statements) {
+		limit = len(statements)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]ParallelResult, len(statements))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, limit)
+	for i, stmt := range statements {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = executeCollect(ctx, pool, stmt)
+			if err := results[i].Err; err != nil && config.FailFast {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return results, firstErr
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			return results, r.Err
+		}
+	}
+	return results, nil
+}
+
+// executeCollect runs one statement on a pooled session and reads its
+// result.
+func executeCollect(ctx context.Context, pool *Pool, stmt Statement) (r ParallelResult) {
+	s, err := pool.Acquire(ctx)
+	if err != nil {
+		return ParallelResult{Err: err}
+	}
+	defer func() {
+		if err := pool.Release(context.WithoutCancel(ctx), s); err != nil && r.Err == nil {
+			r.Err = err
+		}
+	}()
+
+	cursor, err := s.Execute(ctx, stmt.Statement, stmt.Params, stmt.Options...)
+	if err != nil {
+		return ParallelResult{Err: err}
+	}
+	if r.Columns, r.Err = cursor.ColumnNames(); r.Err != nil {
+		return r
+	}
+	if r.Rows, r.Err = cursor.CollectRows(); r.Err != nil {
+		return r
+	}
+	if r.Summary, r.Err = cursor.Summary(); r.Err != nil {
+		return r
+	}
+	if r.Summary != nil {
+		r.Err = r.Summary.Err()
+	}
+	return r
+}
@@ -0,0 +1,114 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+)
+
+// parallelServer echoes each statement as a row, fails statements named
+// "FAIL" and blocks on "SLOW" until cancelled, tracking peak concurrency.
+type parallelServer struct {
+	handshakeServer
+	pb.UnimplementedGqlServiceServer
+	running, peak atomic.Int32
+}
+
+func (s *parallelServer) Execute(r *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	n := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		peak := s.peak.Load()
+		if n <= peak || s.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	switch r.Statement {
+	case "FAIL":
+		return stream.Send(summaryFrame(InvalidSyntax, 0))
+	case "SLOW":
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	}
+	if err := stream.Send(headerFrame("statement")); err != nil {
+		return err
+	}
+	if err := stream.Send(batchFrame([]any{r.Statement})); err != nil {
+		return err
+	}
+	return stream.Send(summaryFrame(Success, 0))
+}
+
+func newParallelPool(t *testing.T, config PoolConfig) (*Pool, *parallelServer) {
+	t.Helper()
+	server := &parallelServer{}
+	pool := NewPool(connectBufconn(t, serveBufconn(t, server), ConnectionConfig{}), config)
+	t.Cleanup(func() { pool.Close(context.Background()) })
+	return pool, server
+}
+
+func TestExecuteParallel(t *testing.T) {
+	ctx := context.Background()
+	pool, server := newParallelPool(t, PoolConfig{MaxIdle: 2})
+
+	var statements []Statement
+	for _, s := range []string{"a", "b", "FAIL", "c", "d", "e"} {
+		statements = append(statements, Statement{Statement: s})
+	}
+	results, err := ExecuteParallel(ctx, pool, statements, ParallelConfig{Concurrency: 2})
+	var statusErr *GqlStatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != InvalidSyntax {
+		t.Fatalf("err = %v, want the FAIL statement's status", err)
+	}
+	for i, r := range results {
+		if i == 2 {
+			if r.Err == nil {
+				t.Fatal("FAIL statement has no error")
+			}
+			continue
+		}
+		if r.Err != nil || len(r.Rows) != 1 || r.Rows[0][0] != statements[i].Statement {
+			t.Fatalf("results[%d] = %+v", i, r)
+		}
+	}
+	if peak := server.peak.Load(); peak > 2 {
+		t.Fatalf("peak concurrency = %d, want at most 2", peak)
+	}
+	if stats := pool.Stats(); stats.InUse != 0 {
+		t.Fatalf("sessions still in use: %+v", stats)
+	}
+}
+
+func TestExecuteParallelFailFast(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := newParallelPool(t, PoolConfig{MaxSessions: 2})
+
+	statements := []Statement{{Statement: "SLOW"}, {Statement: "FAIL"}, {Statement: "a"}, {Statement: "b"}}
+	results, err := ExecuteParallel(ctx, pool, statements, ParallelConfig{FailFast: true})
+	if !IsException(statusCode(err)) {
+		t.Fatalf("err = %v, want the FAIL statement's status", err)
+	}
+	if results[0].Err == nil {
+		t.Fatal("running statement was not cancelled")
+	}
+	for _, r := range results[2:] {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Fatalf("unstarted statement err = %v, want context.Canceled", r.Err)
+		}
+	}
+}
+
+func statusCode(err error) string {
+	var statusErr *GqlStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package gwp
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Default parameter limits.
+const (
+	DefaultParamMaxDepth  = 64
+	DefaultParamMaxValues = 1 << 20
+)
+
+// ParamLimits bounds the parameter values of a statement, so a deeply
+// nested or very large value is rejected with a *ParamError before it is
+// sent instead of exhausting the stack or producing an oversized request.
+// Self-referential lists, maps and pointers are always rejected.
+type ParamLimits struct {
+	// MaxDepth limits the nesting of lists and records. Defaults to
+	// DefaultParamMaxDepth.
+	MaxDepth int
+	// MaxValues limits the number of values across all parameters of a
+	// statement, counting every list element, vector coordinate and record
+	// field. Defaults to DefaultParamMaxValues.
+	MaxValues int
+	// MaxBytes limits the total length of the string and byte values of a
+	// statement's parameters. Zero means no limit.
+	MaxBytes int
+}
+
+// paramLimits returns the parameter limits of the session's connection.
+func (s *GqlSession) paramLimits() ParamLimits {
+	if s.conn == nil {
+		return ParamLimits{}
+	}
+	return s.conn.config.ParamLimits
+}
+
+// strictConversion reports whether the session's connection fails on
+// values of unsupported types instead of converting them to NULL and nil.
+func (s *GqlSession) strictConversion() bool {
+	return s.conn != nil && s.conn.config.StrictConversion
+}
+
+// paramEncoder converts parameter values to protobuf Values, enforcing
+// ParamLimits across the values it converts.
+type paramEncoder struct {
+	limits   ParamLimits
+	values   int
+	bytes    int
+	visiting map[visitKey]bool
+}
+
+// visitKey identifies a list, map or pointer being converted. Slices are
+// told apart by length too, as a slice and its prefix share an address.
+type visitKey struct {
+	kind reflect.Kind
+	ptr  uintptr
+	len  int
+}
+
+func newParamEncoder(limits ParamLimits) *paramEncoder {
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = DefaultParamMaxDepth
+	}
+	if limits.MaxValues <= 0 {
+		limits.MaxValues = DefaultParamMaxValues
+	}
+	return &paramEncoder{limits: limits}
+}
+
+// count adds values and bytes to the totals and checks them against the
+// limits.
+func (e *paramEncoder) count(path string, values, bytes int) error {
+	e.values += values
+	e.bytes += bytes
+	if e.values > e.limits.MaxValues {
+		return &ParamError{Path: path, Message: fmt.Sprintf("parameters exceed %d values", e.limits.MaxValues)}
+	}
+	if e.limits.MaxBytes > 0 && e.bytes > e.limits.MaxBytes {
+		return &ParamError{Path: path, Message: fmt.Sprintf("parameters exceed %d bytes of strings and bytes", e.limits.MaxBytes)}
+	}
+	return nil
+}
+
+// enter checks the depth of a list, record or pointer and that it is not
+// already being converted, then marks it as being converted until leave.
+func (e *paramEncoder) enter(rv reflect.Value, path string, depth int) error {
+	if rv.Kind() != reflect.Pointer && depth >= e.limits.MaxDepth {
+		return &ParamError{Path: path, Message: fmt.Sprintf("nested more than %d levels deep", e.limits.MaxDepth)}
+	}
+	key, ok := visitKeyOf(rv)
+	if !ok {
+		return nil
+	}
+	if e.visiting[key] {
+		return &ParamError{Path: path, Message: "value contains itself"}
+	}
+	if e.visiting == nil {
+		e.visiting = make(map[visitKey]bool)
+	}
+	e.visiting[key] = true
+	return nil
+}
+
+func (e *paramEncoder) leave(rv reflect.Value) {
+	if key, ok := visitKeyOf(rv); ok {
+		delete(e.visiting, key)
+	}
+}
+
+func visitKeyOf(rv reflect.Value) (visitKey, bool) {
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.Len() == 0 {
+			return visitKey{}, false
+		}
+		return visitKey{kind: reflect.Slice, ptr: rv.Pointer(), len: rv.Len()}, true
+	case reflect.Map, reflect.Pointer:
+		return visitKey{kind: rv.Kind(), ptr: rv.Pointer()}, true
+	}
+	return visitKey{}, false
+}
@@ -0,0 +1,178 @@
+package gwp
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// StatementParams returns the distinct $name parameter references in a
+// statement, in order of first appearance. References inside string
+// literals, quoted identifiers and comments are ignored.
+func StatementParams(statement string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	s := statement
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(s, i)
+		case strings.HasPrefix(s[i:], "//") || strings.HasPrefix(s[i:], "--"):
+			if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(s)
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(s)
+			}
+		case c == '$':
+			if i+1 < len(s) && s[i+1] == '`' {
+				end := skipQuoted(s, i+1)
+				if end < len(s) {
+					add(strings.ReplaceAll(s[i+2:end], "``", "`"))
+				}
+				i = end
+				continue
+			}
+			j := i + 1
+			for j < len(s) && isIdentByte(s[j], j == i+1) {
+				j++
+			}
+			add(s[i+1 : j])
+			i = j - 1
+		}
+	}
+	return names
+}
+
+// SplitStatements splits a script at semicolons outside quoted strings,
+// quoted identifiers and comments. It returns the complete statements,
+// trimmed and without empty ones, and the unterminated remainder, which
+// is empty when the script ends with a semicolon.
+func SplitStatements(script string) (statements []string, rest string) {
+	start := 0
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(script, i)
+		case c == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return statements, script[start:]
+			}
+			i += end + 3
+		case (c == '-' || c == '/') && i+1 < len(script) && script[i+1] == c:
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				return statements, script[start:]
+			}
+			i += end
+		case c == ';':
+			if stmt := strings.TrimSpace(script[start:i]); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			start = i + 1
+		}
+	}
+	return statements, script[start:]
+}
+
+// skipQuoted returns the index of the quote closing the literal that opens
+// at s[start]. Backslash escapes and doubled quotes are skipped.
+func skipQuoted(s string, start int) int {
+	q := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case q:
+			if i+1 < len(s) && s[i+1] == q {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isIdentByte(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+// ParameterError reports a mismatch between a statement's parameter
+// references and the parameters supplied with it.
+type ParameterError struct {
+	// Missing are parameters the statement references but were not given.
+	Missing []string
+	// Unused are parameters given but not referenced by the statement.
+	Unused []string
+}
+
+func (e *ParameterError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing parameters: $"+strings.Join(e.Missing, ", $"))
+	}
+	if len(e.Unused) > 0 {
+		parts = append(parts, "unused parameters: "+strings.Join(e.Unused, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ValidateParams checks that params supplies every parameter statement
+// references and, unless allowUnused is set, no others. It returns a
+// *ParameterError describing any mismatch.
+func ValidateParams(statement string, params map[string]any, allowUnused bool) error {
+	refs := StatementParams(statement)
+	e := &ParameterError{}
+	referenced := make(map[string]bool, len(refs))
+	for _, name := range refs {
+		referenced[name] = true
+		if _, ok := params[name]; !ok {
+			e.Missing = append(e.Missing, name)
+		}
+	}
+	if !allowUnused {
+		for name := range params {
+			if !referenced[name] {
+				e.Unused = append(e.Unused, name)
+			}
+		}
+		sort.Strings(e.Unused)
+	}
+	if len(e.Missing) == 0 && len(e.Unused) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ParamValidator is a StatementInterceptor that rejects statements whose
+// parameters do not match their $name references before they are sent.
+type ParamValidator struct {
+	// AllowUnused accepts parameters the statement does not reference.
+	AllowUnused bool
+}
+
+// BeforeExecute implements StatementInterceptor.
+func (v ParamValidator) BeforeExecute(ctx context.Context, info *StatementInfo) (context.Context, error) {
+	return ctx, ValidateParams(info.Statement, info.Params, v.AllowUnused)
+}
+
+// AfterExecute implements StatementInterceptor.
+func (v ParamValidator) AfterExecute(ctx context.Context, info *StatementInfo, result StatementResult) {
+}
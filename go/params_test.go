@@ -0,0 +1,84 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStatementParams(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want []string
+	}{
+		{"MATCH (n {name: $name}) WHERE n.age > $min RETURN n, $name", []string{"name", "min"}},
+		{"RETURN '$notParam', \"$nor\", `$this`", nil},
+		{"RETURN 'it''s $x' + $y", []string{"y"}},
+		{"RETURN $a // $b\n + $c -- $d", []string{"a", "c"}},
+		{"RETURN /* $hidden */ $shown", []string{"shown"}},
+		{"RETURN $`odd name`, $_p1", []string{"odd name", "_p1"}},
+		{"RETURN $1 + $", nil},
+	}
+	for _, tt := range tests {
+		if got := StatementParams(tt.stmt); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("StatementParams(%q) = %q, want %q", tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements, rest := SplitStatements("MATCH (n) RETURN n;\n INSERT (:P {s: 'a;b'}) ; ;-- c;\n/* d; */RETURN `e;`;\nRETURN 1")
+	want := []string{"MATCH (n) RETURN n", "INSERT (:P {s: 'a;b'})", "-- c;\n/* d; */RETURN `e;`"}
+	if !reflect.DeepEqual(statements, want) {
+		t.Fatalf("statements = %q, want %q", statements, want)
+	}
+	if rest != "\nRETURN 1" {
+		t.Fatalf("rest = %q", rest)
+	}
+
+	statements, rest = SplitStatements("RETURN 'unterminated;")
+	if statements != nil || rest != "RETURN 'unterminated;" {
+		t.Fatalf("unterminated string split: %q, %q", statements, rest)
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	stmt := "MATCH (n {name: $name}) WHERE n.age > $min RETURN n"
+
+	if err := ValidateParams(stmt, map[string]any{"name": "Alice", "min": 30}, false); err != nil {
+		t.Fatalf("ValidateParams: %v", err)
+	}
+
+	err := ValidateParams(stmt, nil, false)
+	var pe *ParameterError
+	if !errors.As(err, &pe) || !reflect.DeepEqual(pe.Missing, []string{"name", "min"}) {
+		t.Fatalf("nil params: %v", err)
+	}
+	if !strings.Contains(err.Error(), "$name, $min") {
+		t.Fatalf("error message = %q", err.Error())
+	}
+
+	err = ValidateParams(stmt, map[string]any{"name": "A", "min": 1, "zeta": 0, "extra": 1}, false)
+	if !errors.As(err, &pe) || len(pe.Missing) != 0 || !reflect.DeepEqual(pe.Unused, []string{"extra", "zeta"}) {
+		t.Fatalf("unused params: %v", err)
+	}
+	if err := ValidateParams(stmt, map[string]any{"name": "A", "min": 1, "extra": 1}, true); err != nil {
+		t.Fatalf("AllowUnused: %v", err)
+	}
+}
+
+func TestParamValidatorInterceptor(t *testing.T) {
+	client := &fakeGqlClient{}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, interceptors: interceptorChain{ParamValidator{}}}
+
+	_, err := s.Execute(context.Background(), "RETURN $x", nil)
+	var pe *ParameterError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Execute = %v, want ParameterError", err)
+	}
+	if client.lastReq != nil {
+		t.Fatal("invalid statement should not be sent")
+	}
+}
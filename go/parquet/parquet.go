@@ -0,0 +1,384 @@
+// Package parquet exports query results to Parquet files, for pipelines
+// that load graph query results into a lakehouse without an intermediate
+// CSV or JSON file.
+//
+//	cursor, err := session.Execute(ctx,
+//	    "MATCH (p:Person) RETURN p.name AS name, p.age AS age", nil, gwp.WithRowLease())
+//	if err != nil {
+//	    return err
+//	}
+//	rows, err := parquet.Export(cursor, file, parquet.Config{})
+//
+// Each result column becomes an optional top-level Parquet column. Its
+// type comes from the result header: booleans, integers, floats, strings,
+// byte strings, dates and datetimes map to the matching Parquet types, and
+// other values, such as nodes, lists and records, are written as JSON.
+// Columns the header leaves untyped take their type from the first row.
+package parquet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// Config controls Export.
+type Config struct {
+	// Compression is the codec for column pages. Nil means Snappy.
+	Compression compress.Codec
+	// RowGroupSize is the maximum number of rows in a row group. Zero
+	// means the writer's default.
+	RowGroupSize int64
+}
+
+// kind is the Parquet representation of a result column.
+type kind int
+
+const (
+	kindJSON kind = iota
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindString
+	kindBytes
+	kindDate
+	kindLocalTimestamp
+	kindTimestamp
+)
+
+func (k kind) node() pq.Node {
+	switch k {
+	case kindBool:
+		return pq.Leaf(pq.BooleanType)
+	case kindInt:
+		return pq.Int(64)
+	case kindUint:
+		return pq.Uint(64)
+	case kindFloat:
+		return pq.Leaf(pq.DoubleType)
+	case kindString:
+		return pq.String()
+	case kindBytes:
+		return pq.Leaf(pq.ByteArrayType)
+	case kindDate:
+		return pq.Date()
+	case kindLocalTimestamp:
+		return pq.TimestampAdjusted(pq.Nanosecond, false)
+	case kindTimestamp:
+		return pq.Timestamp(pq.Nanosecond)
+	default:
+		return pq.JSON()
+	}
+}
+
+// typeKind maps a declared column type to its Parquet representation. ok
+// is false for untyped columns.
+func typeKind(t pb.GqlType) (k kind, ok bool) {
+	switch t {
+	case pb.GqlType_TYPE_UNKNOWN, pb.GqlType_TYPE_NULL:
+		return kindJSON, false
+	case pb.GqlType_TYPE_BOOLEAN:
+		return kindBool, true
+	case pb.GqlType_TYPE_INT8, pb.GqlType_TYPE_INT16, pb.GqlType_TYPE_INT32, pb.GqlType_TYPE_INT64:
+		return kindInt, true
+	case pb.GqlType_TYPE_UINT8, pb.GqlType_TYPE_UINT16, pb.GqlType_TYPE_UINT32, pb.GqlType_TYPE_UINT64:
+		return kindUint, true
+	case pb.GqlType_TYPE_FLOAT16, pb.GqlType_TYPE_FLOAT32, pb.GqlType_TYPE_FLOAT64:
+		return kindFloat, true
+	case pb.GqlType_TYPE_STRING:
+		return kindString, true
+	case pb.GqlType_TYPE_BYTES:
+		return kindBytes, true
+	case pb.GqlType_TYPE_DATE:
+		return kindDate, true
+	case pb.GqlType_TYPE_LOCAL_DATETIME:
+		return kindLocalTimestamp, true
+	case pb.GqlType_TYPE_ZONED_DATETIME:
+		return kindTimestamp, true
+	default:
+		return kindJSON, true
+	}
+}
+
+// valueKind infers the Parquet representation of an untyped column from
+// one of its values.
+func valueKind(v any) kind {
+	switch v.(type) {
+	case bool:
+		return kindBool
+	case int64:
+		return kindInt
+	case uint64:
+		return kindUint
+	case float64:
+		return kindFloat
+	case string:
+		return kindString
+	case []byte:
+		return kindBytes
+	case *gwp.GqlDate:
+		return kindDate
+	case *gwp.GqlLocalDateTime:
+		return kindLocalTimestamp
+	case *gwp.GqlZonedDateTime:
+		return kindTimestamp
+	default:
+		return kindJSON
+	}
+}
+
+// column is a result column and its place in the Parquet schema.
+type column struct {
+	name  string
+	kind  kind
+	index int
+}
+
+// Export writes the remaining rows of cursor's current result set to w as
+// a Parquet file and returns the number of rows written. It reads rows
+// with NextRow, so the cursor may use WithRowLease but not WithRawFrames
+// or WithColumnar. The file is complete only if Export returns no error.
+func Export(cursor *gwp.ResultCursor, w io.Writer, config Config) (int64, error) {
+	names, err := cursor.ColumnNames()
+	if err != nil {
+		return 0, err
+	}
+	header, err := cursor.RawHeader()
+	if err != nil {
+		return 0, err
+	}
+	first, err := cursor.NextRow()
+	if err != nil {
+		return 0, err
+	}
+
+	columns := make([]*column, len(names))
+	group := make(pq.Group, len(names))
+	for i, name := range names {
+		if _, dup := group[name]; dup {
+			return 0, fmt.Errorf("parquet: duplicate column %q", name)
+		}
+		var t pb.GqlType
+		if header != nil && i < len(header.Columns) {
+			t = header.Columns[i].GetType().GetType()
+		}
+		k, ok := typeKind(t)
+		if !ok && first != nil && first[i] != nil {
+			k = valueKind(first[i])
+		}
+		columns[i] = &column{name: name, kind: k}
+		group[name] = pq.Optional(k.node())
+	}
+	schema := pq.NewSchema("result", group)
+	for _, c := range columns {
+		leaf, _ := schema.Lookup(c.name)
+		c.index = leaf.ColumnIndex
+	}
+
+	options := []pq.WriterOption{schema}
+	codec := config.Compression
+	if codec == nil {
+		codec = &pq.Snappy
+	}
+	options = append(options, pq.Compression(codec))
+	if config.RowGroupSize > 0 {
+		options = append(options, pq.MaxRowsPerRowGroup(config.RowGroupSize))
+	}
+	writer := pq.NewWriter(w, options...)
+
+	const batchSize = 1024
+	var (
+		written int64
+		batch   = make([]pq.Row, 0, batchSize)
+	)
+	flush := func() error {
+		if _, err := writer.WriteRows(batch); err != nil {
+			return err
+		}
+		written += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+	for row := first; row != nil; {
+		values := make(pq.Row, len(columns))
+		for i, c := range columns {
+			if i >= len(row) {
+				return written, fmt.Errorf("parquet: row has %d values, want %d", len(row), len(columns))
+			}
+			v, err := c.value(row[i])
+			if err != nil {
+				return written, err
+			}
+			values[c.index] = v
+		}
+		batch = append(batch, values)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+		if row, err = cursor.NextRow(); err != nil {
+			return written, err
+		}
+	}
+	if err := flush(); err != nil {
+		return written, err
+	}
+	return written, writer.Close()
+}
+
+// value converts a result value to the column's Parquet value.
+func (c *column) value(v any) (pq.Value, error) {
+	if v == nil {
+		return pq.NullValue().Level(0, 0, c.index), nil
+	}
+	var (
+		out pq.Value
+		ok  = true
+	)
+	switch c.kind {
+	case kindBool:
+		var b bool
+		b, ok = v.(bool)
+		out = pq.BooleanValue(b)
+	case kindInt:
+		var n int64
+		n, ok = v.(int64)
+		out = pq.Int64Value(n)
+	case kindUint:
+		var n uint64
+		n, ok = v.(uint64)
+		if !ok {
+			if i, isInt := v.(int64); isInt && i >= 0 {
+				n, ok = uint64(i), true
+			}
+		}
+		out = pq.Int64Value(int64(n))
+	case kindFloat:
+		var f float64
+		switch x := v.(type) {
+		case float64:
+			f = x
+		case int64:
+			f = float64(x)
+		default:
+			ok = false
+		}
+		out = pq.DoubleValue(f)
+	case kindString:
+		var s string
+		s, ok = v.(string)
+		out = pq.ByteArrayValue([]byte(s))
+	case kindBytes:
+		var b []byte
+		b, ok = v.([]byte)
+		out = pq.ByteArrayValue(b)
+	case kindDate:
+		var d *gwp.GqlDate
+		if d, ok = v.(*gwp.GqlDate); ok {
+			days := dateTime(*d).Unix() / 86400
+			if days < math.MinInt32 || days > math.MaxInt32 {
+				return pq.Value{}, fmt.Errorf("parquet: column %q: date %v out of range", c.name, *d)
+			}
+			out = pq.Int32Value(int32(days))
+		}
+	case kindLocalTimestamp:
+		var t *gwp.GqlLocalDateTime
+		if t, ok = v.(*gwp.GqlLocalDateTime); ok {
+			out = pq.Int64Value(localTime(t.Date, t.Time, 0).UnixNano())
+		}
+	case kindTimestamp:
+		var t *gwp.GqlZonedDateTime
+		if t, ok = v.(*gwp.GqlZonedDateTime); ok {
+			out = pq.Int64Value(localTime(t.Date, t.Time, t.OffsetMinutes).UnixNano())
+		}
+	default:
+		b, err := json.Marshal(jsonValue(v))
+		if err != nil {
+			return pq.Value{}, fmt.Errorf("parquet: column %q: %w", c.name, err)
+		}
+		out = pq.ByteArrayValue(b)
+	}
+	if !ok {
+		return pq.Value{}, fmt.Errorf("parquet: column %q: %T value in a %s column", c.name, v, c.kind.node().Type())
+	}
+	return out.Level(0, 1, c.index), nil
+}
+
+func dateTime(d gwp.GqlDate) time.Time {
+	return time.Date(int(d.Year), time.Month(d.Month), int(d.Day), 0, 0, 0, 0, time.UTC)
+}
+
+// localTime returns the instant of a date and time at a UTC offset.
+func localTime(d gwp.GqlDate, t gwp.GqlLocalTime, offsetMinutes int32) time.Time {
+	return time.Date(int(d.Year), time.Month(d.Month), int(d.Day),
+		int(t.Hour), int(t.Minute), int(t.Second), int(t.Nanosecond),
+		time.FixedZone("", int(offsetMinutes)*60))
+}
+
+// jsonValue converts a result value to plain JSON data. Graph elements
+// become objects with their labels and properties.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case nil, bool, int64, uint64, float64, string, []byte:
+		return v
+	case *gwp.GqlNode:
+		return map[string]any{"id": v.ID, "labels": v.Labels, "properties": jsonMap(v.PropertyMap())}
+	case *gwp.GqlEdge:
+		return map[string]any{
+			"id": v.ID, "labels": v.Labels, "source": v.SourceNodeID, "target": v.TargetNodeID,
+			"properties": jsonMap(v.PropertyMap()),
+		}
+	case *gwp.GqlPath:
+		nodes := make([]any, len(v.Nodes))
+		for i, n := range v.Nodes {
+			nodes[i] = jsonValue(n)
+		}
+		edges := make([]any, len(v.Edges))
+		for i, e := range v.Edges {
+			edges[i] = jsonValue(e)
+		}
+		return map[string]any{"nodes": nodes, "edges": edges}
+	case *gwp.GqlRecord:
+		out := make(map[string]any, len(v.Fields))
+		for _, f := range v.Fields {
+			out[f.Name] = jsonValue(f.Value)
+		}
+		return out
+	case map[string]any:
+		return jsonMap(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = jsonValue(e)
+		}
+		return out
+	case *gwp.GqlDate:
+		return dateTime(*v).Format(time.DateOnly)
+	case *gwp.GqlLocalDateTime:
+		return localTime(v.Date, v.Time, 0).Format("2006-01-02T15:04:05.999999999")
+	case *gwp.GqlZonedDateTime:
+		return localTime(v.Date, v.Time, v.OffsetMinutes).Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func jsonMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = jsonValue(v)
+	}
+	return out
+}
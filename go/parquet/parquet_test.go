@@ -0,0 +1,159 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	pq "github.com/parquet-go/parquet-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// resultServer answers every statement with frames.
+type resultServer struct {
+	pb.UnimplementedSessionServiceServer
+	pb.UnimplementedGqlServiceServer
+	frames []*pb.ExecuteResponse
+}
+
+func (resultServer) Handshake(ctx context.Context, r *pb.HandshakeRequest) (*pb.HandshakeResponse, error) {
+	return &pb.HandshakeResponse{ProtocolVersion: 1, SessionId: "in-process"}, nil
+}
+
+func (resultServer) Close(ctx context.Context, r *pb.CloseRequest) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
+
+func (s resultServer) Execute(r *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	for _, f := range s.frames {
+		if err := stream.Send(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func execute(t *testing.T, frames ...*pb.ExecuteResponse) *gwp.ResultCursor {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	rs := resultServer{frames: frames}
+	pb.RegisterSessionServiceServer(srv, rs)
+	pb.RegisterGqlServiceServer(srv, rs)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	ctx := context.Background()
+	conn, err := gwp.ConnectWithConfig(ctx, "bufnet", gwp.ConnectionConfig{
+		Dialer: func(ctx context.Context, addr string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := session.Execute(ctx, "MATCH (p) RETURN p", nil, gwp.WithRowLease())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cursor
+}
+
+func columnDescriptor(name string, t pb.GqlType) *pb.ColumnDescriptor {
+	return &pb.ColumnDescriptor{Name: name, Type: &pb.TypeDescriptor{Type: t}}
+}
+
+func row(values ...*pb.Value) *pb.Row {
+	return &pb.Row{Values: values}
+}
+
+var null = &pb.Value{Kind: &pb.Value_NullValue{NullValue: &pb.NullValue{}}}
+
+func str(s string) *pb.Value { return &pb.Value{Kind: &pb.Value_StringValue{StringValue: s}} }
+func num(n int64) *pb.Value  { return &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: n}} }
+
+func TestExport(t *testing.T) {
+	date := &pb.Value{Kind: &pb.Value_DateValue{DateValue: &pb.Date{Year: 1970, Month: 1, Day: 3}}}
+	node := &pb.Value{Kind: &pb.Value_NodeValue{NodeValue: &pb.Node{
+		Labels: []string{"Person"}, Properties: map[string]*pb.Value{"name": str("Alice")},
+	}}}
+	cursor := execute(t,
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_Header{Header: &pb.ResultHeader{Columns: []*pb.ColumnDescriptor{
+			columnDescriptor("name", pb.GqlType_TYPE_STRING),
+			columnDescriptor("age", pb.GqlType_TYPE_INT64),
+			columnDescriptor("born", pb.GqlType_TYPE_DATE),
+			columnDescriptor("p", pb.GqlType_TYPE_NODE),
+			columnDescriptor("score", pb.GqlType_TYPE_UNKNOWN),
+		}}}},
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: &pb.RowBatch{Rows: []*pb.Row{
+			row(str("Alice"), num(30), date, node, &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: 0.5}}),
+			row(str("Bob"), null, null, null, null),
+		}}}},
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_Summary{Summary: &pb.ResultSummary{Status: &pb.GqlStatus{Code: gwp.Success}}}},
+	)
+
+	var buf bytes.Buffer
+	n, err := Export(cursor, &buf, Config{})
+	if err != nil || n != 2 {
+		t.Fatalf("Export = %d, %v", n, err)
+	}
+
+	file, err := pq.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.NumRows() != 2 {
+		t.Fatalf("file has %d rows", file.NumRows())
+	}
+	schema := file.Schema()
+	if leaf, ok := schema.Lookup("score"); !ok || leaf.Node.Type().Kind() != pq.Double {
+		t.Fatalf("score column = %v, want an inferred double", leaf.Node)
+	}
+
+	reader := pq.NewReader(bytes.NewReader(buf.Bytes()))
+	rows := make([]pq.Row, 2)
+	if n, _ := reader.ReadRows(rows); n != 2 {
+		t.Fatalf("read %d rows", n)
+	}
+	get := func(r pq.Row, name string) pq.Value {
+		leaf, _ := schema.Lookup(name)
+		return r[leaf.ColumnIndex]
+	}
+	if v := get(rows[0], "age"); v.Int64() != 30 {
+		t.Fatalf("age = %v", v)
+	}
+	if v := get(rows[0], "born"); v.Int32() != 2 {
+		t.Fatalf("born = %v days", v)
+	}
+	if v := get(rows[0], "p"); !bytes.Contains(v.ByteArray(), []byte(`"name":"Alice"`)) {
+		t.Fatalf("p = %s", v.ByteArray())
+	}
+	if v := get(rows[1], "age"); !v.IsNull() {
+		t.Fatalf("age = %v, want null", v)
+	}
+	if v := get(rows[1], "name"); string(v.ByteArray()) != "Bob" {
+		t.Fatalf("name = %v", v)
+	}
+}
+
+func TestExportTypeMismatch(t *testing.T) {
+	cursor := execute(t,
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_Header{Header: &pb.ResultHeader{Columns: []*pb.ColumnDescriptor{
+			columnDescriptor("n", pb.GqlType_TYPE_INT64),
+		}}}},
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: &pb.RowBatch{Rows: []*pb.Row{row(str("one"))}}}},
+	)
+	if _, err := Export(cursor, &bytes.Buffer{}, Config{}); err == nil {
+		t.Fatal("expected an error for a string in an INT64 column")
+	}
+}
@@ -0,0 +1,10 @@
+package gwp
+
+import "context"
+
+// Explain executes the statement under EXPLAIN, so the server returns its
+// query plan without running it. The protocol does not define the shape of
+// the plan; read it from the cursor's rows as the server returns them.
+func (s *GqlSession) Explain(ctx context.Context, statement string, params map[string]any) (*ResultCursor, error) {
+	return s.Execute(ctx, "EXPLAIN "+statement, params)
+}
@@ -0,0 +1,203 @@
+package gwp
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// SRIDWGS84 is the spatial reference system of GPS coordinates and GeoJSON.
+const SRIDWGS84 = 4326
+
+// GqlPoint is a 2D or 3D spatial point in a coordinate reference system.
+//
+// GWP has no spatial value kind, so points are sent as a record with fields
+// srid, x, y and, for 3D points, z. AsPoint converts such a record back.
+type GqlPoint struct {
+	SRID        uint32
+	Coordinates []float64
+}
+
+// NewPoint returns a point with the given SRID and coordinates.
+func NewPoint(srid uint32, coordinates ...float64) *GqlPoint {
+	return &GqlPoint{SRID: srid, Coordinates: coordinates}
+}
+
+// X returns the first coordinate (longitude for geographic points).
+func (p *GqlPoint) X() float64 { return p.coord(0) }
+
+// Y returns the second coordinate (latitude for geographic points).
+func (p *GqlPoint) Y() float64 { return p.coord(1) }
+
+// Z returns the third coordinate, or 0 for a 2D point.
+func (p *GqlPoint) Z() float64 { return p.coord(2) }
+
+func (p *GqlPoint) coord(i int) float64 {
+	if i < len(p.Coordinates) {
+		return p.Coordinates[i]
+	}
+	return 0
+}
+
+// WKT formats the point as Well-Known Text, prefixed with "SRID=n;" (EWKT)
+// when SRID is set. It fails unless the point has 2 or 3 coordinates.
+func (p *GqlPoint) WKT() (string, error) {
+	if err := p.checkDims(); err != nil {
+		return "", err
+	}
+	return p.format(), nil
+}
+
+// String returns the point's WKT. Unlike WKT it does not check the number
+// of coordinates, and lists all of them.
+func (p *GqlPoint) String() string {
+	return p.format()
+}
+
+func (p *GqlPoint) checkDims() error {
+	if n := len(p.Coordinates); n < 2 || n > 3 {
+		return &GqlError{Message: "point must have 2 or 3 coordinates, not " + strconv.Itoa(n)}
+	}
+	return nil
+}
+
+func (p *GqlPoint) format() string {
+	var b strings.Builder
+	if p.SRID != 0 {
+		b.WriteString("SRID=" + strconv.FormatUint(uint64(p.SRID), 10) + ";")
+	}
+	b.WriteString("POINT")
+	if len(p.Coordinates) == 3 {
+		b.WriteString(" Z")
+	}
+	b.WriteString(" (")
+	for i, c := range p.Coordinates {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.FormatFloat(c, 'g', -1, 64))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// ParseWKT parses a POINT in Well-Known Text, optionally prefixed with
+// "SRID=n;".
+func ParseWKT(s string) (*GqlPoint, error) {
+	invalid := &GqlError{Message: "invalid WKT point: " + s}
+	p := &GqlPoint{}
+	rest := strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToUpper(rest), "SRID=") {
+		sridText, body, ok := strings.Cut(rest[len("SRID="):], ";")
+		if !ok {
+			return nil, invalid
+		}
+		srid, err := strconv.ParseUint(strings.TrimSpace(sridText), 10, 32)
+		if err != nil {
+			return nil, invalid
+		}
+		p.SRID = uint32(srid)
+		rest = strings.TrimSpace(body)
+	}
+
+	upper := strings.ToUpper(rest)
+	if !strings.HasPrefix(upper, "POINT") {
+		return nil, invalid
+	}
+	rest = strings.TrimSpace(rest[len("POINT"):])
+	want := 0
+	if strings.HasPrefix(strings.ToUpper(rest), "Z") {
+		want = 3
+		rest = strings.TrimSpace(rest[1:])
+	}
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return nil, invalid
+	}
+	for _, field := range strings.Fields(rest[1 : len(rest)-1]) {
+		c, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		p.Coordinates = append(p.Coordinates, c)
+	}
+	if n := len(p.Coordinates); n < 2 || n > 3 || want != 0 && n != want {
+		return nil, invalid
+	}
+	return p, nil
+}
+
+type geoJSONPoint struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// GeoJSON encodes the point as a GeoJSON Point geometry. GeoJSON
+// coordinates are always WGS 84, so the SRID is not encoded. It fails
+// unless the point has 2 or 3 coordinates.
+func (p *GqlPoint) GeoJSON() ([]byte, error) {
+	if err := p.checkDims(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(geoJSONPoint{Type: "Point", Coordinates: p.Coordinates})
+}
+
+// ParseGeoJSON parses a GeoJSON Point geometry. The result has SRID 4326.
+func ParseGeoJSON(data []byte) (*GqlPoint, error) {
+	var g geoJSONPoint
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, &GqlError{Message: "invalid GeoJSON point: " + err.Error()}
+	}
+	if g.Type != "Point" || len(g.Coordinates) < 2 || len(g.Coordinates) > 3 {
+		return nil, &GqlError{Message: "invalid GeoJSON point: expected a Point with 2 or 3 coordinates"}
+	}
+	return &GqlPoint{SRID: SRIDWGS84, Coordinates: g.Coordinates}, nil
+}
+
+var pointAxes = []string{"x", "y", "z"}
+
+func pointToProto(p *GqlPoint) *pb.Value {
+	fields := []*pb.Field{{Name: "srid", Value: &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: int64(p.SRID)}}}}
+	for i, c := range p.Coordinates {
+		if i >= len(pointAxes) {
+			break
+		}
+		fields = append(fields, &pb.Field{Name: pointAxes[i], Value: &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: c}}})
+	}
+	return &pb.Value{Kind: &pb.Value_RecordValue{RecordValue: &pb.Record{Fields: fields}}}
+}
+
+// AsPoint converts a point-like result value to a GqlPoint. It accepts a
+// *GqlPoint, a record with srid, x, y and optional z fields, and a WKT string.
+func AsPoint(v any) (*GqlPoint, bool) {
+	switch v := v.(type) {
+	case *GqlPoint:
+		return v, true
+	case string:
+		p, err := ParseWKT(v)
+		return p, err == nil
+	case *GqlRecord:
+		p := &GqlPoint{}
+		if srid, ok := v.Get("srid").(int64); ok {
+			p.SRID = uint32(srid)
+		}
+	axes:
+		for _, axis := range pointAxes {
+			switch c := v.Get(axis).(type) {
+			case float64:
+				p.Coordinates = append(p.Coordinates, c)
+			case int64:
+				p.Coordinates = append(p.Coordinates, float64(c))
+			default:
+				break axes
+			}
+		}
+		if len(p.Coordinates) < 2 {
+			return nil, false
+		}
+		return p, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,88 @@
+package gwp
+
+import "testing"
+
+func TestPointWKT(t *testing.T) {
+	tests := []struct {
+		in   string
+		srid uint32
+		dims int
+		out  string
+	}{
+		{"POINT (1 2)", 0, 2, "POINT (1 2)"},
+		{"point(1.5 -2)", 0, 2, "POINT (1.5 -2)"},
+		{"SRID=4326;POINT(4.89 52.37)", 4326, 2, "SRID=4326;POINT (4.89 52.37)"},
+		{"POINT Z (1 2 3)", 0, 3, "POINT Z (1 2 3)"},
+		{"POINT (1 2 3)", 0, 3, "POINT Z (1 2 3)"},
+	}
+	for _, tt := range tests {
+		p, err := ParseWKT(tt.in)
+		if err != nil {
+			t.Fatalf("ParseWKT(%q): %v", tt.in, err)
+		}
+		if p.SRID != tt.srid || len(p.Coordinates) != tt.dims {
+			t.Fatalf("ParseWKT(%q) = %+v", tt.in, p)
+		}
+		if wkt, err := p.WKT(); err != nil || wkt != tt.out {
+			t.Fatalf("WKT() = %q, %v, want %q", wkt, err, tt.out)
+		}
+	}
+	for _, p := range []*GqlPoint{NewPoint(0), NewPoint(0, 1), NewPoint(0, 1, 2, 3, 4)} {
+		if _, err := p.WKT(); err == nil {
+			t.Fatalf("WKT() of %d coordinates: expected error", len(p.Coordinates))
+		}
+		if _, err := p.GeoJSON(); err == nil {
+			t.Fatalf("GeoJSON() of %d coordinates: expected error", len(p.Coordinates))
+		}
+	}
+	for _, bad := range []string{"", "LINESTRING (1 2, 3 4)", "POINT (1)", "POINT Z (1 2)", "POINT (a b)", "SRID=x;POINT (1 2)"} {
+		if _, err := ParseWKT(bad); err == nil {
+			t.Fatalf("ParseWKT(%q): expected error", bad)
+		}
+	}
+}
+
+func TestPointGeoJSON(t *testing.T) {
+	p := NewPoint(SRIDWGS84, 4.89, 52.37)
+	data, err := p.GeoJSON()
+	if err != nil {
+		t.Fatalf("GeoJSON: %v", err)
+	}
+	if string(data) != `{"type":"Point","coordinates":[4.89,52.37]}` {
+		t.Fatalf("GeoJSON = %s", data)
+	}
+	back, err := ParseGeoJSON(data)
+	if err != nil {
+		t.Fatalf("ParseGeoJSON: %v", err)
+	}
+	if back.SRID != SRIDWGS84 || back.X() != 4.89 || back.Y() != 52.37 || back.Z() != 0 {
+		t.Fatalf("ParseGeoJSON = %+v", back)
+	}
+	if _, err := ParseGeoJSON([]byte(`{"type":"LineString","coordinates":[[1,2],[3,4]]}`)); err == nil {
+		t.Fatal("expected error for non-point geometry")
+	}
+}
+
+func TestPointRoundTrip(t *testing.T) {
+	p := NewPoint(7203, 1, 2, 3)
+	got, ok := AsPoint(valueFromProto(mustValueToProto(p)))
+	if !ok {
+		t.Fatal("AsPoint failed on encoded point")
+	}
+	if got.SRID != 7203 || got.X() != 1 || got.Y() != 2 || got.Z() != 3 {
+		t.Fatalf("round trip = %+v", got)
+	}
+	value, err := valueToProto(*p)
+	if err != nil {
+		t.Fatalf("encoding a GqlPoint value: %v", err)
+	}
+	if got, ok := AsPoint(valueFromProto(value)); !ok || got.SRID != 7203 || got.Z() != 3 {
+		t.Fatalf("round trip of a value = %+v, %v", got, ok)
+	}
+	if _, ok := AsPoint(&GqlRecord{Fields: []GqlField{{Name: "x", Value: 1.0}}}); ok {
+		t.Fatal("AsPoint should reject a record without y")
+	}
+	if wkt, ok := AsPoint("POINT (3 4)"); !ok || wkt.Y() != 4 {
+		t.Fatalf("AsPoint(WKT) = %+v, %v", wkt, ok)
+	}
+}
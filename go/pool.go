@@ -0,0 +1,148 @@
+package gwp
+
+import (
+	"context"
+	"sync"
+)
+
+// PoolConfig holds configuration for a session pool.
+type PoolConfig struct {
+	// MaxSessions limits the number of sessions checked out or idle at
+	// once. Zero means no limit.
+	MaxSessions int
+	// MaxIdle limits the number of idle sessions kept for reuse. Zero
+	// means sessions are closed when released.
+	MaxIdle int
+	// SessionOptions are applied to every session the pool creates.
+	SessionOptions []SessionOption
+}
+
+// Pool reuses sessions on a connection.
+type Pool struct {
+	conn   *GqlConnection
+	config PoolConfig
+	slots  chan struct{}
+
+	mu     sync.Mutex
+	idle   []*GqlSession
+	inUse  map[*GqlSession]struct{}
+	closed bool
+}
+
+// NewPool creates a session pool on the connection.
+func NewPool(conn *GqlConnection, config PoolConfig) *Pool {
+	p := &Pool{
+		conn:   conn,
+		config: config,
+		inUse:  make(map[*GqlSession]struct{}),
+	}
+	if config.MaxSessions > 0 {
+		p.slots = make(chan struct{}, config.MaxSessions)
+	}
+	return p
+}
+
+// Acquire returns an idle session or creates a new one, waiting for a free
+// slot if MaxSessions is reached. Return it with Release.
+func (p *Pool) Acquire(ctx context.Context) (*GqlSession, error) {
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.releaseSlot()
+		return nil, &SessionError{Message: "pool is closed"}
+	}
+	var s *GqlSession
+	if n := len(p.idle); n > 0 {
+		s = p.idle[n-1]
+		p.idle = p.idle[:n-1]
+	}
+	p.mu.Unlock()
+
+	if s == nil {
+		var err error
+		s, err = p.conn.CreateSession(ctx, p.config.SessionOptions...)
+		if err != nil {
+			p.releaseSlot()
+			return nil, err
+		}
+	}
+
+	p.mu.Lock()
+	p.inUse[s] = struct{}{}
+	p.mu.Unlock()
+	return s, nil
+}
+
+// Release returns a session to the pool. A session kept for reuse is Reset
+// first, so the next caller does not inherit its graph, schema, time zone,
+// session parameters or default parameters. The session is closed if the
+// pool is full or closed, or if the reset fails.
+func (p *Pool) Release(ctx context.Context, s *GqlSession) error {
+	p.mu.Lock()
+	if _, ok := p.inUse[s]; !ok {
+		p.mu.Unlock()
+		return &SessionError{Message: "session was not acquired from this pool"}
+	}
+	defer p.releaseSlot()
+	delete(p.inUse, s)
+	keep := !p.closed && !s.isClosed() && len(p.idle) < p.config.MaxIdle
+	p.mu.Unlock()
+
+	if keep && s.Reset(ctx) == nil {
+		p.mu.Lock()
+		keep = !p.closed && len(p.idle) < p.config.MaxIdle
+		if keep {
+			p.idle = append(p.idle, s)
+		}
+		p.mu.Unlock()
+		if keep {
+			return nil
+		}
+	}
+	return s.Close(ctx)
+}
+
+// PoolStats describes the sessions held by a pool.
+type PoolStats struct {
+	InUse int
+	Idle  int
+}
+
+// Stats returns the number of checked-out and idle sessions.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{InUse: len(p.inUse), Idle: len(p.idle)}
+}
+
+// Close closes all idle sessions. Sessions still checked out are closed when
+// released.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	var firstErr error
+	for _, s := range idle {
+		if err := s.Close(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (p *Pool) releaseSlot() {
+	if p.slots != nil {
+		<-p.slots
+	}
+}
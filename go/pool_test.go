@@ -0,0 +1,68 @@
+package gwp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPoolAcquireRelease(t *testing.T) {
+	ctx := context.Background()
+	conn := connectBufconn(t, serveBufconn(t, handshakeServer{}), ConnectionConfig{})
+	pool := NewPool(conn, PoolConfig{MaxSessions: 2, MaxIdle: 1})
+	defer pool.Close(ctx)
+
+	s1, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Release(ctx, s1); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Release(ctx, s1); err == nil {
+		t.Fatal("released a session twice")
+	}
+	s2, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s2 != s1 {
+		t.Fatal("idle session not reused")
+	}
+	if stats := pool.Stats(); stats.InUse != 1 || stats.Idle != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	pool.Release(ctx, s2)
+}
+
+func TestPoolReleaseResetsSession(t *testing.T) {
+	ctx := context.Background()
+	pool := NewPool(connectBufconn(t, serveBufconn(t, handshakeServer{}), ConnectionConfig{}), PoolConfig{MaxIdle: 1})
+	defer pool.Close(ctx)
+
+	s, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetGraph(ctx, "tenant_a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetParameter(ctx, "tenant", "a"); err != nil {
+		t.Fatal(err)
+	}
+	s.SetDefaultParams(map[string]any{"tenant": "a"})
+	if err := pool.Release(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != s {
+		t.Fatal("idle session not reused")
+	}
+	if state := next.State(); state.Graph != "" || len(state.Parameters) != 0 || len(state.DefaultParams) != 0 {
+		t.Fatalf("state after release = %+v", state)
+	}
+	pool.Release(ctx, next)
+}
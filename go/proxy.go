@@ -0,0 +1,185 @@
+package gwp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// proxyDialer returns a dialer that reaches servers through the proxy set in
+// config, or chosen per address from the environment.
+func proxyDialer(config ConnectionConfig) (func(ctx context.Context, addr string) (net.Conn, error), error) {
+	var proxyFor func(addr string) (*url.URL, error)
+	if config.Proxy != "" {
+		u, err := parseProxyURL(config.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxyFor = func(string) (*url.URL, error) { return u, nil }
+	} else {
+		proxyFor = proxyFromEnvironment()
+	}
+
+	var direct net.Dialer
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		if path, ok := unixSocketPath(addr); ok {
+			return direct.DialContext(ctx, "unix", path)
+		}
+		u, err := proxyFor(addr)
+		if err != nil {
+			return nil, err
+		}
+		if u == nil {
+			return direct.DialContext(ctx, "tcp", addr)
+		}
+		return dialProxy(ctx, u, addr)
+	}, nil
+}
+
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, &GqlError{Message: "invalid proxy URL: " + err.Error()}
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u, nil
+	}
+	return nil, &GqlError{Message: fmt.Sprintf("unsupported proxy scheme %q", u.Scheme)}
+}
+
+// proxyFromEnvironment chooses a proxy from HTTPS_PROXY, or else ALL_PROXY,
+// honoring NO_PROXY. Like net/http, it does not proxy loopback addresses.
+func proxyFromEnvironment() func(addr string) (*url.URL, error) {
+	env := httpproxy.FromEnvironment()
+	if env.HTTPSProxy == "" {
+		env.HTTPSProxy = getenvAny("ALL_PROXY", "all_proxy")
+	}
+	choose := env.ProxyFunc()
+	return func(addr string) (*url.URL, error) {
+		u, err := choose(&url.URL{Scheme: "https", Host: addr})
+		if err != nil || u == nil {
+			return nil, err
+		}
+		return parseProxyURL(u.String())
+	}
+}
+
+func getenvAny(names ...string) string {
+	for _, n := range names {
+		if v := os.Getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// unixSocketPath returns the socket path of a Unix domain socket address.
+func unixSocketPath(addr string) (string, bool) {
+	switch {
+	case strings.HasPrefix(addr, "unix://"):
+		return strings.TrimPrefix(addr, "unix://"), true
+	case strings.HasPrefix(addr, "unix:"):
+		return strings.TrimPrefix(addr, "unix:"), true
+	case strings.HasPrefix(addr, "/"):
+		return addr, true
+	}
+	return "", false
+}
+
+// dialProxy opens a tunnel to addr through the proxy at u.
+func dialProxy(ctx context.Context, u *url.URL, addr string) (net.Conn, error) {
+	if u.Scheme == "socks5" || u.Scheme == "socks5h" {
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		d, err := proxy.SOCKS5("tcp", hostPort(u, "1080"), auth, proxy.Direct)
+		if err != nil {
+			return nil, err
+		}
+		return d.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+	}
+	return dialConnect(ctx, u, addr)
+}
+
+// dialConnect opens a tunnel with an HTTP CONNECT request.
+func dialConnect(ctx context.Context, u *url.URL, addr string) (net.Conn, error) {
+	var d net.Dialer
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+	conn, err := d.DialContext(ctx, "tcp", hostPort(u, port))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, &GqlError{Message: fmt.Sprintf("proxy %s refused CONNECT to %s: %s", u.Host, addr, resp.Status)}
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+// bufferedConn reads bytes the server sent right after the CONNECT
+// response before reading from the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
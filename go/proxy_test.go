@@ -0,0 +1,186 @@
+package gwp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// listenLoopback listens on a loopback TCP port or skips the test.
+func listenLoopback(t *testing.T) net.Listener {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("loopback TCP unavailable: %v", err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	return lis
+}
+
+// tunnel relays bytes between a client and the dialed target.
+func tunnel(client net.Conn, target string) {
+	backend, err := net.Dial("tcp", target)
+	if err != nil {
+		client.Close()
+		return
+	}
+	go func() {
+		io.Copy(backend, client)
+		backend.Close()
+	}()
+	io.Copy(client, backend)
+	client.Close()
+}
+
+// serveConnectProxy runs an HTTP CONNECT proxy requiring the given
+// Proxy-Authorization header, counting the tunnels it opens.
+func serveConnectProxy(t *testing.T, auth string) (string, *atomic.Int32) {
+	lis := listenLoopback(t)
+	var tunnels atomic.Int32
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				req, err := http.ReadRequest(bufio.NewReader(conn))
+				if err != nil || req.Method != http.MethodConnect {
+					conn.Close()
+					return
+				}
+				if req.Header.Get("Proxy-Authorization") != auth {
+					io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+					conn.Close()
+					return
+				}
+				tunnels.Add(1)
+				io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+				tunnel(conn, req.Host)
+			}()
+		}
+	}()
+	return lis.Addr().String(), &tunnels
+}
+
+// serveSOCKS5 runs a SOCKS5 proxy accepting username/password
+// authentication and CONNECT requests.
+func serveSOCKS5(t *testing.T, user, password string) (string, *atomic.Int32) {
+	lis := listenLoopback(t)
+	var tunnels atomic.Int32
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				r := bufio.NewReader(conn)
+				head := make([]byte, 2)
+				io.ReadFull(r, head)
+				io.ReadFull(r, make([]byte, head[1]))
+				conn.Write([]byte{5, 2})
+
+				// Username/password subnegotiation (RFC 1929).
+				ver, _ := r.ReadByte()
+				ulen, _ := r.ReadByte()
+				u := make([]byte, ulen)
+				io.ReadFull(r, u)
+				plen, _ := r.ReadByte()
+				p := make([]byte, plen)
+				io.ReadFull(r, p)
+				if ver != 1 || string(u) != user || string(p) != password {
+					conn.Write([]byte{1, 1})
+					conn.Close()
+					return
+				}
+				conn.Write([]byte{1, 0})
+
+				req := make([]byte, 4)
+				io.ReadFull(r, req)
+				var host string
+				switch req[3] {
+				case 1:
+					ip := make([]byte, 4)
+					io.ReadFull(r, ip)
+					host = net.IP(ip).String()
+				case 3:
+					n, _ := r.ReadByte()
+					name := make([]byte, n)
+					io.ReadFull(r, name)
+					host = string(name)
+				}
+				port := make([]byte, 2)
+				io.ReadFull(r, port)
+				tunnels.Add(1)
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				tunnel(conn, net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+			}()
+		}
+	}()
+	return lis.Addr().String(), &tunnels
+}
+
+func TestConnectThroughProxy(t *testing.T) {
+	backend := listenLoopback(t)
+	serve(t, backend, handshakeServer{})
+
+	connectAddr, connectTunnels := serveConnectProxy(t, "Basic dXNlcjpzZWNyZXQ=")
+	socksAddr, socksTunnels := serveSOCKS5(t, "user", "secret")
+	tests := []struct {
+		proxy   string
+		tunnels *atomic.Int32
+	}{
+		{"http://user:secret@" + connectAddr, connectTunnels},
+		{"socks5://user:secret@" + socksAddr, socksTunnels},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		conn, err := ConnectWithConfig(ctx, backend.Addr().String(), ConnectionConfig{Proxy: tt.proxy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.CreateSession(ctx); err != nil {
+			t.Fatalf("CreateSession via %s: %v", tt.proxy, err)
+		}
+		conn.Close(ctx)
+		if tt.tunnels.Load() == 0 {
+			t.Fatalf("no tunnel opened by %s", tt.proxy)
+		}
+	}
+
+	conn, err := ConnectWithConfig(ctx, backend.Addr().String(), ConnectionConfig{Proxy: "http://" + connectAddr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	if _, err := conn.CreateSession(ctx); err == nil {
+		t.Fatal("proxy without credentials should refuse the tunnel")
+	}
+}
+
+func TestProxyFromEnvironment(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("ALL_PROXY", "socks5://proxy.example:1080")
+	t.Setenv("NO_PROXY", "internal.example")
+
+	proxyFor := proxyFromEnvironment()
+	if u, err := proxyFor("db.example:50051"); err != nil || u == nil || u.Host != "proxy.example:1080" {
+		t.Fatalf("proxy for db.example = %v, %v", u, err)
+	}
+	if u, err := proxyFor("db.internal.example:50051"); err != nil || u != nil {
+		t.Fatalf("proxy for NO_PROXY host = %v, %v", u, err)
+	}
+
+	if _, err := ConnectWithConfig(context.Background(), "db.example:50051", ConnectionConfig{Proxy: "ftp://proxy"}); err == nil {
+		t.Fatal("unsupported proxy scheme accepted")
+	}
+}
@@ -0,0 +1,125 @@
+package gwp
+
+import (
+	"bufio"
+	"context"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// RegisterQuery registers statement text under a name for ExecuteNamed.
+// Registering an existing name replaces its text.
+func (c *GqlConnection) RegisterQuery(name, statement string) {
+	c.queriesMu.Lock()
+	defer c.queriesMu.Unlock()
+	if c.queries == nil {
+		c.queries = make(map[string]string)
+	}
+	c.queries[name] = statement
+}
+
+// Query returns the statement registered under name.
+func (c *GqlConnection) Query(name string) (string, bool) {
+	c.queriesMu.RLock()
+	defer c.queriesMu.RUnlock()
+	stmt, ok := c.queries[name]
+	return stmt, ok
+}
+
+// LoadQueries registers the queries in every .gql file in fsys. A file may
+// hold several queries, each introduced by a "-- name: <Name>" line as in
+// gwpgen input; a file without name annotations is registered under its path
+// without the extension. Queries already registered under the same names
+// are replaced, so calling LoadQueries again reloads changed files.
+func (c *GqlConnection) LoadQueries(fsys fs.FS) error {
+	loaded := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".gql" {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		for name, stmt := range parseQueryFile(strings.TrimSuffix(p, ".gql"), string(data)) {
+			loaded[name] = stmt
+		}
+		return nil
+	})
+	if err != nil {
+		return &GqlError{Message: "failed to load queries: " + err.Error()}
+	}
+
+	c.queriesMu.Lock()
+	defer c.queriesMu.Unlock()
+	if c.queries == nil {
+		c.queries = make(map[string]string)
+	}
+	for name, stmt := range loaded {
+		c.queries[name] = stmt
+	}
+	return nil
+}
+
+// parseQueryFile splits a .gql file into named statements.
+func parseQueryFile(defaultName, text string) map[string]string {
+	queries := make(map[string]string)
+	var name string
+	var body []string
+	flush := func() {
+		if stmt := strings.TrimSpace(strings.Join(body, "\n")); name != "" && stmt != "" {
+			queries[name] = strings.TrimSuffix(stmt, ";")
+		}
+		body = nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(trimmed, "-- name:"); ok {
+			flush()
+			name, _, _ = strings.Cut(strings.TrimSpace(rest), " ")
+			continue
+		}
+		if strings.HasPrefix(trimmed, "-- ") && strings.Contains(trimmed, ":") && len(body) == 0 {
+			continue // other annotations, such as -- param:
+		}
+		body = append(body, line)
+	}
+	if len(queries) == 0 && name == "" {
+		name = defaultName
+	}
+	flush()
+	return queries
+}
+
+// ExecuteNamed executes the statement registered under name on the
+// session's connection. Interceptors see the name in StatementInfo.QueryName.
+func (s *GqlSession) ExecuteNamed(ctx context.Context, name string, params map[string]any, opts ...ExecuteOption) (*ResultCursor, error) {
+	stmt, err := s.namedQuery(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.execute(ctx, nil, stmt, params, append(opts, withQueryName(name)))
+}
+
+// ExecuteNamed executes the statement registered under name within this
+// transaction.
+func (t *Transaction) ExecuteNamed(ctx context.Context, name string, params map[string]any, opts ...ExecuteOption) (*ResultCursor, error) {
+	stmt, err := t.session.namedQuery(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.Execute(ctx, stmt, params, append(opts, withQueryName(name))...)
+}
+
+func (s *GqlSession) namedQuery(name string) (string, error) {
+	if s.conn != nil {
+		if stmt, ok := s.conn.Query(name); ok {
+			return stmt, nil
+		}
+	}
+	return "", &GqlError{Message: "unknown query: " + name}
+}
@@ -0,0 +1,73 @@
+package gwp
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func TestLoadQueries(t *testing.T) {
+	fsys := fstest.MapFS{
+		"users.gql": {Data: []byte(`-- name: GetUser :one
+-- param: id INT
+MATCH (u:User {id: $id}) RETURN u;
+
+-- name: ListUsers :many
+MATCH (u:User)
+RETURN u
+`)},
+		"reports/daily.gql": {Data: []byte("MATCH (o:Order) RETURN count(o)\n")},
+		"README.md":         {Data: []byte("-- name: Ignored\nRETURN 1")},
+	}
+	c := &GqlConnection{}
+	if err := c.LoadQueries(fsys); err != nil {
+		t.Fatalf("LoadQueries: %v", err)
+	}
+
+	tests := map[string]string{
+		"GetUser":       "MATCH (u:User {id: $id}) RETURN u",
+		"ListUsers":     "MATCH (u:User)\nRETURN u",
+		"reports/daily": "MATCH (o:Order) RETURN count(o)",
+	}
+	for name, want := range tests {
+		if got, ok := c.Query(name); !ok || got != want {
+			t.Fatalf("Query(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+	if _, ok := c.Query("Ignored"); ok {
+		t.Fatal("non-.gql files should be ignored")
+	}
+
+	fsys["reports/daily.gql"] = &fstest.MapFile{Data: []byte("MATCH (o:Order) RETURN sum(o.total)")}
+	if err := c.LoadQueries(fsys); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got, _ := c.Query("reports/daily"); got != "MATCH (o:Order) RETURN sum(o.total)" {
+		t.Fatalf("reloaded query = %q", got)
+	}
+}
+
+func TestExecuteNamed(t *testing.T) {
+	client := &fakeGqlClient{stream: &fakeStream{frames: []*pb.ExecuteResponse{summaryFrame(Success, 0)}}}
+	conn := &GqlConnection{}
+	conn.RegisterQuery("CountUsers", "MATCH (u:User) RETURN count(u)")
+
+	var seen string
+	spy := StatementInterceptorFuncs{Before: func(ctx context.Context, info *StatementInfo) (context.Context, error) {
+		seen = info.QueryName
+		return ctx, nil
+	}}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, conn: conn, interceptors: interceptorChain{spy}}
+
+	if _, err := s.ExecuteNamed(context.Background(), "CountUsers", nil); err != nil {
+		t.Fatalf("ExecuteNamed: %v", err)
+	}
+	if client.lastReq.Statement != "MATCH (u:User) RETURN count(u)" || seen != "CountUsers" {
+		t.Fatalf("sent %q with name %q", client.lastReq.Statement, seen)
+	}
+	if _, err := s.ExecuteNamed(context.Background(), "Missing", nil); err == nil {
+		t.Fatal("expected error for unknown query")
+	}
+}
@@ -0,0 +1,174 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// Cond is a boolean condition in a WHERE clause.
+type Cond interface {
+	// writeCond writes the condition; self is the variable of the element
+	// pattern the condition belongs to, if any.
+	writeCond(w *writer, self string)
+}
+
+// Property refers to a property of a variable.
+type Property struct {
+	variable string
+	name     string
+}
+
+// Prop refers to a property of the node or edge whose Where the condition
+// is passed to.
+func Prop(name string) Property {
+	return Property{name: name}
+}
+
+// Var returns a reference to a variable, for naming its properties with
+// Prop.
+func Var(variable string) Variable {
+	return Variable(variable)
+}
+
+// Variable is a variable bound by a pattern.
+type Variable string
+
+// Prop refers to a property of the variable.
+func (v Variable) Prop(name string) Property {
+	return Property{variable: string(v), name: name}
+}
+
+func (p Property) write(w *writer, self string) {
+	v := p.variable
+	if v == "" {
+		v = self
+	}
+	if v == "" {
+		w.fail(fmt.Errorf("query: property %s has no variable; name it with Var or give its pattern a variable", p.name))
+		return
+	}
+	w.WriteString(gwp.EscapeIdentifier(v) + "." + gwp.EscapeIdentifier(p.name))
+}
+
+// Eq is true if the property equals value.
+func (p Property) Eq(value any) Cond { return comparison{p, "=", value} }
+
+// Ne is true if the property does not equal value.
+func (p Property) Ne(value any) Cond { return comparison{p, "<>", value} }
+
+// Gt is true if the property is greater than value.
+func (p Property) Gt(value any) Cond { return comparison{p, ">", value} }
+
+// Ge is true if the property is greater than or equal to value.
+func (p Property) Ge(value any) Cond { return comparison{p, ">=", value} }
+
+// Lt is true if the property is less than value.
+func (p Property) Lt(value any) Cond { return comparison{p, "<", value} }
+
+// Le is true if the property is less than or equal to value.
+func (p Property) Le(value any) Cond { return comparison{p, "<=", value} }
+
+// In is true if the property equals an element of values, a list.
+func (p Property) In(values any) Cond { return comparison{p, "IN", values} }
+
+// StartsWith is true if the string property starts with prefix.
+func (p Property) StartsWith(prefix string) Cond { return comparison{p, "STARTS WITH", prefix} }
+
+// EndsWith is true if the string property ends with suffix.
+func (p Property) EndsWith(suffix string) Cond { return comparison{p, "ENDS WITH", suffix} }
+
+// Contains is true if the string property contains substr.
+func (p Property) Contains(substr string) Cond { return comparison{p, "CONTAINS", substr} }
+
+// IsNull is true if the property is missing or null.
+func (p Property) IsNull() Cond { return nullTest{p, "IS NULL"} }
+
+// IsNotNull is true if the property is set.
+func (p Property) IsNotNull() Cond { return nullTest{p, "IS NOT NULL"} }
+
+type comparison struct {
+	prop  Property
+	op    string
+	value any
+}
+
+func (c comparison) writeCond(w *writer, self string) {
+	c.prop.write(w, self)
+	w.WriteString(" " + c.op + " ")
+	w.param(c.value)
+}
+
+type nullTest struct {
+	prop Property
+	op   string
+}
+
+func (c nullTest) writeCond(w *writer, self string) {
+	c.prop.write(w, self)
+	w.WriteString(" " + c.op)
+}
+
+// And is true if all conds are.
+func And(conds ...Cond) Cond { return junction{"AND", conds} }
+
+// Or is true if any of conds is.
+func Or(conds ...Cond) Cond { return junction{"OR", conds} }
+
+// Not negates cond.
+func Not(cond Cond) Cond { return negation{cond} }
+
+type junction struct {
+	op    string
+	conds []Cond
+}
+
+func (j junction) writeCond(w *writer, self string) {
+	if len(j.conds) == 0 {
+		w.fail(fmt.Errorf("query: %s of no conditions", j.op))
+		return
+	}
+	if len(j.conds) == 1 {
+		writeCond(w, j.conds[0], self)
+		return
+	}
+	w.WriteByte('(')
+	for i, c := range j.conds {
+		if i > 0 {
+			w.WriteString(" " + j.op + " ")
+		}
+		writeCond(w, c, self)
+	}
+	w.WriteByte(')')
+}
+
+type negation struct {
+	cond Cond
+}
+
+func (n negation) writeCond(w *writer, self string) {
+	w.WriteString("NOT (")
+	writeCond(w, n.cond, self)
+	w.WriteByte(')')
+}
+
+// and combines two conditions, either of which may be nil.
+func and(a, b Cond) Cond {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	return And(a, b)
+}
+
+// writeCond writes cond, failing if it is nil.
+func writeCond(w *writer, cond Cond, self string) {
+	if cond == nil {
+		w.fail(errors.New("query: nil condition"))
+		return
+	}
+	cond.writeCond(w, self)
+}
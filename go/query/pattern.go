@@ -0,0 +1,213 @@
+package query
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// Pattern is a graph pattern in a MATCH clause: a *NodePattern or a
+// *PathPattern.
+type Pattern interface {
+	writePattern(w *writer)
+}
+
+// NodePattern matches nodes.
+type NodePattern struct {
+	variable string
+	labels   []string
+	props    map[string]any
+	where    Cond
+}
+
+// Node returns a pattern binding variable to nodes with all of labels. An
+// empty variable matches without binding.
+func Node(variable string, labels ...string) *NodePattern {
+	return &NodePattern{variable: variable, labels: labels}
+}
+
+// Props restricts the pattern to nodes whose properties equal props.
+func (n *NodePattern) Props(props map[string]any) *NodePattern {
+	n.props = props
+	return n
+}
+
+// Where adds a condition on the node. Properties without a variable, as
+// in Prop("age"), refer to the node, which must then have a variable.
+// Several calls are combined with AND.
+func (n *NodePattern) Where(cond Cond) *NodePattern {
+	n.where = and(n.where, cond)
+	return n
+}
+
+// Out starts a path following an outgoing edge to node.
+func (n *NodePattern) Out(edge *EdgePattern, node *NodePattern) *PathPattern {
+	return (&PathPattern{start: n}).Out(edge, node)
+}
+
+// In starts a path following an incoming edge to node.
+func (n *NodePattern) In(edge *EdgePattern, node *NodePattern) *PathPattern {
+	return (&PathPattern{start: n}).In(edge, node)
+}
+
+// Both starts a path following an edge in either direction to node.
+func (n *NodePattern) Both(edge *EdgePattern, node *NodePattern) *PathPattern {
+	return (&PathPattern{start: n}).Both(edge, node)
+}
+
+func (n *NodePattern) writePattern(w *writer) {
+	w.WriteByte('(')
+	writeElement(w, n.variable, n.labels, ":", n.props, n.where)
+	w.WriteByte(')')
+}
+
+// EdgePattern matches edges.
+type EdgePattern struct {
+	variable string
+	labels   []string
+	props    map[string]any
+	where    Cond
+	min, max int
+}
+
+// Edge returns a pattern binding variable to edges with any of labels. An
+// empty variable matches without binding, and no labels match any edge.
+func Edge(variable string, labels ...string) *EdgePattern {
+	return &EdgePattern{variable: variable, labels: labels}
+}
+
+// Props restricts the pattern to edges whose properties equal props.
+func (e *EdgePattern) Props(props map[string]any) *EdgePattern {
+	e.props = props
+	return e
+}
+
+// Where adds a condition on the edge. Properties without a variable refer
+// to the edge, which must then have a variable.
+func (e *EdgePattern) Where(cond Cond) *EdgePattern {
+	e.where = and(e.where, cond)
+	return e
+}
+
+// Hops makes the pattern match paths of min to max such edges.
+func (e *EdgePattern) Hops(min, max int) *EdgePattern {
+	e.min, e.max = min, max
+	return e
+}
+
+// PathPattern matches paths of nodes joined by edges.
+type PathPattern struct {
+	variable string
+	start    *NodePattern
+	steps    []step
+}
+
+type step struct {
+	direction string
+	edge      *EdgePattern
+	node      *NodePattern
+}
+
+// As binds the matched path to variable.
+func (p *PathPattern) As(variable string) *PathPattern {
+	p.variable = variable
+	return p
+}
+
+// Out extends the path with an outgoing edge to node.
+func (p *PathPattern) Out(edge *EdgePattern, node *NodePattern) *PathPattern {
+	p.steps = append(p.steps, step{"out", edge, node})
+	return p
+}
+
+// In extends the path with an incoming edge to node.
+func (p *PathPattern) In(edge *EdgePattern, node *NodePattern) *PathPattern {
+	p.steps = append(p.steps, step{"in", edge, node})
+	return p
+}
+
+// Both extends the path with an edge in either direction to node.
+func (p *PathPattern) Both(edge *EdgePattern, node *NodePattern) *PathPattern {
+	p.steps = append(p.steps, step{"both", edge, node})
+	return p
+}
+
+func (p *PathPattern) writePattern(w *writer) {
+	if p.variable != "" {
+		w.WriteString(gwp.EscapeIdentifier(p.variable) + " = ")
+	}
+	if p.start == nil {
+		w.fail(errors.New("query: path has no start node"))
+		return
+	}
+	p.start.writePattern(w)
+	for _, s := range p.steps {
+		e := s.edge
+		if e == nil {
+			e = Edge("")
+		}
+		if s.direction == "in" {
+			w.WriteString("<-[")
+		} else {
+			w.WriteString("-[")
+		}
+		writeElement(w, e.variable, e.labels, "|", e.props, e.where)
+		if s.direction == "out" {
+			w.WriteString("]->")
+		} else {
+			w.WriteString("]-")
+		}
+		if e.max > 0 {
+			w.WriteString("{" + strconv.Itoa(e.min) + "," + strconv.Itoa(e.max) + "}")
+		}
+		if s.node == nil {
+			w.fail(errors.New("query: path step has no node"))
+			return
+		}
+		s.node.writePattern(w)
+	}
+}
+
+// writeElement writes the filler of a node or edge pattern. Node labels
+// are joined with sep ":" (all labels), edge labels with "|" (any label).
+func writeElement(w *writer, variable string, labels []string, sep string, props map[string]any, where Cond) {
+	w.WriteString(quoteIdent0(variable))
+	for i, l := range labels {
+		if i == 0 {
+			w.WriteByte(':')
+		} else {
+			w.WriteString(sep)
+		}
+		w.WriteString(gwp.EscapeIdentifier(l))
+	}
+	if len(props) > 0 {
+		keys := make([]string, 0, len(props))
+		for k := range props {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		w.WriteString(" {")
+		for i, k := range keys {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString(gwp.EscapeIdentifier(k) + ": ")
+			w.param(props[k])
+		}
+		w.WriteByte('}')
+	}
+	if where != nil {
+		w.WriteString(" WHERE ")
+		where.writeCond(w, variable)
+	}
+}
+
+// quoteIdent0 quotes a variable, leaving an empty one empty.
+func quoteIdent0(name string) string {
+	if name == "" {
+		return ""
+	}
+	return gwp.EscapeIdentifier(name)
+}
@@ -0,0 +1,214 @@
+// Package query builds parameterized GQL queries.
+//
+//	stmt, params, err := query.Match(
+//		query.Node("p", "Person").Where(query.Prop("age").Gt(30)),
+//	).Return("p.name").OrderBy("p.name").Limit(10).Build()
+//	cursor, err := session.Execute(ctx, stmt, params)
+//
+// produces
+//
+//	MATCH (p:Person WHERE p.age > $p0) RETURN p.name ORDER BY p.name LIMIT 10
+//
+// Values given to conditions are always sent as parameters, and labels,
+// variables and property names are quoted as identifiers, so user input
+// cannot change the structure of the query. Expressions passed to Return
+// and OrderBy are written as is and must not contain user input.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// Query is a MATCH query under construction. Its methods modify and return
+// it.
+type Query struct {
+	clauses []clause
+	where   Cond
+	returns []string
+	orderBy []string
+	offset  int
+	limit   int
+}
+
+type clause struct {
+	optional bool
+	patterns []Pattern
+}
+
+// Match starts a query matching all of patterns.
+func Match(patterns ...Pattern) *Query {
+	return &Query{clauses: []clause{{patterns: patterns}}}
+}
+
+// Match adds a MATCH clause.
+func (q *Query) Match(patterns ...Pattern) *Query {
+	q.clauses = append(q.clauses, clause{patterns: patterns})
+	return q
+}
+
+// OptionalMatch adds an OPTIONAL MATCH clause.
+func (q *Query) OptionalMatch(patterns ...Pattern) *Query {
+	q.clauses = append(q.clauses, clause{optional: true, patterns: patterns})
+	return q
+}
+
+// Where adds a condition on the whole match. Its properties must name
+// their variable, as in Var("p").Prop("age"). Several calls are combined
+// with AND.
+func (q *Query) Where(cond Cond) *Query {
+	q.where = and(q.where, cond)
+	return q
+}
+
+// Return sets the returned expressions, such as "p.name" or "count(*) AS n".
+func (q *Query) Return(items ...string) *Query {
+	q.returns = append(q.returns, items...)
+	return q
+}
+
+// OrderBy sets the sort expressions, such as "p.age DESC".
+func (q *Query) OrderBy(items ...string) *Query {
+	q.orderBy = append(q.orderBy, items...)
+	return q
+}
+
+// Offset skips the first n results.
+func (q *Query) Offset(n int) *Query {
+	q.offset = n
+	return q
+}
+
+// Limit returns at most n results.
+func (q *Query) Limit(n int) *Query {
+	q.limit = n
+	return q
+}
+
+// Build returns the statement and its parameters. Without Return, every
+// variable bound by the patterns is returned. It fails if the query cannot
+// be written as valid GQL: a property without a variable to refer to, an
+// empty And or Or, or a pattern or condition that is nil.
+func (q *Query) Build() (string, map[string]any, error) {
+	w := &writer{params: make(map[string]any)}
+	for i, c := range q.clauses {
+		if i > 0 {
+			w.WriteByte(' ')
+		}
+		if c.optional {
+			w.WriteString("OPTIONAL ")
+		}
+		w.WriteString("MATCH ")
+		for j, p := range c.patterns {
+			if j > 0 {
+				w.WriteString(", ")
+			}
+			if p == nil {
+				w.fail(errors.New("query: nil pattern"))
+				continue
+			}
+			p.writePattern(w)
+		}
+	}
+	if q.where != nil {
+		w.WriteString(" WHERE ")
+		q.where.writeCond(w, "")
+	}
+
+	returns := q.returns
+	if len(returns) == 0 {
+		returns = q.variables()
+	}
+	w.WriteString(" RETURN ")
+	if len(returns) == 0 {
+		w.WriteString("*")
+	} else {
+		w.WriteString(strings.Join(returns, ", "))
+	}
+	if len(q.orderBy) > 0 {
+		w.WriteString(" ORDER BY " + strings.Join(q.orderBy, ", "))
+	}
+	if q.offset > 0 {
+		fmt.Fprintf(w, " OFFSET %d", q.offset)
+	}
+	if q.limit > 0 {
+		fmt.Fprintf(w, " LIMIT %d", q.limit)
+	}
+	if w.err != nil {
+		return "", nil, w.err
+	}
+	return w.String(), w.params, nil
+}
+
+// String returns the statement Build produces, or the error if it fails.
+func (q *Query) String() string {
+	s, _, err := q.Build()
+	if err != nil {
+		return err.Error()
+	}
+	return s
+}
+
+// variables returns the quoted variables bound by the query's patterns.
+func (q *Query) variables() []string {
+	var vars []string
+	seen := make(map[string]bool)
+	add := func(v string) {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			vars = append(vars, gwp.EscapeIdentifier(v))
+		}
+	}
+	for _, c := range q.clauses {
+		for _, p := range c.patterns {
+			switch p := p.(type) {
+			case *NodePattern:
+				if p != nil {
+					add(p.variable)
+				}
+			case *PathPattern:
+				if p == nil {
+					continue
+				}
+				add(p.variable)
+				if p.start != nil {
+					add(p.start.variable)
+				}
+				for _, s := range p.steps {
+					if s.edge != nil {
+						add(s.edge.variable)
+					}
+					if s.node != nil {
+						add(s.node.variable)
+					}
+				}
+			}
+		}
+	}
+	return vars
+}
+
+// writer accumulates a statement and its parameters, and the first error
+// met writing them.
+type writer struct {
+	strings.Builder
+	params map[string]any
+	err    error
+}
+
+// fail records err unless an earlier error was recorded.
+func (w *writer) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// param adds v as a parameter and writes its reference.
+func (w *writer) param(v any) {
+	name := fmt.Sprintf("p%d", len(w.params))
+	w.params[name] = v
+	w.WriteString("$" + name)
+}
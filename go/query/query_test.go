@@ -0,0 +1,101 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  *Query
+		stmt   string
+		params map[string]any
+	}{
+		{
+			"node where",
+			Match(Node("p", "Person").Where(Prop("age").Gt(30))).Return("p.name").OrderBy("p.name").Limit(10),
+			"MATCH (p:Person WHERE p.age > $p0) RETURN p.name ORDER BY p.name LIMIT 10",
+			map[string]any{"p0": 30},
+		},
+		{
+			"path with props and default return",
+			Match(Node("a", "Person").Props(map[string]any{"name": "Alice", "active": true}).
+				Out(Edge("k", "KNOWS", "LIKES").Where(Prop("since").Lt(2020)), Node("b", "Person")).
+				In(Edge(""), Node("", "Company"))),
+			"MATCH (a:Person {active: $p0, name: $p1})-[k:KNOWS|LIKES WHERE k.since < $p2]->(b:Person)<-[]-(:Company) RETURN a, k, b",
+			map[string]any{"p0": true, "p1": "Alice", "p2": 2020},
+		},
+		{
+			"quantified path, optional match and top-level where",
+			Match(Node("a", "Person")).
+				OptionalMatch(Node("a").Both(Edge("", "KNOWS").Hops(1, 3), Node("f")).As("path")).
+				Where(Or(Var("a").Prop("name").StartsWith("A"), Not(Var("f").Prop("email").IsNull()))).
+				Where(Var("a").Prop("tags").In([]any{"x", "y"})).
+				Return("a.name", "count(f) AS friends").Offset(5),
+			"MATCH (a:Person) OPTIONAL MATCH path = (a)-[:KNOWS]-{1,3}(f)" +
+				" WHERE ((a.name STARTS WITH $p0 OR NOT (f.email IS NULL)) AND a.tags IN $p1)" +
+				" RETURN a.name, count(f) AS friends OFFSET 5",
+			map[string]any{"p0": "A", "p1": []any{"x", "y"}},
+		},
+		{
+			"nil edge",
+			Match(Node("a").Out(nil, Node("b"))),
+			"MATCH (a)-[]->(b) RETURN a, b",
+			map[string]any{},
+		},
+		{
+			"identifiers are quoted",
+			Match(Node("n", "Odd`Label", "Two Words").Where(Prop("first name").Eq("x' OR 1=1"))),
+			"MATCH (n:`Odd``Label`:`Two Words` WHERE n.`first name` = $p0) RETURN n",
+			map[string]any{"p0": "x' OR 1=1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, params, err := tt.query.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stmt != tt.stmt {
+				t.Fatalf("statement:\n%s\nwant:\n%s", stmt, tt.stmt)
+			}
+			if !reflect.DeepEqual(params, tt.params) {
+				t.Fatalf("params = %v, want %v", params, tt.params)
+			}
+		})
+	}
+}
+
+func TestBuildIsRepeatable(t *testing.T) {
+	q := Match(Node("p", "Person").Where(And(Prop("age").Ge(18), Prop("age").Le(65))))
+	first, _, _ := q.Build()
+	if second := q.String(); first != second {
+		t.Fatalf("Build changed between calls: %q, %q", first, second)
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *Query
+		err   string
+	}{
+		{"property of anonymous node", Match(Node("", "Person").Where(Prop("age").Gt(30))), "property age has no variable"},
+		{"property without variable in match where", Match(Node("p")).Where(Prop("age").Gt(30)), "property age has no variable"},
+		{"empty and", Match(Node("p")).Where(And()), "AND of no conditions"},
+		{"empty or", Match(Node("p").Where(Or())), "OR of no conditions"},
+		{"nil condition", Match(Node("p")).Where(Not(nil)), "nil condition"},
+		{"nil node", Match(Node("a").Out(Edge("e"), nil)), "path step has no node"},
+		{"nil pattern", Match(nil), "nil pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, params, err := tt.query.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("Build = %q, %v, %v; want error %q", stmt, params, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package gwp
+
+import (
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+var errRawCursor = &GqlError{Message: "cursor returns raw frames; use NextRawBatch"}
+
+// NextRawBatch returns the next row batch as received from the server, or
+// nil when done. The cursor must have been created with WithRawFrames.
+func (c *ResultCursor) NextRawBatch() (*pb.RowBatch, error) {
+	if !c.raw {
+		return nil, &GqlError{Message: "cursor does not return raw frames; execute with WithRawFrames"}
+	}
+	if len(c.rawBatches) == 0 {
+		if err := c.consumeUntilRowsOrDone(); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.rawBatches) == 0 {
+		return nil, nil
+	}
+	batch := c.rawBatches[0]
+	c.rawBatches[0] = nil
+	c.rawBatches = c.rawBatches[1:]
+	return batch, nil
+}
+
+// RawHeader returns the result header as received from the server, or nil
+// if the result has none.
+func (c *ResultCursor) RawHeader() (*pb.ResultHeader, error) {
+	if c.header == nil {
+		if err := c.consumeUntilRowsOrDone(); err != nil {
+			return nil, err
+		}
+	}
+	return c.header, nil
+}
+
+// RawSummary returns the result summary as received from the server,
+// consuming remaining frames if needed.
+func (c *ResultCursor) RawSummary() (*pb.ResultSummary, error) {
+	if _, err := c.Summary(); err != nil {
+		return nil, err
+	}
+	return c.summary, nil
+}
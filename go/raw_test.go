@@ -0,0 +1,59 @@
+package gwp
+
+import "testing"
+
+func TestRawFrames(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("name", "age"),
+		batchFrame([]any{"Alice", int64(30)}),
+		batchFrame([]any{"Bob", int64(25)}, []any{"Carol", int64(41)}),
+		summaryFrame(Success, 3),
+	)
+	c.raw = true
+
+	header, err := c.RawHeader()
+	if err != nil || len(header.Columns) != 2 {
+		t.Fatalf("RawHeader = %v, %v", header, err)
+	}
+	if _, err := c.NextRow(); err == nil {
+		t.Fatal("NextRow should fail in raw mode")
+	}
+
+	var rows int
+	for {
+		batch, err := c.NextRawBatch()
+		if err != nil {
+			t.Fatalf("NextRawBatch: %v", err)
+		}
+		if batch == nil {
+			break
+		}
+		rows += len(batch.Rows)
+	}
+	if rows != 3 {
+		t.Fatalf("got %d raw rows, want 3", rows)
+	}
+
+	summary, err := c.RawSummary()
+	if err != nil || summary.RowsAffected != 3 {
+		t.Fatalf("RawSummary = %v, %v", summary, err)
+	}
+}
+
+func TestRawFramesSummaryDiscardsBatches(t *testing.T) {
+	c := newTestCursor(headerFrame("n"), batchFrame([]any{int64(1)}), summaryFrame(Success, 1))
+	c.raw = true
+	if ok, err := c.IsSuccess(); err != nil || !ok {
+		t.Fatalf("IsSuccess = %v, %v", ok, err)
+	}
+	if batch, err := c.NextRawBatch(); batch != nil || err != nil {
+		t.Fatalf("NextRawBatch after Summary = %v, %v", batch, err)
+	}
+}
+
+func TestNextRawBatchRequiresRawMode(t *testing.T) {
+	c := newTestCursor(headerFrame("n"), summaryFrame(Success, 0))
+	if _, err := c.NextRawBatch(); err == nil {
+		t.Fatal("expected error without WithRawFrames")
+	}
+}
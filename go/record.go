@@ -0,0 +1,119 @@
+package gwp
+
+import (
+	"iter"
+)
+
+// Record is a result row keyed by column name. Records from the same
+// cursor share their column index.
+type Record struct {
+	columns *recordColumns
+	values  []any
+}
+
+// recordColumns maps column names to positions. It is built once per cursor.
+type recordColumns struct {
+	names []string
+	index map[string]int
+}
+
+func newRecordColumns(names []string) *recordColumns {
+	index := make(map[string]int, len(names))
+	for i, n := range names {
+		if _, dup := index[n]; !dup {
+			index[n] = i
+		}
+	}
+	return &recordColumns{names: names, index: index}
+}
+
+// Keys returns the column names.
+func (r *Record) Keys() []string {
+	return r.columns.names
+}
+
+// Values returns the values in column order.
+func (r *Record) Values() []any {
+	return r.values
+}
+
+// Len returns the number of columns.
+func (r *Record) Len() int {
+	return len(r.values)
+}
+
+// Get returns the value of the named column. The second result is false if
+// the record has no such column.
+func (r *Record) Get(name string) (any, bool) {
+	i, ok := r.columns.index[name]
+	if !ok || i >= len(r.values) {
+		return nil, false
+	}
+	return r.values[i], true
+}
+
+// MustGet returns the value of the named column. It panics if the record
+// has no such column.
+func (r *Record) MustGet(name string) any {
+	v, ok := r.Get(name)
+	if !ok {
+		panic("gwp: record has no column " + name)
+	}
+	return v
+}
+
+// Index returns the value of the i-th column.
+func (r *Record) Index(i int) any {
+	return r.values[i]
+}
+
+// All iterates over the columns and their values in column order.
+func (r *Record) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for i, v := range r.values {
+			if !yield(r.columns.names[i], v) {
+				return
+			}
+		}
+	}
+}
+
+// AsMap returns the record as a map from column name to value.
+func (r *Record) AsMap() map[string]any {
+	m := make(map[string]any, len(r.values))
+	for name, v := range r.All() {
+		m[name] = v
+	}
+	return m
+}
+
+// NextRecord returns the next row as a Record, or nil when done.
+func (c *ResultCursor) NextRecord() (*Record, error) {
+	row, err := c.NextRow()
+	if err != nil || row == nil {
+		return nil, err
+	}
+	if c.recordColumns == nil {
+		names, err := c.ColumnNames()
+		if err != nil {
+			return nil, err
+		}
+		c.recordColumns = newRecordColumns(names)
+	}
+	return &Record{columns: c.recordColumns, values: row}, nil
+}
+
+// CollectRecords collects all remaining rows as Records.
+func (c *ResultCursor) CollectRecords() ([]*Record, error) {
+	var records []*Record
+	for {
+		r, err := c.NextRecord()
+		if err != nil {
+			return records, err
+		}
+		if r == nil {
+			return records, nil
+		}
+		records = append(records, r)
+	}
+}
@@ -0,0 +1,54 @@
+package gwp
+
+import "testing"
+
+func TestCursorRecords(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("name", "age"),
+		batchFrame([]any{"Alice", int64(30)}),
+		batchFrame([]any{"Bob", nil}),
+		summaryFrame(Success, 2),
+	)
+	records, err := c.CollectRecords()
+	if err != nil {
+		t.Fatalf("CollectRecords: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records", len(records))
+	}
+
+	alice := records[0]
+	if v, ok := alice.Get("age"); !ok || v != int64(30) {
+		t.Fatalf("Get(age) = %v, %v", v, ok)
+	}
+	if _, ok := alice.Get("missing"); ok {
+		t.Fatal("Get(missing) should report false")
+	}
+	if alice.MustGet("name") != "Alice" || alice.Index(1) != int64(30) || alice.Len() != 2 {
+		t.Fatalf("unexpected record %v", alice.Values())
+	}
+	if v, ok := records[1].Get("age"); !ok || v != nil {
+		t.Fatalf("NULL column = %v, %v", v, ok)
+	}
+	if records[0].columns != records[1].columns {
+		t.Fatal("records should share the column index")
+	}
+
+	var keys []string
+	for k := range alice.All() {
+		keys = append(keys, k)
+	}
+	if len(keys) != 2 || keys[0] != "name" {
+		t.Fatalf("All keys = %v", keys)
+	}
+	if m := alice.AsMap(); m["name"] != "Alice" || len(m) != 2 {
+		t.Fatalf("AsMap = %v", m)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustGet should panic for a missing column")
+		}
+	}()
+	alice.MustGet("missing")
+}
@@ -0,0 +1,349 @@
+// Package replay records the RPCs of a GWP connection and replays them
+// without a server, so client code can be tested deterministically where no
+// server is available.
+//
+// Record once against a real server:
+//
+//	rec := replay.NewRecorder()
+//	conn, err := gwp.Connect(ctx, addr, append(rec.DialOptions(),
+//	    grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+//	// ... run the code under test ...
+//	conn.Close(ctx)
+//	err = rec.SaveFile("testdata/people.replay")
+//
+// and replay in tests:
+//
+//	player, err := replay.LoadFile("testdata/people.replay")
+//	conn, err := player.Connect(ctx)
+//	// ... run the same code ...
+//	err = player.Done()
+//
+// Calls are replayed in the order they were recorded, and each must match
+// the recorded method and request; a mismatch fails the call with
+// codes.FailedPrecondition. Code that issues RPCs concurrently, such as
+// session heartbeats, is therefore not replayable.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// Interaction is one recorded RPC. Messages are stored in protobuf JSON
+// form.
+type Interaction struct {
+	Method    string            `json:"method"`
+	Request   json.RawMessage   `json:"request"`
+	Responses []json.RawMessage `json:"responses,omitempty"`
+	// Code and Message hold the status the call ended with, if it failed.
+	Code    codes.Code  `json:"code,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Trailer metadata.MD `json:"trailer,omitempty"`
+}
+
+func (in *Interaction) err() error {
+	if in.Code == codes.OK {
+		return nil
+	}
+	return status.Error(in.Code, in.Message)
+}
+
+func (in *Interaction) setErr(err error) {
+	if err != nil && err != io.EOF {
+		s := status.Convert(err)
+		in.Code, in.Message = s.Code(), s.Message()
+	}
+}
+
+func marshal(m any) json.RawMessage {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// Recorder captures the RPCs made through its dial options.
+type Recorder struct {
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// DialOptions returns the interceptors that record calls. Pass them to
+// gwp.Connect or ConnectionConfig.DialOptions together with transport
+// credentials.
+func (r *Recorder) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(r.unary),
+		grpc.WithChainStreamInterceptor(r.stream),
+	}
+}
+
+// add reserves the interaction's place in call order.
+func (r *Recorder) add(in *Interaction) {
+	r.mu.Lock()
+	r.interactions = append(r.interactions, in)
+	r.mu.Unlock()
+}
+
+func (r *Recorder) unary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	in := &Interaction{Method: method, Request: marshal(req)}
+	r.add(in)
+	var trailer metadata.MD
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Trailer(&trailer))...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		in.Responses = []json.RawMessage{marshal(reply)}
+	}
+	in.setErr(err)
+	in.Trailer = trailer
+	return err
+}
+
+func (r *Recorder) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		in := &Interaction{Method: method}
+		in.setErr(err)
+		r.add(in)
+		return nil, err
+	}
+	in := &Interaction{Method: method}
+	r.add(in)
+	return &recordingStream{ClientStream: cs, rec: r, in: in}, nil
+}
+
+// Save writes the recorded interactions to w, one JSON object per line.
+func (r *Recorder) Save(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	enc := json.NewEncoder(w)
+	for _, in := range r.interactions {
+		if err := enc.Encode(in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveFile writes the recorded interactions to the named file.
+func (r *Recorder) SaveFile(path string) error {
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+type recordingStream struct {
+	grpc.ClientStream
+	rec *Recorder
+	in  *Interaction
+}
+
+func (s *recordingStream) SendMsg(m any) error {
+	s.rec.mu.Lock()
+	s.in.Request = marshal(m)
+	s.rec.mu.Unlock()
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *recordingStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+	if err == nil {
+		s.in.Responses = append(s.in.Responses, marshal(m))
+		return nil
+	}
+	s.in.setErr(err)
+	s.in.Trailer = s.ClientStream.Trailer()
+	return err
+}
+
+// Player serves recorded interactions in place of a server.
+type Player struct {
+	mu           sync.Mutex
+	interactions []*Interaction
+	next         int
+}
+
+// Load reads interactions written by Recorder.Save.
+func Load(r io.Reader) (*Player, error) {
+	p := &Player{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		in := &Interaction{}
+		if err := json.Unmarshal(sc.Bytes(), in); err != nil {
+			return nil, fmt.Errorf("replay: interaction %d: %w", len(p.interactions)+1, err)
+		}
+		p.interactions = append(p.interactions, in)
+	}
+	return p, sc.Err()
+}
+
+// LoadFile reads interactions from the named file.
+func LoadFile(path string) (*Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// DialOptions returns the interceptors that answer calls from the
+// recording. No call reaches the network.
+func (p *Player) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(p.unary),
+		grpc.WithChainStreamInterceptor(p.stream),
+	}
+}
+
+// Connect returns a connection served by the player.
+func (p *Player) Connect(ctx context.Context) (*gwp.GqlConnection, error) {
+	return gwp.Connect(ctx, "passthrough:///replay", p.DialOptions()...)
+}
+
+// Done reports an error if recorded interactions were not replayed.
+func (p *Player) Done() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n := len(p.interactions) - p.next; n > 0 {
+		return fmt.Errorf("replay: %d recorded calls not replayed, next is %s", n, p.interactions[p.next].Method)
+	}
+	return nil
+}
+
+// take returns the next interaction if it matches method and req.
+func (p *Player) take(method string, req any) (*Interaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next >= len(p.interactions) {
+		return nil, status.Errorf(codes.FailedPrecondition, "replay: unexpected call %s after end of recording", method)
+	}
+	in := p.interactions[p.next]
+	if in.Method != method {
+		return nil, status.Errorf(codes.FailedPrecondition, "replay: call %d is %s, recorded %s", p.next+1, method, in.Method)
+	}
+	if in.Request != nil {
+		recorded := proto.Clone(req.(proto.Message))
+		proto.Reset(recorded)
+		if err := protojson.Unmarshal(in.Request, recorded); err != nil {
+			return nil, status.Errorf(codes.Internal, "replay: call %d: %v", p.next+1, err)
+		}
+		if !proto.Equal(recorded, req.(proto.Message)) {
+			return nil, status.Errorf(codes.FailedPrecondition, "replay: call %d to %s has request %v, recorded %s", p.next+1, method, req, in.Request)
+		}
+	}
+	p.next++
+	return in, nil
+}
+
+func (p *Player) unary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	in, err := p.take(method, req)
+	if err != nil {
+		return err
+	}
+	setTrailer(opts, in.Trailer)
+	if err := in.err(); err != nil {
+		return err
+	}
+	if len(in.Responses) == 0 {
+		return status.Errorf(codes.Internal, "replay: %s has no recorded response", method)
+	}
+	return protojson.Unmarshal(in.Responses[0], reply.(proto.Message))
+}
+
+// setTrailer fills the destinations of grpc.Trailer call options.
+func setTrailer(opts []grpc.CallOption, md metadata.MD) {
+	for _, o := range opts {
+		if t, ok := o.(grpc.TrailerCallOption); ok {
+			*t.TrailerAddr = md
+		}
+	}
+}
+
+func (p *Player) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return &replayStream{ctx: ctx, player: p, method: method}, nil
+}
+
+// replayStream is a server-streaming call answered from an interaction,
+// which is looked up when the request is sent.
+type replayStream struct {
+	ctx    context.Context
+	player *Player
+	method string
+	in     *Interaction
+	err    error
+	next   int
+}
+
+func (s *replayStream) Header() (metadata.MD, error) { return metadata.MD{}, nil }
+func (s *replayStream) CloseSend() error             { return nil }
+func (s *replayStream) Context() context.Context     { return s.ctx }
+
+func (s *replayStream) Trailer() metadata.MD {
+	if s.in == nil {
+		return nil
+	}
+	return s.in.Trailer
+}
+
+func (s *replayStream) SendMsg(m any) error {
+	s.in, s.err = s.player.take(s.method, m)
+	return nil
+}
+
+func (s *replayStream) RecvMsg(m any) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.in == nil {
+		return status.Error(codes.Internal, "replay: no request sent")
+	}
+	if err := s.ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	if s.next < len(s.in.Responses) {
+		s.next++
+		return protojson.Unmarshal(s.in.Responses[s.next-1], m.(proto.Message))
+	}
+	if err := s.in.err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
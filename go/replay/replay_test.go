@@ -0,0 +1,190 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	gwp "github.com/GrafeoDB/gql-wire-protocol/go"
+)
+
+// startTestServer runs the test server binary, skipping the test if it has
+// not been built.
+func startTestServer(t *testing.T) string {
+	t.Helper()
+	binary := filepath.Join("..", "..", "target", "release", "gwp-test-server")
+	if _, err := os.Stat(binary); err != nil {
+		t.Skip("gwp-test-server not built")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	cmd := exec.Command(binary, fmt.Sprint(port))
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	ctx := context.Background()
+	conn, err := gwp.Connect(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	if err := conn.WaitUntilReady(ctx, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+// scenario is the client code exercised by recording and replay.
+func scenario(ctx context.Context, conn *gwp.GqlConnection) ([][]any, int64, error) {
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	cursor, err := session.Execute(ctx, "MATCH (p:Person) RETURN p.name, p.age", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := cursor.CollectRows()
+	if err != nil {
+		return nil, 0, err
+	}
+	cursor, err = session.Execute(ctx, "INSERT (:Person {name: $name})", map[string]any{"name": "Carol"})
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := cursor.RowsAffected()
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, n, session.Close(ctx)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	addr := startTestServer(t)
+	ctx := context.Background()
+
+	rec := NewRecorder()
+	conn, err := gwp.Connect(ctx, addr, append(rec.DialOptions(), grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRows, wantN, err := scenario(ctx, conn)
+	if err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+	conn.Close(ctx)
+
+	path := filepath.Join(t.TempDir(), "scenario.replay")
+	if err := rec.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	player, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	conn, err = player.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	rows, n, err := scenario(ctx, conn)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !reflect.DeepEqual(rows, wantRows) || n != wantN {
+		t.Fatalf("replayed %v, %d; recorded %v, %d", rows, n, wantRows, wantN)
+	}
+	if err := player.Done(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const recording = `{"method":"/gql.SessionService/Handshake","request":{"protocolVersion":1},"responses":[{"sessionId":"s1","protocolVersion":1}]}
+{"method":"/gql.GqlService/Execute","request":{"sessionId":"s1","statement":"RETURN 1"},"responses":[{"header":{"columns":[{"name":"x"}]}},{"rowBatch":{"rows":[{"values":[{"integerValue":"1"}]}]}}],"code":14,"message":"connection reset"}
+`
+
+func TestReplayWithoutServer(t *testing.T) {
+	ctx := context.Background()
+	player, err := Load(bytes.NewBufferString(recording))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	conn, err := player.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if session.SessionID() != "s1" {
+		t.Fatalf("session ID = %q", session.SessionID())
+	}
+
+	cursor, err := session.Execute(ctx, "RETURN 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row, err := cursor.NextRow(); err != nil || row[0] != int64(1) {
+		t.Fatalf("NextRow = %v, %v", row, err)
+	}
+	if _, err := cursor.NextRow(); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected recorded Unavailable error, got %v", err)
+	}
+	if err := player.Done(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	ctx := context.Background()
+	player, err := Load(bytes.NewBufferString(recording))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := player.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	session, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cursor, err := session.Execute(ctx, "RETURN 2", nil)
+	if err == nil {
+		_, err = cursor.NextRow()
+	}
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition for mismatched request, got %v", err)
+	}
+	if err := player.Done(); err == nil {
+		t.Fatal("Done should report the unreplayed call")
+	}
+}
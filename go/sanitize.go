@@ -0,0 +1,170 @@
+package gwp
+
+import (
+	"strings"
+)
+
+// DefaultRedactedParams are the parameter name fragments redacted when
+// RedactionPolicy.Params is empty.
+var DefaultRedactedParams = []string{
+	"password", "passwd", "secret", "token", "apikey", "api_key",
+	"credential", "private_key", "ssn", "email", "phone",
+}
+
+// defaultRedactionMask is used when RedactionPolicy.Mask is empty.
+const defaultRedactionMask = "[REDACTED]"
+
+// RedactionPolicy configures a Sanitizer. The zero value masks literals and
+// redacts DefaultRedactedParams.
+type RedactionPolicy struct {
+	// KeepLiterals leaves string and numeric literals in statement text
+	// unmasked.
+	KeepLiterals bool
+	// Params are the parameter names whose values are redacted, matched
+	// case-insensitively as substrings, so "password" also matches
+	// "newPassword". Keys of map values are matched the same way. Defaults
+	// to DefaultRedactedParams.
+	Params []string
+	// Redact, if set, is asked about values whose names Params does not
+	// match, and redacts them if it returns true.
+	Redact func(name string, value any) bool
+	// Mask replaces redacted values. Defaults to "[REDACTED]".
+	Mask string
+}
+
+// Sanitizer masks literal values in statements and redacts sensitive
+// parameters, so statements can be written to logs and spans without
+// leaking passwords or personal data. It is safe for concurrent use.
+type Sanitizer struct {
+	policy RedactionPolicy
+}
+
+// NewSanitizer returns a Sanitizer applying policy.
+func NewSanitizer(policy RedactionPolicy) *Sanitizer {
+	if len(policy.Params) == 0 {
+		policy.Params = DefaultRedactedParams
+	}
+	params := make([]string, len(policy.Params))
+	for i, p := range policy.Params {
+		params[i] = strings.ToLower(p)
+	}
+	policy.Params = params
+	if policy.Mask == "" {
+		policy.Mask = defaultRedactionMask
+	}
+	return &Sanitizer{policy: policy}
+}
+
+// Statement returns statement with each string and numeric literal replaced
+// by "?", unless the policy keeps literals. Parameter references,
+// identifiers and comments are unchanged.
+func (s *Sanitizer) Statement(statement string) string {
+	if s.policy.KeepLiterals {
+		return statement
+	}
+	var b strings.Builder
+	b.Grow(len(statement))
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+		switch {
+		case c == '\'' || c == '"':
+			b.WriteByte('?')
+			i = skipQuoted(statement, i)
+		case c == '`':
+			end := skipQuoted(statement, i)
+			b.WriteString(statement[i:min(end+1, len(statement))])
+			i = end
+		case c == '/' && i+1 < len(statement) && statement[i+1] == '*':
+			end := strings.Index(statement[i+2:], "*/")
+			if end < 0 {
+				end = len(statement)
+			} else {
+				end += i + 4
+			}
+			b.WriteString(statement[i:end])
+			i = end - 1
+		case (c == '-' || c == '/') && i+1 < len(statement) && statement[i+1] == c:
+			end := strings.IndexByte(statement[i:], '\n')
+			if end < 0 {
+				end = len(statement)
+			} else {
+				end += i
+			}
+			b.WriteString(statement[i:end])
+			i = end - 1
+		case c >= '0' && c <= '9' && (i == 0 || !isIdentByte(statement[i-1], false) && statement[i-1] != '$'):
+			b.WriteByte('?')
+			i = skipNumber(statement, i) - 1
+		case isIdentByte(c, true) || c == '$':
+			// Copy whole words so digits inside identifiers stay.
+			end := i + 1
+			for end < len(statement) && isIdentByte(statement[end], false) {
+				end++
+			}
+			b.WriteString(statement[i:end])
+			i = end - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// skipNumber returns the index after the numeric literal starting at
+// s[start], including any fraction, exponent, radix prefix or suffix.
+func skipNumber(s string, start int) int {
+	i := start
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9', c == '.', isIdentByte(c, true):
+			i++
+		case (c == '+' || c == '-') && (s[i-1] == 'e' || s[i-1] == 'E'):
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// Params returns a copy of params with the values of sensitive parameters
+// replaced by the policy's mask. Maps are copied and redacted by key, and
+// list elements inherit the name of their list.
+func (s *Sanitizer) Params(params map[string]any) map[string]any {
+	if params == nil {
+		return nil
+	}
+	out := make(map[string]any, len(params))
+	for k, v := range params {
+		out[k] = s.value(k, v)
+	}
+	return out
+}
+
+func (s *Sanitizer) value(name string, v any) any {
+	if s.redacted(name, v) {
+		return s.policy.Mask
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		return s.Params(t)
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = s.value(name, e)
+		}
+		return out
+	}
+	return v
+}
+
+func (s *Sanitizer) redacted(name string, v any) bool {
+	lower := strings.ToLower(name)
+	for _, p := range s.policy.Params {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return s.policy.Redact != nil && s.policy.Redact(name, v)
+}
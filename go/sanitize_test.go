@@ -0,0 +1,87 @@
+package gwp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSanitizerStatement(t *testing.T) {
+	s := NewSanitizer(RedactionPolicy{})
+	tests := map[string]string{
+		"MATCH (u:User {email: 'a@b.c', age: 42}) RETURN u":       "MATCH (u:User {email: ?, age: ?}) RETURN u",
+		`MATCH (n1:Label2) WHERE n1.x > 1.5e-3 RETURN "it''s"`:    "MATCH (n1:Label2) WHERE n1.x > ? RETURN ?",
+		"MATCH (n:`Weird 'name' 7`) WHERE n.id = $id2 RETURN n":   "MATCH (n:`Weird 'name' 7`) WHERE n.id = $id2 RETURN n",
+		"/* app 'billing' v2 */ RETURN 0x1F -- total 3\nLIMIT 10": "/* app 'billing' v2 */ RETURN ? -- total 3\nLIMIT ?",
+		"RETURN 'unterminated":                                    "RETURN ?",
+	}
+	for in, want := range tests {
+		if got := s.Statement(in); got != want {
+			t.Errorf("Statement(%q)\n got %q\nwant %q", in, got, want)
+		}
+	}
+
+	keep := NewSanitizer(RedactionPolicy{KeepLiterals: true})
+	if got := keep.Statement("RETURN 'x'"); got != "RETURN 'x'" {
+		t.Errorf("KeepLiterals: got %q", got)
+	}
+}
+
+func TestSanitizerParams(t *testing.T) {
+	s := NewSanitizer(RedactionPolicy{})
+	params := map[string]any{
+		"name":        "Alice",
+		"newPassword": "hunter2",
+		"user":        map[string]any{"Email": "a@b.c", "age": int64(30)},
+		"tokens":      []any{"t1", "t2"},
+	}
+	want := map[string]any{
+		"name":        "Alice",
+		"newPassword": "[REDACTED]",
+		"user":        map[string]any{"Email": "[REDACTED]", "age": int64(30)},
+		"tokens":      "[REDACTED]",
+	}
+	if got := s.Params(params); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Params = %v, want %v", got, want)
+	}
+	if params["newPassword"] != "hunter2" {
+		t.Fatal("Params modified its argument")
+	}
+
+	custom := NewSanitizer(RedactionPolicy{
+		Params: []string{"dob"},
+		Redact: func(name string, v any) bool { return name == "ids" },
+		Mask:   "***",
+	})
+	got := custom.Params(map[string]any{"DOB": "2000-01-01", "ids": []any{1, 2}, "password": "x"})
+	want = map[string]any{"DOB": "***", "ids": "***", "password": "x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("custom Params = %v, want %v", got, want)
+	}
+}
+
+func TestSlowQueryLoggerSanitizer(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlowQueryLogger(SlowQueryConfig{
+		Threshold: time.Millisecond,
+		Logger:    slog.New(slog.NewTextHandler(&buf, nil)),
+		Sanitizer: NewSanitizer(RedactionPolicy{}),
+	})
+	info := &StatementInfo{
+		SessionID: "s1",
+		Statement: "MATCH (u:User {name: 'Alice'}) SET u.password = $password",
+		Params:    map[string]any{"password": "hunter2", "limit": int64(5)},
+	}
+	logger.AfterExecute(context.Background(), info, StatementResult{Duration: time.Second})
+	out := buf.String()
+	if strings.Contains(out, "Alice") || strings.Contains(out, "hunter2") {
+		t.Fatalf("sensitive values logged: %s", out)
+	}
+	if !strings.Contains(out, "[REDACTED]") || !strings.Contains(out, "limit:5") {
+		t.Fatalf("unexpected log: %s", out)
+	}
+}
@@ -0,0 +1,301 @@
+package gwp
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// ErrNoRows is returned by One when the result has no rows.
+var ErrNoRows = &GqlError{Message: "no rows in result"}
+
+// Collect reads all remaining rows of cursor into values of type T.
+//
+// If T is a struct (or pointer to struct), each row is mapped onto it: a
+// single node, edge or record column is mapped by property name, otherwise
+// columns are mapped by name. Fields match a `gwp:"name"` tag or, without
+// one, their name case-insensitively; a tag of "-" skips the field. Options
+// after a comma in the tag, as used by the ogm package, are ignored.
+// Otherwise the result must have a single column, converted to T.
+//
+// Pointer fields and pointer T receive nil for NULL, and NullString and the
+// other Null types have Valid set to false; NULL into any other non-pointer
+// is an error. Types with a registered ValueCodec are decoded with it.
+func Collect[T any](c *ResultCursor) ([]T, error) {
+	names, err := c.ColumnNames()
+	if err != nil {
+		return nil, err
+	}
+	var result []T
+	for {
+		row, err := c.NextRow()
+		if err != nil {
+			return result, err
+		}
+		if row == nil {
+			break
+		}
+		v, err := scanRow[T](names, row)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, v)
+	}
+	return result, checkCursorStatus(c)
+}
+
+// One reads the only row of cursor into a value of type T, as Collect does.
+// It returns ErrNoRows if there are no rows and an error if there is more
+// than one.
+func One[T any](c *ResultCursor) (T, error) {
+	var zero T
+	rows, err := Collect[T](c)
+	if err != nil {
+		return zero, err
+	}
+	switch len(rows) {
+	case 0:
+		return zero, ErrNoRows
+	case 1:
+		return rows[0], nil
+	default:
+		return zero, &GqlError{Message: fmt.Sprintf("expected one row, got %d", len(rows))}
+	}
+}
+
+// ScanValue stores the result value v in the variable dst points to,
+// converting it as Collect does; nodes, edges, records and maps are mapped
+// onto structs field by field. It suits types only known at run time.
+func ScanValue(dst any, v any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &GqlError{Message: fmt.Sprintf("ScanValue requires a non-nil pointer, got %T", dst)}
+	}
+	return assignValue(rv.Elem(), v)
+}
+
+func scanRow[T any](columns []string, row []any) (T, error) {
+	var result T
+	dst := reflect.ValueOf(&result).Elem()
+
+	if isStructTarget(dst.Type()) {
+		if len(row) == 1 {
+			switch row[0].(type) {
+			case *GqlNode, *GqlEdge, *GqlRecord:
+				return result, assignValue(dst, row[0])
+			}
+		}
+		fields := make(map[string]any, len(columns))
+		for i, name := range columns {
+			if i < len(row) {
+				fields[name] = row[i]
+			}
+		}
+		return result, assignValue(dst, fields)
+	}
+
+	if len(row) != 1 {
+		return result, &GqlError{Message: fmt.Sprintf("cannot scan %d columns into %s", len(row), dst.Type())}
+	}
+	return result, assignValue(dst, row[0])
+}
+
+// isStructTarget reports whether t is a user struct that rows are mapped
+// onto field by field, as opposed to a value type like GqlDate or a type
+// with a registered codec.
+func isStructTarget(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !valueStructTypes[t] && lookupValueCodec(t) == nil
+}
+
+// valueStructTypes are the struct types scanned as a single value.
+var valueStructTypes = map[reflect.Type]bool{
+	reflect.TypeFor[GqlNode]():          true,
+	reflect.TypeFor[GqlEdge]():          true,
+	reflect.TypeFor[GqlPath]():          true,
+	reflect.TypeFor[GqlRecord]():        true,
+	reflect.TypeFor[GqlDate]():          true,
+	reflect.TypeFor[GqlLocalTime]():     true,
+	reflect.TypeFor[GqlZonedTime]():     true,
+	reflect.TypeFor[GqlLocalDateTime](): true,
+	reflect.TypeFor[GqlZonedDateTime](): true,
+	reflect.TypeFor[GqlDuration]():      true,
+	reflect.TypeFor[GqlDecimal]():       true,
+	reflect.TypeFor[GqlPoint]():         true,
+	reflect.TypeFor[NullString]():       true,
+	reflect.TypeFor[NullInt64]():        true,
+	reflect.TypeFor[NullFloat64]():      true,
+	reflect.TypeFor[NullBool]():         true,
+	reflect.TypeFor[NullTime]():         true,
+}
+
+// assignValue stores the decoded value v into dst, converting as needed.
+func assignValue(dst reflect.Value, v any) error {
+	t := dst.Type()
+	if dst.CanAddr() && reflect.PointerTo(t).Implements(nullScannerType) {
+		return dst.Addr().Interface().(nullScanner).scanNull(v)
+	}
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			dst.SetZero()
+			return nil
+		}
+		return &GqlError{Message: "cannot scan NULL into " + t.String()}
+	}
+
+	src := reflect.ValueOf(v)
+	if src.Type().AssignableTo(t) {
+		dst.Set(src)
+		return nil
+	}
+	// Decoded values such as *GqlDate and *GqlNode into value targets.
+	if src.Kind() == reflect.Pointer && src.Type().Elem().AssignableTo(t) {
+		if src.IsNil() {
+			return assignValue(dst, nil)
+		}
+		dst.Set(src.Elem())
+		return nil
+	}
+	if codec := lookupValueCodec(t); codec != nil {
+		out, err := codec.FromValue(v)
+		if err != nil {
+			return err
+		}
+		return assignValue(dst, out)
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(t.Elem())
+		if err := assignValue(elem.Elem(), v); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch n := v.(type) {
+		case int64:
+			if dst.OverflowInt(n) {
+				return overflowError(v, t)
+			}
+			dst.SetInt(n)
+			return nil
+		case uint64:
+			if n > math.MaxInt64 || dst.OverflowInt(int64(n)) {
+				return overflowError(v, t)
+			}
+			dst.SetInt(int64(n))
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch n := v.(type) {
+		case uint64:
+			if dst.OverflowUint(n) {
+				return overflowError(v, t)
+			}
+			dst.SetUint(n)
+			return nil
+		case int64:
+			if n < 0 || dst.OverflowUint(uint64(n)) {
+				return overflowError(v, t)
+			}
+			dst.SetUint(uint64(n))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch n := v.(type) {
+		case float64:
+			dst.SetFloat(n)
+			return nil
+		case int64:
+			dst.SetFloat(float64(n))
+			return nil
+		}
+	case reflect.String:
+		if s, ok := v.(string); ok {
+			dst.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := v.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.Slice:
+		if list, ok := v.([]any); ok {
+			out := reflect.MakeSlice(t, len(list), len(list))
+			for i, e := range list {
+				if err := assignValue(out.Index(i), e); err != nil {
+					return err
+				}
+			}
+			dst.Set(out)
+			return nil
+		}
+	case reflect.Struct:
+		switch s := v.(type) {
+		case *GqlNode:
+			return assignFields(dst, s.PropertyMap())
+		case *GqlEdge:
+			return assignFields(dst, s.PropertyMap())
+		case *GqlRecord:
+			fields := make(map[string]any, len(s.Fields))
+			for _, f := range s.Fields {
+				fields[f.Name] = f.Value
+			}
+			return assignFields(dst, fields)
+		case map[string]any:
+			return assignFields(dst, s)
+		}
+	}
+	return &GqlError{Message: fmt.Sprintf("cannot scan %T into %s", v, t)}
+}
+
+func overflowError(v any, t reflect.Type) error {
+	return &GqlError{Message: fmt.Sprintf("value %v overflows %s", v, t)}
+}
+
+// assignFields maps values onto the exported fields of the struct dst.
+// Values without a matching field are ignored.
+func assignFields(dst reflect.Value, values map[string]any) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("gwp"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag, _, _ = strings.Cut(tag, ","); tag != "" {
+				name = tag
+			}
+		}
+		v, ok := lookupField(values, name)
+		if !ok {
+			continue
+		}
+		if err := assignValue(dst.Field(i), v); err != nil {
+			return &GqlError{Message: "field " + f.Name + ": " + err.Error()}
+		}
+	}
+	return nil
+}
+
+func lookupField(values map[string]any, name string) (any, bool) {
+	if v, ok := values[name]; ok {
+		return v, true
+	}
+	for k, v := range values {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,179 @@
+package gwp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+type scanPerson struct {
+	Name     string
+	Age      int32
+	Nickname *string `gwp:"nick"`
+	Ignored  string  `gwp:"-"`
+}
+
+func TestCollectScalar(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("n"),
+		batchFrame([]any{int64(1)}, []any{int64(2)}),
+		summaryFrame(Success, 0),
+	)
+	got, err := Collect[int](c)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(got) != 2 || got[1] != 2 {
+		t.Fatalf("Collect = %v", got)
+	}
+}
+
+func TestCollectNullable(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("name"),
+		batchFrame([]any{"Alice"}, []any{nil}),
+		summaryFrame(Success, 0),
+	)
+	got, err := Collect[*string](c)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if *got[0] != "Alice" || got[1] != nil {
+		t.Fatalf("Collect = %v", got)
+	}
+
+	c = newTestCursor(headerFrame("name"), batchFrame([]any{nil}), summaryFrame(Success, 0))
+	if _, err := Collect[string](c); err == nil || !strings.Contains(err.Error(), "NULL") {
+		t.Fatalf("expected NULL error, got %v", err)
+	}
+}
+
+func TestCollectStructFromColumns(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("name", "AGE", "nick"),
+		batchFrame([]any{"Alice", int64(30), "Al"}, []any{"Bob", int64(25), nil}),
+		summaryFrame(Success, 0),
+	)
+	got, err := Collect[scanPerson](c)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if got[0].Name != "Alice" || got[0].Age != 30 || *got[0].Nickname != "Al" {
+		t.Fatalf("row 0 = %+v", got[0])
+	}
+	if got[1].Nickname != nil {
+		t.Fatalf("row 1 = %+v", got[1])
+	}
+}
+
+func TestCollectStructFromNode(t *testing.T) {
+	node := &pb.Value{Kind: &pb.Value_NodeValue{NodeValue: &pb.Node{
+		Labels: []string{"Person"},
+		Properties: map[string]*pb.Value{
+			"name":    mustValueToProto("Alice"),
+			"age":     mustValueToProto(int64(30)),
+			"Ignored": mustValueToProto("x"),
+		},
+	}}}
+	c := newTestCursor(
+		headerFrame("p"),
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: &pb.RowBatch{
+			Rows: []*pb.Row{{Values: []*pb.Value{node}}},
+		}}},
+		summaryFrame(Success, 0),
+	)
+	p, err := One[*scanPerson](c)
+	if err != nil {
+		t.Fatalf("One: %v", err)
+	}
+	if p.Name != "Alice" || p.Age != 30 || p.Ignored != "" || p.Nickname != nil {
+		t.Fatalf("mapped node = %+v", p)
+	}
+
+	got, err := One[*scanPerson](newTestCursor(headerFrame("p"), summaryFrame(Success, 0)))
+	if !errors.Is(err, ErrNoRows) || got != nil {
+		t.Fatalf("One on empty result = %v, %v", got, err)
+	}
+}
+
+func TestCollectValueTargets(t *testing.T) {
+	date := GqlDate{Year: 2024, Month: 2, Day: 29}
+	c := newTestCursor(headerFrame("d"), batchFrame([]any{date}, []any{nil}), summaryFrame(Success, 0))
+	dates, err := Collect[*GqlDate](c)
+	if err != nil || len(dates) != 2 || *dates[0] != date || dates[1] != nil {
+		t.Fatalf("Collect[*GqlDate] = %v, %v", dates, err)
+	}
+	c = newTestCursor(headerFrame("d"), batchFrame([]any{date}), summaryFrame(Success, 0))
+	if got, err := One[GqlDate](c); err != nil || got != date {
+		t.Fatalf("One[GqlDate] = %v, %v", got, err)
+	}
+	c = newTestCursor(headerFrame("d"), batchFrame([]any{NewDecimal(1250, 2)}), summaryFrame(Success, 0))
+	if got, err := One[GqlDecimal](c); err != nil || got.String() != "12.50" {
+		t.Fatalf("One[GqlDecimal] = %v, %v", got, err)
+	}
+
+	node := &pb.Value{Kind: &pb.Value_NodeValue{NodeValue: &pb.Node{
+		Id:         []byte{7},
+		Labels:     []string{"Person"},
+		Properties: map[string]*pb.Value{"name": mustValueToProto("Alice")},
+	}}}
+	c = newTestCursor(headerFrame("p"), &pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: &pb.RowBatch{
+		Rows: []*pb.Row{{Values: []*pb.Value{node}}},
+	}}}, summaryFrame(Success, 0))
+	n, err := One[GqlNode](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.ID) != 1 || n.ID[0] != 7 || n.Labels[0] != "Person" || n.Properties["name"] != "Alice" {
+		t.Fatalf("One[GqlNode] = %+v", n)
+	}
+}
+
+func TestScanErrors(t *testing.T) {
+	if _, err := scanRow[int8]([]string{"n"}, []any{int64(300)}); err == nil {
+		t.Fatal("expected overflow error")
+	}
+	if _, err := scanRow[int]([]string{"a", "b"}, []any{int64(1), int64(2)}); err == nil {
+		t.Fatal("expected column count error")
+	}
+	if _, err := scanRow[bool]([]string{"n"}, []any{"yes"}); err == nil {
+		t.Fatal("expected type error")
+	}
+	if got, err := scanRow[[]float32]([]string{"v"}, []any{[]any{1.5, int64(2)}}); err != nil || got[1] != 2 {
+		t.Fatalf("scan list = %v, %v", got, err)
+	}
+	if got, err := scanRow[*GqlDate]([]string{"d"}, []any{&GqlDate{Year: 2024}}); err != nil || got.Year != 2024 {
+		t.Fatalf("scan GqlDate = %v, %v", got, err)
+	}
+}
+
+func TestOneMultipleRows(t *testing.T) {
+	c := newTestCursor(
+		headerFrame("n"),
+		batchFrame([]any{int64(1)}, []any{int64(2)}),
+		summaryFrame(Success, 0),
+	)
+	if _, err := One[int64](c); err == nil {
+		t.Fatal("expected error for two rows")
+	}
+}
+
+func TestScanValue(t *testing.T) {
+	type tagged struct {
+		Email string `gwp:"email,key"`
+		Name  string `gwp:",omitempty"`
+	}
+	node := &GqlNode{Properties: map[string]any{"email": "a@example.com", "name": "Alice"}}
+	var got tagged
+	if err := ScanValue(&got, node); err != nil {
+		t.Fatalf("ScanValue: %v", err)
+	}
+	if got.Email != "a@example.com" || got.Name != "Alice" {
+		t.Fatalf("got %+v", got)
+	}
+	if err := ScanValue(got, node); err == nil {
+		t.Fatal("expected error for a non-pointer destination")
+	}
+}
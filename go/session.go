@@ -2,17 +2,67 @@ package gwp
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
+// Querier executes GQL statements. It is implemented by GqlSession and
+// Transaction.
+type Querier interface {
+	Execute(ctx context.Context, statement string, params map[string]any, opts ...ExecuteOption) (*ResultCursor, error)
+}
+
 // GqlSession is an active session with a GWP server.
+//
+// A GqlSession is safe for concurrent use by multiple goroutines. Changes to
+// session state (SetGraph, SetSchema, SetTimeZone, SetParameter, Reset) are
+// serialized with statement submission: a statement is sent either entirely
+// before or entirely after a concurrent configuration change. Cursors and
+// transactions returned by a session are not safe for concurrent use; each
+// should be consumed by a single goroutine.
 type GqlSession struct {
 	sessionID     string
 	sessionClient pb.SessionServiceClient
 	gqlClient     pb.GqlServiceClient
-	closed        bool
+	catalogClient pb.CatalogServiceClient
+	adminClient   pb.AdminServiceClient
+	searchClient  pb.SearchServiceClient
+	features      []string
+	lastActivity  atomic.Int64
+	onClose       func(*GqlSession)
+	conn          *GqlConnection
+	interceptors  interceptorChain
+	lost          atomic.Bool
+	limiter       *limiter
+
+	// protocolVersion is the version agreed in the handshake.
+	protocolVersion uint32
+
+	// stateMu is held for writing while session state is changed on the
+	// server and for reading while a statement or transaction is started.
+	stateMu sync.RWMutex
+
+	// mu guards the fields below.
+	mu                  sync.Mutex
+	graph               string
+	schema              string
+	closed              bool
+	heartbeatStop       chan struct{}
+	notificationHandler func(Notification)
+	defaultParams       map[string]any
+	leakCheck           *sessionLeakCheck
+	timeZone            int32
+	timeZoneSet         bool
+	params              map[string]any
+	running             map[*ResultCursor]string
 }
 
 // SessionID returns the session identifier.
@@ -20,42 +70,246 @@ func (s *GqlSession) SessionID() string {
 	return s.sessionID
 }
 
-// Execute executes a GQL statement and returns a result cursor.
-func (s *GqlSession) Execute(ctx context.Context, statement string, params map[string]any) (*ResultCursor, error) {
-	protoParams := make(map[string]*pb.Value, len(params))
-	for k, v := range params {
-		protoParams[k] = valueToProto(v)
+// Execute executes a GQL statement and returns a result cursor. Values read
+// from results can be passed back as parameters; nodes, edges and paths are
+// sent as references, with their IDs and labels but not their properties.
+func (s *GqlSession) Execute(ctx context.Context, statement string, params map[string]any, opts ...ExecuteOption) (*ResultCursor, error) {
+	return s.execute(ctx, nil, statement, params, opts)
+}
+
+// execute sends an ExecuteRequest, optionally within a transaction, and wraps
+// the response stream in a cursor.
+func (s *GqlSession) execute(ctx context.Context, transactionID *string, statement string, params map[string]any, opts []ExecuteOption) (*ResultCursor, error) {
+	s.touch()
+	o := newExecuteOptions(opts)
+	params = s.withDefaultParams(params)
+
+	var info *StatementInfo
+	start := time.Now()
+	if len(s.interceptors) > 0 {
+		info = &StatementInfo{
+			SessionID:   s.sessionID,
+			QueryName:   o.queryName,
+			Graph:       o.graph,
+			Schema:      o.schema,
+			QueryID:     o.queryID,
+			Annotations: o.annotations,
+			Statement:   statement,
+			Params:      params,
+		}
+		if transactionID != nil {
+			info.TransactionID = *transactionID
+		}
+		var err error
+		if ctx, err = s.interceptors.before(ctx, info, start); err != nil {
+			return nil, err
+		}
+		statement, params = info.Statement, info.Params
+		o.annotations = info.Annotations
 	}
 
-	stream, err := s.gqlClient.Execute(ctx, &pb.ExecuteRequest{
-		SessionId:  s.sessionID,
-		Statement:  statement,
-		Parameters: protoParams,
-	})
+	if o.graph != "" {
+		statement = "USE " + EscapeIdentifier(o.graph) + " " + statement
+	}
+	if o.schema != "" {
+		statement = "AT " + EscapeIdentifier(o.schema) + " " + statement
+	}
+	if o.profile {
+		statement = "PROFILE " + statement
+	}
+
+	var cacheKey string
+	cache := s.resultCache(o, transactionID != nil)
+	if cache != nil {
+		var err error
+		if cacheKey, err = s.cacheKey(cache, o, statement, params); err != nil {
+			// The parameters cannot be encoded, which encodeParams reports
+			// below.
+			cache = nil
+		}
+	}
+	if cache != nil {
+		if frames, ok := cache.get(cacheKey); ok {
+			if s.isClosed() {
+				if info != nil {
+					s.interceptors.after(ctx, info, StatementResult{Duration: time.Since(start), Err: errSessionClosed})
+				}
+				return nil, errSessionClosed
+			}
+			cursor := newResultCursor(&cachedStream{frames: frames})
+			cursor.session = s
+			cursor.queryID = o.queryID
+			cursor.lazyProperties = o.lazyProps
+			cursor.strictConversion = s.strictConversion()
+			s.observe(ctx, cursor, info, start)
+			return cursor, nil
+		}
+	}
+
+	if o.queryID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, queryIDKey, o.queryID)
+	}
+	ctx = withAnnotations(ctx, o.annotations)
+	if len(o.metadata) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, o.metadata...)
+	}
+
+	protoParams, err := encodeParams(params, s.paramLimits())
+	if err != nil {
+		if info != nil {
+			s.interceptors.after(ctx, info, StatementResult{Duration: time.Since(start), Err: err})
+		}
+		return nil, err
+	}
+
+	var breaker *circuitBreaker
+	var probe bool
+	release, err := s.admit(ctx)
+	if err == nil && s.conn != nil {
+		if err = s.conn.beginWork(transactionID != nil); err == nil {
+			if breaker = s.conn.breaker(s.endpoint()); breaker != nil {
+				if probe, err = breaker.allow(time.Now()); err != nil {
+					s.conn.endWork()
+				}
+			}
+		}
+		if err != nil {
+			release()
+		}
+	}
 	if err != nil {
+		if info != nil {
+			s.interceptors.after(ctx, info, StatementResult{Duration: time.Since(start), Err: err})
+		}
 		return nil, err
 	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	stream, err := s.send(streamCtx, &pb.ExecuteRequest{
+		SessionId:     s.sessionID,
+		Statement:     statement,
+		Parameters:    protoParams,
+		TransactionId: transactionID,
+	}, o.callOpts)
+	if err != nil {
+		cancel()
+		release()
+		if s.conn != nil {
+			s.conn.endWork()
+		}
+		if breaker != nil {
+			breaker.record(probe, err, time.Now())
+		}
+		s.checkLost(err)
+		if info != nil {
+			s.interceptors.after(ctx, info, StatementResult{Duration: time.Since(start), Err: err})
+		}
+		return nil, err
+	}
+
+	var frames resultCursorStream = stream
+	var recorded func(*ResultCursor, error)
+	if cache != nil {
+		frames, recorded = cache.record(stream, cacheKey)
+	}
+	cursor := newResultCursor(frames)
+	cursor.session = s
+	cursor.cancel = cancel
+	cursor.queryID = o.queryID
+	cursor.raw = o.rawFrames
+	cursor.lease = o.rowLease
+	cursor.columnar = o.columnar
+	cursor.lazyProperties = o.lazyProps
+	cursor.strictConversion = s.strictConversion()
+	cursor.onDone = append(cursor.onDone, s.checkLost, func(error) { release() })
+	if s.conn != nil {
+		s.conn.trackCursor(cursor)
+	}
+	if breaker != nil {
+		cursor.onDone = append(cursor.onDone, func(err error) { breaker.record(probe, err, time.Now()) })
+	}
+	if recorded != nil {
+		cursor.onDone = append(cursor.onDone, func(err error) { recorded(cursor, err) })
+	}
+	if o.queryID != "" {
+		s.trackQuery(cursor, o.queryID)
+	}
+	s.observe(ctx, cursor, info, start)
+	return cursor, nil
+}
 
-	return newResultCursor(stream), nil
+// observe reports the cursor's statement to the interceptors when it
+// completes. info is nil if the session has no interceptors.
+func (s *GqlSession) observe(ctx context.Context, cursor *ResultCursor, info *StatementInfo, start time.Time) {
+	if info == nil {
+		return
+	}
+	cursor.onDone = append(cursor.onDone, func(err error) {
+		result := StatementResult{Duration: time.Since(start), Rows: cursor.rowsReceived, Frames: cursor.stats.Frames, Err: err}
+		if cursor.summary != nil {
+			result.Summary = &ResultSummary{proto: cursor.summary, queryID: cursor.queryID}
+		}
+		s.interceptors.after(ctx, info, result)
+	})
+}
+
+// send starts the Execute stream unless the session is closed.
+func (s *GqlSession) send(ctx context.Context, req *pb.ExecuteRequest, callOpts []grpc.CallOption) (pb.GqlService_ExecuteClient, error) {
+	if s.isClosed() {
+		return nil, errSessionClosed
+	}
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+	return s.gqlClient.Execute(s.withEndpointKey(ctx), req, callOpts...)
 }
 
 // BeginTransaction begins a new explicit transaction.
-func (s *GqlSession) BeginTransaction(ctx context.Context, readOnly bool) (*Transaction, error) {
+func (s *GqlSession) BeginTransaction(ctx context.Context, readOnly bool, opts ...TransactionOption) (*Transaction, error) {
+	s.touch()
+	o := newTransactionOptions(opts)
 	mode := pb.TransactionMode_READ_WRITE
 	if readOnly {
 		mode = pb.TransactionMode_READ_ONLY
 	}
 
+	if s.isClosed() {
+		return nil, errSessionClosed
+	}
+	if s.conn != nil {
+		if err := s.conn.beginWork(false); err != nil {
+			return nil, err
+		}
+	}
+	tx, err := s.beginTransaction(ctx, mode, o.metadata)
+	if err != nil {
+		if s.conn != nil {
+			s.conn.endWork()
+		}
+		return nil, err
+	}
+	if s.conn != nil {
+		s.conn.trackTransaction(tx, true)
+	}
+	return tx, nil
+}
+
+// beginTransaction starts a transaction on the server. md is sent with
+// every RPC of the transaction.
+func (s *GqlSession) beginTransaction(ctx context.Context, mode pb.TransactionMode, md []string) (*Transaction, error) {
+	if len(md) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, md...)
+	}
+	s.stateMu.RLock()
 	resp, err := s.gqlClient.BeginTransaction(ctx, &pb.BeginRequest{
 		SessionId: s.sessionID,
 		Mode:      mode,
 	})
+	s.stateMu.RUnlock()
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.Status != nil && IsException(resp.Status.Code) {
-		return nil, &GqlStatusError{Code: resp.Status.Code, Message: resp.Status.Message}
+		return nil, newStatusError(resp.Status.Code, resp.Status.Message)
 	}
 
 	if resp.TransactionId == "" {
@@ -63,46 +317,126 @@ func (s *GqlSession) BeginTransaction(ctx context.Context, readOnly bool) (*Tran
 	}
 
 	return &Transaction{
+		session:       s,
 		sessionID:     s.sessionID,
 		transactionID: resp.TransactionId,
 		gqlClient:     s.gqlClient,
+		readOnly:      mode == pb.TransactionMode_READ_ONLY,
+		metadata:      md,
+		started:       time.Now(),
 	}, nil
 }
 
+// SetDefaultParams sets parameters sent with every statement on this
+// session, including statements run in its transactions, such as a tenant
+// ID. A parameter passed to Execute takes precedence over a default of the
+// same name. The map is copied; pass nil to clear the defaults. Reset
+// clears them.
+func (s *GqlSession) SetDefaultParams(params map[string]any) {
+	var defaults map[string]any
+	if len(params) > 0 {
+		defaults = make(map[string]any, len(params))
+		for k, v := range params {
+			defaults[k] = v
+		}
+	}
+	s.mu.Lock()
+	s.defaultParams = defaults
+	s.mu.Unlock()
+}
+
+// withDefaultParams returns params merged over the session defaults.
+func (s *GqlSession) withDefaultParams(params map[string]any) map[string]any {
+	s.mu.Lock()
+	defaults := s.defaultParams
+	s.mu.Unlock()
+	if len(defaults) == 0 {
+		return params
+	}
+	merged := make(map[string]any, len(defaults)+len(params))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	return merged
+}
+
 // SetGraph sets the current graph for the session.
 func (s *GqlSession) SetGraph(ctx context.Context, name string) error {
-	_, err := s.sessionClient.Configure(ctx, &pb.ConfigureRequest{
+	err := s.configure(ctx, &pb.ConfigureRequest{
 		SessionId: s.sessionID,
 		Property:  &pb.ConfigureRequest_Graph{Graph: name},
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.graph = name
+	s.mu.Unlock()
+	return nil
 }
 
 // SetSchema sets the current schema for the session.
 func (s *GqlSession) SetSchema(ctx context.Context, name string) error {
-	_, err := s.sessionClient.Configure(ctx, &pb.ConfigureRequest{
+	err := s.configure(ctx, &pb.ConfigureRequest{
 		SessionId: s.sessionID,
 		Property:  &pb.ConfigureRequest_Schema{Schema: name},
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.schema = name
+	s.mu.Unlock()
+	return nil
 }
 
 // SetTimeZone sets the session timezone offset in minutes.
 func (s *GqlSession) SetTimeZone(ctx context.Context, offsetMinutes int32) error {
-	_, err := s.sessionClient.Configure(ctx, &pb.ConfigureRequest{
+	err := s.configure(ctx, &pb.ConfigureRequest{
 		SessionId: s.sessionID,
 		Property:  &pb.ConfigureRequest_TimeZoneOffsetMinutes{TimeZoneOffsetMinutes: offsetMinutes},
 	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.timeZone = offsetMinutes
+	s.timeZoneSet = true
+	s.mu.Unlock()
+	return nil
+}
+
+// configure sends a Configure request while holding the session state lock.
+func (s *GqlSession) configure(ctx context.Context, req *pb.ConfigureRequest) error {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	_, err := s.sessionClient.Configure(ctx, req)
 	return err
 }
 
-// Reset resets session state to defaults.
+// Reset resets session state to defaults, and clears the parameters set
+// with SetDefaultParams.
 func (s *GqlSession) Reset(ctx context.Context) error {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
 	_, err := s.sessionClient.Reset(ctx, &pb.ResetRequest{
 		SessionId: s.sessionID,
 		Target:    pb.ResetTarget_RESET_ALL,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.graph = ""
+	s.schema = ""
+	s.timeZoneSet = false
+	s.params = nil
+	s.defaultParams = nil
+	s.mu.Unlock()
+	return nil
 }
 
 // Ping pings the server and returns a timestamp.
@@ -111,51 +445,138 @@ func (s *GqlSession) Ping(ctx context.Context) (int64, error) {
 		SessionId: s.sessionID,
 	})
 	if err != nil {
+		s.checkLost(err)
 		return 0, err
 	}
 	return resp.Timestamp, nil
 }
 
-// Close closes the session.
+// Close closes the session. Closing an already closed session is a no-op.
 func (s *GqlSession) Close(ctx context.Context) error {
+	s.mu.Lock()
 	if s.closed {
+		s.mu.Unlock()
 		return nil
 	}
+	s.closed = true
+	s.stopHeartbeat()
+	s.stopLeakCheck()
+	s.mu.Unlock()
+	if s.onClose != nil {
+		s.onClose(s)
+	}
+
 	_, err := s.sessionClient.Close(ctx, &pb.CloseRequest{
 		SessionId: s.sessionID,
 	})
-	s.closed = true
 	return err
 }
 
+// isClosed reports whether Close has been called.
+func (s *GqlSession) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// currentGraph returns the graph last set with SetGraph.
+func (s *GqlSession) currentGraph() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.graph
+}
+
+// currentSchema returns the schema last set with SetSchema.
+func (s *GqlSession) currentSchema() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schema
+}
+
 // resultCursorStream is the interface for the gRPC stream.
 type resultCursorStream interface {
 	Recv() (*pb.ExecuteResponse, error)
 }
 
 func newResultCursor(stream resultCursorStream) *ResultCursor {
-	return &ResultCursor{stream: stream}
+	return &ResultCursor{stream: stream, started: time.Now()}
 }
 
 // ResultCursor is a cursor over streaming result frames.
 type ResultCursor struct {
-	stream      resultCursorStream
-	header      *pb.ResultHeader
-	summary     *pb.ResultSummary
+	stream       resultCursorStream
+	header       *pb.ResultHeader
+	summary      *pb.ResultSummary
 	bufferedRows [][]any
-	rowIndex    int
-	done        bool
+	rowIndex     int
+	done         bool
+	session      *GqlSession
+	// cancel cancels the stream's context, once the stream has ended or
+	// when ForEach stops early.
+	cancel  context.CancelFunc
+	queryID string
+	// onDone hooks run once when the stream completes, with the stream
+	// error or nil once the summary or end of stream is reached, or when
+	// the cursor is closed or cancelled. doneMu guards them, as Cancel may
+	// run them from another goroutine.
+	doneMu       sync.Mutex
+	onDone       []func(err error)
+	rowsReceived int64
+
+	recordColumns *recordColumns
+
+	// raw is set by WithRawFrames, lease by WithRowLease and columnar by
+	// WithColumnar; in these modes row batches are kept undecoded in
+	// rawBatches.
+	raw        bool
+	lease      bool
+	columnar   bool
+	rawBatches []*pb.RowBatch
+	leaseIndex int
+	leaseRow   *[]any
+	// spilling is set while CollectSpilled runs, which also keeps row
+	// batches undecoded.
+	spilling bool
+
+	// err is the error that ended the stream before its summary, reported
+	// again by Summary.
+	err error
+
+	// lazyProperties is set by WithLazyProperties.
+	lazyProperties bool
+	// strictConversion is set by ConnectionConfig.StrictConversion.
+	strictConversion bool
+
+	// discard skips decoding row batches for the rest of the result set.
+	discard bool
+
+	// pending is the first frame of the next result set, or pendingErr the
+	// stream error, read after a summary.
+	pending    *pb.ExecuteResponse
+	pendingErr error
+
+	started time.Time
+	stats   CursorStats
 }
 
 func (c *ResultCursor) consumeUntilRowsOrDone() error {
-	for !c.done && c.rowIndex >= len(c.bufferedRows) {
-		resp, err := c.stream.Recv()
-		if err == io.EOF {
+	for !c.done && c.rowIndex >= len(c.bufferedRows) && len(c.rawBatches) == 0 {
+		resp, err := c.recv()
+		if err == io.EOF && c.header == nil {
 			c.done = true
+			c.finish(nil)
 			return nil
 		}
 		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if c.header != nil {
+				err = &IncompleteResultError{Rows: c.rowsReceived, Cause: err}
+			}
 			c.done = true
+			c.err = err
+			c.finish(err)
 			return err
 		}
 
@@ -163,21 +584,56 @@ func (c *ResultCursor) consumeUntilRowsOrDone() error {
 		case *pb.ExecuteResponse_Header:
 			c.header = f.Header
 		case *pb.ExecuteResponse_RowBatch:
+			c.rowsReceived += int64(len(f.RowBatch.Rows))
+			if c.discard {
+				continue
+			}
+			if c.raw || c.lease || c.columnar || c.spilling {
+				c.rawBatches = append(c.rawBatches, f.RowBatch)
+				continue
+			}
+			start := time.Now()
 			for _, row := range f.RowBatch.Rows {
 				values := make([]any, len(row.Values))
 				for i, v := range row.Values {
-					values[i] = valueFromProto(v)
+					if values[i], err = c.decode(i, v); err != nil {
+						c.done = true
+						c.err = err
+						c.finish(err)
+						return err
+					}
 				}
 				c.bufferedRows = append(c.bufferedRows, values)
 			}
+			c.recordDecode(len(f.RowBatch.Rows), start)
 		case *pb.ExecuteResponse_Summary:
 			c.summary = f.Summary
 			c.done = true
+			c.peekNextResultSet()
+			if c.session != nil {
+				if h := c.session.notificationHandlerFunc(); h != nil {
+					for _, w := range f.Summary.Warnings {
+						h(notificationFromProto(w))
+					}
+				}
+			}
+			c.finish(nil)
 		}
 	}
 	return nil
 }
 
+// finish runs the cursor's completion hooks.
+func (c *ResultCursor) finish(err error) {
+	c.doneMu.Lock()
+	hooks := c.onDone
+	c.onDone = nil
+	c.doneMu.Unlock()
+	for _, h := range hooks {
+		h(err)
+	}
+}
+
 // ColumnNames returns the column names from the result header.
 func (c *ResultCursor) ColumnNames() ([]string, error) {
 	if c.header == nil {
@@ -197,6 +653,15 @@ func (c *ResultCursor) ColumnNames() ([]string, error) {
 
 // NextRow returns the next row, or nil when done.
 func (c *ResultCursor) NextRow() ([]any, error) {
+	if c.raw {
+		return nil, errRawCursor
+	}
+	if c.columnar {
+		return nil, errColumnarCursor
+	}
+	if c.lease {
+		return c.nextLeasedRow()
+	}
 	if c.rowIndex < len(c.bufferedRows) {
 		row := c.bufferedRows[c.rowIndex]
 		c.rowIndex++
@@ -227,20 +692,70 @@ func (c *ResultCursor) CollectRows() ([][]any, error) {
 		if row == nil {
 			return rows, nil
 		}
+		if c.lease {
+			row = slices.Clone(row)
+		}
 		rows = append(rows, row)
 	}
 }
 
-// Summary returns the result summary. Consumes remaining frames if needed.
+// Single returns the only row of the result. It returns ErrNoRows if there
+// are no rows and an error if there is more than one.
+func (c *ResultCursor) Single() ([]any, error) {
+	rows, err := c.CollectRows()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCursorStatus(c); err != nil {
+		return nil, err
+	}
+	switch len(rows) {
+	case 0:
+		return nil, ErrNoRows
+	case 1:
+		return rows[0], nil
+	default:
+		return nil, &GqlError{Message: fmt.Sprintf("expected one row, got %d", len(rows))}
+	}
+}
+
+// ScalarInt returns the value of a single-row, single-column integer result,
+// such as RETURN count(n).
+func (c *ResultCursor) ScalarInt() (int64, error) {
+	return One[int64](c)
+}
+
+// ScalarFloat returns the value of a single-row, single-column numeric result.
+func (c *ResultCursor) ScalarFloat() (float64, error) {
+	return One[float64](c)
+}
+
+// ScalarString returns the value of a single-row, single-column string result.
+func (c *ResultCursor) ScalarString() (string, error) {
+	return One[string](c)
+}
+
+// ScalarBool returns the value of a single-row, single-column boolean result.
+func (c *ResultCursor) ScalarBool() (bool, error) {
+	return One[bool](c)
+}
+
+// Summary returns the result summary. Remaining rows are read from the
+// stream and discarded without being decoded.
 func (c *ResultCursor) Summary() (*ResultSummary, error) {
+	if !c.done {
+		c.skipBuffered()
+	}
 	for !c.done {
-		c.rowIndex = len(c.bufferedRows)
 		if err := c.consumeUntilRowsOrDone(); err != nil {
 			return nil, err
 		}
 	}
+	if c.err != nil {
+		return nil, c.err
+	}
 	if c.summary != nil {
-		return &ResultSummary{proto: c.summary}, nil
+		return &ResultSummary{proto: c.summary, queryID: c.queryID}, nil
 	}
 	return nil, nil
 }
@@ -271,7 +786,8 @@ func (c *ResultCursor) RowsAffected() (int64, error) {
 
 // ResultSummary wraps a protobuf result summary.
 type ResultSummary struct {
-	proto *pb.ResultSummary
+	proto   *pb.ResultSummary
+	queryID string
 }
 
 // StatusCode returns the GQLSTATUS code.
@@ -299,3 +815,14 @@ func (s *ResultSummary) RowsAffected() int64 {
 func (s *ResultSummary) IsSuccess() bool {
 	return IsSuccess(s.StatusCode())
 }
+
+// Err returns the summary's exception status as an error, or nil if the
+// statement did not fail. Class 40 statuses are returned as a
+// *TransientError and others as a *GqlStatusError.
+func (s *ResultSummary) Err() error {
+	code := s.StatusCode()
+	if code == "" || !IsException(code) {
+		return nil
+	}
+	return newStatusError(code, s.Message())
+}
@@ -0,0 +1,36 @@
+package gwp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSessionDefaultParams(t *testing.T) {
+	client := &fakeGqlClient{stream: &fakeStream{frames: nil}}
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+	ctx := context.Background()
+
+	defaults := map[string]any{"tenant": "acme", "beta": true}
+	s.SetDefaultParams(defaults)
+	defaults["tenant"] = "changed"
+
+	params := map[string]any{"beta": false, "name": "Alice"}
+	if _, err := s.Execute(ctx, "MATCH (n {name: $name}) RETURN n", params); err != nil {
+		t.Fatal(err)
+	}
+	got := client.lastReq.Parameters
+	if len(got) != 3 || got["tenant"].GetStringValue() != "acme" || got["beta"].GetBooleanValue() || got["name"].GetStringValue() != "Alice" {
+		t.Fatalf("parameters = %v", got)
+	}
+	if len(params) != 2 {
+		t.Fatalf("caller's params modified: %v", params)
+	}
+
+	s.SetDefaultParams(nil)
+	if _, err := s.Execute(ctx, "RETURN 1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.lastReq.Parameters) != 0 {
+		t.Fatalf("parameters after clearing defaults = %v", client.lastReq.Parameters)
+	}
+}
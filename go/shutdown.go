@@ -0,0 +1,125 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+)
+
+var errShuttingDown = &GqlError{Message: "connection is shutting down"}
+
+// beginWork registers a statement or transaction in progress. While the
+// connection shuts down only statements of open transactions are
+// accepted.
+func (c *GqlConnection) beginWork(inTransaction bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.draining && !inTransaction {
+		return errShuttingDown
+	}
+	c.inFlight++
+	return nil
+}
+
+// endWork unregisters a statement or transaction once it has finished.
+func (c *GqlConnection) endWork() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight--
+	if c.inFlight == 0 && c.drained != nil {
+		close(c.drained)
+		c.drained = nil
+	}
+}
+
+// trackCursor records a statement's cursor, registered with beginWork,
+// until it has finished.
+func (c *GqlConnection) trackCursor(cursor *ResultCursor) {
+	c.mu.Lock()
+	if c.cursors == nil {
+		c.cursors = make(map[*ResultCursor]struct{})
+	}
+	c.cursors[cursor] = struct{}{}
+	c.mu.Unlock()
+	cursor.onDone = append(cursor.onDone, func(error) {
+		c.mu.Lock()
+		delete(c.cursors, cursor)
+		c.mu.Unlock()
+		c.endWork()
+	})
+}
+
+// trackTransaction records an open transaction, or forgets it once it has
+// finished.
+func (c *GqlConnection) trackTransaction(t *Transaction, open bool) {
+	c.mu.Lock()
+	if open {
+		if c.transactions == nil {
+			c.transactions = make(map[*Transaction]struct{})
+		}
+		c.transactions[t] = struct{}{}
+	} else {
+		delete(c.transactions, t)
+	}
+	c.mu.Unlock()
+}
+
+// Shutdown closes the connection gracefully, for example when a pod is
+// terminated. New sessions, transactions and statements outside open
+// transactions are rejected at once. Shutdown then waits until every cursor
+// has been read to the end, closed or cancelled and every transaction has
+// committed or rolled back, or until ctx is done. Cursors still open are
+// cancelled, transactions still open rolled back, sessions closed and the
+// connection closed. If ctx ends the wait, its error
+// is returned unless closing fails.
+func (c *GqlConnection) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	c.draining = true
+	var drained chan struct{}
+	if c.inFlight > 0 {
+		if c.drained == nil {
+			c.drained = make(chan struct{})
+		}
+		drained = c.drained
+	}
+	c.mu.Unlock()
+
+	var waitErr error
+	if drained != nil {
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			waitErr = ctx.Err()
+		}
+	}
+
+	c.mu.Lock()
+	cursors := make([]*ResultCursor, 0, len(c.cursors))
+	for cursor := range c.cursors {
+		cursors = append(cursors, cursor)
+	}
+	c.mu.Unlock()
+	for _, cursor := range cursors {
+		cursor.Cancel()
+	}
+
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundRollbackTimeout)
+	defer cancel()
+	c.mu.Lock()
+	leftover := make([]*Transaction, 0, len(c.transactions))
+	for t := range c.transactions {
+		leftover = append(leftover, t)
+	}
+	c.mu.Unlock()
+	var rollbackErr error
+	for _, t := range leftover {
+		rollbackErr = errors.Join(rollbackErr, t.Rollback(cleanupCtx))
+	}
+
+	if err := c.Close(cleanupCtx); err != nil {
+		return err
+	}
+	if rollbackErr != nil {
+		return rollbackErr
+	}
+	return waitErr
+}
@@ -0,0 +1,179 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+)
+
+// drainServer holds "SLOW" statements until release is closed and records
+// rolled back transactions.
+type drainServer struct {
+	handshakeServer
+	pb.UnimplementedGqlServiceServer
+	release chan struct{}
+
+	mu        sync.Mutex
+	rollbacks int
+}
+
+func (s *drainServer) Execute(r *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	if r.Statement == "SLOW" {
+		<-s.release
+	}
+	return stream.Send(summaryFrame(Success, 0))
+}
+
+func (s *drainServer) BeginTransaction(ctx context.Context, r *pb.BeginRequest) (*pb.BeginResponse, error) {
+	return &pb.BeginResponse{TransactionId: "tx"}, nil
+}
+
+func (s *drainServer) Commit(ctx context.Context, r *pb.CommitRequest) (*pb.CommitResponse, error) {
+	return &pb.CommitResponse{}, nil
+}
+
+func (s *drainServer) Rollback(ctx context.Context, r *pb.RollbackRequest) (*pb.RollbackResponse, error) {
+	s.mu.Lock()
+	s.rollbacks++
+	s.mu.Unlock()
+	return &pb.RollbackResponse{}, nil
+}
+
+func newDrainConn(t *testing.T) (*GqlConnection, *drainServer) {
+	t.Helper()
+	server := &drainServer{release: make(chan struct{})}
+	return connectBufconn(t, serveBufconn(t, server), ConnectionConfig{}), server
+}
+
+func TestShutdownDrains(t *testing.T) {
+	ctx := context.Background()
+	conn, server := newDrainConn(t)
+	s, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := s.Execute(ctx, "SLOW", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := s.BeginTransaction(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- conn.Shutdown(ctx) }()
+	for !conn.isDraining() {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := s.Execute(ctx, "RETURN 1", nil); !errors.Is(err, errShuttingDown) {
+		t.Fatalf("Execute while draining: %v", err)
+	}
+	if _, err := s.BeginTransaction(ctx, false); !errors.Is(err, errShuttingDown) {
+		t.Fatalf("BeginTransaction while draining: %v", err)
+	}
+	if _, err := conn.CreateSession(ctx); !errors.Is(err, errShuttingDown) {
+		t.Fatalf("CreateSession while draining: %v", err)
+	}
+	txCursor, err := tx.Execute(ctx, "INSERT (:A)", nil)
+	if err != nil {
+		t.Fatalf("statement in open transaction: %v", err)
+	}
+	if _, err := txCursor.Summary(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown returned with a cursor in flight: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(server.release)
+	if _, err := cursor.Summary(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	server.mu.Lock()
+	if server.rollbacks != 0 {
+		t.Fatalf("%d transactions rolled back after draining", server.rollbacks)
+	}
+	server.mu.Unlock()
+	if conn.Stats().OpenSessions != 0 {
+		t.Fatal("sessions left open")
+	}
+}
+
+func TestShutdownRollsBackLeftovers(t *testing.T) {
+	ctx := context.Background()
+	conn, server := newDrainConn(t)
+	s, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.BeginTransaction(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := conn.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want DeadlineExceeded", err)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.rollbacks != 1 {
+		t.Fatalf("%d transactions rolled back, want 1", server.rollbacks)
+	}
+}
+
+func TestShutdownClosedAndLeftoverCursors(t *testing.T) {
+	ctx := context.Background()
+	conn, server := newDrainConn(t)
+	t.Cleanup(func() { close(server.release) })
+	s, err := conn.CreateSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed, err := s.Execute(ctx, "SLOW", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Execute(ctx, "SLOW", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	shutdownCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	go func() { done <- conn.Shutdown(shutdownCtx) }()
+	for !conn.isDraining() {
+		time.Sleep(time.Millisecond)
+	}
+	closed.Close()
+	conn.mu.Lock()
+	inFlight := conn.inFlight
+	conn.mu.Unlock()
+	if inFlight != 1 {
+		t.Fatalf("%d statements in flight after Close, want 1", inFlight)
+	}
+
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want DeadlineExceeded", err)
+	}
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if conn.inFlight != 0 || len(conn.cursors) != 0 {
+		t.Fatalf("%d statements in flight after Shutdown", conn.inFlight)
+	}
+}
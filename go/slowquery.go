@@ -0,0 +1,189 @@
+package gwp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDurationBuckets are the upper bounds of the default statement
+// duration histogram buckets.
+var DefaultDurationBuckets = []time.Duration{
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond,
+	25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond,
+	250 * time.Millisecond, 500 * time.Millisecond, time.Second,
+	2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// DurationHistogram counts durations in fixed buckets. It is safe for
+// concurrent use and implements expvar.Var, so it can be published with
+// expvar.Publish.
+type DurationHistogram struct {
+	bounds []time.Duration
+	counts []atomic.Uint64 // one per bound, plus one for +Inf
+	count  atomic.Uint64
+	sum    atomic.Int64
+}
+
+// NewDurationHistogram returns a histogram with the given bucket upper
+// bounds, or DefaultDurationBuckets if none are given.
+func NewDurationHistogram(bounds ...time.Duration) *DurationHistogram {
+	if len(bounds) == 0 {
+		bounds = DefaultDurationBuckets
+	}
+	bounds = append([]time.Duration(nil), bounds...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+	return &DurationHistogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+// Observe records a duration.
+func (h *DurationHistogram) Observe(d time.Duration) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] })
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+// HistogramSnapshot is a point-in-time copy of a DurationHistogram.
+type HistogramSnapshot struct {
+	// Bounds are the bucket upper bounds.
+	Bounds []time.Duration
+	// Cumulative holds, for each bound, the number of observations less
+	// than or equal to it, as in the Prometheus exposition format.
+	Cumulative []uint64
+	Count      uint64
+	Sum        time.Duration
+}
+
+// Snapshot returns the histogram's current counts.
+func (h *DurationHistogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Bounds:     h.bounds,
+		Cumulative: make([]uint64, len(h.bounds)),
+		Count:      h.count.Load(),
+		Sum:        time.Duration(h.sum.Load()),
+	}
+	var total uint64
+	for i := range h.bounds {
+		total += h.counts[i].Load()
+		s.Cumulative[i] = total
+	}
+	return s
+}
+
+// String returns the histogram as JSON, for expvar.
+func (h *DurationHistogram) String() string {
+	s := h.Snapshot()
+	buckets := make(map[string]uint64, len(s.Bounds))
+	for i, b := range s.Bounds {
+		buckets[fmt.Sprintf("%g", b.Seconds())] = s.Cumulative[i]
+	}
+	data, _ := json.Marshal(struct {
+		Buckets map[string]uint64 `json:"buckets"`
+		Count   uint64            `json:"count"`
+		Sum     float64           `json:"sum"`
+	}{buckets, s.Count, s.Sum.Seconds()})
+	return string(data)
+}
+
+// SlowQueryConfig holds configuration for a SlowQueryLogger.
+type SlowQueryConfig struct {
+	// Threshold is the duration above which a statement is logged.
+	// Defaults to one second.
+	Threshold time.Duration
+	// Logger receives slow statements at warning level. Defaults to
+	// slog.Default().
+	Logger *slog.Logger
+	// Histogram records the duration of every statement. Defaults to a new
+	// histogram with DefaultDurationBuckets.
+	Histogram *DurationHistogram
+	// Sanitizer, if set, masks literals in logged statements and logs
+	// parameter values with sensitive ones redacted. Otherwise statements
+	// are logged as executed and parameters by type only.
+	Sanitizer *Sanitizer
+}
+
+// SlowQueryLogger is a StatementInterceptor that logs statements slower
+// than a threshold and records all statement durations in a histogram.
+// Parameter values are only logged through a Sanitizer; otherwise only
+// their names and types are.
+type SlowQueryLogger struct {
+	config SlowQueryConfig
+}
+
+// NewSlowQueryLogger returns a SlowQueryLogger. Add it to
+// ConnectionConfig.Interceptors or a session's WithInterceptors.
+func NewSlowQueryLogger(config SlowQueryConfig) *SlowQueryLogger {
+	if config.Threshold == 0 {
+		config.Threshold = time.Second
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	if config.Histogram == nil {
+		config.Histogram = NewDurationHistogram()
+	}
+	return &SlowQueryLogger{config: config}
+}
+
+// Histogram returns the statement duration histogram.
+func (l *SlowQueryLogger) Histogram() *DurationHistogram {
+	return l.config.Histogram
+}
+
+// BeforeExecute implements StatementInterceptor.
+func (l *SlowQueryLogger) BeforeExecute(ctx context.Context, info *StatementInfo) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterExecute implements StatementInterceptor.
+func (l *SlowQueryLogger) AfterExecute(ctx context.Context, info *StatementInfo, result StatementResult) {
+	l.config.Histogram.Observe(result.Duration)
+	if result.Duration < l.config.Threshold {
+		return
+	}
+
+	statement := info.Statement
+	if l.config.Sanitizer != nil {
+		statement = l.config.Sanitizer.Statement(statement)
+	}
+	attrs := []slog.Attr{
+		slog.Duration("duration", result.Duration),
+		slog.String("statement", statement),
+		slog.String("session_id", info.SessionID),
+	}
+	if info.TransactionID != "" {
+		attrs = append(attrs, slog.String("transaction_id", info.TransactionID))
+	}
+	if len(info.Params) > 0 {
+		if l.config.Sanitizer != nil {
+			attrs = append(attrs, slog.Any("params", l.config.Sanitizer.Params(info.Params)))
+		} else {
+			attrs = append(attrs, slog.Any("params", sanitizeParams(info.Params)))
+		}
+	}
+	if result.Summary != nil {
+		attrs = append(attrs, slog.String("status", result.Summary.StatusCode()))
+	}
+	if result.Err != nil {
+		attrs = append(attrs, slog.String("error", result.Err.Error()))
+	}
+	l.config.Logger.LogAttrs(ctx, slog.LevelWarn, "slow statement", attrs...)
+}
+
+// sanitizeParams replaces parameter values with their Go types.
+func sanitizeParams(params map[string]any) map[string]string {
+	out := make(map[string]string, len(params))
+	for k, v := range params {
+		if v == nil {
+			out[k] = "null"
+			continue
+		}
+		out[k] = fmt.Sprintf("%T", v)
+	}
+	return out
+}
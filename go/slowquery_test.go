@@ -0,0 +1,74 @@
+package gwp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+var _ expvar.Var = (*DurationHistogram)(nil)
+
+func TestDurationHistogram(t *testing.T) {
+	h := NewDurationHistogram(100*time.Millisecond, 10*time.Millisecond)
+	h.Observe(5 * time.Millisecond)
+	h.Observe(10 * time.Millisecond)
+	h.Observe(50 * time.Millisecond)
+	h.Observe(time.Second)
+
+	s := h.Snapshot()
+	if s.Bounds[0] != 10*time.Millisecond {
+		t.Fatalf("bounds not sorted: %v", s.Bounds)
+	}
+	if s.Cumulative[0] != 2 || s.Cumulative[1] != 3 || s.Count != 4 {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	if s.Sum != 1065*time.Millisecond {
+		t.Fatalf("sum = %v", s.Sum)
+	}
+
+	var decoded struct {
+		Buckets map[string]uint64
+		Count   uint64
+	}
+	if err := json.Unmarshal([]byte(h.String()), &decoded); err != nil {
+		t.Fatalf("String() is not JSON: %v", err)
+	}
+	if decoded.Count != 4 || decoded.Buckets["0.01"] != 2 {
+		t.Fatalf("String() = %s", h.String())
+	}
+}
+
+func TestSlowQueryLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlowQueryLogger(SlowQueryConfig{
+		Threshold: 100 * time.Millisecond,
+		Logger:    slog.New(slog.NewTextHandler(&buf, nil)),
+	})
+	info := &StatementInfo{
+		SessionID: "s1",
+		Statement: "MATCH (u:User {password: $pw}) RETURN u",
+		Params:    map[string]any{"pw": "hunter2"},
+	}
+
+	logger.AfterExecute(context.Background(), info, StatementResult{Duration: 10 * time.Millisecond})
+	if buf.Len() != 0 {
+		t.Fatalf("fast statement logged: %s", buf.String())
+	}
+
+	logger.AfterExecute(context.Background(), info, StatementResult{Duration: 200 * time.Millisecond})
+	out := buf.String()
+	if !strings.Contains(out, "slow statement") || !strings.Contains(out, "MATCH (u:User") {
+		t.Fatalf("slow statement not logged: %s", out)
+	}
+	if strings.Contains(out, "hunter2") || !strings.Contains(out, "pw:string") {
+		t.Fatalf("parameters not sanitized: %s", out)
+	}
+	if logger.Histogram().Snapshot().Count != 2 {
+		t.Fatal("histogram should record every statement")
+	}
+}
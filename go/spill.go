@@ -0,0 +1,213 @@
+package gwp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/protobuf/proto"
+)
+
+// SpillConfig configures CollectSpilled.
+type SpillConfig struct {
+	// MemoryRows is the number of rows kept in memory. Later rows are
+	// written to a temporary file. Defaults to 10000.
+	MemoryRows int
+	// Dir is the directory of the temporary file. Defaults to
+	// os.TempDir().
+	Dir string
+}
+
+// SpilledRows holds the rows collected by CollectSpilled: the first rows in
+// memory and the rest in a temporary file, read back as they are iterated.
+// Close removes the file.
+type SpilledRows struct {
+	memory  [][]any
+	file    *os.File
+	size    int64
+	spilled int
+	// decode decodes a spilled value of column i as the cursor would have.
+	decode func(i int, v *pb.Value) (any, error)
+}
+
+// CollectSpilled reads the remaining rows of the current result set like
+// CollectRows, but keeps at most config.MemoryRows of them in memory and
+// writes the rest to a temporary file, so that very large results, as in
+// export jobs, do not exhaust memory. Spilled rows are stored as the server
+// sent them and decoded as they are read back, so a value that fails to
+// decode under ConnectionConfig.StrictConversion is reported by All. The
+// caller must Close the result. On error, the rows read so far are
+// discarded.
+func (c *ResultCursor) CollectSpilled(config SpillConfig) (*SpilledRows, error) {
+	if c.raw {
+		return nil, errRawCursor
+	}
+	if c.columnar {
+		return nil, errColumnarCursor
+	}
+	if config.MemoryRows <= 0 {
+		config.MemoryRows = 10000
+	}
+	r := &SpilledRows{decode: c.decode}
+	// Rows already decoded by NextRow have no undecoded form left.
+	for c.rowIndex < len(c.bufferedRows) {
+		r.memory = append(r.memory, c.bufferedRows[c.rowIndex])
+		c.rowIndex++
+	}
+	c.spilling = true
+	defer func() { c.spilling = false }()
+
+	var w *bufio.Writer
+	var lenBuf [binary.MaxVarintLen64]byte
+	for {
+		row, err := c.nextProtoRow()
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		if row == nil {
+			break
+		}
+		if len(r.memory) < config.MemoryRows {
+			start := time.Now()
+			values := make([]any, len(row.Values))
+			for i, v := range row.Values {
+				if values[i], err = c.decode(i, v); err != nil {
+					r.Close()
+					return nil, err
+				}
+			}
+			c.recordDecode(1, start)
+			r.memory = append(r.memory, values)
+			continue
+		}
+
+		if r.file == nil {
+			if r.file, err = os.CreateTemp(config.Dir, "gwp-spill-*"); err != nil {
+				return nil, err
+			}
+			w = bufio.NewWriter(r.file)
+		}
+		data, err := proto.Marshal(row)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		n := binary.PutUvarint(lenBuf[:], uint64(len(data)))
+		w.Write(lenBuf[:n])
+		if _, err := w.Write(data); err != nil {
+			r.Close()
+			return nil, err
+		}
+		r.size += int64(n + len(data))
+		r.spilled++
+	}
+	if w != nil {
+		if err := w.Flush(); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// nextProtoRow returns the next row of the current result set as the server
+// sent it, or nil when done. The cursor must be spilling, with no decoded
+// rows left.
+func (c *ResultCursor) nextProtoRow() (*pb.Row, error) {
+	for {
+		if len(c.rawBatches) > 0 {
+			batch := c.rawBatches[0]
+			if c.leaseIndex < len(batch.Rows) {
+				row := batch.Rows[c.leaseIndex]
+				c.leaseIndex++
+				return row, nil
+			}
+			c.rawBatches[0] = nil
+			c.rawBatches = c.rawBatches[1:]
+			c.leaseIndex = 0
+			continue
+		}
+		if c.done {
+			c.releaseLeasedRow()
+			return nil, nil
+		}
+		if err := c.consumeUntilRowsOrDone(); err != nil {
+			c.releaseLeasedRow()
+			return nil, err
+		}
+	}
+}
+
+// Len returns the number of rows.
+func (r *SpilledRows) Len() int {
+	return len(r.memory) + r.spilled
+}
+
+// Spilled returns the number of rows written to disk.
+func (r *SpilledRows) Spilled() int {
+	return r.spilled
+}
+
+// All iterates over the rows in result order, reading spilled rows back
+// from disk. It may be called more than once. Iteration stops at the first
+// read error, which is yielded with a nil row.
+func (r *SpilledRows) All() iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		for _, row := range r.memory {
+			if !yield(row, nil) {
+				return
+			}
+		}
+		if r.file == nil {
+			return
+		}
+		br := bufio.NewReader(io.NewSectionReader(r.file, 0, r.size))
+		var data []byte
+		for i := 0; i < r.spilled; i++ {
+			n, err := binary.ReadUvarint(br)
+			if err == nil {
+				data = slices.Grow(data[:0], int(n))[:n]
+				_, err = io.ReadFull(br, data)
+			}
+			var row pb.Row
+			if err == nil {
+				err = proto.Unmarshal(data, &row)
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			values := make([]any, len(row.Values))
+			for j, v := range row.Values {
+				if values[j], err = r.decode(j, v); err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+			if !yield(values, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Close removes the temporary file, if any. Closing twice is a no-op.
+func (r *SpilledRows) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	f := r.file
+	r.file = nil
+	r.spilled = 0
+	closeErr := f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	return closeErr
+}
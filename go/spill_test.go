@@ -0,0 +1,111 @@
+package gwp
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+func TestCollectSpilled(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestCursor(
+		headerFrame("n", "name"),
+		batchFrame([]any{int64(1), "a"}, []any{int64(2), "b"}),
+		batchFrame([]any{int64(3), nil}, []any{int64(4), []any{"x", 1.5}}),
+		summaryFrame(Success, 0),
+	)
+	rows, err := c.CollectSpilled(SpillConfig{MemoryRows: 2, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows.Len() != 4 || rows.Spilled() != 2 {
+		t.Fatalf("Len = %d, Spilled = %d", rows.Len(), rows.Spilled())
+	}
+
+	for pass := 0; pass < 2; pass++ {
+		var got []int64
+		for row, err := range rows.All() {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, row[0].(int64))
+			if row[0] == int64(4) {
+				if list, ok := row[1].([]any); !ok || len(list) != 2 || list[0] != "x" {
+					t.Fatalf("spilled list = %#v", row[1])
+				}
+			}
+		}
+		if len(got) != 4 || got[2] != 3 || got[3] != 4 {
+			t.Fatalf("pass %d: rows %v", pass, got)
+		}
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("temporary file left behind: %v", entries)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectSpilledError(t *testing.T) {
+	dir := t.TempDir()
+	failure := errors.New("stream broken")
+	c := newResultCursor(&fakeStream{frames: []*pb.ExecuteResponse{
+		headerFrame("n"),
+		batchFrame([]any{int64(1)}, []any{int64(2)}),
+	}, err: failure})
+	if _, err := c.CollectSpilled(SpillConfig{MemoryRows: 1, Dir: dir}); !errors.Is(err, failure) {
+		t.Fatalf("err = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("temporary file left behind: %v", entries)
+	}
+}
+
+func TestCollectSpilledNodes(t *testing.T) {
+	nodeRow := func(id, name string) *pb.Row {
+		return &pb.Row{Values: []*pb.Value{{Kind: &pb.Value_NodeValue{NodeValue: pbNode(id, "Person", name)}}}}
+	}
+	c := newTestCursor(
+		headerFrame("n"),
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: &pb.RowBatch{Rows: []*pb.Row{
+			nodeRow("a", "Alice"),
+		}}}},
+		&pb.ExecuteResponse{Frame: &pb.ExecuteResponse_RowBatch{RowBatch: &pb.RowBatch{Rows: []*pb.Row{
+			nodeRow("b", "Bob"), nodeRow("c", "Carol"),
+		}}}},
+		summaryFrame(Success, 0),
+	)
+	first, err := c.NextRow()
+	if err != nil || first[0].(*GqlNode).Properties["name"] != "Alice" {
+		t.Fatalf("NextRow = %v, %v", first, err)
+	}
+	rows, err := c.CollectSpilled(SpillConfig{MemoryRows: 1, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if rows.Len() != 2 || rows.Spilled() != 1 {
+		t.Fatalf("Len = %d, Spilled = %d", rows.Len(), rows.Spilled())
+	}
+	var names []any
+	for row, err := range rows.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		node := row[0].(*GqlNode)
+		if len(node.Labels) != 1 || node.Labels[0] != "Person" {
+			t.Fatalf("labels = %v", node.Labels)
+		}
+		names = append(names, node.Properties["name"])
+	}
+	if len(names) != 2 || names[0] != "Bob" || names[1] != "Carol" {
+		t.Fatalf("names = %v", names)
+	}
+}
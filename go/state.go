@@ -0,0 +1,104 @@
+package gwp
+
+import (
+	"context"
+	"maps"
+	"sort"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// SessionState is a snapshot of the configuration of a session, taken by
+// State and applied to another session by Restore, for example to rebuild a
+// session on a new server after failover.
+type SessionState struct {
+	Graph  string
+	Schema string
+	// TimeZoneOffsetMinutes is nil unless SetTimeZone was called.
+	TimeZoneOffsetMinutes *int32
+	// Parameters are the server-side session parameters set with
+	// SetParameter.
+	Parameters map[string]any
+	// DefaultParams are the client-side defaults set with
+	// SetDefaultParams.
+	DefaultParams map[string]any
+}
+
+// SetParameter sets a session parameter on the server, available to every
+// later statement of the session as $name.
+func (s *GqlSession) SetParameter(ctx context.Context, name string, value any) error {
+	pv, err := newParamEncoder(s.paramLimits()).encode(value, "$"+name, 0)
+	if err != nil {
+		return err
+	}
+	err = s.configure(ctx, &pb.ConfigureRequest{
+		SessionId: s.sessionID,
+		Property: &pb.ConfigureRequest_Parameter{Parameter: &pb.SessionParameter{
+			Name:  name,
+			Value: pv,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if s.params == nil {
+		s.params = make(map[string]any)
+	}
+	s.params[name] = value
+	s.mu.Unlock()
+	return nil
+}
+
+// State returns the session's current configuration as set through this
+// client. Settings made by statements, such as a USE clause, are not seen.
+func (s *GqlSession) State() SessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := SessionState{
+		Graph:         s.graph,
+		Schema:        s.schema,
+		Parameters:    maps.Clone(s.params),
+		DefaultParams: maps.Clone(s.defaultParams),
+	}
+	if s.timeZoneSet {
+		tz := s.timeZone
+		state.TimeZoneOffsetMinutes = &tz
+	}
+	return state
+}
+
+// Restore applies state to the session, typically a new one: it sets the
+// graph, schema, time zone and session parameters present in state on the
+// server, in that order, and replaces the default parameters. Settings
+// absent from state are left as they are. Restore stops at the first
+// failure.
+func (s *GqlSession) Restore(ctx context.Context, state SessionState) error {
+	if state.Graph != "" {
+		if err := s.SetGraph(ctx, state.Graph); err != nil {
+			return err
+		}
+	}
+	if state.Schema != "" {
+		if err := s.SetSchema(ctx, state.Schema); err != nil {
+			return err
+		}
+	}
+	if state.TimeZoneOffsetMinutes != nil {
+		if err := s.SetTimeZone(ctx, *state.TimeZoneOffsetMinutes); err != nil {
+			return err
+		}
+	}
+	names := make([]string, 0, len(state.Parameters))
+	for name := range state.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := s.SetParameter(ctx, name, state.Parameters[name]); err != nil {
+			return err
+		}
+	}
+	s.SetDefaultParams(state.DefaultParams)
+	return nil
+}
@@ -0,0 +1,86 @@
+package gwp
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+)
+
+// configureClient records Configure requests.
+type configureClient struct {
+	pb.SessionServiceClient
+	requests []*pb.ConfigureRequest
+}
+
+func (c *configureClient) Configure(ctx context.Context, in *pb.ConfigureRequest, opts ...grpc.CallOption) (*pb.ConfigureResponse, error) {
+	c.requests = append(c.requests, in)
+	return &pb.ConfigureResponse{}, nil
+}
+
+func (c *configureClient) Reset(ctx context.Context, in *pb.ResetRequest, opts ...grpc.CallOption) (*pb.ResetResponse, error) {
+	return &pb.ResetResponse{}, nil
+}
+
+func TestSessionStateRestore(t *testing.T) {
+	ctx := context.Background()
+	old := &GqlSession{sessionID: "s1", sessionClient: &configureClient{}}
+	if state := old.State(); state.TimeZoneOffsetMinutes != nil || state.Graph != "" {
+		t.Fatalf("initial state = %+v", state)
+	}
+	old.SetGraph(ctx, "social")
+	old.SetTimeZone(ctx, -300)
+	old.SetParameter(ctx, "tenant", "acme")
+	old.SetParameter(ctx, "limit", int64(10))
+	old.SetDefaultParams(map[string]any{"region": "eu"})
+
+	state := old.State()
+	if state.Graph != "social" || state.Schema != "" || *state.TimeZoneOffsetMinutes != -300 ||
+		state.Parameters["tenant"] != "acme" || state.DefaultParams["region"] != "eu" {
+		t.Fatalf("State = %+v", state)
+	}
+	state.Parameters["tenant"] = "changed"
+	if old.State().Parameters["tenant"] != "acme" {
+		t.Fatal("State shares its maps with the session")
+	}
+	state.Parameters["tenant"] = "acme"
+
+	client := &configureClient{}
+	fresh := &GqlSession{sessionID: "s2", sessionClient: client}
+	if err := fresh.Restore(ctx, state); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range client.requests {
+		switch p := r.Property.(type) {
+		case *pb.ConfigureRequest_Graph:
+			got = append(got, "graph="+p.Graph)
+		case *pb.ConfigureRequest_TimeZoneOffsetMinutes:
+			got = append(got, "tz")
+		case *pb.ConfigureRequest_Parameter:
+			got = append(got, "param="+p.Parameter.Name)
+		default:
+			got = append(got, "other")
+		}
+	}
+	want := []string{"graph=social", "tz", "param=limit", "param=tenant"}
+	if len(got) != len(want) {
+		t.Fatalf("requests = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("requests = %v, want %v", got, want)
+		}
+	}
+	if restored := fresh.State(); restored.Graph != "social" || restored.DefaultParams["region"] != "eu" || len(restored.Parameters) != 2 {
+		t.Fatalf("restored state = %+v", restored)
+	}
+
+	if err := fresh.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if reset := fresh.State(); reset.Graph != "" || reset.TimeZoneOffsetMinutes != nil || reset.Parameters != nil || reset.DefaultParams != nil {
+		t.Fatalf("state after Reset = %+v", reset)
+	}
+}
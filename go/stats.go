@@ -0,0 +1,61 @@
+package gwp
+
+import (
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/protobuf/proto"
+)
+
+// CursorStats reports how much a cursor has read from its result stream,
+// for attributing bandwidth and latency to individual statements. Counts
+// cover every result set read so far.
+type CursorStats struct {
+	// Frames is the number of response frames received.
+	Frames int64
+	// Rows is the number of rows received, whether decoded or not.
+	Rows int64
+	// RowsDecoded is the number of rows decoded into Go values. Rows
+	// skipped by Discard or Summary, or read with WithRawFrames, are not
+	// decoded.
+	RowsDecoded int64
+	// WireBytes is the encoded size of the frames received, before
+	// transport compression.
+	WireBytes int64
+	// DecodeTime is the time spent decoding rows into Go values.
+	DecodeTime time.Duration
+	// TimeToFirstRow runs from the start of the statement until its first
+	// row arrived, or is zero if no row has arrived.
+	TimeToFirstRow time.Duration
+}
+
+// Stats returns the cursor's statistics so far.
+func (c *ResultCursor) Stats() CursorStats {
+	return c.stats
+}
+
+// recvFrame reads the next frame from the stream and accounts for it.
+func (c *ResultCursor) recvFrame() (*pb.ExecuteResponse, error) {
+	resp, err := c.stream.Recv()
+	if err != nil {
+		if c.cancel != nil {
+			c.cancel()
+		}
+		return nil, err
+	}
+	c.stats.Frames++
+	c.stats.WireBytes += int64(proto.Size(resp))
+	if batch := resp.GetRowBatch(); batch != nil && len(batch.Rows) > 0 {
+		c.stats.Rows += int64(len(batch.Rows))
+		if c.stats.TimeToFirstRow == 0 {
+			c.stats.TimeToFirstRow = time.Since(c.started)
+		}
+	}
+	return resp, nil
+}
+
+// recordDecode accounts for rows decoded since start.
+func (c *ResultCursor) recordDecode(rows int, start time.Time) {
+	c.stats.RowsDecoded += int64(rows)
+	c.stats.DecodeTime += time.Since(start)
+}
@@ -0,0 +1,67 @@
+package gwp
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCursorStats(t *testing.T) {
+	frames := []*pb.ExecuteResponse{
+		headerFrame("name"),
+		batchFrame([]any{"Alice"}, []any{"Bob"}),
+		batchFrame([]any{"Carol"}),
+		summaryFrame(Success, 0),
+		headerFrame("n"),
+		batchFrame([]any{int64(1)}),
+		summaryFrame(Success, 0),
+	}
+	var wireBytes int64
+	for _, f := range frames {
+		wireBytes += int64(proto.Size(f))
+	}
+	c := newTestCursor(frames...)
+	c.started = time.Now().Add(-time.Millisecond)
+
+	if s := c.Stats(); s != (CursorStats{}) {
+		t.Fatalf("Stats before reading = %+v", s)
+	}
+	if _, err := c.NextRow(); err != nil {
+		t.Fatal(err)
+	}
+	s := c.Stats()
+	if s.Frames != 2 || s.Rows != 2 || s.RowsDecoded != 2 || s.TimeToFirstRow < time.Millisecond {
+		t.Fatalf("Stats after first row = %+v", s)
+	}
+
+	// The rest of the first result set is skipped without decoding.
+	if more, err := c.NextResultSet(); err != nil || !more {
+		t.Fatalf("NextResultSet = %v, %v", more, err)
+	}
+	if _, err := c.CollectRows(); err != nil {
+		t.Fatal(err)
+	}
+	s = c.Stats()
+	if s.Frames != int64(len(frames)) || s.Rows != 4 || s.RowsDecoded != 3 || s.WireBytes != wireBytes {
+		t.Fatalf("Stats = %+v, want %d frames and %d bytes", s, len(frames), wireBytes)
+	}
+	if s.TimeToFirstRow > time.Second {
+		t.Fatalf("timings = %+v", s)
+	}
+}
+
+func TestCursorStatsRowLease(t *testing.T) {
+	c := newTestCursor(
+		batchFrame([]any{"Alice"}, []any{"Bob"}),
+		summaryFrame(Success, 0),
+	)
+	c.lease = true
+	if _, err := c.NextRow(); err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Stats(); s.Rows != 2 || s.RowsDecoded != 1 {
+		t.Fatalf("Stats = %+v", s)
+	}
+}
@@ -2,12 +2,17 @@ package gwp
 
 // GQLSTATUS constants (ISO/IEC 39075 Chapter 23).
 const (
-	Success            = "00000"
-	OmittedResult      = "00001"
-	Warning            = "01000"
-	NoData             = "02000"
-	InvalidSyntax      = "42001"
-	GraphTypeViolation = "G2000"
+	Success       = "00000"
+	OmittedResult = "00001"
+	Warning       = "01000"
+	NoData        = "02000"
+	Informational = "03000"
+	InvalidSyntax = "42001"
+	// TransactionRollback is the class 40 status a server reports when it
+	// rolled a transaction back, for example on a serialization conflict
+	// or deadlock. Replaying the transaction may succeed.
+	TransactionRollback = "40000"
+	GraphTypeViolation  = "G2000"
 )
 
 // StatusClass extracts the 2-character class from a 5-character GQLSTATUS code.
@@ -33,8 +38,19 @@ func IsNoData(code string) bool {
 	return StatusClass(code) == "02"
 }
 
+// IsInformational checks if the status indicates an informational notice (class 03).
+func IsInformational(code string) bool {
+	return StatusClass(code) == "03"
+}
+
+// IsTransactionRollback checks if the status indicates that the transaction
+// was rolled back (class 40).
+func IsTransactionRollback(code string) bool {
+	return StatusClass(code) == "40"
+}
+
 // IsException checks if the status indicates an exception.
 func IsException(code string) bool {
 	cls := StatusClass(code)
-	return cls != "00" && cls != "01" && cls != "02"
+	return cls != "00" && cls != "01" && cls != "02" && cls != "03"
 }
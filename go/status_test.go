@@ -38,6 +38,15 @@ func TestNoData(t *testing.T) {
 	}
 }
 
+func TestInformational(t *testing.T) {
+	if !IsInformational(Informational) {
+		t.Fatal("expected informational")
+	}
+	if IsException(Informational) {
+		t.Fatal("expected not exception")
+	}
+}
+
 func TestException(t *testing.T) {
 	if !IsException(InvalidSyntax) {
 		t.Fatal("expected exception")
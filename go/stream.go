@@ -0,0 +1,123 @@
+package gwp
+
+import (
+	"context"
+	"iter"
+)
+
+// StreamConfig controls ExecuteStream.
+type StreamConfig struct {
+	// BatchSize is the number of rows sent with each statement. Zero
+	// means 1000.
+	BatchSize int
+	// Param names the list parameter that holds a batch of rows. Empty
+	// means "rows".
+	Param string
+	// Params are passed with every batch alongside the rows.
+	Params map[string]any
+	// Options apply to every batch's statement.
+	Options []ExecuteOption
+}
+
+// StreamResult reports the rows written by ExecuteStream.
+type StreamResult struct {
+	// Rows is the number of source rows in batches that succeeded.
+	Rows int64
+	// Batches is the number of statements that succeeded.
+	Batches int
+	// RowsAffected sums the rows affected reported for each batch.
+	RowsAffected int64
+}
+
+// ExecuteStream runs a parameterized write over a stream of parameter rows,
+// such as an ingestion feed. The protocol has no client-streaming Execute,
+// so rows are grouped into batches and statement runs once per batch with
+// the batch as a list of records in the parameter named by config.Param;
+// the statement iterates over it, for example
+//
+//	FOR row IN $rows INSERT (:Person {name: row.name, age: row.age})
+//
+// This costs one round trip per batch instead of one per row. Batches run
+// in order on the session, inside its transaction if the statement begins
+// one; ExecuteStream stops at the first failing batch and returns the
+// counts of the batches before it with the error. Use ChanRows to stream
+// rows from a channel.
+func (s *GqlSession) ExecuteStream(ctx context.Context, statement string, rows iter.Seq[map[string]any], config StreamConfig) (*StreamResult, error) {
+	size := config.BatchSize
+	if size <= 0 {
+		size = 1000
+	}
+	param := config.Param
+	if param == "" {
+		param = "rows"
+	}
+
+	result := &StreamResult{}
+	batch := make([]any, 0, size)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		params := make(map[string]any, len(config.Params)+1)
+		for k, v := range config.Params {
+			params[k] = v
+		}
+		params[param] = batch
+		affected, err := s.executeStreamBatch(ctx, statement, params, config.Options)
+		if err != nil {
+			return err
+		}
+		result.Rows += int64(len(batch))
+		result.Batches++
+		result.RowsAffected += affected
+		batch = make([]any, 0, size)
+		return nil
+	}
+
+	for row := range rows {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		batch = append(batch, row)
+		if len(batch) == size {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, flush()
+}
+
+func (s *GqlSession) executeStreamBatch(ctx context.Context, statement string, params map[string]any, opts []ExecuteOption) (int64, error) {
+	cursor, err := s.Execute(ctx, statement, params, opts...)
+	if err != nil {
+		return 0, err
+	}
+	summary, err := cursor.Discard(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if summary == nil {
+		return 0, nil
+	}
+	if err := summary.Err(); err != nil {
+		return 0, err
+	}
+	return summary.RowsAffected(), nil
+}
+
+// ChanRows adapts a channel of parameter rows for ExecuteStream. The stream
+// ends when ch is closed. ExecuteStream stops reading on error, so
+// producers should also stop when the context they share is done.
+func ChanRows(ch <-chan map[string]any) iter.Seq[map[string]any] {
+	return func(yield func(map[string]any) bool) {
+		for row := range ch {
+			if !yield(row) {
+				return
+			}
+		}
+	}
+}
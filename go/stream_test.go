@@ -0,0 +1,84 @@
+package gwp
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+)
+
+// batchClient answers each statement with a summary counting the rows in
+// its batch, failing the batch numbered failAt.
+type batchClient struct {
+	pb.GqlServiceClient
+	reqs   []*pb.ExecuteRequest
+	failAt int
+}
+
+func (c *batchClient) Execute(ctx context.Context, in *pb.ExecuteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.ExecuteResponse], error) {
+	c.reqs = append(c.reqs, in)
+	code := Success
+	if len(c.reqs) == c.failAt {
+		code = "22000"
+	}
+	n := int64(len(in.Parameters["rows"].GetListValue().GetElements()))
+	return &fakeClientStream{fakeStream: &fakeStream{frames: []*pb.ExecuteResponse{summaryFrame(code, n)}}}, nil
+}
+
+func streamRows(n int) []map[string]any {
+	rows := make([]map[string]any, n)
+	for i := range rows {
+		rows[i] = map[string]any{"id": int64(i)}
+	}
+	return rows
+}
+
+func TestExecuteStream(t *testing.T) {
+	client := &batchClient{}
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+
+	result, err := s.ExecuteStream(context.Background(), "FOR row IN $rows INSERT (:N {id: row.id})",
+		slices.Values(streamRows(25)), StreamConfig{BatchSize: 10, Params: map[string]any{"graph": "g"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rows != 25 || result.Batches != 3 || result.RowsAffected != 25 {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(client.reqs) != 3 {
+		t.Fatalf("sent %d statements, want 3", len(client.reqs))
+	}
+	last := client.reqs[2].Parameters
+	if len(last["rows"].GetListValue().Elements) != 5 || last["graph"].GetStringValue() != "g" {
+		t.Fatalf("last batch params = %v", last)
+	}
+}
+
+func TestExecuteStreamStopsOnFailure(t *testing.T) {
+	client := &batchClient{failAt: 2}
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+
+	ch := make(chan map[string]any)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		defer close(ch)
+		for _, row := range streamRows(100) {
+			select {
+			case ch <- row:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	result, err := s.ExecuteStream(ctx, "FOR row IN $rows INSERT (:N)", ChanRows(ch), StreamConfig{BatchSize: 10})
+	if err == nil || statusCode(err) != "22000" {
+		t.Fatalf("err = %v, want the batch's status error", err)
+	}
+	if result.Batches != 1 || result.Rows != 10 || len(client.reqs) != 2 {
+		t.Fatalf("result = %+v after %d statements", result, len(client.reqs))
+	}
+}
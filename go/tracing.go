@@ -0,0 +1,90 @@
+package gwp
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Trace context travels to the server in the W3C "traceparent" and
+// "tracestate" request headers, so server-side query logs can be joined
+// with client traces. A statement's query ID is sent in the "gwp-query-id"
+// header, for proxies and servers that log it; the protocol does not define
+// this header and the server does not report query IDs back.
+const (
+	traceparentHeaderKey = "traceparent"
+	tracestateHeaderKey  = "tracestate"
+	queryIDKey           = "gwp-query-id"
+)
+
+type traceContextKey struct{}
+
+type traceContext struct {
+	traceparent string
+	tracestate  string
+}
+
+// ContextWithTraceParent returns a copy of ctx carrying a W3C trace context,
+// which is sent with every RPC made with the returned context. tracestate
+// may be empty.
+//
+// Applications using a tracing library can instead set
+// ConnectionConfig.TraceMetadata to extract the headers from the library's
+// span context.
+func ContextWithTraceParent(ctx context.Context, traceparent, tracestate string) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, traceContext{traceparent: traceparent, tracestate: tracestate})
+}
+
+// traceMetadataFromContext is the default ConnectionConfig.TraceMetadata.
+func traceMetadataFromContext(ctx context.Context) map[string]string {
+	tc, ok := ctx.Value(traceContextKey{}).(traceContext)
+	if !ok || tc.traceparent == "" {
+		return nil
+	}
+	md := map[string]string{traceparentHeaderKey: tc.traceparent}
+	if tc.tracestate != "" {
+		md[tracestateHeaderKey] = tc.tracestate
+	}
+	return md
+}
+
+// withTraceMetadata attaches the headers extract returns for ctx, skipping
+// keys already present in the outgoing metadata, such as those set by a
+// tracing interceptor.
+func withTraceMetadata(ctx context.Context, extract func(context.Context) map[string]string) context.Context {
+	headers := extract(ctx)
+	if len(headers) == 0 {
+		return ctx
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	kv := make([]string, 0, 2*len(headers))
+	for k, v := range headers {
+		if v != "" && len(md.Get(k)) == 0 {
+			kv = append(kv, k, v)
+		}
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// traceDialOptions installs interceptors propagating trace metadata on every
+// RPC of the connection.
+func traceDialOptions(extract func(context.Context) map[string]string) []grpc.DialOption {
+	if extract == nil {
+		extract = traceMetadataFromContext
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(withTraceMetadata(ctx, extract), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(withTraceMetadata(ctx, extract), desc, cc, method, opts...)
+		}),
+	}
+}
+
+// QueryID returns the ID set with WithQueryID, or "" if there is none. It
+// is client-side only: the server does not assign or report query IDs.
+func (s *ResultSummary) QueryID() string {
+	return s.queryID
+}
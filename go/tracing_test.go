@@ -0,0 +1,186 @@
+package gwp
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataServer records the request metadata of Ping and Execute.
+type metadataServer struct {
+	handshakeServer
+	pb.UnimplementedGqlServiceServer
+
+	mu   sync.Mutex
+	seen []metadata.MD
+}
+
+func (s *metadataServer) record(ctx context.Context) metadata.MD {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	s.seen = append(s.seen, md)
+	s.mu.Unlock()
+	return md
+}
+
+func (s *metadataServer) Ping(ctx context.Context, r *pb.PingRequest) (*pb.PongResponse, error) {
+	s.record(ctx)
+	return &pb.PongResponse{Timestamp: 1}, nil
+}
+
+func (s *metadataServer) Execute(r *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteResponse]) error {
+	s.record(stream.Context())
+	return stream.Send(summaryFrame(Success, 0))
+}
+
+func (s *metadataServer) last() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seen[len(s.seen)-1]
+}
+
+func startMetadataServer(t *testing.T, config ConnectionConfig) (*metadataServer, *GqlSession) {
+	t.Helper()
+	ms := &metadataServer{}
+	s, err := connectBufconn(t, serveBufconn(t, ms), config).CreateSession(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ms, s
+}
+
+func TestTraceParentPropagation(t *testing.T) {
+	ms, s := startMetadataServer(t, ConnectionConfig{})
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	ctx := ContextWithTraceParent(context.Background(), traceparent, "vendor=1")
+	if _, err := s.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	md := ms.last()
+	if got := md.Get("traceparent"); len(got) != 1 || got[0] != traceparent {
+		t.Fatalf("traceparent = %v", got)
+	}
+	if got := md.Get("tracestate"); len(got) != 1 || got[0] != "vendor=1" {
+		t.Fatalf("tracestate = %v", got)
+	}
+
+	// Headers set by the caller are not replaced.
+	ctx = metadata.AppendToOutgoingContext(ctx, "traceparent", "caller")
+	if _, err := s.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := ms.last().Get("traceparent"); len(got) != 1 || got[0] != "caller" {
+		t.Fatalf("traceparent = %v, want caller's", got)
+	}
+
+	if _, err := s.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := ms.last().Get("traceparent"); len(got) != 0 {
+		t.Fatalf("traceparent = %v without trace context", got)
+	}
+}
+
+func TestCustomTraceMetadata(t *testing.T) {
+	ms, s := startMetadataServer(t, ConnectionConfig{
+		TraceMetadata: func(ctx context.Context) map[string]string {
+			return map[string]string{"x-request-id": "req-7"}
+		},
+	})
+	cursor, err := s.Execute(context.Background(), "RETURN 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cursor.Summary(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ms.last().Get("x-request-id"); len(got) != 1 || got[0] != "req-7" {
+		t.Fatalf("x-request-id = %v", got)
+	}
+}
+
+func TestQueryID(t *testing.T) {
+	ms, s := startMetadataServer(t, ConnectionConfig{})
+	ctx := context.Background()
+
+	var seen string
+	s.interceptors = interceptorChain{StatementInterceptorFuncs{Before: func(ctx context.Context, info *StatementInfo) (context.Context, error) {
+		seen = info.QueryID
+		return ctx, nil
+	}}}
+	cursor, err := s.Execute(ctx, "RETURN 1", nil, WithQueryID("client-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := cursor.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.QueryID() != "client-1" || seen != "client-1" {
+		t.Fatalf("QueryID = %q, interceptor saw %q", summary.QueryID(), seen)
+	}
+	if got := ms.last().Get(queryIDKey); len(got) != 1 || got[0] != "client-1" {
+		t.Fatalf("%s header = %v", queryIDKey, got)
+	}
+
+	cursor, err = s.Execute(ctx, "RETURN 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary, err = cursor.Summary(); err != nil || summary.QueryID() != "" {
+		t.Fatalf("QueryID without WithQueryID = %q, %v", summary.QueryID(), err)
+	}
+	if got := ms.last().Get(queryIDKey); len(got) != 0 {
+		t.Fatalf("%s header without WithQueryID = %v", queryIDKey, got)
+	}
+}
+
+func TestConfiguredInterceptors(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var calls []string
+	unary := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			calls = append(calls, name+" "+path.Base(method)+" "+strings.Join(md.Get("traceparent"), ","))
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+	ms, s := startMetadataServer(t, ConnectionConfig{
+		UnaryInterceptors: []grpc.UnaryClientInterceptor{unary("first"), unary("second")},
+		StreamInterceptors: []grpc.StreamClientInterceptor{func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			calls = append(calls, "stream "+path.Base(method))
+			return streamer(metadata.AppendToOutgoingContext(ctx, "x-tenant", "acme"), desc, cc, method, opts...)
+		}},
+	})
+
+	ctx := ContextWithTraceParent(context.Background(), traceparent, "")
+	if _, err := s.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := s.Execute(ctx, "RETURN 1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cursor.Summary(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"first Handshake ", "second Handshake ",
+		"first Ping " + traceparent, "second Ping " + traceparent,
+		"stream Execute",
+	}
+	if strings.Join(calls, "|") != strings.Join(want, "|") {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+	if got := ms.last().Get("x-tenant"); len(got) != 1 {
+		t.Fatalf("x-tenant = %v", got)
+	}
+}
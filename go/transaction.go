@@ -2,17 +2,39 @@ package gwp
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// backgroundRollbackTimeout bounds the rollback of an abandoned transaction.
+const backgroundRollbackTimeout = 10 * time.Second
+
 // Transaction is an explicit transaction within a session.
+//
+// If the context of Execute, of reading one of its cursors, or of Commit is
+// cancelled or times out, or the Commit call fails, the transaction is rolled
+// back in the background and further statements in it are rejected.
 type Transaction struct {
+	session       *GqlSession
 	sessionID     string
 	transactionID string
 	gqlClient     pb.GqlServiceClient
-	committed     bool
-	rolledBack    bool
+	readOnly      bool
+	metadata      []string
+	started       time.Time
+
+	mu         sync.Mutex
+	committed  bool
+	rolledBack bool
+	abandoned  bool
+	released   bool
+	// rollbackDone is closed once a background rollback has finished.
+	rollbackDone chan struct{}
 }
 
 // TransactionID returns the transaction identifier.
@@ -21,60 +43,156 @@ func (t *Transaction) TransactionID() string {
 }
 
 // Execute executes a statement within this transaction.
-func (t *Transaction) Execute(ctx context.Context, statement string, params map[string]any) (*ResultCursor, error) {
-	protoParams := make(map[string]*pb.Value, len(params))
-	for k, v := range params {
-		protoParams[k] = valueToProto(v)
+func (t *Transaction) Execute(ctx context.Context, statement string, params map[string]any, opts ...ExecuteOption) (*ResultCursor, error) {
+	t.mu.Lock()
+	abandoned := t.abandoned
+	t.mu.Unlock()
+	if abandoned {
+		return nil, errTransactionAbandoned
 	}
 
+	if t.readOnly {
+		opts = append(opts[:len(opts):len(opts)], inReadOnlyTransaction())
+	}
+	if len(t.metadata) > 0 {
+		opts = append(opts[:len(opts):len(opts)], WithCallMetadata(t.metadata...))
+	}
 	txID := t.transactionID
-	stream, err := t.gqlClient.Execute(ctx, &pb.ExecuteRequest{
-		SessionId:     t.sessionID,
-		Statement:     statement,
-		Parameters:    protoParams,
-		TransactionId: &txID,
-	})
+	cursor, err := t.session.execute(ctx, &txID, statement, params, opts)
 	if err != nil {
+		if isCancellation(ctx, err) {
+			t.rollbackInBackground(ctx)
+		}
 		return nil, err
 	}
-
-	return newResultCursor(stream), nil
+	cursor.onDone = append(cursor.onDone, func(err error) {
+		if err != nil && isCancellation(ctx, err) {
+			t.rollbackInBackground(ctx)
+		}
+	})
+	return cursor, nil
 }
 
 // Commit commits the transaction.
 func (t *Transaction) Commit(ctx context.Context) error {
-	resp, err := t.gqlClient.Commit(ctx, &pb.CommitRequest{
+	resp, err := t.gqlClient.Commit(t.withMetadata(ctx), &pb.CommitRequest{
 		SessionId:     t.sessionID,
 		TransactionId: t.transactionID,
 	})
 	if err != nil {
+		t.rollbackInBackground(ctx)
 		return err
 	}
-	t.committed = true
-
 	if resp.Status != nil && IsException(resp.Status.Code) {
-		return &GqlStatusError{Code: resp.Status.Code, Message: resp.Status.Message}
+		return newStatusError(resp.Status.Code, resp.Status.Message)
 	}
+	t.mu.Lock()
+	t.committed = true
+	t.mu.Unlock()
+	t.release()
 	return nil
 }
 
 // Rollback rolls back the transaction. No-op after commit or previous rollback.
 func (t *Transaction) Rollback(ctx context.Context) error {
-	if t.committed || t.rolledBack {
+	t.mu.Lock()
+	finished := t.committed || t.rolledBack
+	t.mu.Unlock()
+	if finished {
 		return nil
 	}
 
-	resp, err := t.gqlClient.Rollback(ctx, &pb.RollbackRequest{
+	resp, err := t.gqlClient.Rollback(t.withMetadata(ctx), &pb.RollbackRequest{
 		SessionId:     t.sessionID,
 		TransactionId: t.transactionID,
 	})
+	t.release()
 	if err != nil {
 		return err
 	}
+	t.mu.Lock()
 	t.rolledBack = true
+	t.mu.Unlock()
 
 	if resp.Status != nil && IsException(resp.Status.Code) {
-		return &GqlStatusError{Code: resp.Status.Code, Message: resp.Status.Message}
+		return newStatusError(resp.Status.Code, resp.Status.Message)
 	}
 	return nil
 }
+
+// rollbackInBackground abandons the transaction and rolls it back with a
+// context detached from ctx's cancellation. It is a no-op if the transaction
+// has already finished.
+func (t *Transaction) rollbackInBackground(ctx context.Context) {
+	t.mu.Lock()
+	if t.committed || t.rolledBack {
+		t.mu.Unlock()
+		return
+	}
+	t.rolledBack = true
+	t.abandoned = true
+	done := make(chan struct{})
+	t.rollbackDone = done
+	t.mu.Unlock()
+	t.release()
+
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundRollbackTimeout)
+		defer cancel()
+		t.gqlClient.Rollback(t.withMetadata(ctx), &pb.RollbackRequest{
+			SessionId:     t.sessionID,
+			TransactionId: t.transactionID,
+		})
+	}()
+}
+
+// rollbackAndWait rolls the transaction back unless it has finished, with a
+// context detached from ctx's cancellation, and waits for a rollback
+// running in the background. The server allows one transaction per
+// session, so a transaction is replayed only once the previous attempt is
+// gone.
+func (t *Transaction) rollbackAndWait(ctx context.Context) {
+	t.mu.Lock()
+	done := t.rollbackDone
+	t.mu.Unlock()
+	if done != nil {
+		<-done
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundRollbackTimeout)
+	defer cancel()
+	t.Rollback(ctx)
+}
+
+// withMetadata attaches the transaction's WithTransactionMetadata headers
+// to ctx.
+func (t *Transaction) withMetadata(ctx context.Context) context.Context {
+	if len(t.metadata) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, t.metadata...)
+}
+
+// release marks the transaction finished for a connection shutting down.
+func (t *Transaction) release() {
+	t.mu.Lock()
+	released := t.released
+	t.released = true
+	t.mu.Unlock()
+	if released || t.session == nil || t.session.conn == nil {
+		return
+	}
+	t.session.conn.trackTransaction(t, false)
+	t.session.conn.endWork()
+}
+
+// isCancellation reports whether err was caused by ctx being cancelled or
+// timing out, locally or as reported by the server.
+func isCancellation(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	code := status.Code(err)
+	return code == codes.Canceled || code == codes.DeadlineExceeded
+}
@@ -0,0 +1,229 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeGqlClient serves Execute from a fixed stream and records rollbacks.
+type fakeGqlClient struct {
+	pb.GqlServiceClient
+	stream    *fakeStream
+	execErr   error
+	commitErr error
+	// commitStatus, if set, is the status of every commit.
+	commitStatus *pb.GqlStatus
+	rollbacks    chan string
+	lastReq      *pb.ExecuteRequest
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	*fakeStream
+}
+
+func (s *fakeClientStream) Trailer() metadata.MD {
+	return nil
+}
+
+func (c *fakeGqlClient) Execute(ctx context.Context, in *pb.ExecuteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.ExecuteResponse], error) {
+	c.lastReq = in
+	if c.execErr != nil {
+		return nil, c.execErr
+	}
+	return &fakeClientStream{fakeStream: c.stream}, nil
+}
+
+func (c *fakeGqlClient) Commit(ctx context.Context, in *pb.CommitRequest, opts ...grpc.CallOption) (*pb.CommitResponse, error) {
+	if c.commitErr != nil {
+		return nil, c.commitErr
+	}
+	return &pb.CommitResponse{Status: c.commitStatus}, nil
+}
+
+func (c *fakeGqlClient) Rollback(ctx context.Context, in *pb.RollbackRequest, opts ...grpc.CallOption) (*pb.RollbackResponse, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	c.rollbacks <- in.TransactionId
+	return &pb.RollbackResponse{}, nil
+}
+
+func newFakeTransaction(client *fakeGqlClient) *Transaction {
+	client.rollbacks = make(chan string, 4)
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+	return &Transaction{session: s, sessionID: "s1", transactionID: "tx1", gqlClient: client}
+}
+
+func expectRollback(t *testing.T, client *fakeGqlClient) {
+	t.Helper()
+	select {
+	case id := <-client.rollbacks:
+		if id != "tx1" {
+			t.Fatalf("rolled back %q, want tx1", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("transaction was not rolled back")
+	}
+}
+
+func TestTransactionRollbackOnCancelledExecute(t *testing.T) {
+	client := &fakeGqlClient{execErr: status.Error(codes.Canceled, "context canceled")}
+	tx := newFakeTransaction(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tx.Execute(ctx, "MATCH (n) RETURN n", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	expectRollback(t, client)
+
+	_, err := tx.Execute(context.Background(), "MATCH (n) RETURN n", nil)
+	if !errors.Is(err, errTransactionAbandoned) {
+		t.Fatalf("Execute after abandon = %v", err)
+	}
+	if err := tx.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback after abandon: %v", err)
+	}
+	if len(client.rollbacks) != 0 {
+		t.Fatal("explicit Rollback after abandon should be a no-op")
+	}
+}
+
+func TestTransactionRollbackOnCancelledStream(t *testing.T) {
+	client := &fakeGqlClient{stream: &fakeStream{
+		frames: []*pb.ExecuteResponse{headerFrame("n")},
+		err:    status.Error(codes.DeadlineExceeded, "deadline exceeded"),
+	}}
+	tx := newFakeTransaction(client)
+
+	cursor, err := tx.Execute(context.Background(), "MATCH (n) RETURN n", nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if _, err := cursor.CollectRows(); err == nil {
+		t.Fatal("expected stream error")
+	}
+	expectRollback(t, client)
+}
+
+func TestTransactionRollbackOnFailedCommit(t *testing.T) {
+	client := &fakeGqlClient{commitErr: status.Error(codes.Unavailable, "connection reset")}
+	tx := newFakeTransaction(client)
+
+	if err := tx.Commit(context.Background()); err == nil {
+		t.Fatal("expected commit error")
+	}
+	expectRollback(t, client)
+}
+
+func TestTransactionRejectedCommit(t *testing.T) {
+	client := &fakeGqlClient{commitStatus: &pb.GqlStatus{Code: TransactionRollback, Message: "serialization conflict"}}
+	tx := newFakeTransaction(client)
+
+	if err := tx.Commit(context.Background()); !IsTransient(err) {
+		t.Fatalf("Commit = %v, want a transient error", err)
+	}
+	if tx.Status() != TransactionOpen {
+		t.Fatalf("Status after rejected commit = %s, want open", tx.Status())
+	}
+	if err := tx.Rollback(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expectRollback(t, client)
+}
+
+func TestTransactionKeptOnStatementError(t *testing.T) {
+	client := &fakeGqlClient{stream: &fakeStream{err: status.Error(codes.InvalidArgument, "syntax error")}}
+	tx := newFakeTransaction(client)
+
+	cursor, err := tx.Execute(context.Background(), "MATCH", nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if _, err := cursor.CollectRows(); err == nil {
+		t.Fatal("expected stream error")
+	}
+	if _, err := tx.Execute(context.Background(), "MATCH (n) RETURN n", nil); errors.Is(err, errTransactionAbandoned) {
+		t.Fatal("transaction should remain usable after a statement error")
+	}
+	if len(client.rollbacks) != 0 {
+		t.Fatal("unexpected rollback")
+	}
+}
+
+// metadataGqlClient records the outgoing metadata of each RPC by method.
+type metadataGqlClient struct {
+	pb.GqlServiceClient
+	md map[string]metadata.MD
+}
+
+func (c *metadataGqlClient) record(ctx context.Context, method string) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if c.md == nil {
+		c.md = make(map[string]metadata.MD)
+	}
+	c.md[method] = md
+}
+
+func (c *metadataGqlClient) BeginTransaction(ctx context.Context, in *pb.BeginRequest, opts ...grpc.CallOption) (*pb.BeginResponse, error) {
+	c.record(ctx, "Begin")
+	return &pb.BeginResponse{TransactionId: "tx1"}, nil
+}
+
+func (c *metadataGqlClient) Execute(ctx context.Context, in *pb.ExecuteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.ExecuteResponse], error) {
+	c.record(ctx, "Execute")
+	return &fakeClientStream{fakeStream: &fakeStream{}}, nil
+}
+
+func (c *metadataGqlClient) Commit(ctx context.Context, in *pb.CommitRequest, opts ...grpc.CallOption) (*pb.CommitResponse, error) {
+	c.record(ctx, "Commit")
+	return &pb.CommitResponse{}, nil
+}
+
+func TestCallMetadata(t *testing.T) {
+	ctx := context.Background()
+	client := &metadataGqlClient{}
+	s := &GqlSession{sessionID: "s1", gqlClient: client}
+
+	if _, err := s.Execute(ctx, "RETURN 1", nil, WithCallMetadata("X-Route", "b")); err != nil {
+		t.Fatal(err)
+	}
+	if got := client.md["Execute"].Get("x-route"); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("x-route = %v", got)
+	}
+
+	tx, err := s.BeginTransaction(ctx, false, WithTransactionMetadata("x-tenant", "acme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Execute(ctx, "RETURN 1", nil, WithCallMetadata("x-route", "c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"Begin", "Execute", "Commit"} {
+		if got := client.md[method].Get("x-tenant"); len(got) != 1 || got[0] != "acme" {
+			t.Errorf("%s x-tenant = %v", method, got)
+		}
+	}
+	if got := client.md["Execute"].Get("x-route"); len(got) != 1 || got[0] != "c" {
+		t.Errorf("transaction statement x-route = %v", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("odd key-value count did not panic")
+		}
+	}()
+	WithCallMetadata("x-route")
+}
@@ -0,0 +1,122 @@
+package gwp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// TranscriptConfig holds configuration for a TranscriptRecorder.
+type TranscriptConfig struct {
+	// Sanitizer masks literals in recorded statements and redacts
+	// sensitive parameters. Defaults to NewSanitizer(RedactionPolicy{}).
+	Sanitizer *Sanitizer
+	// OmitParams records parameters by type only, without their values.
+	OmitParams bool
+	// MaxEntries, if positive, stops recording after that many
+	// statements, bounding the transcript of a long-running session.
+	MaxEntries int
+}
+
+// TranscriptEntry is one statement in a transcript.
+type TranscriptEntry struct {
+	Time          time.Time      `json:"time"`
+	SessionID     string         `json:"session_id"`
+	TransactionID string         `json:"transaction_id,omitempty"`
+	QueryID       string         `json:"query_id,omitempty"`
+	Statement     string         `json:"statement"`
+	Params        map[string]any `json:"params,omitempty"`
+	// Duration is in nanoseconds.
+	Duration time.Duration `json:"duration_ns"`
+	Status   string        `json:"status,omitempty"`
+	Rows     int64         `json:"rows"`
+	Frames   int64         `json:"frames"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// TranscriptRecorder is a StatementInterceptor that writes a transcript of
+// the statements it sees, one JSON TranscriptEntry per line, for attaching
+// to bug reports against a server. Statements and parameters pass through
+// a Sanitizer before they are written. Add it to a session with
+// WithInterceptors, or to ConnectionConfig.Interceptors to record every
+// session. It is safe for concurrent use.
+type TranscriptRecorder struct {
+	config TranscriptConfig
+
+	mu      sync.Mutex
+	w       io.Writer
+	entries int
+	err     error
+}
+
+// NewTranscriptRecorder returns a TranscriptRecorder writing to w.
+func NewTranscriptRecorder(w io.Writer, config TranscriptConfig) *TranscriptRecorder {
+	if config.Sanitizer == nil {
+		config.Sanitizer = NewSanitizer(RedactionPolicy{})
+	}
+	return &TranscriptRecorder{config: config, w: w}
+}
+
+// Err returns the first error writing the transcript. Recording stops
+// after a write error.
+func (r *TranscriptRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// BeforeExecute implements StatementInterceptor.
+func (r *TranscriptRecorder) BeforeExecute(ctx context.Context, info *StatementInfo) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterExecute implements StatementInterceptor.
+func (r *TranscriptRecorder) AfterExecute(ctx context.Context, info *StatementInfo, result StatementResult) {
+	entry := TranscriptEntry{
+		Time:          time.Now().Add(-result.Duration).UTC(),
+		SessionID:     info.SessionID,
+		TransactionID: info.TransactionID,
+		QueryID:       info.QueryID,
+		Statement:     r.config.Sanitizer.Statement(info.Statement),
+		Duration:      result.Duration,
+		Rows:          result.Rows,
+		Frames:        result.Frames,
+	}
+	if len(info.Params) > 0 {
+		if r.config.OmitParams {
+			entry.Params = paramTypes(info.Params)
+		} else {
+			entry.Params = r.config.Sanitizer.Params(info.Params)
+		}
+	}
+	if result.Summary != nil {
+		entry.Status = result.Summary.StatusCode()
+	}
+	if result.Err != nil {
+		entry.Error = result.Err.Error()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		// Record parameters that do not encode as JSON by type.
+		entry.Params = paramTypes(info.Params)
+		data, _ = json.Marshal(entry)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil || (r.config.MaxEntries > 0 && r.entries >= r.config.MaxEntries) {
+		return
+	}
+	r.entries++
+	_, r.err = r.w.Write(append(data, '\n'))
+}
+
+func paramTypes(params map[string]any) map[string]any {
+	out := make(map[string]any, len(params))
+	for k, v := range sanitizeParams(params) {
+		out[k] = v
+	}
+	return out
+}
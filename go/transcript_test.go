@@ -0,0 +1,105 @@
+package gwp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readTranscript(t *testing.T, data []byte) []TranscriptEntry {
+	t.Helper()
+	var entries []TranscriptEntry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		var e TranscriptEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestTranscriptRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewTranscriptRecorder(&buf, TranscriptConfig{MaxEntries: 2})
+	ctx := context.Background()
+	info := &StatementInfo{
+		SessionID: "s1",
+		Statement: "MATCH (u:User {name: 'alice'}) WHERE u.age > 30 RETURN u",
+		Params:    map[string]any{"password": "hunter2", "limit": int64(10), "fn": func() {}},
+	}
+	r.AfterExecute(ctx, info, StatementResult{
+		Duration: 5 * time.Millisecond, Rows: 3, Frames: 3,
+		Summary: &ResultSummary{proto: summaryFrame(Success, 0).GetSummary()},
+	})
+	r.AfterExecute(ctx, &StatementInfo{SessionID: "s1", TransactionID: "tx1", Statement: "RETURN 1", Params: map[string]any{"token": "abc"}},
+		StatementResult{Err: errors.New("stream reset")})
+	r.AfterExecute(ctx, &StatementInfo{SessionID: "s1", Statement: "RETURN 2"}, StatementResult{})
+
+	entries := readTranscript(t, buf.Bytes())
+	if len(entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2", len(entries))
+	}
+	first := entries[0]
+	if strings.Contains(first.Statement, "alice") || first.Status != Success || first.Rows != 3 || first.Frames != 3 {
+		t.Fatalf("first entry = %+v", first)
+	}
+	if first.Duration != 5*time.Millisecond {
+		t.Fatalf("duration = %v", first.Duration)
+	}
+	if first.Params["fn"] != "func()" || first.Params["password"] != "string" {
+		t.Fatalf("params = %v", first.Params)
+	}
+	second := entries[1]
+	if second.Error != "stream reset" || second.TransactionID != "tx1" || second.Params["token"] != "[REDACTED]" {
+		t.Fatalf("second entry = %+v", second)
+	}
+	if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "abc") {
+		t.Fatalf("transcript leaks secrets: %s", buf.String())
+	}
+}
+
+func TestTranscriptOmitParams(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewTranscriptRecorder(&buf, TranscriptConfig{OmitParams: true})
+	r.AfterExecute(context.Background(), &StatementInfo{Statement: "RETURN $name", Params: map[string]any{"name": "Alice"}}, StatementResult{})
+	entries := readTranscript(t, buf.Bytes())
+	if len(entries) != 1 || entries[0].Params["name"] != "string" {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
+
+func TestTranscriptSession(t *testing.T) {
+	requireTestServer(t)
+	ctx := context.Background()
+	conn, err := Connect(ctx, testEndpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	var buf bytes.Buffer
+	r := NewTranscriptRecorder(&buf, TranscriptConfig{})
+	session, err := conn.CreateSession(ctx, WithInterceptors(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close(ctx)
+
+	cursor, err := session.Execute(ctx, "MATCH (n) RETURN n", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cursor.CollectRows(); err != nil {
+		t.Fatal(err)
+	}
+	entries := readTranscript(t, buf.Bytes())
+	if len(entries) != 1 || entries[0].Frames < 2 || entries[0].SessionID == "" || r.Err() != nil {
+		t.Fatalf("entries = %+v, err %v", entries, r.Err())
+	}
+}
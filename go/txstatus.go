@@ -0,0 +1,103 @@
+package gwp
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// TransactionStatus is the state of a transaction as known to the client.
+type TransactionStatus int
+
+const (
+	// TransactionOpen is a transaction that has not been committed or
+	// rolled back.
+	TransactionOpen TransactionStatus = iota
+	TransactionCommitted
+	TransactionRolledBack
+	// TransactionAbandoned is a transaction rolled back by the client
+	// after a cancelled statement or commit, or by KillTransaction.
+	TransactionAbandoned
+)
+
+func (s TransactionStatus) String() string {
+	switch s {
+	case TransactionOpen:
+		return "open"
+	case TransactionCommitted:
+		return "committed"
+	case TransactionRolledBack:
+		return "rolled back"
+	case TransactionAbandoned:
+		return "abandoned"
+	}
+	return "unknown"
+}
+
+// Status returns the state of the transaction as known to the client. The
+// wire protocol has no RPC to look a transaction up on the server, so a
+// transaction the server ended on its own, for example after a timeout,
+// is reported open until a statement or Commit in it fails.
+func (t *Transaction) Status() TransactionStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case t.committed:
+		return TransactionCommitted
+	case t.abandoned:
+		return TransactionAbandoned
+	case t.rolledBack:
+		return TransactionRolledBack
+	}
+	return TransactionOpen
+}
+
+// TransactionInfo describes an open transaction.
+type TransactionInfo struct {
+	SessionID     string
+	TransactionID string
+	ReadOnly      bool
+	Started       time.Time
+}
+
+// Transactions returns the open transactions begun through the connection,
+// oldest first. The server's transactions of other clients are not
+// listed, as the wire protocol has no RPC for them.
+func (c *GqlConnection) Transactions() []TransactionInfo {
+	c.mu.Lock()
+	infos := make([]TransactionInfo, 0, len(c.transactions))
+	for t := range c.transactions {
+		infos = append(infos, TransactionInfo{
+			SessionID:     t.sessionID,
+			TransactionID: t.transactionID,
+			ReadOnly:      t.readOnly,
+			Started:       t.started,
+		})
+	}
+	c.mu.Unlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Started.Before(infos[j].Started) })
+	return infos
+}
+
+// KillTransaction rolls back an open transaction begun through the
+// connection, such as one found stuck with Transactions. Further
+// statements in it fail as for an abandoned transaction. It returns a
+// *TransactionError if the connection has no such open transaction.
+func (c *GqlConnection) KillTransaction(ctx context.Context, transactionID string) error {
+	var tx *Transaction
+	c.mu.Lock()
+	for t := range c.transactions {
+		if t.transactionID == transactionID {
+			tx = t
+			break
+		}
+	}
+	c.mu.Unlock()
+	if tx == nil {
+		return &TransactionError{Message: "no open transaction " + transactionID}
+	}
+	tx.mu.Lock()
+	tx.abandoned = true
+	tx.mu.Unlock()
+	return tx.Rollback(ctx)
+}
@@ -0,0 +1,78 @@
+package gwp
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+)
+
+// txClient begins transactions with sequential IDs and records rollbacks.
+type txClient struct {
+	pb.GqlServiceClient
+	begun      int
+	rolledBack []string
+}
+
+func (c *txClient) BeginTransaction(ctx context.Context, in *pb.BeginRequest, opts ...grpc.CallOption) (*pb.BeginResponse, error) {
+	c.begun++
+	return &pb.BeginResponse{TransactionId: "tx" + strconv.Itoa(c.begun)}, nil
+}
+
+func (c *txClient) Commit(ctx context.Context, in *pb.CommitRequest, opts ...grpc.CallOption) (*pb.CommitResponse, error) {
+	return &pb.CommitResponse{}, nil
+}
+
+func (c *txClient) Rollback(ctx context.Context, in *pb.RollbackRequest, opts ...grpc.CallOption) (*pb.RollbackResponse, error) {
+	c.rolledBack = append(c.rolledBack, in.TransactionId)
+	return &pb.RollbackResponse{}, nil
+}
+
+func TestTransactionStatusAndKill(t *testing.T) {
+	ctx := context.Background()
+	client := &txClient{}
+	conn := &GqlConnection{}
+	s := &GqlSession{sessionID: "s1", gqlClient: client, conn: conn}
+
+	committed, _ := s.BeginTransaction(ctx, false)
+	stuck, _ := s.BeginTransaction(ctx, true)
+	rolledBack, _ := s.BeginTransaction(ctx, false)
+	if committed.Status() != TransactionOpen {
+		t.Fatalf("Status = %v, want open", committed.Status())
+	}
+	if err := committed.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := rolledBack.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if committed.Status() != TransactionCommitted || rolledBack.Status() != TransactionRolledBack {
+		t.Fatalf("Status = %v, %v", committed.Status(), rolledBack.Status())
+	}
+
+	open := conn.Transactions()
+	if len(open) != 1 || open[0].TransactionID != "tx2" || !open[0].ReadOnly || open[0].SessionID != "s1" {
+		t.Fatalf("Transactions = %+v", open)
+	}
+
+	if err := conn.KillTransaction(ctx, "tx2"); err != nil {
+		t.Fatal(err)
+	}
+	if stuck.Status() != TransactionAbandoned || len(conn.Transactions()) != 0 {
+		t.Fatalf("after kill: Status = %v, Transactions = %+v", stuck.Status(), conn.Transactions())
+	}
+	if len(client.rolledBack) != 2 || client.rolledBack[1] != "tx2" {
+		t.Fatalf("rolled back %v", client.rolledBack)
+	}
+	if _, err := stuck.Execute(ctx, "RETURN 1", nil); !errors.Is(err, errTransactionAbandoned) {
+		t.Fatalf("Execute after kill = %v", err)
+	}
+
+	var te *TransactionError
+	if err := conn.KillTransaction(ctx, "tx2"); !errors.As(err, &te) {
+		t.Fatalf("second kill = %v", err)
+	}
+}
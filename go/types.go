@@ -1,10 +1,20 @@
 package gwp
 
+import (
+	"sort"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
 // GqlNode is a property graph node.
 type GqlNode struct {
 	ID         []byte
 	Labels     []string
 	Properties map[string]any
+
+	// rawProperties holds the undecoded properties of a node read with
+	// WithLazyProperties until PropertyMap decodes them.
+	rawProperties map[string]*pb.Value
 }
 
 // HasLabel checks if the node has the given label.
@@ -17,6 +27,12 @@ func (n *GqlNode) HasLabel(label string) bool {
 	return false
 }
 
+// SortedProperties returns the node's properties ordered by name, for
+// output that must not depend on map iteration order.
+func (n *GqlNode) SortedProperties() []GqlField {
+	return sortedFields(n.PropertyMap())
+}
+
 // GqlEdge is a property graph edge.
 type GqlEdge struct {
 	ID           []byte
@@ -25,6 +41,10 @@ type GqlEdge struct {
 	TargetNodeID []byte
 	Undirected   bool
 	Properties   map[string]any
+
+	// rawProperties holds the undecoded properties of an edge read with
+	// WithLazyProperties until PropertyMap decodes them.
+	rawProperties map[string]*pb.Value
 }
 
 // HasLabel checks if the edge has the given label.
@@ -37,6 +57,23 @@ func (e *GqlEdge) HasLabel(label string) bool {
 	return false
 }
 
+// SortedProperties returns the edge's properties ordered by name, for
+// output that must not depend on map iteration order.
+func (e *GqlEdge) SortedProperties() []GqlField {
+	return sortedFields(e.PropertyMap())
+}
+
+// sortedFields returns the entries of m as fields ordered by name. Property
+// maps carry no order on the wire, so name order is the only stable one.
+func sortedFields(m map[string]any) []GqlField {
+	fields := make([]GqlField, 0, len(m))
+	for name, v := range m {
+		fields = append(fields, GqlField{Name: name, Value: v})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
 // GqlPath is an alternating sequence of nodes and edges.
 type GqlPath struct {
 	Nodes []*GqlNode
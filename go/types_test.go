@@ -54,3 +54,18 @@ func TestRecordGet(t *testing.T) {
 		t.Fatal("expected z=nil")
 	}
 }
+
+func TestSortedProperties(t *testing.T) {
+	node := &GqlNode{Properties: map[string]any{"name": "Alice", "age": int64(30), "city": "Oslo"}}
+	fields := node.SortedProperties()
+	if len(fields) != 3 || fields[0].Name != "age" || fields[1].Name != "city" || fields[2].Name != "name" {
+		t.Fatalf("SortedProperties = %v", fields)
+	}
+	if fields[0].Value != int64(30) {
+		t.Fatalf("age = %v", fields[0].Value)
+	}
+	edge := &GqlEdge{}
+	if fields := edge.SortedProperties(); len(fields) != 0 {
+		t.Fatalf("SortedProperties of edge without properties = %v", fields)
+	}
+}
@@ -0,0 +1,186 @@
+package gwp
+
+import (
+	"context"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+)
+
+// GqlVector is an embedding vector. It is sent as a list of floats, the
+// representation GWP servers use for vector properties.
+type GqlVector []float32
+
+// AsVector converts a vector-like value to a GqlVector. It accepts
+// GqlVector, []float32, []float64, and the []any of numbers a vector
+// property decodes to.
+func AsVector(v any) (GqlVector, bool) {
+	switch v := v.(type) {
+	case GqlVector:
+		return v, true
+	case []float32:
+		return GqlVector(v), true
+	case []float64:
+		out := make(GqlVector, len(v))
+		for i, f := range v {
+			out[i] = float32(f)
+		}
+		return out, true
+	case []any:
+		out := make(GqlVector, len(v))
+		for i, e := range v {
+			switch n := e.(type) {
+			case float64:
+				out[i] = float32(n)
+			case int64:
+				out[i] = float32(n)
+			default:
+				return nil, false
+			}
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+func vectorToProto(v []float32) *pb.Value {
+	elems := make([]*pb.Value, len(v))
+	for i, f := range v {
+		elems[i] = &pb.Value{Kind: &pb.Value_FloatValue{FloatValue: float64(f)}}
+	}
+	return &pb.Value{Kind: &pb.Value_ListValue{ListValue: &pb.GqlList{Elements: elems}}}
+}
+
+// VectorMetric is the distance function of a vector index.
+type VectorMetric string
+
+// Vector index metrics.
+const (
+	MetricCosine     VectorMetric = "cosine"
+	MetricEuclidean  VectorMetric = "euclidean"
+	MetricDotProduct VectorMetric = "dot_product"
+	MetricManhattan  VectorMetric = "manhattan"
+)
+
+// VectorIndexConfig holds configuration for a vector index. Zero values
+// leave the setting to the server. Graph defaults to the session's current
+// graph.
+type VectorIndexConfig struct {
+	Graph          string
+	Label          string
+	Property       string
+	Dimensions     uint32
+	Metric         VectorMetric
+	M              uint32
+	EfConstruction uint32
+}
+
+func (c VectorIndexConfig) toProto() *pb.VectorIndexDef {
+	def := &pb.VectorIndexDef{Label: c.Label, Property: c.Property}
+	if c.Dimensions > 0 {
+		def.Dimensions = &c.Dimensions
+	}
+	if c.Metric != "" {
+		metric := string(c.Metric)
+		def.Metric = &metric
+	}
+	if c.M > 0 {
+		def.M = &c.M
+	}
+	if c.EfConstruction > 0 {
+		def.EfConstruction = &c.EfConstruction
+	}
+	return def
+}
+
+// CreateVectorIndex creates an HNSW vector index on a node property.
+func (s *GqlSession) CreateVectorIndex(ctx context.Context, config VectorIndexConfig) error {
+	_, err := s.adminClient.CreateIndex(ctx, &pb.CreateIndexRequest{
+		Graph: s.graphOrCurrent(config.Graph),
+		Index: &pb.CreateIndexRequest_VectorIndex{VectorIndex: config.toProto()},
+	})
+	return err
+}
+
+// DropVectorIndex drops the vector index on config.Label and
+// config.Property. Returns true if it existed.
+func (s *GqlSession) DropVectorIndex(ctx context.Context, config VectorIndexConfig) (bool, error) {
+	resp, err := s.adminClient.DropIndex(ctx, &pb.DropIndexRequest{
+		Graph: s.graphOrCurrent(config.Graph),
+		Index: &pb.DropIndexRequest_VectorIndex{VectorIndex: config.toProto()},
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.Existed, nil
+}
+
+// VectorSearchConfig holds the parameters of a k-nearest-neighbour search.
+// Graph defaults to the session's current graph.
+type VectorSearchConfig struct {
+	Graph    string
+	Label    string
+	Property string
+	Vector   GqlVector
+	K        uint32
+	// Ef is the HNSW search beam width. Zero uses the server default.
+	Ef uint32
+	// Filters restricts hits to nodes whose properties equal the given values.
+	Filters map[string]any
+}
+
+// SearchHit is a single search result.
+type SearchHit struct {
+	// NodeID is the server's internal numeric node identifier, not the
+	// element ID of a GqlNode.
+	NodeID     uint64
+	Score      float64
+	Properties map[string]any
+}
+
+// VectorSearch returns the K nodes nearest to config.Vector using a vector
+// index.
+func (s *GqlSession) VectorSearch(ctx context.Context, config VectorSearchConfig) ([]SearchHit, error) {
+	req := &pb.VectorSearchRequest{
+		Graph:       s.graphOrCurrent(config.Graph),
+		Label:       config.Label,
+		Property:    config.Property,
+		QueryVector: config.Vector,
+		K:           config.K,
+	}
+	if config.Ef > 0 {
+		req.Ef = &config.Ef
+	}
+	if len(config.Filters) > 0 {
+		var err error
+		if req.Filters, err = encodeParams(config.Filters, s.paramLimits()); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := s.searchClient.VectorSearch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return searchHitsFromProto(resp.Hits), nil
+}
+
+func searchHitsFromProto(hits []*pb.SearchHit) []SearchHit {
+	result := make([]SearchHit, len(hits))
+	for i, h := range hits {
+		props := make(map[string]any, len(h.Properties))
+		for k, v := range h.Properties {
+			props[k] = valueFromProto(v)
+		}
+		result[i] = SearchHit{NodeID: h.NodeId, Score: h.Score, Properties: props}
+	}
+	return result
+}
+
+// graphOrCurrent returns graph, or the session's current graph if empty.
+func (s *GqlSession) graphOrCurrent(graph string) string {
+	if graph != "" {
+		return graph
+	}
+	return s.currentGraph()
+}
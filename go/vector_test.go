@@ -0,0 +1,91 @@
+package gwp
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+)
+
+type fakeSearchClient struct {
+	pb.SearchServiceClient
+	req *pb.VectorSearchRequest
+}
+
+func (c *fakeSearchClient) VectorSearch(ctx context.Context, in *pb.VectorSearchRequest, opts ...grpc.CallOption) (*pb.VectorSearchResponse, error) {
+	c.req = in
+	return &pb.VectorSearchResponse{Hits: []*pb.SearchHit{
+		{NodeId: 7, Score: 0.9, Properties: map[string]*pb.Value{"name": mustValueToProto("Alice")}},
+	}}, nil
+}
+
+type fakeAdminClient struct {
+	pb.AdminServiceClient
+	created *pb.CreateIndexRequest
+}
+
+func (c *fakeAdminClient) CreateIndex(ctx context.Context, in *pb.CreateIndexRequest, opts ...grpc.CallOption) (*pb.CreateIndexResponse, error) {
+	c.created = in
+	return &pb.CreateIndexResponse{}, nil
+}
+
+func TestVectorParameters(t *testing.T) {
+	for _, v := range []any{GqlVector{1, 0.5}, []float32{1, 0.5}, []float64{1, 0.5}} {
+		list := mustValueToProto(v).GetListValue()
+		if list == nil || len(list.Elements) != 2 || list.Elements[1].GetFloatValue() != 0.5 {
+			t.Fatalf("mustValueToProto(%T) = %v", v, list)
+		}
+	}
+
+	decoded := valueFromProto(mustValueToProto(GqlVector{1, 2}))
+	vec, ok := AsVector(decoded)
+	if !ok || len(vec) != 2 || vec[1] != 2 {
+		t.Fatalf("AsVector(%v) = %v, %v", decoded, vec, ok)
+	}
+	if _, ok := AsVector([]any{"x"}); ok {
+		t.Fatal("AsVector should reject non-numeric lists")
+	}
+}
+
+func TestCreateVectorIndex(t *testing.T) {
+	admin := &fakeAdminClient{}
+	s := &GqlSession{adminClient: admin, graph: "social"}
+
+	err := s.CreateVectorIndex(context.Background(), VectorIndexConfig{
+		Label: "Doc", Property: "embedding", Dimensions: 384, Metric: MetricCosine,
+	})
+	if err != nil {
+		t.Fatalf("CreateVectorIndex: %v", err)
+	}
+	def := admin.created.GetVectorIndex()
+	if admin.created.Graph != "social" || def.Label != "Doc" || def.GetDimensions() != 384 || def.GetMetric() != "cosine" {
+		t.Fatalf("unexpected request: %v", admin.created)
+	}
+	if def.M != nil || def.EfConstruction != nil {
+		t.Fatalf("unset options should be omitted: %v", def)
+	}
+}
+
+func TestVectorSearch(t *testing.T) {
+	search := &fakeSearchClient{}
+	s := &GqlSession{searchClient: search}
+
+	hits, err := s.VectorSearch(context.Background(), VectorSearchConfig{
+		Graph: "g", Label: "Doc", Property: "embedding",
+		Vector: GqlVector{0.1, 0.2}, K: 5,
+		Filters: map[string]any{"lang": "en"},
+	})
+	if err != nil {
+		t.Fatalf("VectorSearch: %v", err)
+	}
+	if len(hits) != 1 || hits[0].NodeID != 7 || hits[0].Properties["name"] != "Alice" {
+		t.Fatalf("unexpected hits: %+v", hits)
+	}
+	if search.req.K != 5 || len(search.req.QueryVector) != 2 || search.req.Ef != nil {
+		t.Fatalf("unexpected request: %v", search.req)
+	}
+	if search.req.Filters["lang"].GetStringValue() != "en" {
+		t.Fatalf("filters not sent: %v", search.req.Filters)
+	}
+}
@@ -0,0 +1,83 @@
+package gwp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The range of GWP protocol versions the client speaks.
+const (
+	MinProtocolVersion uint32 = 1
+	MaxProtocolVersion uint32 = 1
+)
+
+// handshake creates a server session, negotiating the protocol version.
+func (c *GqlConnection) handshake(ctx context.Context) (*pb.HandshakeResponse, uint32, error) {
+	max := c.config.MaxProtocolVersion
+	if max == 0 || max > MaxProtocolVersion {
+		max = MaxProtocolVersion
+	}
+	var creds *pb.AuthCredentials
+	if c.tokens != nil {
+		var err error
+		if creds, err = c.tokens.handshakeCredentials(ctx); err != nil {
+			return nil, 0, err
+		}
+	}
+	return negotiateVersion(ctx, c.sessionClient, creds, MinProtocolVersion, max)
+}
+
+// negotiateVersion offers the highest version in [min, max] and falls back
+// to lower versions while the server rejects the one offered, since a
+// handshake carries a single version. The server answers with the version
+// it agreed to, which must not exceed the one offered; a server that leaves
+// it unset is taken to agree.
+func negotiateVersion(ctx context.Context, client pb.SessionServiceClient, creds *pb.AuthCredentials, min, max uint32) (*pb.HandshakeResponse, uint32, error) {
+	for v := max; ; v-- {
+		resp, err := client.Handshake(ctx, &pb.HandshakeRequest{ProtocolVersion: v, Credentials: creds})
+		if err != nil {
+			if v > min && isVersionRejected(err) {
+				continue
+			}
+			return nil, 0, err
+		}
+		agreed := resp.ProtocolVersion
+		if agreed == 0 {
+			agreed = v
+		}
+		if agreed < min || agreed > v {
+			if resp.SessionId != "" {
+				client.Close(ctx, &pb.CloseRequest{SessionId: resp.SessionId})
+			}
+			return nil, 0, &SessionError{Message: fmt.Sprintf(
+				"server chose protocol version %d, client supports %d to %d", agreed, min, v)}
+		}
+		return resp, agreed, nil
+	}
+}
+
+// isVersionRejected reports whether a handshake error may mean the server
+// does not speak the offered protocol version.
+func isVersionRejected(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+// ProtocolVersion returns the protocol version agreed with the server.
+func (s *GqlSession) ProtocolVersion() uint32 {
+	return s.protocolVersion
+}
+
+// Features returns the capability flags the server advertised in the
+// handshake.
+func (s *GqlSession) Features() []string {
+	return slices.Clone(s.features)
+}
@@ -0,0 +1,69 @@
+package gwp
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/GrafeoDB/gql-wire-protocol/go/gen/gql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// versionedSessionClient accepts handshakes up to version max and answers
+// with reply, or the offered version if reply is zero.
+type versionedSessionClient struct {
+	pb.SessionServiceClient
+	max     uint32
+	reply   uint32
+	offered []uint32
+	closed  []string
+}
+
+func (c *versionedSessionClient) Handshake(ctx context.Context, in *pb.HandshakeRequest, opts ...grpc.CallOption) (*pb.HandshakeResponse, error) {
+	c.offered = append(c.offered, in.ProtocolVersion)
+	if in.ProtocolVersion > c.max {
+		return nil, status.Errorf(codes.FailedPrecondition, "unsupported protocol version %d", in.ProtocolVersion)
+	}
+	reply := c.reply
+	if reply == 0 {
+		reply = in.ProtocolVersion
+	}
+	return &pb.HandshakeResponse{ProtocolVersion: reply, SessionId: "s1"}, nil
+}
+
+func (c *versionedSessionClient) Close(ctx context.Context, in *pb.CloseRequest, opts ...grpc.CallOption) (*pb.CloseResponse, error) {
+	c.closed = append(c.closed, in.SessionId)
+	return &pb.CloseResponse{}, nil
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	ctx := context.Background()
+
+	client := &versionedSessionClient{max: 1}
+	_, v, err := negotiateVersion(ctx, client, nil, 1, 3)
+	if err != nil || v != 1 {
+		t.Fatalf("negotiateVersion = %d, %v", v, err)
+	}
+	if len(client.offered) != 3 || client.offered[0] != 3 {
+		t.Fatalf("offered %v, want 3, 2, 1", client.offered)
+	}
+
+	client = &versionedSessionClient{max: 1}
+	if _, _, err := negotiateVersion(ctx, client, nil, 2, 3); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("server older than client minimum: err = %v", err)
+	}
+
+	client = &versionedSessionClient{max: 5, reply: 4}
+	if _, _, err := negotiateVersion(ctx, client, nil, 1, 2); err == nil || len(client.closed) != 1 {
+		t.Fatalf("server chose a newer version: err = %v, closed %v", err, client.closed)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	s := &GqlSession{features: []string{"role=primary", "subscriptions"}}
+	s.Features()[0] = "changed"
+	if got := s.Features(); got[0] != "role=primary" || got[1] != "subscriptions" {
+		t.Fatalf("Features = %v, want a copy", got)
+	}
+}